@@ -0,0 +1,67 @@
+-- name: CompareCatalogs :many
+-- Files under source_prefix of one bucket and target_prefix of another (or the same) bucket, joined by
+-- their key relative to the prefix. Only differing files are returned, each with its first difference:
+-- missing on one side, then size, then ETag, then storage class. Pages by offset, or by key after after_key.
+WITH source AS (
+    SELECT substr(key, length(sqlc.arg('source_prefix')::text) + 1) AS rel_key, size, COALESCE(etag, '') AS etag,
+           COALESCE(NULLIF(storage_class, ''), 'STANDARD')::text AS storage_class
+    FROM s3_objects
+    WHERE bucket_id = sqlc.arg('source_bucket_id')::integer
+      AND is_folder = FALSE
+      AND starts_with(key, sqlc.arg('source_prefix')::text)
+), target AS (
+    SELECT substr(key, length(sqlc.arg('target_prefix')::text) + 1) AS rel_key, size, COALESCE(etag, '') AS etag,
+           COALESCE(NULLIF(storage_class, ''), 'STANDARD')::text AS storage_class
+    FROM s3_objects
+    WHERE bucket_id = sqlc.arg('target_bucket_id')::integer
+      AND is_folder = FALSE
+      AND starts_with(key, sqlc.arg('target_prefix')::text)
+), compared AS (
+    SELECT COALESCE(s.rel_key, t.rel_key)::text AS rel_key,
+           s.size AS source_size, s.etag AS source_etag, s.storage_class AS source_storage_class,
+           t.size AS target_size, t.etag AS target_etag, t.storage_class AS target_storage_class,
+           (CASE WHEN t.rel_key IS NULL THEN 'missing-target'
+                 WHEN s.rel_key IS NULL THEN 'missing-source'
+                 WHEN s.size <> t.size THEN 'size'
+                 WHEN s.etag <> t.etag THEN 'etag'
+                 WHEN s.storage_class <> t.storage_class THEN 'storage-class'
+                 ELSE '' END)::text AS difference
+    FROM source s
+    FULL OUTER JOIN target t ON t.rel_key = s.rel_key
+)
+SELECT rel_key, source_size, source_etag, source_storage_class,
+       target_size, target_etag, target_storage_class, difference
+FROM compared
+WHERE difference <> ''
+  AND (sqlc.arg('only_difference')::text = '' OR difference = sqlc.arg('only_difference')::text)
+  AND rel_key > sqlc.arg('after_key')::text
+ORDER BY rel_key
+LIMIT sqlc.arg('row_limit')::integer OFFSET sqlc.arg('row_offset')::integer;
+
+-- name: SummarizeCatalogComparison :one
+-- Number of files on each side of a comparison and of files with each difference, as in CompareCatalogs
+WITH source AS (
+    SELECT substr(key, length(sqlc.arg('source_prefix')::text) + 1) AS rel_key, size, COALESCE(etag, '') AS etag,
+           COALESCE(NULLIF(storage_class, ''), 'STANDARD')::text AS storage_class
+    FROM s3_objects
+    WHERE bucket_id = sqlc.arg('source_bucket_id')::integer
+      AND is_folder = FALSE
+      AND starts_with(key, sqlc.arg('source_prefix')::text)
+), target AS (
+    SELECT substr(key, length(sqlc.arg('target_prefix')::text) + 1) AS rel_key, size, COALESCE(etag, '') AS etag,
+           COALESCE(NULLIF(storage_class, ''), 'STANDARD')::text AS storage_class
+    FROM s3_objects
+    WHERE bucket_id = sqlc.arg('target_bucket_id')::integer
+      AND is_folder = FALSE
+      AND starts_with(key, sqlc.arg('target_prefix')::text)
+)
+SELECT COUNT(s.rel_key)::bigint AS source_objects,
+       COUNT(t.rel_key)::bigint AS target_objects,
+       COUNT(*) FILTER (WHERE t.rel_key IS NULL)::bigint AS missing_target,
+       COUNT(*) FILTER (WHERE s.rel_key IS NULL)::bigint AS missing_source,
+       COUNT(*) FILTER (WHERE s.size <> t.size)::bigint AS size_mismatches,
+       COUNT(*) FILTER (WHERE s.size = t.size AND s.etag <> t.etag)::bigint AS etag_mismatches,
+       COUNT(*) FILTER (WHERE s.size = t.size AND s.etag = t.etag
+                          AND s.storage_class <> t.storage_class)::bigint AS storage_class_mismatches
+FROM source s
+FULL OUTER JOIN target t ON t.rel_key = s.rel_key;
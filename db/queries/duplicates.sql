@@ -0,0 +1,71 @@
+-- name: DeleteDuplicateGroups :exec
+DELETE FROM duplicate_groups WHERE bucket_id = $1;
+
+-- name: DeleteDuplicatePrefixes :exec
+DELETE FROM duplicate_prefixes WHERE bucket_id = $1;
+
+-- name: InsertDuplicateGroups :exec
+-- Files under key_prefix sharing an ETag and a size; ETags with a part count are multipart
+INSERT INTO duplicate_groups (bucket_id, etag, size, objects, wasted_bytes, multipart)
+SELECT bucket_id, etag, size, COUNT(*), (COUNT(*) - 1) * size, etag LIKE '%-%'
+FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')::integer
+  AND is_folder = FALSE
+  AND COALESCE(etag, '') <> ''
+  AND size >= sqlc.arg('min_size')::bigint
+  AND starts_with(key, sqlc.arg('key_prefix')::text)
+GROUP BY bucket_id, etag, size
+HAVING COUNT(*) > 1;
+
+-- name: InsertDuplicatePrefixes :exec
+-- The oldest file of each group is the original: the other copies count as wasted in their folder
+INSERT INTO duplicate_prefixes (bucket_id, prefix, copies, wasted_bytes)
+SELECT c.bucket_id, c.prefix, COUNT(*), SUM(c.size)::bigint
+FROM (
+    SELECT o.bucket_id, COALESCE(o.prefix, '') AS prefix, o.size,
+           ROW_NUMBER() OVER (PARTITION BY o.etag, o.size ORDER BY o.last_modified, o.key) AS copy
+    FROM s3_objects o
+    JOIN duplicate_groups g ON g.bucket_id = o.bucket_id AND g.etag = o.etag AND g.size = o.size
+    WHERE o.bucket_id = sqlc.arg('bucket_id')::integer
+      AND o.is_folder = FALSE
+      AND starts_with(o.key, sqlc.arg('key_prefix')::text)
+) c
+WHERE c.copy > 1
+GROUP BY c.bucket_id, c.prefix;
+
+-- name: UpsertDuplicateReport :exec
+INSERT INTO duplicate_reports (bucket_id, groups, wasted_bytes, computed_at)
+SELECT sqlc.arg('bucket_id')::integer, COUNT(*), COALESCE(SUM(wasted_bytes), 0)::bigint, NOW()
+FROM duplicate_groups
+WHERE bucket_id = sqlc.arg('bucket_id')::integer
+ON CONFLICT (bucket_id) DO UPDATE SET
+    groups = EXCLUDED.groups,
+    wasted_bytes = EXCLUDED.wasted_bytes,
+    computed_at = EXCLUDED.computed_at;
+
+-- name: GetDuplicateReport :one
+SELECT r.bucket_id, r.groups, r.wasted_bytes, r.computed_at FROM duplicate_reports r
+JOIN buckets b ON b.id = r.bucket_id
+WHERE b.name = $1;
+
+-- name: ListDuplicateGroups :many
+SELECT * FROM duplicate_groups
+WHERE bucket_id = $1
+ORDER BY wasted_bytes DESC, id
+LIMIT $2 OFFSET $3;
+
+-- name: ListDuplicatePrefixes :many
+SELECT prefix, copies, wasted_bytes FROM duplicate_prefixes
+WHERE bucket_id = $1
+ORDER BY wasted_bytes DESC, prefix
+LIMIT $2;
+
+-- name: ListDuplicateObjects :many
+-- Current files of the listed groups, oldest first; files deleted since the report are gone
+SELECT g.id AS group_id, o.key, o.last_modified, o.storage_class
+FROM duplicate_groups g
+JOIN s3_objects o ON o.bucket_id = g.bucket_id AND o.etag = g.etag AND o.size = g.size
+WHERE g.id = ANY(sqlc.arg('group_ids')::integer[])
+  AND o.is_folder = FALSE
+  AND starts_with(o.key, sqlc.arg('key_prefix')::text)
+ORDER BY g.id, o.last_modified, o.key;
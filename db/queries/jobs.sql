@@ -0,0 +1,69 @@
+-- name: CreateJob :one
+INSERT INTO jobs (kind, bucket_name, source, destination, target_connection, target_bucket, options, dry_run)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetJob :one
+SELECT * FROM jobs
+WHERE id = $1;
+
+-- name: StartJob :exec
+UPDATE jobs
+SET status = 'running',
+    total_items = $2,
+    total_bytes = $3,
+    started_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateJobProgress :exec
+UPDATE jobs
+SET done_items = $2,
+    failed_items = $3,
+    skipped_items = $4,
+    done_bytes = $5,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: FinishJob :exec
+UPDATE jobs
+SET status = sqlc.arg('status')::text,
+    error_message = sqlc.narg('error_message'),
+    completed_at = NOW(),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id');
+
+-- name: FailInterruptedJobs :execrows
+-- Jobs still pending or running when the process starts were interrupted by a restart
+UPDATE jobs
+SET status = 'failed',
+    error_message = 'interrupted by a restart',
+    completed_at = NOW(),
+    updated_at = NOW()
+WHERE status IN ('pending', 'running');
+
+-- name: MoveS3ObjectTree :execrows
+-- Rewrites the key and prefix of an object, or of a folder marker and all its descendants
+-- when src ends with "/": moving "a/b/" to "c/d/" turns "a/b/x/y.txt" (prefix "a/b/x/")
+-- into "c/d/x/y.txt" (prefix "c/d/x/"). src_pattern is src with its LIKE wildcards escaped, so the
+-- descendants are a range of idx_s3_objects_key_prefix.
+UPDATE s3_objects
+SET key = sqlc.arg('dst')::text || substr(key, length(sqlc.arg('src')::text) + 1),
+    prefix = CASE
+        WHEN key = sqlc.arg('src')::text THEN sqlc.arg('dst_parent')::text
+        ELSE sqlc.arg('dst')::text || substr(prefix, length(sqlc.arg('src')::text) + 1)
+    END,
+    updated_at = NOW()
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (key = sqlc.arg('src')::text
+       OR (right(sqlc.arg('src')::text, 1) = '/'
+           AND key LIKE sqlc.arg('src_pattern')::text || '%'));
+
+-- name: DeleteS3ObjectTree :execrows
+-- Removes a key and, when it ends with "/", everything below it; key_pattern is key with its LIKE
+-- wildcards escaped
+DELETE FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (key = sqlc.arg('key')::text
+       OR (right(sqlc.arg('key')::text, 1) = '/'
+           AND key LIKE sqlc.arg('key_pattern')::text || '%'));
@@ -0,0 +1,19 @@
+-- name: SimulateLifecycleAction :one
+-- Files a lifecycle action applies to: those last modified before due_before now, and those
+-- reaching it before upcoming_before. Files last modified before not_before are left to a later
+-- action of the rule. Only the listed storage classes are counted, all of them when the list is empty.
+SELECT
+    COUNT(*) FILTER (WHERE o.last_modified <= sqlc.arg('due_before')::timestamptz) AS due_objects,
+    COALESCE(SUM(o.size) FILTER (WHERE o.last_modified <= sqlc.arg('due_before')::timestamptz), 0)::bigint AS due_bytes,
+    COUNT(*) FILTER (WHERE o.last_modified > sqlc.arg('due_before')::timestamptz) AS upcoming_objects,
+    COALESCE(SUM(o.size) FILTER (WHERE o.last_modified > sqlc.arg('due_before')::timestamptz), 0)::bigint AS upcoming_bytes
+FROM s3_objects o
+JOIN buckets b ON b.id = o.bucket_id
+WHERE b.name = sqlc.arg('bucket_name')
+  AND o.is_folder = false
+  AND starts_with(o.key, sqlc.arg('prefix')::text)
+  AND o.size >= sqlc.arg('min_size')::bigint
+  AND o.last_modified <= sqlc.arg('upcoming_before')::timestamptz
+  AND o.last_modified > sqlc.arg('not_before')::timestamptz
+  AND (COALESCE(cardinality(sqlc.arg('storage_classes')::text[]), 0) = 0
+       OR COALESCE(NULLIF(o.storage_class, ''), 'STANDARD') = ANY(sqlc.arg('storage_classes')::text[]));
@@ -0,0 +1,67 @@
+-- name: InsertObjectChange :exec
+INSERT INTO object_changes (bucket_id, scan_job_id, key, change, old_size, old_etag, new_size, new_etag)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: RecordDeletedObjectChanges :exec
+-- Files about to be removed from the catalog by the deletion cleanup of a scan
+INSERT INTO object_changes (bucket_id, scan_job_id, key, change, old_size, old_etag)
+SELECT bucket_id, sqlc.arg('scan_job_id')::integer, key, 'deleted', size, etag
+FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')::integer
+  AND marked_for_deletion = TRUE
+  AND is_folder = FALSE;
+
+-- name: PurgeObjectChanges :execrows
+-- Deletes up to max_rows of the changes recorded before changed_before, oldest first
+DELETE FROM object_changes
+WHERE id IN (
+    SELECT id FROM object_changes
+    WHERE changed_at < sqlc.arg('changed_before')::timestamptz
+    ORDER BY id
+    LIMIT sqlc.arg('max_rows')::integer
+);
+
+-- name: ListScanJobChangeCounts :many
+-- Latest scan jobs of a bucket with the number of changes they saw under key_prefix
+SELECT j.id, j.status, j.started_at, j.completed_at,
+       COUNT(c.id) FILTER (WHERE c.change = 'created')::bigint AS created,
+       COUNT(c.id) FILTER (WHERE c.change = 'modified')::bigint AS modified,
+       COUNT(c.id) FILTER (WHERE c.change = 'deleted')::bigint AS deleted
+FROM scan_jobs j
+LEFT JOIN object_changes c ON c.scan_job_id = j.id AND starts_with(c.key, sqlc.arg('key_prefix')::text)
+WHERE j.bucket_id = sqlc.arg('bucket_id')::integer
+GROUP BY j.id
+ORDER BY j.id DESC
+LIMIT $1;
+
+-- name: DiffScanJobs :many
+-- Net change of each file under key_prefix between the end of scan from_job_id and the end of scan
+-- to_job_id: its state before the first change and after the last. Files created then deleted, and
+-- files changed back to their first state, are left out.
+WITH events AS (
+    SELECT key, change, old_size, old_etag, new_size, new_etag,
+           ROW_NUMBER() OVER (PARTITION BY key ORDER BY id) AS first_rank,
+           ROW_NUMBER() OVER (PARTITION BY key ORDER BY id DESC) AS last_rank
+    FROM object_changes
+    WHERE bucket_id = sqlc.arg('bucket_id')::integer
+      AND scan_job_id > sqlc.arg('from_job_id')::integer
+      AND scan_job_id <= sqlc.arg('to_job_id')::integer
+      AND starts_with(key, sqlc.arg('key_prefix')::text)
+), net AS (
+    SELECT f.key, f.change AS first_change, l.change AS last_change,
+           f.old_size, f.old_etag, l.new_size, l.new_etag
+    FROM events f
+    JOIN events l ON l.key = f.key AND l.last_rank = 1
+    WHERE f.first_rank = 1
+)
+SELECT key,
+       (CASE WHEN first_change = 'created' THEN 'created'
+             WHEN last_change = 'deleted' THEN 'deleted'
+             ELSE 'modified' END)::text AS change,
+       old_size, old_etag, new_size, new_etag
+FROM net
+WHERE NOT (first_change = 'created' AND last_change = 'deleted')
+  AND NOT (first_change <> 'created' AND last_change <> 'deleted'
+           AND old_size IS NOT DISTINCT FROM new_size AND old_etag IS NOT DISTINCT FROM new_etag)
+ORDER BY key
+LIMIT $1;
@@ -0,0 +1,58 @@
+-- name: ListObjectsWithStaleTags :many
+-- Files whose tags were never fetched or were fetched for another ETag, in id order for keyset paging
+SELECT id, key, etag FROM s3_objects
+WHERE bucket_id = $1
+  AND is_folder = FALSE
+  AND tags_etag IS DISTINCT FROM etag
+  AND id > sqlc.arg('after_id')::integer
+ORDER BY id
+LIMIT $2;
+
+-- name: DeleteObjectTags :exec
+DELETE FROM object_tags
+WHERE object_id = $1
+  AND (sqlc.arg('source')::text = '' OR source = sqlc.arg('source')::text);
+
+-- name: InsertObjectTag :exec
+INSERT INTO object_tags (object_id, source, key, value)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (object_id, source, key) DO UPDATE SET value = EXCLUDED.value;
+
+-- name: SetObjectTagsEtag :exec
+UPDATE s3_objects SET tags_etag = $2
+WHERE id = $1;
+
+-- name: SearchS3ObjectsByTags :many
+-- Objects whose key contains pattern and which carry every (source, key, value) filter;
+-- an empty filter value matches any value of the key
+SELECT o.* FROM s3_objects o
+WHERE o.bucket_id = $1
+  AND o.key ILIKE '%' || sqlc.arg('pattern')::text || '%'
+  AND (
+    SELECT COUNT(*) FROM object_tags t
+    JOIN generate_subscripts(sqlc.arg('tag_keys')::text[], 1) AS i
+      ON t.source = (sqlc.arg('sources')::text[])[i]
+      AND t.key = (sqlc.arg('tag_keys')::text[])[i]
+      AND (sqlc.arg('tag_values')::text[])[i] IN ('', t.value)
+    WHERE t.object_id = o.id
+  ) = cardinality(sqlc.arg('tag_keys')::text[])
+ORDER BY o.is_folder DESC, o.key ASC
+LIMIT $2;
+
+-- name: SummarizeObjectTags :many
+-- Number and total size of the objects of a bucket per tag value
+SELECT t.source, t.key, t.value, COUNT(*) AS objects, COALESCE(SUM(o.size), 0)::bigint AS total_size
+FROM object_tags t
+JOIN s3_objects o ON o.id = t.object_id
+WHERE o.bucket_id = $1
+  AND (sqlc.arg('tag_key')::text = '' OR t.key = sqlc.arg('tag_key')::text)
+GROUP BY t.source, t.key, t.value
+ORDER BY t.source DESC, t.key, objects DESC, t.value
+LIMIT $2;
+
+-- name: CountTagIndexedObjects :one
+-- Files of a bucket whose tags are up to date, and all files
+SELECT COUNT(*) FILTER (WHERE tags_etag IS NOT DISTINCT FROM etag)::bigint AS indexed,
+       COUNT(*)::bigint AS total
+FROM s3_objects
+WHERE bucket_id = $1 AND is_folder = FALSE;
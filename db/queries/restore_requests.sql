@@ -0,0 +1,45 @@
+-- name: CreateRestoreRequest :one
+INSERT INTO restore_requests (bucket_name, key, tier, days, requested_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetPendingRestoreRequest :one
+-- The running restore of an object, if any
+SELECT * FROM restore_requests
+WHERE bucket_name = $1 AND key = $2 AND status = 'pending'
+ORDER BY requested_at DESC
+LIMIT 1;
+
+-- name: ListRestoreRequests :many
+SELECT * FROM restore_requests
+ORDER BY requested_at DESC
+LIMIT $1;
+
+-- name: ListPendingRestoreRequests :many
+-- Requests the poller checks, least recently checked first
+SELECT * FROM restore_requests
+WHERE status = 'pending'
+ORDER BY checked_at NULLS FIRST, id
+LIMIT $1;
+
+-- name: UpdateRestoreRequestStatus :exec
+UPDATE restore_requests
+SET status = sqlc.arg('status')::text,
+    expires_at = sqlc.narg('expires_at'),
+    error_message = sqlc.arg('error_message')::text,
+    checked_at = NOW(),
+    completed_at = CASE WHEN sqlc.arg('status')::text = 'pending' THEN NULL ELSE COALESCE(completed_at, NOW()) END
+WHERE id = sqlc.arg('id');
+
+-- name: ExpireRestoreRequests :execrows
+-- Restored copies past their expiry are gone from S3
+UPDATE restore_requests
+SET status = 'expired'
+WHERE status = 'restored' AND expires_at < NOW();
+
+-- name: TakeRestoreNotifications :many
+-- Restored objects the requester was not told about yet; they are only returned once
+UPDATE restore_requests
+SET notified = TRUE
+WHERE requested_by = $1 AND status = 'restored' AND NOT notified
+RETURNING *;
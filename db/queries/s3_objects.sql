@@ -53,26 +53,40 @@ WHERE bucket_id = $1
 ORDER BY is_folder DESC, key ASC
 LIMIT $3;
 
+-- name: SearchS3ObjectsRanked :many
+-- Substring search backed by the pg_trgm GIN indexes (requires the pg_trgm extension)
+-- The ILIKE filter uses idx_s3_objects_key_trgm. Only the first candidates, in the order of
+-- SearchS3Objects, are ranked so broad terms do not sort every match by similarity: results
+-- whose basename matches come first, then by trigram similarity of the basename to the term
+SELECT * FROM s3_objects
+WHERE id IN (
+  SELECT c.id FROM s3_objects c
+  WHERE c.bucket_id = sqlc.arg('bucket_id')
+    AND c.key ILIKE '%' || sqlc.arg('pattern')::text || '%'
+  ORDER BY c.is_folder DESC, c.key ASC
+  LIMIT sqlc.arg('candidates')::int
+)
+ORDER BY (basename ILIKE '%' || sqlc.arg('pattern')::text || '%') DESC,
+         similarity(basename, sqlc.arg('term')::text) DESC,
+         is_folder DESC,
+         key ASC
+LIMIT sqlc.arg('result_limit')::int;
+
+-- name: HasTrigramExtension :one
+-- Reports whether pg_trgm is installed (trigram search indexes available)
+SELECT EXISTS (
+  SELECT 1 FROM pg_catalog.pg_extension WHERE extname = 'pg_trgm')::boolean AS installed;
+
+-- name: BucketHasFiles :one
+-- Reports whether the catalog holds any file of the bucket, false before its first scan
+SELECT EXISTS (
+  SELECT 1 FROM s3_objects WHERE bucket_id = $1 AND is_folder = FALSE)::boolean AS has_files;
+
 -- name: CountS3Objects :one
 SELECT COUNT(*) FROM s3_objects
 WHERE bucket_id = $1 
   AND ($2 = '' OR prefix = $2);
 
--- name: GetCursorForListS3Folders :one
--- Get cursor position for keyset pagination of folders only
-SELECT key FROM s3_objects
-WHERE bucket_id = $1
-  AND (
-    -- Handle root level (empty prefix): objects with empty or null prefix
-    ($2 = '' AND (prefix = '' OR prefix IS NULL))
-    OR
-    -- Handle non-empty prefix: exact prefix match
-    ($2 != '' AND prefix = $2)
-  )
-  AND is_folder = true
-ORDER BY key ASC
-LIMIT 1 OFFSET $3;
-
 -- name: ListS3Folders :many
 SELECT * FROM s3_objects
 WHERE bucket_id = $1
@@ -88,21 +102,6 @@ WHERE bucket_id = $1
 ORDER BY key ASC
 LIMIT $3;
 
--- name: GetCursorForListS3Files :one
--- Get cursor position for keyset pagination of files only
-SELECT key FROM s3_objects
-WHERE bucket_id = $1
-  AND (
-    -- Handle root level (empty prefix): objects with empty or null prefix
-    ($2 = '' AND (prefix = '' OR prefix IS NULL))
-    OR
-    -- Handle non-empty prefix: exact prefix match
-    ($2 != '' AND prefix = $2)
-  )
-  AND is_folder = false
-ORDER BY key ASC
-LIMIT 1 OFFSET $3;
-
 -- name: ListS3Files :many
 SELECT * FROM s3_objects
 WHERE bucket_id = $1
@@ -132,6 +131,62 @@ ON CONFLICT (bucket_id, key) DO UPDATE SET
     updated_at = NOW()
 RETURNING *;
 
+-- name: UpsertInventoryFolders :exec
+-- Parent folders of a batch of files read from an S3 Inventory report
+INSERT INTO s3_objects (bucket_id, key, size, last_modified, is_folder, prefix)
+SELECT sqlc.arg('bucket_id')::integer, (sqlc.arg('keys')::text[])[n], 0, NOW(), TRUE,
+       NULLIF((sqlc.arg('prefixes')::text[])[n], '')
+FROM generate_subscripts(sqlc.arg('keys')::text[], 1) AS n
+ON CONFLICT (bucket_id, key) DO UPDATE SET
+    is_folder = TRUE,
+    marked_for_deletion = FALSE,
+    updated_at = NOW();
+
+-- name: UpsertInventoryObjects :many
+-- A batch of files read from an S3 Inventory report, recording their changes for the scan job unless
+-- record_changes is false. The changes CTE sees the catalog as it was before the upsert. Empty strings
+-- stand for NULL.
+-- Returns whether each file is new to the catalog.
+WITH incoming AS (
+    SELECT (sqlc.arg('keys')::text[])[n] AS key,
+           (sqlc.arg('sizes')::bigint[])[n] AS size,
+           (sqlc.arg('last_modified')::text[])[n] AS last_modified,
+           (sqlc.arg('etags')::text[])[n] AS etag,
+           (sqlc.arg('storage_classes')::text[])[n] AS storage_class,
+           (sqlc.arg('encryption_statuses')::text[])[n] AS encryption_status,
+           (sqlc.arg('tag_counts')::text[])[n] AS tag_count,
+           (sqlc.arg('prefixes')::text[])[n] AS prefix
+    FROM generate_subscripts(sqlc.arg('keys')::text[], 1) AS n
+), changes AS (
+    INSERT INTO object_changes (bucket_id, scan_job_id, key, change, old_size, old_etag, new_size, new_etag)
+    SELECT sqlc.arg('bucket_id')::integer, sqlc.arg('scan_job_id')::integer, i.key,
+           CASE WHEN o.id IS NULL THEN 'created' ELSE 'modified' END,
+           o.size, o.etag, i.size, NULLIF(i.etag, '')
+    FROM incoming i
+    LEFT JOIN s3_objects o ON o.bucket_id = sqlc.arg('bucket_id')::integer AND o.key = i.key
+    WHERE sqlc.arg('record_changes')::boolean
+      AND (o.id IS NULL OR o.size <> i.size OR o.etag IS DISTINCT FROM NULLIF(i.etag, ''))
+)
+INSERT INTO s3_objects (
+    bucket_id, key, size, last_modified, etag, storage_class, encryption_status, tag_count, is_folder, prefix
+)
+SELECT sqlc.arg('bucket_id')::integer, key, size, NULLIF(last_modified, '')::timestamptz, NULLIF(etag, ''),
+       NULLIF(storage_class, ''), NULLIF(encryption_status, ''), NULLIF(tag_count, '')::integer, FALSE,
+       NULLIF(prefix, '')
+FROM incoming
+ON CONFLICT (bucket_id, key) DO UPDATE SET
+    size = EXCLUDED.size,
+    last_modified = EXCLUDED.last_modified,
+    etag = EXCLUDED.etag,
+    storage_class = EXCLUDED.storage_class,
+    encryption_status = EXCLUDED.encryption_status,
+    tag_count = EXCLUDED.tag_count,
+    is_folder = EXCLUDED.is_folder,
+    prefix = EXCLUDED.prefix,
+    marked_for_deletion = FALSE,
+    updated_at = NOW()
+RETURNING (xmax = 0)::boolean AS created;
+
 -- name: UpdateS3Object :one
 UPDATE s3_objects
 SET size = $3,
@@ -146,32 +201,31 @@ RETURNING *;
 DELETE FROM s3_objects
 WHERE bucket_id = $1 AND key = $2;
 
+-- name: DeleteS3ObjectsByKeys :execrows
+DELETE FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id') AND key = ANY(sqlc.arg('keys')::text[]);
+
+-- name: GetS3ObjectTreeStats :one
+-- Counts the objects stored under a folder, its marker included. prefix_pattern is the folder
+-- with its LIKE wildcards escaped, so the match is a range of idx_s3_objects_key_prefix.
+SELECT COUNT(*)::bigint AS object_count,
+       COALESCE(SUM(size), 0)::bigint AS total_size
+FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND key LIKE sqlc.arg('prefix_pattern')::text || '%';
+
+-- name: ListS3ObjectTreeSample :many
+SELECT key FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND key LIKE sqlc.arg('prefix_pattern')::text || '%'
+  AND is_folder = false
+ORDER BY key
+LIMIT sqlc.arg('max_keys');
+
 -- name: DeleteS3ObjectsByBucket :exec
 DELETE FROM s3_objects
 WHERE bucket_id = $1;
 
--- name: GetCursorForDirectChildren :one
--- Get cursor position for keyset pagination of direct children
-SELECT is_folder, key FROM s3_objects
-WHERE bucket_id = $1
-  AND (
-    -- Handle root level (empty prefix): objects with empty or null prefix
-    ($2 = '' AND (prefix = '' OR prefix IS NULL))
-    OR
-    -- Handle non-empty prefix: exact prefix match
-    ($2 != '' AND prefix = $2)
-  )
-  AND key != $2
-  AND (
-    -- Direct files: files whose prefix exactly matches the given prefix
-    (is_folder = false)
-    OR
-    -- Direct folders: folders whose prefix exactly matches the given prefix
-    (is_folder = true)
-  )
-ORDER BY is_folder DESC, key ASC
-LIMIT 1 OFFSET $3;
-
 -- name: GetDirectChildren :many
 -- Get only immediate children (files and folders) under a specific prefix
 -- For hierarchical navigation - not recursive
@@ -266,4 +320,21 @@ WHERE bucket_id = $1
     ($2 != '' AND prefix = $2)
   )
   AND key != $2
-  AND is_folder = false;
\ No newline at end of file
+  AND is_folder = false;
+-- name: ListS3ObjectsByKeys :many
+SELECT * FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id') AND key = ANY(sqlc.arg('keys')::text[]);
+
+-- name: ListExportObjects :many
+-- A batch of the files exported by s3xplorer export, after after_key in key order, following the unique
+-- (bucket_id, key) index instead of sorting the bucket. The pattern and exclude filters are ignored when empty.
+SELECT key, size, last_modified, COALESCE(etag, '')::text AS etag, COALESCE(storage_class, '')::text AS storage_class
+FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')::integer
+  AND is_folder = FALSE
+  AND starts_with(key, sqlc.arg('prefix')::text)
+  AND (sqlc.arg('pattern')::text = '' OR key ILIKE '%' || sqlc.arg('pattern')::text || '%')
+  AND (sqlc.arg('exclude')::text = '' OR NOT starts_with(key, sqlc.arg('exclude')::text))
+  AND key > sqlc.arg('after_key')::text
+ORDER BY key
+LIMIT sqlc.arg('batch_size')::integer;
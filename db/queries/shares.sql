@@ -0,0 +1,26 @@
+-- name: CreateShare :one
+INSERT INTO shares (token, bucket_name, key, creator, proxied, url, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetShareByToken :one
+SELECT * FROM shares
+WHERE token = $1;
+
+-- name: ListSharesByCreator :many
+SELECT * FROM shares
+WHERE creator = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: RevokeShare :execrows
+UPDATE shares
+SET revoked = TRUE,
+    revoked_at = NOW()
+WHERE id = $1 AND creator = $2 AND revoked = FALSE;
+
+-- name: RecordShareDownload :exec
+UPDATE shares
+SET download_count = download_count + 1,
+    last_downloaded_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,23 @@
+-- name: CreateTrashItem :one
+INSERT INTO trash_items (bucket_name, original_key, trash_bucket, trash_key, version_id, delete_marker_id, size, deleted_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetTrashItem :one
+SELECT * FROM trash_items
+WHERE id = $1;
+
+-- name: ListTrashItems :many
+SELECT * FROM trash_items
+ORDER BY deleted_at DESC
+LIMIT $1;
+
+-- name: ListTrashItemsDeletedBefore :many
+SELECT * FROM trash_items
+WHERE deleted_at < $1
+ORDER BY deleted_at
+LIMIT $2;
+
+-- name: DeleteTrashItem :execrows
+DELETE FROM trash_items
+WHERE id = $1;
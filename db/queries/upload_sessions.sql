@@ -0,0 +1,38 @@
+-- name: CreateUploadSession :one
+INSERT INTO upload_sessions (id, bucket_name, key, upload_id, content_type, size, part_size)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetUploadSession :one
+SELECT * FROM upload_sessions
+WHERE id = $1;
+
+-- name: SetUploadSessionStatus :exec
+UPDATE upload_sessions
+SET status = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpsertUploadPart :exec
+INSERT INTO upload_parts (session_id, part_number, etag, size)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (session_id, part_number) DO UPDATE SET
+    etag = EXCLUDED.etag,
+    size = EXCLUDED.size,
+    created_at = NOW();
+
+-- name: TouchUploadSession :exec
+UPDATE upload_sessions
+SET updated_at = NOW()
+WHERE id = $1;
+
+-- name: ListUploadParts :many
+SELECT * FROM upload_parts
+WHERE session_id = $1
+ORDER BY part_number;
+
+-- name: ListStaleUploadSessions :many
+SELECT * FROM upload_sessions
+WHERE status = 'active' AND updated_at < $1
+ORDER BY updated_at
+LIMIT $2;
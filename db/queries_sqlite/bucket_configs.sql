@@ -0,0 +1,12 @@
+-- name: UpsertBucketConfig :exec
+INSERT INTO bucket_configs (bucket_id, scan_job_id, config, fetched_at)
+VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT (bucket_id) DO UPDATE SET
+    scan_job_id = excluded.scan_job_id,
+    config = excluded.config,
+    fetched_at = excluded.fetched_at;
+
+-- name: GetBucketConfig :one
+SELECT bc.config, bc.fetched_at FROM bucket_configs bc
+JOIN buckets b ON b.id = bc.bucket_id
+WHERE b.name = ?;
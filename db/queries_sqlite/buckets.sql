@@ -0,0 +1,99 @@
+-- name: GetBucket :one
+SELECT * FROM buckets
+WHERE name = ?;
+
+-- name: GetBucketByID :one
+SELECT * FROM buckets
+WHERE id = ?;
+
+-- name: ListBuckets :many
+SELECT * FROM buckets
+ORDER BY name;
+
+-- name: ListAccessibleBuckets :many
+-- Returns only buckets that are accessible (not marked for deletion and no recent permanent failures)
+SELECT b.* FROM buckets b
+WHERE b.marked_for_deletion = false
+  AND (b.access_error IS NULL OR b.last_accessible_at > datetime('now', '-24 hours'))
+ORDER BY b.name;
+
+-- name: ListBucketsWithStatus :many
+-- Returns all buckets with their latest scan job status for admin/debug purposes
+-- SQLite has no LATERAL joins: the latest scan job of each bucket is picked by a window function.
+SELECT
+  b.*,
+  sj.status AS latest_scan_status,
+  sj.error_message AS latest_scan_error,
+  sj.completed_at AS latest_scan_completed_at
+FROM buckets b
+LEFT JOIN (
+  SELECT j.bucket_id, j.status, j.error_message, j.completed_at,
+         ROW_NUMBER() OVER (PARTITION BY j.bucket_id ORDER BY j.created_at DESC, j.id DESC) AS position
+  FROM scan_jobs j
+) sj ON sj.bucket_id = b.id AND sj.position = 1
+ORDER BY b.name;
+
+-- name: CreateBucket :one
+INSERT INTO buckets (name, region)
+VALUES (?, ?)
+ON CONFLICT (name) DO UPDATE SET
+    region = excluded.region,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteBucket :exec
+DELETE FROM buckets
+WHERE id = ?;
+
+-- Bucket lifecycle management queries for bucket synchronization
+
+-- name: MarkAllBucketsForDeletion :exec
+UPDATE buckets
+SET marked_for_deletion = true
+WHERE marked_for_deletion = false;
+
+-- name: MarkBucketForDeletion :exec
+UPDATE buckets
+SET marked_for_deletion = true
+WHERE id = ?;
+
+-- name: UnmarkBucketForDeletion :exec
+UPDATE buckets
+SET marked_for_deletion = false,
+    last_accessible_at = CURRENT_TIMESTAMP,
+    access_error = NULL
+WHERE id = ?;
+
+-- name: UpdateBucketAccessError :exec
+UPDATE buckets
+SET access_error = sqlc.narg('access_error'),
+    last_accessible_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id');
+
+-- name: GetBucketsMarkedForDeletion :many
+SELECT * FROM buckets
+WHERE marked_for_deletion = true;
+
+-- name: GetInaccessibleBuckets :many
+SELECT * FROM buckets
+WHERE last_accessible_at < datetime('now', '-' || CAST(sqlc.arg('hours') AS INTEGER) || ' hours')
+   OR (last_accessible_at IS NULL
+       AND created_at < datetime('now', '-' || CAST(sqlc.arg('hours') AS INTEGER) || ' hours'));
+
+-- name: GetBucketsToDelete :many
+SELECT * FROM buckets
+WHERE marked_for_deletion = true
+  AND (last_accessible_at < datetime('now', '-' || CAST(sqlc.arg('hours') AS INTEGER) || ' hours')
+       OR (last_accessible_at IS NULL
+           AND created_at < datetime('now', '-' || CAST(sqlc.arg('hours') AS INTEGER) || ' hours')));
+
+-- name: DeleteMarkedBuckets :exec
+DELETE FROM buckets
+WHERE marked_for_deletion = true
+  AND (last_accessible_at < datetime('now', '-' || CAST(sqlc.arg('hours') AS INTEGER) || ' hours')
+       OR (last_accessible_at IS NULL
+           AND created_at < datetime('now', '-' || CAST(sqlc.arg('hours') AS INTEGER) || ' hours')));
+
+-- name: CountMarkedBuckets :one
+SELECT COUNT(*) FROM buckets
+WHERE marked_for_deletion = true;
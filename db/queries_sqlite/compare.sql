@@ -0,0 +1,68 @@
+-- name: CompareCatalogs :many
+-- Files under source_prefix of one bucket and target_prefix of another (or the same) bucket, joined by
+-- their key relative to the prefix. Only differing files are returned, each with its first difference:
+-- missing on one side, then size, then ETag, then storage class. Pages by offset, or by key after after_key.
+-- The columns of the side a file is missing from are zero values.
+SELECT rel_key, source_size, source_etag, source_storage_class,
+       target_size, target_etag, target_storage_class, difference
+FROM (
+    SELECT CAST(COALESCE(s.rel_key, t.rel_key) AS TEXT) AS rel_key,
+           CAST(COALESCE(s.size, 0) AS BIGINT) AS source_size, CAST(COALESCE(s.etag, '') AS TEXT) AS source_etag,
+           CAST(COALESCE(s.storage_class, '') AS TEXT) AS source_storage_class,
+           CAST(COALESCE(t.size, 0) AS BIGINT) AS target_size, CAST(COALESCE(t.etag, '') AS TEXT) AS target_etag,
+           CAST(COALESCE(t.storage_class, '') AS TEXT) AS target_storage_class,
+           CAST(CASE WHEN t.rel_key IS NULL THEN 'missing-target'
+                     WHEN s.rel_key IS NULL THEN 'missing-source'
+                     WHEN s.size <> t.size THEN 'size'
+                     WHEN s.etag <> t.etag THEN 'etag'
+                     WHEN s.storage_class <> t.storage_class THEN 'storage-class'
+                     ELSE '' END AS TEXT) AS difference
+    FROM (
+        SELECT substr(so.key, length(CAST(sqlc.arg('source_prefix') AS TEXT)) + 1) AS rel_key, so.size,
+               COALESCE(so.etag, '') AS etag, COALESCE(NULLIF(so.storage_class, ''), 'STANDARD') AS storage_class
+        FROM s3_objects so
+        WHERE so.bucket_id = sqlc.arg('source_bucket_id')
+          AND so.is_folder = FALSE
+          AND substr(so.key, 1, length(CAST(sqlc.arg('source_prefix') AS TEXT))) = CAST(sqlc.arg('source_prefix') AS TEXT)
+    ) AS s
+    FULL OUTER JOIN (
+        SELECT substr(tobj.key, length(CAST(sqlc.arg('target_prefix') AS TEXT)) + 1) AS rel_key, tobj.size,
+               COALESCE(tobj.etag, '') AS etag, COALESCE(NULLIF(tobj.storage_class, ''), 'STANDARD') AS storage_class
+        FROM s3_objects tobj
+        WHERE tobj.bucket_id = sqlc.arg('target_bucket_id')
+          AND tobj.is_folder = FALSE
+          AND substr(tobj.key, 1, length(CAST(sqlc.arg('target_prefix') AS TEXT))) = CAST(sqlc.arg('target_prefix') AS TEXT)
+    ) AS t ON t.rel_key = s.rel_key
+) AS compared
+WHERE difference <> ''
+  AND (CAST(sqlc.arg('only_difference') AS TEXT) = '' OR difference = CAST(sqlc.arg('only_difference') AS TEXT))
+  AND rel_key > CAST(sqlc.arg('after_key') AS TEXT)
+ORDER BY rel_key
+LIMIT sqlc.arg('row_limit') OFFSET sqlc.arg('row_offset');
+
+-- name: SummarizeCatalogComparison :one
+-- Number of files on each side of a comparison and of files with each difference, as in CompareCatalogs
+SELECT COUNT(s.rel_key) AS source_objects,
+       COUNT(t.rel_key) AS target_objects,
+       COUNT(*) FILTER (WHERE t.rel_key IS NULL) AS missing_target,
+       COUNT(*) FILTER (WHERE s.rel_key IS NULL) AS missing_source,
+       COUNT(*) FILTER (WHERE s.size <> t.size) AS size_mismatches,
+       COUNT(*) FILTER (WHERE s.size = t.size AND s.etag <> t.etag) AS etag_mismatches,
+       COUNT(*) FILTER (WHERE s.size = t.size AND s.etag = t.etag
+                          AND s.storage_class <> t.storage_class) AS storage_class_mismatches
+FROM (
+    SELECT substr(so.key, length(CAST(sqlc.arg('source_prefix') AS TEXT)) + 1) AS rel_key, so.size,
+           COALESCE(so.etag, '') AS etag, COALESCE(NULLIF(so.storage_class, ''), 'STANDARD') AS storage_class
+    FROM s3_objects so
+    WHERE so.bucket_id = sqlc.arg('source_bucket_id')
+      AND so.is_folder = FALSE
+      AND substr(so.key, 1, length(CAST(sqlc.arg('source_prefix') AS TEXT))) = CAST(sqlc.arg('source_prefix') AS TEXT)
+) AS s
+FULL OUTER JOIN (
+    SELECT substr(tobj.key, length(CAST(sqlc.arg('target_prefix') AS TEXT)) + 1) AS rel_key, tobj.size,
+           COALESCE(tobj.etag, '') AS etag, COALESCE(NULLIF(tobj.storage_class, ''), 'STANDARD') AS storage_class
+    FROM s3_objects tobj
+    WHERE tobj.bucket_id = sqlc.arg('target_bucket_id')
+      AND tobj.is_folder = FALSE
+      AND substr(tobj.key, 1, length(CAST(sqlc.arg('target_prefix') AS TEXT))) = CAST(sqlc.arg('target_prefix') AS TEXT)
+) AS t ON t.rel_key = s.rel_key;
@@ -0,0 +1,72 @@
+-- name: DeleteDuplicateGroups :exec
+DELETE FROM duplicate_groups WHERE bucket_id = ?;
+
+-- name: DeleteDuplicatePrefixes :exec
+DELETE FROM duplicate_prefixes WHERE bucket_id = ?;
+
+-- name: InsertDuplicateGroups :exec
+-- Files under key_prefix sharing an ETag and a size; ETags with a part count are multipart
+INSERT INTO duplicate_groups (bucket_id, etag, size, objects, wasted_bytes, multipart)
+SELECT o.bucket_id, o.etag, o.size, COUNT(*), (COUNT(*) - 1) * o.size, instr(o.etag, '-') > 0
+FROM s3_objects o
+WHERE o.bucket_id = sqlc.arg('bucket_id')
+  AND o.is_folder = FALSE
+  AND COALESCE(o.etag, '') <> ''
+  AND o.size >= CAST(sqlc.arg('min_size') AS BIGINT)
+  AND substr(o.key, 1, length(CAST(sqlc.arg('key_prefix') AS TEXT))) = CAST(sqlc.arg('key_prefix') AS TEXT)
+GROUP BY o.bucket_id, o.etag, o.size
+HAVING COUNT(*) > 1;
+
+-- name: InsertDuplicatePrefixes :exec
+-- The oldest file of each group is the original: the other copies count as wasted in their folder
+INSERT INTO duplicate_prefixes (bucket_id, prefix, copies, wasted_bytes)
+SELECT c.bucket_id, c.prefix, COUNT(*), SUM(c.size)
+FROM (
+    SELECT o.bucket_id, COALESCE(o.prefix, '') AS prefix, o.size,
+           ROW_NUMBER() OVER (PARTITION BY o.etag, o.size ORDER BY o.last_modified, o.key) AS copy
+    FROM s3_objects o
+    JOIN duplicate_groups g ON g.bucket_id = o.bucket_id AND g.etag = o.etag AND g.size = o.size
+    WHERE o.bucket_id = sqlc.arg('bucket_id')
+      AND o.is_folder = FALSE
+      AND substr(o.key, 1, length(CAST(sqlc.arg('key_prefix') AS TEXT))) = CAST(sqlc.arg('key_prefix') AS TEXT)
+) c
+WHERE c.copy > 1
+GROUP BY c.bucket_id, c.prefix;
+
+-- name: UpsertDuplicateReport :exec
+INSERT INTO duplicate_reports (bucket_id, groups, wasted_bytes, computed_at)
+SELECT sqlc.arg('bucket_id'), COUNT(*), COALESCE(SUM(g.wasted_bytes), 0), CURRENT_TIMESTAMP
+FROM duplicate_groups g
+WHERE g.bucket_id = sqlc.arg('bucket_id')
+ON CONFLICT (bucket_id) DO UPDATE SET
+    groups = excluded.groups,
+    wasted_bytes = excluded.wasted_bytes,
+    computed_at = excluded.computed_at;
+
+-- name: GetDuplicateReport :one
+SELECT r.bucket_id, r.groups, r.wasted_bytes, r.computed_at FROM duplicate_reports r
+JOIN buckets b ON b.id = r.bucket_id
+WHERE b.name = ?;
+
+-- name: ListDuplicateGroups :many
+SELECT * FROM duplicate_groups
+WHERE bucket_id = sqlc.arg('bucket_id')
+ORDER BY wasted_bytes DESC, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListDuplicatePrefixes :many
+SELECT prefix, copies, wasted_bytes FROM duplicate_prefixes
+WHERE bucket_id = sqlc.arg('bucket_id')
+ORDER BY wasted_bytes DESC, prefix
+LIMIT sqlc.arg('limit');
+
+-- name: ListDuplicateObjects :many
+-- Current files of the listed groups, oldest first; files deleted since the report are gone.
+-- group_ids is a JSON array of integers.
+SELECT g.id AS group_id, o.key, o.last_modified, o.storage_class
+FROM duplicate_groups g
+JOIN s3_objects o ON o.bucket_id = g.bucket_id AND o.etag = g.etag AND o.size = g.size
+WHERE g.id IN (SELECT j.value FROM (SELECT CAST(sqlc.arg('group_ids') AS TEXT) AS doc) AS batch, json_each(batch.doc) AS j)
+  AND o.is_folder = FALSE
+  AND substr(o.key, 1, length(CAST(sqlc.arg('key_prefix') AS TEXT))) = CAST(sqlc.arg('key_prefix') AS TEXT)
+ORDER BY g.id, o.last_modified, o.key;
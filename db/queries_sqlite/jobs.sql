@@ -0,0 +1,70 @@
+-- name: CreateJob :one
+INSERT INTO jobs (kind, bucket_name, source, destination, target_connection, target_bucket, options, dry_run)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetJob :one
+SELECT * FROM jobs
+WHERE id = ?;
+
+-- name: StartJob :exec
+UPDATE jobs
+SET status = 'running',
+    total_items = ?,
+    total_bytes = ?,
+    started_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: UpdateJobProgress :exec
+UPDATE jobs
+SET done_items = ?,
+    failed_items = ?,
+    skipped_items = ?,
+    done_bytes = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: FinishJob :exec
+UPDATE jobs
+SET status = CAST(sqlc.arg('status') AS TEXT),
+    error_message = sqlc.narg('error_message'),
+    completed_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id');
+
+-- name: FailInterruptedJobs :execrows
+-- Jobs still pending or running when the process starts were interrupted by a restart
+UPDATE jobs
+SET status = 'failed',
+    error_message = 'interrupted by a restart',
+    completed_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE status IN ('pending', 'running');
+
+-- name: MoveS3ObjectTree :execrows
+-- Rewrites the key and prefix of an object, or of a folder marker and all its descendants
+-- when src ends with "/": moving "a/b/" to "c/d/" turns "a/b/x/y.txt" (prefix "a/b/x/")
+-- into "c/d/x/y.txt" (prefix "c/d/x/"). Keys compare as bytes and 0xF5 never occurs in UTF-8,
+-- so the descendants are a range of UNIQUE(bucket_id, key).
+UPDATE s3_objects
+SET key = CAST(sqlc.arg('dst') AS TEXT) || substr(key, length(CAST(sqlc.arg('src') AS TEXT)) + 1),
+    prefix = CASE
+        WHEN key = CAST(sqlc.arg('src') AS TEXT) THEN CAST(sqlc.arg('dst_parent') AS TEXT)
+        ELSE CAST(sqlc.arg('dst') AS TEXT) || substr(prefix, length(CAST(sqlc.arg('src') AS TEXT)) + 1)
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (key = CAST(sqlc.arg('src') AS TEXT)
+       OR (substr(CAST(sqlc.arg('src') AS TEXT), -1) = '/'
+           AND key >= CAST(sqlc.arg('src') AS TEXT)
+           AND key < CAST(sqlc.arg('src') AS TEXT) || CAST(x'F5' AS TEXT)));
+
+-- name: DeleteS3ObjectTree :execrows
+-- Removes a key and, when it ends with "/", everything below it
+DELETE FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (key = CAST(sqlc.arg('key') AS TEXT)
+       OR (substr(CAST(sqlc.arg('key') AS TEXT), -1) = '/'
+           AND key >= CAST(sqlc.arg('key') AS TEXT)
+           AND key < CAST(sqlc.arg('key') AS TEXT) || CAST(x'F5' AS TEXT)));
@@ -0,0 +1,21 @@
+-- name: SimulateLifecycleAction :one
+-- Files a lifecycle action applies to: those last modified before due_before now, and those
+-- reaching it before upcoming_before. Files last modified before not_before are left to a later
+-- action of the rule. Only the listed storage classes are counted, all of them when the list is empty.
+-- storage_classes is a JSON array of strings.
+SELECT
+    COUNT(CASE WHEN o.last_modified <= sqlc.arg('due_before') THEN 1 END) AS due_objects,
+    CAST(COALESCE(SUM(CASE WHEN o.last_modified <= sqlc.arg('due_before') THEN o.size END), 0) AS BIGINT) AS due_bytes,
+    COUNT(CASE WHEN o.last_modified > sqlc.arg('due_before') THEN 1 END) AS upcoming_objects,
+    CAST(COALESCE(SUM(CASE WHEN o.last_modified > sqlc.arg('due_before') THEN o.size END), 0) AS BIGINT) AS upcoming_bytes
+FROM s3_objects o
+JOIN buckets b ON b.id = o.bucket_id
+JOIN (SELECT CAST(sqlc.arg('storage_classes') AS TEXT) AS classes) AS f
+WHERE b.name = sqlc.arg('bucket_name')
+  AND o.is_folder = false
+  AND substr(o.key, 1, length(CAST(sqlc.arg('prefix') AS TEXT))) = CAST(sqlc.arg('prefix') AS TEXT)
+  AND o.size >= CAST(sqlc.arg('min_size') AS BIGINT)
+  AND o.last_modified <= sqlc.arg('upcoming_before')
+  AND o.last_modified > sqlc.arg('not_before')
+  AND (json_array_length(f.classes) = 0
+       OR COALESCE(NULLIF(o.storage_class, ''), 'STANDARD') IN (SELECT value FROM json_each(f.classes)));
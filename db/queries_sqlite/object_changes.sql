@@ -0,0 +1,68 @@
+-- name: InsertObjectChange :exec
+INSERT INTO object_changes (bucket_id, scan_job_id, key, change, old_size, old_etag, new_size, new_etag)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: RecordDeletedObjectChanges :exec
+-- Files about to be removed from the catalog by the deletion cleanup of a scan
+INSERT INTO object_changes (bucket_id, scan_job_id, key, change, old_size, old_etag)
+SELECT o.bucket_id, sqlc.arg('scan_job_id'), o.key, 'deleted', o.size, o.etag
+FROM s3_objects o
+WHERE o.bucket_id = sqlc.arg('bucket_id')
+  AND o.marked_for_deletion = TRUE
+  AND o.is_folder = FALSE;
+
+-- name: PurgeObjectChanges :execrows
+-- Deletes up to max_rows of the changes recorded before changed_before, oldest first
+DELETE FROM object_changes
+WHERE id IN (
+    SELECT c.id FROM object_changes c
+    WHERE c.changed_at < sqlc.arg('changed_before')
+    ORDER BY c.id
+    LIMIT sqlc.arg('max_rows')
+);
+
+-- name: ListScanJobChangeCounts :many
+-- Latest scan jobs of a bucket with the number of changes they saw under key_prefix
+SELECT j.id, j.status, j.started_at, j.completed_at,
+       COUNT(c.id) FILTER (WHERE c.change = 'created') AS created,
+       COUNT(c.id) FILTER (WHERE c.change = 'modified') AS modified,
+       COUNT(c.id) FILTER (WHERE c.change = 'deleted') AS deleted
+FROM scan_jobs j
+LEFT JOIN object_changes c ON c.scan_job_id = j.id
+  AND substr(c.key, 1, length(CAST(sqlc.arg('key_prefix') AS TEXT))) = CAST(sqlc.arg('key_prefix') AS TEXT)
+WHERE j.bucket_id = sqlc.arg('bucket_id')
+GROUP BY j.id
+ORDER BY j.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: DiffScanJobs :many
+-- Net change of each file under key_prefix between the end of scan from_job_id and the end of scan
+-- to_job_id: its state before the first change and after the last. Files created then deleted, and
+-- files changed back to their first state, are left out.
+WITH events AS (
+    SELECT c.key, c.change, c.old_size, c.old_etag, c.new_size, c.new_etag,
+           ROW_NUMBER() OVER (PARTITION BY c.key ORDER BY c.id) AS first_rank,
+           ROW_NUMBER() OVER (PARTITION BY c.key ORDER BY c.id DESC) AS last_rank
+    FROM object_changes c
+    WHERE c.bucket_id = sqlc.arg('bucket_id')
+      AND c.scan_job_id > sqlc.arg('from_job_id')
+      AND c.scan_job_id <= sqlc.arg('to_job_id')
+      AND substr(c.key, 1, length(CAST(sqlc.arg('key_prefix') AS TEXT))) = CAST(sqlc.arg('key_prefix') AS TEXT)
+), net AS (
+    SELECT f.key, f.change AS first_change, l.change AS last_change,
+           f.old_size, f.old_etag, l.new_size, l.new_etag
+    FROM events f
+    JOIN events l ON l.key = f.key AND l.last_rank = 1
+    WHERE f.first_rank = 1
+)
+SELECT key,
+       CAST(CASE WHEN first_change = 'created' THEN 'created'
+                 WHEN last_change = 'deleted' THEN 'deleted'
+                 ELSE 'modified' END AS TEXT) AS change,
+       old_size, old_etag, new_size, new_etag
+FROM net
+WHERE NOT (first_change = 'created' AND last_change = 'deleted')
+  AND NOT (first_change <> 'created' AND last_change <> 'deleted'
+           AND old_size IS new_size AND old_etag IS new_etag)
+ORDER BY key
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,60 @@
+-- name: ListObjectsWithStaleTags :many
+-- Files whose tags were never fetched or were fetched for another ETag, in id order for keyset paging
+SELECT id, key, etag FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND is_folder = FALSE
+  AND tags_etag IS NOT etag
+  AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: DeleteObjectTags :exec
+DELETE FROM object_tags
+WHERE object_id = sqlc.arg('object_id')
+  AND (CAST(sqlc.arg('source') AS TEXT) = '' OR source = CAST(sqlc.arg('source') AS TEXT));
+
+-- name: InsertObjectTag :exec
+INSERT INTO object_tags (object_id, source, key, value)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (object_id, source, key) DO UPDATE SET value = excluded.value;
+
+-- name: SetObjectTagsEtag :exec
+UPDATE s3_objects SET tags_etag = sqlc.narg('tags_etag')
+WHERE id = sqlc.arg('id');
+
+-- name: SearchS3ObjectsByTags :many
+-- Objects whose key contains pattern and which carry every (source, key, value) filter;
+-- an empty filter value matches any value of the key. filters is a JSON array of
+-- {"source", "key", "value"} objects.
+SELECT o.* FROM s3_objects o
+WHERE o.bucket_id = sqlc.arg('bucket_id')
+  AND (o.key LIKE ('%' || CAST(sqlc.arg('pattern') AS TEXT) || '%') ESCAPE '\')
+  AND (
+    SELECT COUNT(*) FROM object_tags t
+    JOIN (
+      SELECT json_extract(j.value, '$.source') AS tag_source, json_extract(j.value, '$.key') AS tag_key,
+             json_extract(j.value, '$.value') AS tag_value
+      FROM (SELECT CAST(sqlc.arg('filters') AS TEXT) AS doc) AS batch, json_each(batch.doc) AS j
+    ) AS f ON t.source = f.tag_source AND t.key = f.tag_key AND f.tag_value IN ('', t.value)
+    WHERE t.object_id = o.id
+  ) = json_array_length(CAST(sqlc.arg('filters') AS TEXT))
+ORDER BY o.is_folder DESC, o.key ASC
+LIMIT sqlc.arg('limit');
+
+-- name: SummarizeObjectTags :many
+-- Number and total size of the objects of a bucket per tag value
+SELECT t.source, t.key, t.value, COUNT(*) AS objects, CAST(COALESCE(SUM(o.size), 0) AS BIGINT) AS total_size
+FROM object_tags t
+JOIN s3_objects o ON o.id = t.object_id
+WHERE o.bucket_id = sqlc.arg('bucket_id')
+  AND (CAST(sqlc.arg('tag_key') AS TEXT) = '' OR t.key = CAST(sqlc.arg('tag_key') AS TEXT))
+GROUP BY t.source, t.key, t.value
+ORDER BY t.source DESC, t.key, objects DESC, t.value
+LIMIT sqlc.arg('limit');
+
+-- name: CountTagIndexedObjects :one
+-- Files of a bucket whose tags are up to date, and all files
+SELECT COUNT(*) FILTER (WHERE tags_etag IS etag) AS "indexed",
+       COUNT(*) AS total
+FROM s3_objects
+WHERE bucket_id = ? AND is_folder = FALSE;
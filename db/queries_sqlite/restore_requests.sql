@@ -0,0 +1,48 @@
+-- name: CreateRestoreRequest :one
+INSERT INTO restore_requests (bucket_name, key, tier, days, requested_by)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetPendingRestoreRequest :one
+-- The running restore of an object, if any
+SELECT * FROM restore_requests
+WHERE bucket_name = ? AND key = ? AND status = 'pending'
+ORDER BY requested_at DESC, id DESC
+LIMIT 1;
+
+-- name: ListRestoreRequests :many
+SELECT * FROM restore_requests
+ORDER BY requested_at DESC, id DESC
+LIMIT ?;
+
+-- name: ListPendingRestoreRequests :many
+-- Requests the poller checks, least recently checked first
+SELECT * FROM restore_requests
+WHERE status = 'pending'
+ORDER BY checked_at NULLS FIRST, id
+LIMIT ?;
+
+-- name: UpdateRestoreRequestStatus :exec
+UPDATE restore_requests
+SET status = CAST(sqlc.arg('status') AS TEXT),
+    expires_at = sqlc.narg('expires_at'),
+    error_message = CAST(sqlc.arg('error_message') AS TEXT),
+    checked_at = CURRENT_TIMESTAMP,
+    completed_at = CASE
+        WHEN CAST(sqlc.arg('status') AS TEXT) = 'pending' THEN NULL
+        ELSE COALESCE(completed_at, CURRENT_TIMESTAMP)
+    END
+WHERE id = sqlc.arg('id');
+
+-- name: ExpireRestoreRequests :execrows
+-- Restored copies past their expiry are gone from S3
+UPDATE restore_requests
+SET status = 'expired'
+WHERE status = 'restored' AND expires_at < strftime('%Y-%m-%d %H:%M:%f+00:00', 'now');
+
+-- name: TakeRestoreNotifications :many
+-- Restored objects the requester was not told about yet; they are only returned once
+UPDATE restore_requests
+SET notified = TRUE
+WHERE requested_by = ? AND status = 'restored' AND NOT notified
+RETURNING *;
@@ -0,0 +1,340 @@
+-- name: GetS3Object :one
+SELECT * FROM s3_objects
+WHERE bucket_id = ? AND key = ?;
+
+-- name: GetCursorForListS3Objects :one
+-- Get cursor position for keyset pagination
+SELECT is_folder, key FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (sqlc.arg('prefix') = '' OR prefix = sqlc.arg('prefix'))
+ORDER BY is_folder DESC, key ASC
+LIMIT 1 OFFSET sqlc.arg('offset');
+
+-- name: ListS3Objects :many
+SELECT * FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (sqlc.arg('prefix') = '' OR prefix = sqlc.arg('prefix'))
+  AND (sqlc.narg('cursor_is_folder') IS NULL
+       OR (is_folder, key) > (sqlc.narg('cursor_is_folder'), CAST(sqlc.narg('cursor_key') AS TEXT)))
+ORDER BY is_folder DESC, key ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetCursorForListS3ObjectsByPrefix :one
+-- Get cursor position for keyset pagination with prefix search
+SELECT is_folder, key FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND substr(prefix, 1, length(CAST(sqlc.arg('prefix') AS TEXT))) = CAST(sqlc.arg('prefix') AS TEXT)
+ORDER BY is_folder DESC, key ASC
+LIMIT 1 OFFSET sqlc.arg('offset');
+
+-- name: ListS3ObjectsByPrefix :many
+SELECT * FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND substr(prefix, 1, length(CAST(sqlc.arg('prefix') AS TEXT))) = CAST(sqlc.arg('prefix') AS TEXT)
+  AND (sqlc.narg('cursor_is_folder') IS NULL
+       OR (is_folder, key) > (sqlc.narg('cursor_is_folder'), CAST(sqlc.narg('cursor_key') AS TEXT)))
+ORDER BY is_folder DESC, key ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetCursorForSearchS3Objects :one
+-- Get cursor position for keyset pagination in search results
+SELECT is_folder, key FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (key LIKE ('%' || CAST(sqlc.arg('pattern') AS TEXT) || '%') ESCAPE '\')
+ORDER BY is_folder DESC, key ASC
+LIMIT 1 OFFSET sqlc.arg('offset');
+
+-- name: SearchS3Objects :many
+SELECT * FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (key LIKE ('%' || CAST(sqlc.arg('pattern') AS TEXT) || '%') ESCAPE '\')
+  AND (sqlc.narg('cursor_is_folder') IS NULL
+       OR (is_folder, key) > (sqlc.narg('cursor_is_folder'), CAST(sqlc.narg('cursor_key') AS TEXT)))
+ORDER BY is_folder DESC, key ASC
+LIMIT sqlc.arg('limit');
+
+-- name: SearchS3ObjectsRanked :many
+-- Substring search ranking the first candidates, in the order of SearchS3Objects, with results whose
+-- basename matches first. SQLite has no trigram similarity, so they are not ranked any further.
+SELECT o.* FROM s3_objects o
+JOIN (
+  SELECT c.id, (c.basename LIKE ('%' || CAST(sqlc.arg('pattern') AS TEXT) || '%') ESCAPE '\') AS basename_match
+  FROM s3_objects c
+  WHERE c.bucket_id = sqlc.arg('bucket_id')
+    AND (c.key LIKE ('%' || CAST(sqlc.arg('pattern') AS TEXT) || '%') ESCAPE '\')
+  ORDER BY c.is_folder DESC, c.key ASC
+  LIMIT sqlc.arg('candidates')
+) AS r ON r.id = o.id
+ORDER BY r.basename_match DESC, o.is_folder DESC, o.key ASC
+LIMIT sqlc.arg('result_limit');
+
+-- name: HasTrigramExtension :one
+-- SQLite has no trigram indexes: searches always use SearchS3Objects
+SELECT CAST(FALSE AS BOOLEAN) AS installed;
+
+-- name: BucketHasFiles :one
+-- Reports whether the catalog holds any file of the bucket, false before its first scan
+SELECT CAST(EXISTS (
+  SELECT 1 FROM s3_objects WHERE bucket_id = ? AND is_folder = FALSE) AS BOOLEAN) AS has_files;
+
+-- name: CountS3Objects :one
+SELECT COUNT(*) FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (sqlc.arg('prefix') = '' OR prefix = sqlc.arg('prefix'));
+
+-- name: ListS3Folders :many
+SELECT * FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (
+    -- Handle root level (empty prefix): objects with empty or null prefix
+    (sqlc.arg('prefix') = '' AND (prefix = '' OR prefix IS NULL))
+    OR
+    -- Handle non-empty prefix: exact prefix match
+    (sqlc.arg('prefix') != '' AND prefix = sqlc.arg('prefix'))
+  )
+  AND is_folder = true
+  AND (CAST(sqlc.narg('cursor_key') AS TEXT) IS NULL OR key > CAST(sqlc.narg('cursor_key') AS TEXT))
+ORDER BY key ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListS3Files :many
+SELECT * FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (
+    -- Handle root level (empty prefix): objects with empty or null prefix
+    (sqlc.arg('prefix') = '' AND (prefix = '' OR prefix IS NULL))
+    OR
+    -- Handle non-empty prefix: exact prefix match
+    (sqlc.arg('prefix') != '' AND prefix = sqlc.arg('prefix'))
+  )
+  AND is_folder = false
+  AND (CAST(sqlc.narg('cursor_key') AS TEXT) IS NULL OR key > CAST(sqlc.narg('cursor_key') AS TEXT))
+ORDER BY key ASC
+LIMIT sqlc.arg('limit');
+
+-- name: CreateS3Object :one
+INSERT INTO s3_objects (bucket_id, key, size, last_modified, etag, storage_class, is_folder, prefix)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (bucket_id, key) DO UPDATE SET
+    size = excluded.size,
+    last_modified = excluded.last_modified,
+    etag = excluded.etag,
+    storage_class = excluded.storage_class,
+    is_folder = excluded.is_folder,
+    prefix = excluded.prefix,
+    marked_for_deletion = FALSE,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: UpsertInventoryFolders :exec
+-- Parent folders of a batch of files read from an S3 Inventory report, a JSON array of
+-- {"key", "prefix"} objects
+INSERT INTO s3_objects (bucket_id, key, size, last_modified, is_folder, prefix)
+SELECT sqlc.arg('bucket_id'), json_extract(j.value, '$.key'), 0, CURRENT_TIMESTAMP, TRUE,
+       NULLIF(json_extract(j.value, '$.prefix'), '')
+FROM (SELECT CAST(sqlc.arg('folders') AS TEXT) AS doc) AS batch, json_each(batch.doc) AS j
+WHERE TRUE
+ON CONFLICT (bucket_id, key) DO UPDATE SET
+    is_folder = TRUE,
+    marked_for_deletion = FALSE,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: RecordInventoryChanges :exec
+-- Changes of a batch of files read from an S3 Inventory report, a JSON array of objects as in
+-- UpsertInventoryObjects, to record before the batch is upserted
+INSERT INTO object_changes (bucket_id, scan_job_id, key, change, old_size, old_etag, new_size, new_etag)
+SELECT sqlc.arg('bucket_id'), sqlc.arg('scan_job_id'), i.object_key,
+       CASE WHEN o.id IS NULL THEN 'created' ELSE 'modified' END,
+       o.size, o.etag, i.object_size, NULLIF(i.object_etag, '')
+FROM (
+    SELECT json_extract(j.value, '$.key') AS object_key,
+           json_extract(j.value, '$.size') AS object_size,
+           json_extract(j.value, '$.etag') AS object_etag
+    FROM (SELECT CAST(sqlc.arg('objects') AS TEXT) AS doc) AS batch, json_each(batch.doc) AS j
+) AS i
+LEFT JOIN s3_objects o ON o.bucket_id = sqlc.arg('bucket_id') AND o.key = i.object_key
+WHERE o.id IS NULL OR o.size <> i.object_size OR o.etag IS NOT NULLIF(i.object_etag, '');
+
+-- name: UpsertInventoryObjects :exec
+-- A batch of files read from an S3 Inventory report, a JSON array of {"key", "size", "last_modified",
+-- "etag", "storage_class", "encryption_status", "tag_count", "prefix"} objects. Empty strings stand for NULL.
+INSERT INTO s3_objects (
+    bucket_id, key, size, last_modified, etag, storage_class, encryption_status, tag_count, is_folder, prefix
+)
+SELECT sqlc.arg('bucket_id'), json_extract(j.value, '$.key'), json_extract(j.value, '$.size'),
+       NULLIF(json_extract(j.value, '$.last_modified'), ''), NULLIF(json_extract(j.value, '$.etag'), ''),
+       NULLIF(json_extract(j.value, '$.storage_class'), ''), NULLIF(json_extract(j.value, '$.encryption_status'), ''),
+       CAST(NULLIF(json_extract(j.value, '$.tag_count'), '') AS INTEGER), FALSE,
+       NULLIF(json_extract(j.value, '$.prefix'), '')
+FROM (SELECT CAST(sqlc.arg('objects') AS TEXT) AS doc) AS batch, json_each(batch.doc) AS j
+WHERE TRUE
+ON CONFLICT (bucket_id, key) DO UPDATE SET
+    size = excluded.size,
+    last_modified = excluded.last_modified,
+    etag = excluded.etag,
+    storage_class = excluded.storage_class,
+    encryption_status = excluded.encryption_status,
+    tag_count = excluded.tag_count,
+    is_folder = excluded.is_folder,
+    prefix = excluded.prefix,
+    marked_for_deletion = FALSE,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: UpdateS3Object :one
+UPDATE s3_objects
+SET size = sqlc.arg('size'),
+    last_modified = sqlc.narg('last_modified'),
+    etag = sqlc.narg('etag'),
+    storage_class = sqlc.narg('storage_class'),
+    updated_at = CURRENT_TIMESTAMP
+WHERE bucket_id = sqlc.arg('bucket_id') AND key = sqlc.arg('key')
+RETURNING *;
+
+-- name: DeleteS3Object :exec
+DELETE FROM s3_objects
+WHERE bucket_id = ? AND key = ?;
+
+-- name: DeleteS3ObjectsByKeys :execrows
+-- keys is a JSON array of strings
+DELETE FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND key IN (SELECT j.value FROM (SELECT CAST(sqlc.arg('keys') AS TEXT) AS doc) AS batch, json_each(batch.doc) AS j);
+
+-- name: GetS3ObjectTreeStats :one
+-- Counts the objects stored under a folder, its marker included. Keys compare as bytes and 0xF5
+-- never occurs in UTF-8, so the folder is a range of UNIQUE(bucket_id, key).
+SELECT COUNT(*) AS object_count,
+       CAST(COALESCE(SUM(size), 0) AS BIGINT) AS total_size
+FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND key >= CAST(sqlc.arg('prefix') AS TEXT) AND key < CAST(sqlc.arg('prefix') AS TEXT) || CAST(x'F5' AS TEXT);
+
+-- name: ListS3ObjectTreeSample :many
+SELECT key FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND key >= CAST(sqlc.arg('prefix') AS TEXT) AND key < CAST(sqlc.arg('prefix') AS TEXT) || CAST(x'F5' AS TEXT)
+  AND is_folder = false
+ORDER BY key
+LIMIT sqlc.arg('max_keys');
+
+-- name: DeleteS3ObjectsByBucket :exec
+DELETE FROM s3_objects
+WHERE bucket_id = ?;
+
+-- name: GetDirectChildren :many
+-- Get only immediate children (files and folders) under a specific prefix
+-- For hierarchical navigation - not recursive
+SELECT * FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (
+    -- Handle root level (empty prefix): objects with empty or null prefix
+    (sqlc.arg('prefix') = '' AND (prefix = '' OR prefix IS NULL))
+    OR
+    -- Handle non-empty prefix: exact prefix match
+    (sqlc.arg('prefix') != '' AND prefix = sqlc.arg('prefix'))
+  )
+  AND key != sqlc.arg('prefix')
+  AND (
+    -- Direct files: files whose prefix exactly matches the given prefix
+    (is_folder = false)
+    OR
+    -- Direct folders: folders whose prefix exactly matches the given prefix
+    (is_folder = true)
+  )
+  AND (sqlc.narg('cursor_is_folder') IS NULL
+       OR (is_folder, key) > (sqlc.narg('cursor_is_folder'), CAST(sqlc.narg('cursor_key') AS TEXT)))
+ORDER BY is_folder DESC, key ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetParentFolder :one
+-- Get parent folder information for breadcrumb navigation
+SELECT * FROM s3_objects
+WHERE bucket_id = ?
+  AND key = ?
+  AND is_folder = true
+LIMIT 1;
+
+-- name: GetBreadcrumbPath :many
+-- Get all parent folders for breadcrumb navigation
+SELECT * FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND is_folder = true
+  AND substr(CAST(sqlc.arg('key') AS TEXT), 1, length(key)) = key
+  AND key != CAST(sqlc.arg('key') AS TEXT)
+ORDER BY LENGTH(key) ASC;
+
+-- name: MarkAllObjectsForDeletion :exec
+-- Mark all objects in a bucket as potentially deleted before scanning
+UPDATE s3_objects
+SET marked_for_deletion = TRUE,
+    updated_at = CURRENT_TIMESTAMP
+WHERE bucket_id = ?;
+
+-- name: UnmarkObjectForDeletion :exec
+-- Unmark a specific object as not deleted (found during scan)
+UPDATE s3_objects
+SET marked_for_deletion = FALSE,
+    updated_at = CURRENT_TIMESTAMP
+WHERE bucket_id = ? AND key = ?;
+
+-- name: DeleteMarkedObjects :exec
+-- Delete all objects that are still marked for deletion after scan
+DELETE FROM s3_objects
+WHERE bucket_id = ? AND marked_for_deletion = TRUE;
+
+-- name: CountMarkedObjects :one
+-- Count objects marked for deletion
+SELECT COUNT(*) FROM s3_objects
+WHERE bucket_id = ? AND marked_for_deletion = TRUE;
+
+-- name: CountDirectChildrenFolders :one
+-- Count only immediate child folders under a specific prefix
+-- For pagination - not recursive
+SELECT COUNT(*) FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (
+    -- Handle root level (empty prefix): objects with empty or null prefix
+    (sqlc.arg('prefix') = '' AND (prefix = '' OR prefix IS NULL))
+    OR
+    -- Handle non-empty prefix: exact prefix match
+    (sqlc.arg('prefix') != '' AND prefix = sqlc.arg('prefix'))
+  )
+  AND key != sqlc.arg('prefix')
+  AND is_folder = true;
+
+-- name: CountDirectChildrenFiles :one
+-- Count only immediate child files under a specific prefix
+-- For pagination - not recursive
+SELECT COUNT(*) FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND (
+    -- Handle root level (empty prefix): objects with empty or null prefix
+    (sqlc.arg('prefix') = '' AND (prefix = '' OR prefix IS NULL))
+    OR
+    -- Handle non-empty prefix: exact prefix match
+    (sqlc.arg('prefix') != '' AND prefix = sqlc.arg('prefix'))
+  )
+  AND key != sqlc.arg('prefix')
+  AND is_folder = false;
+
+-- name: ListS3ObjectsByKeys :many
+-- keys is a JSON array of strings
+SELECT * FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND key IN (SELECT j.value FROM (SELECT CAST(sqlc.arg('keys') AS TEXT) AS doc) AS batch, json_each(batch.doc) AS j);
+
+-- name: ListExportObjects :many
+-- A batch of the files exported by s3xplorer export, after after_key in key order. The pattern and
+-- exclude filters are ignored when empty.
+SELECT key, size, last_modified, COALESCE(etag, '') AS etag, COALESCE(storage_class, '') AS storage_class
+FROM s3_objects
+WHERE bucket_id = sqlc.arg('bucket_id')
+  AND is_folder = FALSE
+  AND substr(key, 1, length(CAST(sqlc.arg('prefix') AS TEXT))) = CAST(sqlc.arg('prefix') AS TEXT)
+  AND (CAST(sqlc.arg('pattern') AS TEXT) = ''
+       OR (key LIKE ('%' || CAST(sqlc.arg('pattern') AS TEXT) || '%') ESCAPE '\'))
+  AND (CAST(sqlc.arg('exclude') AS TEXT) = ''
+       OR substr(key, 1, length(CAST(sqlc.arg('exclude') AS TEXT))) != CAST(sqlc.arg('exclude') AS TEXT))
+  AND key > CAST(sqlc.arg('after_key') AS TEXT)
+ORDER BY key
+LIMIT sqlc.arg('batch_size');
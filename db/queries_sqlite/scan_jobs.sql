@@ -0,0 +1,91 @@
+-- name: GetScanJob :one
+SELECT * FROM scan_jobs
+WHERE id = ?;
+
+-- name: ListScanJobs :many
+SELECT * FROM scan_jobs
+WHERE bucket_id = sqlc.arg('bucket_id')
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetLatestScanJob :one
+SELECT * FROM scan_jobs
+WHERE bucket_id = ?
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+
+-- name: CreateScanJob :one
+INSERT INTO scan_jobs (bucket_id, status)
+VALUES (?, ?)
+RETURNING *;
+
+-- name: CreateGlobalScanJob :one
+INSERT INTO scan_jobs (bucket_id, status)
+VALUES (NULL, ?)
+RETURNING *;
+
+-- name: UpdateScanJobStatus :one
+UPDATE scan_jobs
+SET status = CAST(sqlc.arg('status') AS TEXT),
+    started_at = CASE
+        WHEN CAST(sqlc.arg('status') AS TEXT) = 'running' THEN CURRENT_TIMESTAMP
+        ELSE started_at
+    END,
+    completed_at = CASE
+        WHEN CAST(sqlc.arg('status') AS TEXT) = 'completed' THEN CURRENT_TIMESTAMP
+        WHEN CAST(sqlc.arg('status') AS TEXT) = 'failed' THEN CURRENT_TIMESTAMP
+        ELSE completed_at
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: UpdateScanJobProgress :one
+UPDATE scan_jobs
+SET objects_scanned = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: UpdateScanJobError :one
+UPDATE scan_jobs
+SET status = 'failed',
+    error_message = ?,
+    completed_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: UpdateScanJobStats :one
+UPDATE scan_jobs
+SET objects_scanned = ?,
+    objects_created = ?,
+    objects_updated = ?,
+    objects_deleted = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: UpdateScanJobBucketStats :one
+UPDATE scan_jobs
+SET buckets_validated = ?,
+    buckets_marked_inaccessible = ?,
+    buckets_cleaned_up = ?,
+    bucket_validation_errors = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: UpdateScanJobFullStats :one
+UPDATE scan_jobs
+SET objects_scanned = ?,
+    objects_created = ?,
+    objects_updated = ?,
+    objects_deleted = ?,
+    buckets_validated = ?,
+    buckets_marked_inaccessible = ?,
+    buckets_cleaned_up = ?,
+    bucket_validation_errors = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
@@ -0,0 +1,26 @@
+-- name: CreateShare :one
+INSERT INTO shares (token, bucket_name, key, creator, proxied, url, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetShareByToken :one
+SELECT * FROM shares
+WHERE token = ?;
+
+-- name: ListSharesByCreator :many
+SELECT * FROM shares
+WHERE creator = ?
+ORDER BY created_at DESC, id DESC
+LIMIT ?;
+
+-- name: RevokeShare :execrows
+UPDATE shares
+SET revoked = TRUE,
+    revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND creator = ? AND revoked = FALSE;
+
+-- name: RecordShareDownload :exec
+UPDATE shares
+SET download_count = download_count + 1,
+    last_downloaded_at = CURRENT_TIMESTAMP
+WHERE id = ?;
@@ -0,0 +1,100 @@
+-- Sorted directory listings (GetDirectChildrenSorted), one query per sort field and direction so
+-- each can seek on its index: idx_s3_objects_keyset for names, idx_s3_objects_sort_* otherwise.
+-- The sort expressions must match the index expressions of migration 20261018000002 exactly.
+-- A page starts after the cursor, the sort value and key of the last entry of the previous page,
+-- so deep pages are a seek like the first one. A NULL cursor_key starts at the first entry.
+-- Row comparisons are valid because the sort expression and key are ordered in the same direction.
+
+-- name: ListDirectChildrenByNameAsc :many
+SELECT * FROM s3_objects o
+WHERE o.bucket_id = sqlc.arg('bucket_id')
+  AND o.is_folder = sqlc.arg('is_folder')
+  AND o.key != CAST(sqlc.arg('prefix') AS TEXT)
+  AND ((CAST(sqlc.arg('prefix') AS TEXT) = '' AND (o.prefix = '' OR o.prefix IS NULL))
+       OR (CAST(sqlc.arg('prefix') AS TEXT) != '' AND o.prefix = CAST(sqlc.arg('prefix') AS TEXT)))
+  AND (CAST(sqlc.narg('cursor_key') AS TEXT) IS NULL OR o.key > CAST(sqlc.narg('cursor_key') AS TEXT))
+ORDER BY o.key ASC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListDirectChildrenByNameDesc :many
+SELECT * FROM s3_objects o
+WHERE o.bucket_id = sqlc.arg('bucket_id')
+  AND o.is_folder = sqlc.arg('is_folder')
+  AND o.key != CAST(sqlc.arg('prefix') AS TEXT)
+  AND ((CAST(sqlc.arg('prefix') AS TEXT) = '' AND (o.prefix = '' OR o.prefix IS NULL))
+       OR (CAST(sqlc.arg('prefix') AS TEXT) != '' AND o.prefix = CAST(sqlc.arg('prefix') AS TEXT)))
+  AND (CAST(sqlc.narg('cursor_key') AS TEXT) IS NULL OR o.key < CAST(sqlc.narg('cursor_key') AS TEXT))
+ORDER BY o.key DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListDirectChildrenBySizeAsc :many
+SELECT * FROM s3_objects o
+WHERE o.bucket_id = sqlc.arg('bucket_id')
+  AND o.is_folder = sqlc.arg('is_folder')
+  AND o.key != CAST(sqlc.arg('prefix') AS TEXT)
+  AND ((CAST(sqlc.arg('prefix') AS TEXT) = '' AND (o.prefix = '' OR o.prefix IS NULL))
+       OR (CAST(sqlc.arg('prefix') AS TEXT) != '' AND o.prefix = CAST(sqlc.arg('prefix') AS TEXT)))
+  AND (CAST(sqlc.narg('cursor_key') AS TEXT) IS NULL
+       OR (o.size, o.key) > (CAST(sqlc.arg('cursor_size') AS BIGINT), CAST(sqlc.narg('cursor_key') AS TEXT)))
+ORDER BY o.size ASC, o.key ASC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListDirectChildrenBySizeDesc :many
+SELECT * FROM s3_objects o
+WHERE o.bucket_id = sqlc.arg('bucket_id')
+  AND o.is_folder = sqlc.arg('is_folder')
+  AND o.key != CAST(sqlc.arg('prefix') AS TEXT)
+  AND ((CAST(sqlc.arg('prefix') AS TEXT) = '' AND (o.prefix = '' OR o.prefix IS NULL))
+       OR (CAST(sqlc.arg('prefix') AS TEXT) != '' AND o.prefix = CAST(sqlc.arg('prefix') AS TEXT)))
+  AND (CAST(sqlc.narg('cursor_key') AS TEXT) IS NULL
+       OR (o.size, o.key) < (CAST(sqlc.arg('cursor_size') AS BIGINT), CAST(sqlc.narg('cursor_key') AS TEXT)))
+ORDER BY o.size DESC, o.key DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListDirectChildrenByDateAsc :many
+SELECT * FROM s3_objects o
+WHERE o.bucket_id = sqlc.arg('bucket_id')
+  AND o.is_folder = sqlc.arg('is_folder')
+  AND o.key != CAST(sqlc.arg('prefix') AS TEXT)
+  AND ((CAST(sqlc.arg('prefix') AS TEXT) = '' AND (o.prefix = '' OR o.prefix IS NULL))
+       OR (CAST(sqlc.arg('prefix') AS TEXT) != '' AND o.prefix = CAST(sqlc.arg('prefix') AS TEXT)))
+  AND (CAST(sqlc.narg('cursor_key') AS TEXT) IS NULL
+       OR (COALESCE(o.last_modified, '1970-01-01 00:00:00+00:00'), o.key) > (sqlc.arg('cursor_last_modified'), CAST(sqlc.narg('cursor_key') AS TEXT)))
+ORDER BY COALESCE(o.last_modified, '1970-01-01 00:00:00+00:00') ASC, o.key ASC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListDirectChildrenByDateDesc :many
+SELECT * FROM s3_objects o
+WHERE o.bucket_id = sqlc.arg('bucket_id')
+  AND o.is_folder = sqlc.arg('is_folder')
+  AND o.key != CAST(sqlc.arg('prefix') AS TEXT)
+  AND ((CAST(sqlc.arg('prefix') AS TEXT) = '' AND (o.prefix = '' OR o.prefix IS NULL))
+       OR (CAST(sqlc.arg('prefix') AS TEXT) != '' AND o.prefix = CAST(sqlc.arg('prefix') AS TEXT)))
+  AND (CAST(sqlc.narg('cursor_key') AS TEXT) IS NULL
+       OR (COALESCE(o.last_modified, '1970-01-01 00:00:00+00:00'), o.key) < (sqlc.arg('cursor_last_modified'), CAST(sqlc.narg('cursor_key') AS TEXT)))
+ORDER BY COALESCE(o.last_modified, '1970-01-01 00:00:00+00:00') DESC, o.key DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListDirectChildrenByStorageAsc :many
+SELECT * FROM s3_objects o
+WHERE o.bucket_id = sqlc.arg('bucket_id')
+  AND o.is_folder = sqlc.arg('is_folder')
+  AND o.key != CAST(sqlc.arg('prefix') AS TEXT)
+  AND ((CAST(sqlc.arg('prefix') AS TEXT) = '' AND (o.prefix = '' OR o.prefix IS NULL))
+       OR (CAST(sqlc.arg('prefix') AS TEXT) != '' AND o.prefix = CAST(sqlc.arg('prefix') AS TEXT)))
+  AND (CAST(sqlc.narg('cursor_key') AS TEXT) IS NULL
+       OR (COALESCE(o.storage_class, ''), o.key) > (CAST(sqlc.arg('cursor_storage_class') AS TEXT), CAST(sqlc.narg('cursor_key') AS TEXT)))
+ORDER BY COALESCE(o.storage_class, '') ASC, o.key ASC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListDirectChildrenByStorageDesc :many
+SELECT * FROM s3_objects o
+WHERE o.bucket_id = sqlc.arg('bucket_id')
+  AND o.is_folder = sqlc.arg('is_folder')
+  AND o.key != CAST(sqlc.arg('prefix') AS TEXT)
+  AND ((CAST(sqlc.arg('prefix') AS TEXT) = '' AND (o.prefix = '' OR o.prefix IS NULL))
+       OR (CAST(sqlc.arg('prefix') AS TEXT) != '' AND o.prefix = CAST(sqlc.arg('prefix') AS TEXT)))
+  AND (CAST(sqlc.narg('cursor_key') AS TEXT) IS NULL
+       OR (COALESCE(o.storage_class, ''), o.key) < (CAST(sqlc.arg('cursor_storage_class') AS TEXT), CAST(sqlc.narg('cursor_key') AS TEXT)))
+ORDER BY COALESCE(o.storage_class, '') DESC, o.key DESC
+LIMIT sqlc.arg('page_size');
@@ -0,0 +1,23 @@
+-- name: CreateTrashItem :one
+INSERT INTO trash_items (bucket_name, original_key, trash_bucket, trash_key, version_id, delete_marker_id, size, deleted_by)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetTrashItem :one
+SELECT * FROM trash_items
+WHERE id = ?;
+
+-- name: ListTrashItems :many
+SELECT * FROM trash_items
+ORDER BY deleted_at DESC, id DESC
+LIMIT ?;
+
+-- name: ListTrashItemsDeletedBefore :many
+SELECT * FROM trash_items
+WHERE deleted_at < ?
+ORDER BY deleted_at, id
+LIMIT ?;
+
+-- name: DeleteTrashItem :execrows
+DELETE FROM trash_items
+WHERE id = ?;
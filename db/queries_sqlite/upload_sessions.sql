@@ -0,0 +1,38 @@
+-- name: CreateUploadSession :one
+INSERT INTO upload_sessions (id, bucket_name, key, upload_id, content_type, size, part_size)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetUploadSession :one
+SELECT * FROM upload_sessions
+WHERE id = ?;
+
+-- name: SetUploadSessionStatus :exec
+UPDATE upload_sessions
+SET status = sqlc.arg('status'),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id');
+
+-- name: UpsertUploadPart :exec
+INSERT INTO upload_parts (session_id, part_number, etag, size)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (session_id, part_number) DO UPDATE SET
+    etag = excluded.etag,
+    size = excluded.size,
+    created_at = CURRENT_TIMESTAMP;
+
+-- name: TouchUploadSession :exec
+UPDATE upload_sessions
+SET updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: ListUploadParts :many
+SELECT * FROM upload_parts
+WHERE session_id = ?
+ORDER BY part_number;
+
+-- name: ListStaleUploadSessions :many
+SELECT * FROM upload_sessions
+WHERE status = 'active' AND updated_at < ?
+ORDER BY updated_at
+LIMIT ?;
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	configapp "github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dbsvc"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/export"
+)
+
+// exportBufferSize is the write buffer of the export command.
+const exportBufferSize = 1 << 20
+
+// ErrUnknownExportFormat is returned by the export command for an unknown -format.
+var ErrUnknownExportFormat = errors.New("unknown export format")
+
+// runExport implements the export command: it writes the files of the catalog of a bucket to stdout
+// or to a file, without starting the web server. Logs go to stderr so stdout can be piped.
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	fileName := flags.String("f", "", "Configuration file")
+	bucket := flags.String("bucket", "", "Bucket to export (default: the configured bucket)")
+	prefix := flags.String("prefix", "", "Only export the files under this prefix (default: the configured prefix)")
+	search := flags.String("search", "", "Only export the files with this text in their key")
+	format := flags.String("format", export.FormatCSV, "Export format: csv, ndjson or parquet")
+	output := flags.String("o", "", "Output file (default: stdout)")
+	_ = flags.Parse(args)
+
+	if *fileName == "" {
+		flags.Usage()
+		return ErrConfigFileNotProvided
+	}
+	if !slices.Contains(export.Formats, *format) {
+		return fmt.Errorf("%w: %s", ErrUnknownExportFormat, *format)
+	}
+	cfg, err := configapp.ReadYamlCnxFile(*fileName)
+	if err != nil {
+		return fmt.Errorf("error reading configuration file: %w", err)
+	}
+
+	filter := dto.CatalogFilter{Bucket: *bucket, Prefix: *prefix, Search: *search}
+	if filter.Bucket == "" {
+		filter.Bucket = cfg.S3.Bucket
+	}
+	if filter.Bucket == cfg.S3.Bucket && filter.Prefix == "" {
+		filter.Prefix = cfg.S3.Prefix
+	}
+
+	l := initTrace(cfg.LogLevel, os.Stderr)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbConn, err := openCatalog(ctx, cfg, l)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = dbConn.Close() }()
+	dbService := dbsvc.NewService(cfg, dbConn)
+	dbService.SetLogger(l)
+
+	return writeExport(ctx, dbService, filter, *format, *output, l)
+}
+
+// writeExport writes the export of filter to output, or to stdout when output is empty.
+// An interrupted export does not leave a partial output file.
+func writeExport(
+	ctx context.Context, src export.Source, filter dto.CatalogFilter, format, output string, l *slog.Logger,
+) error {
+	var out io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("cannot create output file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+
+	start := time.Now()
+	buffered := bufio.NewWriterSize(out, exportBufferSize)
+	count, err := export.Catalog(ctx, src, filter, format, buffered)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		if output != "" {
+			_ = os.Remove(output)
+		}
+		return err
+	}
+	l.Info("Catalog exported",
+		slog.String("bucket", filter.Bucket),
+		slog.String("format", format),
+		slog.Int64("files", count),
+		slog.Duration("duration", time.Since(start)))
+	return nil
+}
@@ -8,10 +8,14 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
 	"github.com/sgaunet/s3xplorer/pkg/views"
 )
 
@@ -142,20 +146,35 @@ func (s *App) loadAndRenderBucketContents(
 	r *http.Request,
 	folderPath string,
 ) error {
+	// Parse sort parameters (unknown values fall back to the default order)
+	sortOpts, err := ParseSortParams(r)
+	if err != nil {
+		s.log.Warn("Invalid sort parameters", slog.String("error", err.Error()))
+	}
+
 	// Parse pagination parameters
 	page, err := ParsePaginationParams(r)
 	if err != nil {
 		// Invalid page parameter, redirect to page 1
 		s.log.Warn("Invalid page parameter", slog.String("error", err.Error()))
-		redirectURL := fmt.Sprintf("/?folder=%s&page=1", folderPath)
+		redirectURL := fmt.Sprintf("/?folder=%s&page=1%s", url.QueryEscape(folderPath), sortOpts.QueryString())
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return nil
+	}
+
+	// Parse the cursor of the page, the entry of the neighbouring page it follows or precedes
+	cursor, err := ParseCursorParams(r, page)
+	if err != nil {
+		s.log.Warn("Invalid page cursor", slog.String("error", err.Error()))
+		redirectURL := fmt.Sprintf("/?folder=%s&page=1%s", url.QueryEscape(folderPath), sortOpts.QueryString())
 		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 		return nil
 	}
 
 	// Get paginated direct children (immediate subfolders and files)
 	const pageSize = 50
-	folders, files, totalFolders, totalFiles, err := s.dbsvc.GetDirectChildrenPaginated(
-		ctx, s.cfg.S3.Bucket, folderPath, page, pageSize,
+	folders, files, totalFolders, totalFiles, err := s.dbsvc.GetDirectChildrenSorted(
+		ctx, s.cfg.S3.Bucket, folderPath, pageSize, sortOpts, cursor,
 	)
 	if err != nil {
 		s.log.Error("Error getting paginated children", slog.String("error", err.Error()))
@@ -166,15 +185,16 @@ func (s *App) loadAndRenderBucketContents(
 	totalItems := totalFolders + totalFiles
 	paging := dto.NewPaginationInfo(totalItems, pageSize, page)
 
-	// Validate page number against actual total pages
+	// Validate page number against actual total pages. A short page before a cursor means entries were
+	// removed since the page was linked: the page number is stale, so the listing restarts.
 	validPage := ValidatePageNumber(page, paging.TotalPages)
-	if page != validPage {
+	if page != validPage || (cursor.Before != nil && len(folders)+len(files) < pageSize) {
 		// Page is out of bounds, redirect to page 1
 		s.log.Debug("Page out of bounds, redirecting",
 			slog.Int("requested", page),
 			slog.Int("valid", validPage),
 			slog.Int("totalPages", paging.TotalPages))
-		redirectURL := fmt.Sprintf("/?folder=%s&page=1", folderPath)
+		redirectURL := fmt.Sprintf("/?folder=%s&page=1%s", url.QueryEscape(folderPath), sortOpts.QueryString())
 		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 		return nil
 	}
@@ -184,7 +204,7 @@ func (s *App) loadAndRenderBucketContents(
 
 	// Render the index page with hierarchical navigation and pagination
 	err = views.RenderIndexHierarchical(
-		folders, files, folderPath, breadcrumbs, s.cfg, &paging,
+		folders, files, folderPath, breadcrumbs, s.cfg, &paging, sortOpts,
 	).Render(ctx, w)
 	if err != nil {
 		s.log.Error("Failed to render index page", slog.String("error", err.Error()))
@@ -256,35 +276,170 @@ func (s *App) extractAndValidateKey(r *http.Request) (string, error) {
 	return key, nil
 }
 
-// downloadS3Object downloads an object from S3 and streams it to the HTTP response.
-func (s *App) downloadS3Object(ctx context.Context, w http.ResponseWriter, key string) error {
-	p := s3.GetObjectInput{
-		Bucket: &s.cfg.S3.Bucket,
-		Key:    &key,
+// downloadOptionsFromRequest maps the client's range and conditional headers to S3 GetObject options.
+func downloadOptionsFromRequest(r *http.Request) s3svc.GetObjectOptions {
+	opts := s3svc.GetObjectOptions{
+		IfMatch:     r.Header.Get("If-Match"),
+		IfNoneMatch: r.Header.Get("If-None-Match"),
+	}
+
+	// S3 serves a single range per request; multi-range requests get the full object (allowed by RFC 9110)
+	if rng := r.Header.Get("Range"); strings.HasPrefix(rng, "bytes=") && !strings.Contains(rng, ",") {
+		opts.Range = rng
+	}
+
+	// If-Modified-Since is ignored when If-None-Match is present (RFC 9110 section 13.1.3)
+	if opts.IfNoneMatch == "" {
+		if t, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil {
+			opts.IfModifiedSince = &t
+		}
+	}
+	if opts.IfMatch == "" {
+		if t, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil {
+			opts.IfUnmodifiedSince = &t
+		}
+	}
+	return opts
+}
+
+// applyIfRange drops the range when the If-Range validator no longer matches the object,
+// so the client receives the full, current object instead of a mismatched piece.
+func applyIfRange(
+	ctx context.Context, r *http.Request, svc *s3svc.Service, key string, opts *s3svc.GetObjectOptions,
+) error {
+	ifRange := r.Header.Get("If-Range")
+	if ifRange == "" || opts.Range == "" {
+		return nil
 	}
 
-	o, err := s.awsS3Client.GetObject(ctx, &p)
+	info, err := svc.StatObjectVersion(ctx, key, opts.VersionID)
 	if err != nil {
+		return fmt.Errorf("error getting object metadata: %w", err)
+	}
+
+	if t, err := http.ParseTime(ifRange); err == nil {
+		if info.LastModified.Truncate(time.Second).After(t) {
+			opts.Range = ""
+		}
+		return nil
+	}
+	// Only strong ETags can validate a range
+	if strings.HasPrefix(ifRange, "W/") || ifRange != info.ETag {
+		opts.Range = ""
+	}
+	return nil
+}
+
+// downloadS3Object streams an object from S3 to the HTTP response.
+// Range and conditional headers are forwarded to S3 so seeking and resumed downloads
+// work without transferring the whole object; 206, 304, 412 and 416 are answered accordingly.
+// svc selects the bucket the object is read from, and versionID a version other than the current one.
+func (s *App) downloadS3Object(
+	ctx context.Context, w http.ResponseWriter, r *http.Request, svc *s3svc.Service, key, versionID string,
+) error {
+	opts := downloadOptionsFromRequest(r)
+	opts.VersionID = versionID
+	if err := applyIfRange(ctx, r, svc, key, &opts); err != nil {
+		return err
+	}
+
+	o, err := svc.GetObjectStream(ctx, key, opts)
+	switch {
+	case errors.Is(err, s3svc.ErrNotModified):
+		// A 304 carries the validators the client compares with its cached copy
+		if info, statErr := svc.StatObjectVersion(ctx, key, versionID); statErr == nil {
+			if info.ETag != "" {
+				w.Header().Set("ETag", info.ETag)
+			}
+			if !info.LastModified.IsZero() {
+				w.Header().Set("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
+			}
+		}
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	case errors.Is(err, s3svc.ErrPreconditionFailed):
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return nil
+	case errors.Is(err, s3svc.ErrRangeNotSatisfiable):
+		if info, statErr := svc.StatObjectVersion(ctx, key, versionID); statErr == nil {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+		}
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		return nil
+	case err != nil:
 		return fmt.Errorf("error getting object from S3: %w", err)
 	}
 	defer o.Body.Close() //nolint:errcheck
 
-	w.Header().Set("Content-Disposition", "attachment; filename="+key)
-	// Handle ContentType which is a pointer
 	contentType := "application/octet-stream" // Default content type
-	if o.ContentType != nil {
-		contentType = *o.ContentType
+	if o.ContentType != "" {
+		contentType = o.ContentType
 	}
-	w.Header().Set("Content-Type", contentType)
 
-	_, err = io.Copy(w, o.Body)
-	if err != nil {
-		return fmt.Errorf("error copying S3 object to response: %w", err)
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Disposition", contentDisposition("attachment", path.Base(key)))
+	h.Set("Content-Length", strconv.FormatInt(o.ContentLength, 10))
+	h.Set("Accept-Ranges", "bytes")
+	if o.ETag != "" {
+		h.Set("ETag", o.ETag)
 	}
+	if !o.LastModified.IsZero() {
+		h.Set("Last-Modified", o.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	status := http.StatusOK
+	if o.Partial() {
+		h.Set("Content-Range", o.ContentRange)
+		status = http.StatusPartialContent
+	}
+	w.WriteHeader(status)
 
+	// Headers are sent: a copy error (usually the client going away) can only be logged
+	if _, err := io.Copy(w, o.Body); err != nil {
+		s.log.Warn("downloadS3Object: error copying S3 object to response",
+			slog.String("key", key), slog.String("error", err.Error()))
+	}
 	return nil
 }
 
+// contentDisposition builds an RFC 6266 Content-Disposition header value.
+// It always carries an ASCII filename fallback and adds an RFC 5987 filename* parameter
+// when the name contains characters that cannot be sent as a plain quoted string.
+func contentDisposition(dispositionType, filename string) string {
+	var fallback strings.Builder
+	needsExtended := false
+	for _, r := range filename {
+		if r == '"' || r == '\\' || r < 0x20 || r > 0x7e {
+			fallback.WriteByte('_')
+			needsExtended = true
+			continue
+		}
+		fallback.WriteRune(r)
+	}
+
+	value := fmt.Sprintf("%s; filename=\"%s\"", dispositionType, fallback.String())
+	if needsExtended {
+		value += "; filename*=UTF-8''" + rfc5987Escape(filename)
+	}
+	return value
+}
+
+// rfc5987Escape percent-encodes a value for an RFC 5987 ext-value (attr-char is kept as is).
+func rfc5987Escape(value string) string {
+	const attrChars = "!#$&+-.^_`|~"
+	var b strings.Builder
+	for _, c := range []byte(value) {
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+			strings.IndexByte(attrChars, c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
 // DownloadFile handles the download request for a specific file from S3.
 func (s *App) DownloadFile(w http.ResponseWriter, r *http.Request) {
 	// Extract and validate the key parameter
@@ -295,8 +450,14 @@ func (s *App) DownloadFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Older versions are only served when version history is enabled
+	versionID := ""
+	if s.cfg.S3.EnableVersions {
+		versionID = r.URL.Query().Get("version")
+	}
+
 	// Download the object from S3
-	err = s.downloadS3Object(r.Context(), w, key)
+	err = s.downloadS3Object(r.Context(), w, r, s.s3svc, key, versionID)
 	if err != nil {
 		s.log.Error("DownloadFile: download failed", slog.String("error", err.Error()))
 		s.renderErrorPage(r.Context(), w, err.Error())
@@ -304,51 +465,6 @@ func (s *App) DownloadFile(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// RestoreHandler restores an object from Glacier.
-func (s *App) RestoreHandler(w http.ResponseWriter, r *http.Request) {
-	var err error
-	var f string
-	keys, ok := r.URL.Query()["key"]
-
-	if !ok || len(keys[0]) < 1 {
-		return
-	}
-	folder, ok := r.URL.Query()["folder"]
-	if !ok || len(folder[0]) < 1 {
-		f = ""
-	} else {
-		// Query()["key"] will return an array of items,
-		// we only want the single item.
-		f = folder[0]
-	}
-	// Query()["key"] will return an array of items,
-	// we only want the single item.
-	key := keys[0]
-	s.log.Debug("RestoreHandler", slog.String("key", key), slog.String("f", f))
-
-	if s.cfg.S3.Prefix != "" {
-		if !strings.HasPrefix(key, s.cfg.S3.Prefix) {
-			s.log.Error("RestoreHandler: Invalid key")
-			if renderErr := views.RenderError("Invalid key").Render(r.Context(), w); renderErr != nil {
-				s.log.Error("Failed to render error page", slog.String("error", renderErr.Error()))
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
-			}
-			return
-		}
-	}
-
-	err = s.s3svc.RestoreObject(r.Context(), key)
-	if err != nil {
-		s.log.Error("RestoreHandler: error when called RestoreObject", slog.String("error", err.Error()))
-		if renderErr := views.RenderError(err.Error()).Render(r.Context(), w); renderErr != nil {
-			s.log.Error("Failed to render error page", slog.String("error", renderErr.Error()))
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
-		return
-	}
-	http.Redirect(w, r, "/?folder="+f, http.StatusTemporaryRedirect)
-}
-
 // HealthCheckHandler provides overall application health status.
 func (s *App) HealthCheckHandler(w http.ResponseWriter, _ *http.Request) {
 	health := make(map[string]any)
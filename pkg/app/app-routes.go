@@ -8,11 +8,66 @@ func (s *App) initRouter() {
 	s.router.HandleFunc("/favicon.ico", views.FaviconHandler)
 	s.router.HandleFunc("/", s.IndexBucket)
 	s.router.HandleFunc("/download", s.DownloadFile)
-	s.router.HandleFunc("/restore", s.RestoreHandler)
+	s.router.HandleFunc("/preview", s.PreviewHandler)
+	s.router.HandleFunc("/preview/raw", s.PreviewRawHandler)
+	s.router.HandleFunc("/archive", s.ArchiveHandler).Methods("GET", "POST")
+	s.router.HandleFunc("/share", s.ShareFormHandler).Methods("GET")
+	s.router.HandleFunc("/share", s.CreateShareHandler).Methods("POST")
+	s.router.HandleFunc("/shares", s.MySharesHandler).Methods("GET")
+	s.router.HandleFunc("/shares/revoke", s.RevokeShareHandler).Methods("POST")
+	s.router.HandleFunc("/s/{token}", s.SharedObjectHandler).Methods("GET", "HEAD")
+	s.router.HandleFunc("/restore", s.RestoreFormHandler).Methods("GET")
+	s.router.HandleFunc("/restore", s.RestoreHandler).Methods("POST")
+	s.router.HandleFunc("/restores", s.RestoresHandler).Methods("GET")
+	s.router.HandleFunc("/api/restores/notifications", s.RestoreNotificationsHandler).Methods("POST")
 	s.router.HandleFunc("/search", s.SearchHandler)
 	s.router.HandleFunc("/buckets", s.BucketListingHandler)
+	s.router.HandleFunc("/buckets/config", s.BucketConfigHandler).Methods("GET")
+	s.router.HandleFunc("/buckets/config", s.RefreshBucketConfigHandler).Methods("POST")
+	s.router.HandleFunc("/lifecycle", s.LifecycleHandler).Methods("GET")
+	s.router.HandleFunc("/lifecycle/rule", s.LifecycleRuleFormHandler).Methods("GET")
+	s.router.HandleFunc("/lifecycle/rule", s.LifecycleRuleHandler).Methods("POST")
+	s.router.HandleFunc("/lifecycle/delete", s.DeleteLifecycleRuleHandler).Methods("POST")
 	s.router.HandleFunc("/upload", s.UploadHandler).Methods("POST")
+	s.router.HandleFunc("/upload/batch", s.BatchUploadHandler).Methods("POST")
+	s.router.HandleFunc("/api/uploads", s.CreateUploadSessionHandler).Methods("POST")
+	s.router.HandleFunc("/api/uploads/conflicts", s.UploadConflictsHandler).Methods("POST")
+	s.router.HandleFunc("/api/uploads/{id}", s.GetUploadSessionHandler).Methods("GET")
+	s.router.HandleFunc("/api/uploads/{id}", s.AbortUploadSessionHandler).Methods("DELETE")
+	s.router.HandleFunc("/api/uploads/{id}/parts/{number:[0-9]+}", s.UploadPartHandler).Methods("PUT")
+	s.router.HandleFunc("/api/uploads/{id}/complete", s.CompleteUploadSessionHandler).Methods("POST")
 	s.router.HandleFunc("/delete", s.DeleteHandler).Methods("POST")
+	s.router.HandleFunc("/delete/folder", s.DeleteFolderPreviewHandler).Methods("GET")
+	s.router.HandleFunc("/delete/folder", s.DeleteFolderHandler).Methods("POST")
+	s.router.HandleFunc("/object", s.ObjectDetailsHandler).Methods("GET")
+	s.router.HandleFunc("/object/tags", s.UpdateTagsHandler).Methods("POST")
+	s.router.HandleFunc("/object/metadata", s.UpdateMetadataHandler).Methods("POST")
+	s.router.HandleFunc("/versions", s.VersionsHandler).Methods("GET")
+	s.router.HandleFunc("/versions/promote", s.PromoteVersionHandler).Methods("POST")
+	s.router.HandleFunc("/versions/restore", s.RestoreDeletedHandler).Methods("POST")
+	s.router.HandleFunc("/deleted", s.DeletedObjectsHandler).Methods("GET")
+	s.router.HandleFunc("/tags", s.TagsHandler).Methods("GET")
+	s.router.HandleFunc("/duplicates", s.DuplicatesHandler).Methods("GET")
+	s.router.HandleFunc("/changes", s.ChangesHandler).Methods("GET")
+	s.router.HandleFunc("/api/changes", s.ScanDiffHandler).Methods("GET")
+	s.router.HandleFunc("/compare", s.CompareHandler).Methods("GET")
+	s.router.HandleFunc("/compare/export", s.CompareExportHandler).Methods("GET")
+	s.router.HandleFunc("/compare/fix", s.CompareFixHandler).Methods("POST")
+	s.router.HandleFunc("/export", s.ExportHandler).Methods("GET")
+	s.router.HandleFunc("/trash", s.TrashHandler).Methods("GET")
+	s.router.HandleFunc("/trash/restore", s.RestoreTrashHandler).Methods("POST")
+	s.router.HandleFunc("/trash/purge", s.PurgeTrashHandler).Methods("POST")
+	s.router.HandleFunc("/folders", s.CreateFolderHandler).Methods("POST")
+	s.router.HandleFunc("/rename", s.RenameFormHandler).Methods("GET")
+	s.router.HandleFunc("/rename", s.RenameHandler).Methods("POST")
+	s.router.HandleFunc("/move", s.MoveFormHandler).Methods("GET")
+	s.router.HandleFunc("/move", s.MoveHandler).Methods("POST")
+	s.router.HandleFunc("/copy", s.CopyFormHandler).Methods("GET")
+	s.router.HandleFunc("/copy", s.CopyHandler).Methods("POST")
+	s.router.HandleFunc("/storage-class", s.StorageClassFormHandler).Methods("GET")
+	s.router.HandleFunc("/storage-class", s.StorageClassHandler).Methods("POST")
+	s.router.HandleFunc("/jobs/{id:[0-9]+}", s.JobHandler).Methods("GET")
+	s.router.HandleFunc("/api/jobs/{id:[0-9]+}", s.JobStatusHandler).Methods("GET")
 	s.router.HandleFunc("/health", s.HealthCheckHandler)
 	s.router.HandleFunc("/health/database", s.DatabaseHealthHandler)
 	s.srv.Handler = s.router
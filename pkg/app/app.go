@@ -26,6 +26,13 @@ type App struct {
 	router      *mux.Router
 	srv         *http.Server
 	log         *slog.Logger
+
+	// connections holds the clients of the extra connections of the configuration, by name
+	connections map[string]*s3.Client
+
+	// background is cancelled by StopServer to stop janitors and running jobs
+	background     context.Context //nolint:containedctx // lifetime of the App, not of a request
+	stopBackground context.CancelFunc
 }
 
 // emptyLogger returns a logger that discards all log entries.
@@ -65,6 +72,17 @@ func NewApp(cfg config.Config, s3Client *s3.Client, dbService *dbsvc.Service) *A
 	}
 
 	s.initRouter()
+	s.background, s.stopBackground = context.WithCancel(context.Background())
+
+	if dbService != nil {
+		s.failInterruptedJobs()
+	}
+
+	// Abort resumable uploads abandoned by the browser
+	if cfg.S3.EnableUpload && dbService != nil {
+		go s.runUploadJanitor(s.background)
+	}
+
 	// Start the web server in a goroutine
 	go func() {
 		err := s.startWebServer()
@@ -77,6 +95,30 @@ func NewApp(cfg config.Config, s3Client *s3.Client, dbService *dbsvc.Service) *A
 	return s
 }
 
+// backgroundContext returns the context of work that outlives a request, such as jobs.
+func (s *App) backgroundContext() context.Context {
+	if s.background == nil {
+		return context.Background()
+	}
+	return s.background
+}
+
+// failInterruptedJobs marks the jobs a previous process left running as failed.
+func (s *App) failInterruptedJobs() {
+	const timeout = 10 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	n, err := s.dbsvc.FailInterruptedJobs(ctx)
+	if err != nil {
+		s.log.Warn("Failed to clean up interrupted jobs", slog.String("error", err.Error()))
+		return
+	}
+	if n > 0 {
+		s.log.Warn("Marked jobs interrupted by a restart as failed", slog.Int64("count", n))
+	}
+}
+
 // SetLogger sets the logger of the App.
 func (s *App) SetLogger(l *slog.Logger) {
 	s.log = l
@@ -87,6 +129,11 @@ func (s *App) SetLogger(l *slog.Logger) {
 	// Note: dbHealth logger is set during initialization and doesn't need updating
 }
 
+// SetConnections sets the S3 clients of the extra connections, keyed by connection name.
+func (s *App) SetConnections(clients map[string]*s3.Client) {
+	s.connections = clients
+}
+
 // GetDatabaseHealth returns the database health monitor.
 func (s *App) GetDatabaseHealth() *health.DatabaseHealth {
 	return s.dbHealth
@@ -102,6 +149,9 @@ func (s *App) IsDatabaseHealthy() bool {
 
 // StopServer stops the web server.
 func (s *App) StopServer() error {
+	if s.stopBackground != nil {
+		s.stopBackground()
+	}
 	if s.dbHealth != nil {
 		s.dbHealth.Stop()
 	}
@@ -0,0 +1,21 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc/s3svctest"
+)
+
+// newFakeS3App returns an app without the database, configured with cfg, whose S3 requests are
+// answered by handler.
+func newFakeS3App(t *testing.T, handler http.Handler, cfg config.Config) *App {
+	t.Helper()
+
+	client := s3svctest.NewClient(t, handler)
+	svc := s3svc.NewS3Svc(cfg, client)
+	svc.SetLogger(emptyLogger())
+	return &App{cfg: cfg, awsS3Client: client, s3svc: svc, log: emptyLogger()}
+}
@@ -0,0 +1,286 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/sgaunet/s3xplorer/pkg/archive"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+)
+
+var (
+	// ErrArchiveEmpty is returned when there is nothing to put in an archive.
+	ErrArchiveEmpty = errors.New("no files to download")
+	// ErrArchiveTooManyObjects is returned when a folder holds more objects than archive.max_objects.
+	ErrArchiveTooManyObjects = errors.New("too many objects for one archive")
+	// ErrArchiveTooLarge is returned when the selected objects exceed archive.max_total_bytes.
+	ErrArchiveTooLarge = errors.New("archive exceeds the configured size limit")
+	// ErrArchiveOutsidePrefix is returned when a folder or key is outside the configured prefix.
+	ErrArchiveOutsidePrefix = errors.New("cannot download outside configured prefix")
+)
+
+// ArchiveHandler streams a folder (GET/POST "folder") or a multi-selection (POST "keys")
+// as a ZIP or tar.gz archive built on the fly from S3 GETs.
+// Archived Glacier objects are skipped and listed in the MANIFEST.txt entry of the archive.
+func (s *App) ArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		s.renderErrorPage(ctx, w, "Invalid archive request: "+err.Error())
+		return
+	}
+
+	format, err := archive.ParseFormat(r.FormValue("format"))
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	folder := r.FormValue("folder")
+	keys := r.Form["keys"]
+	if err := s.validateArchivePaths(folder, keys); err != nil {
+		s.log.Warn("Archive request outside configured prefix", slog.String("folder", folder))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	manifest := &archive.Manifest{Source: s.cfg.S3.Bucket + "/" + folder, CreatedAt: time.Now()}
+	objects, err := s.resolveArchiveObjects(ctx, folder, keys, manifest)
+	if err != nil {
+		s.log.Error("Failed to prepare archive", slog.String("folder", folder), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	s.log.Info("Archive download",
+		slog.String("folder", folder),
+		slog.String("format", string(format)),
+		slog.Int("objects", len(objects)),
+		slog.Int("skipped", len(manifest.Skipped)))
+
+	filename := archiveBaseName(folder, s.cfg.S3.Bucket, len(keys) > 0) + "." + format.Extension()
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", contentDisposition("attachment", filename))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if err := s.streamArchive(ctx, w, format, folder, objects, manifest); err != nil {
+		// Headers and part of the body are already sent; the client sees a truncated archive
+		s.log.Error("Archive streaming aborted", slog.String("folder", folder), slog.String("error", err.Error()))
+	}
+}
+
+// validateArchivePaths ensures the folder and every key respect the configured prefix.
+func (s *App) validateArchivePaths(folder string, keys []string) error {
+	if s.cfg.S3.Prefix == "" {
+		return nil
+	}
+	if len(keys) == 0 && !strings.HasPrefix(folder, s.cfg.S3.Prefix) {
+		return fmt.Errorf("%w: %s", ErrArchiveOutsidePrefix, folder)
+	}
+	for _, key := range keys {
+		if !strings.HasPrefix(key, s.cfg.S3.Prefix) {
+			return fmt.Errorf("%w: %s", ErrArchiveOutsidePrefix, key)
+		}
+	}
+	return nil
+}
+
+// resolveArchiveObjects returns the objects to archive, enforcing the object and size caps.
+// Selected keys are looked up individually; a folder is expanded recursively from the catalog.
+// Objects that cannot be read (archived, restore in progress) are recorded in the manifest.
+func (s *App) resolveArchiveObjects(
+	ctx context.Context, folder string, keys []string, manifest *archive.Manifest,
+) ([]dto.S3Object, error) {
+	maxObjects := s.cfg.Archive.MaxObjects
+
+	var candidates []dto.S3Object
+	if len(keys) > 0 {
+		if len(keys) > maxObjects {
+			return nil, fmt.Errorf("%w: %d selected, limit is %d", ErrArchiveTooManyObjects, len(keys), maxObjects)
+		}
+		for _, key := range keys {
+			obj, err := s.dbsvc.GetObject(ctx, s.cfg.S3.Bucket, key)
+			if err != nil {
+				manifest.Skip(key, "not found in catalog")
+				continue
+			}
+			candidates = append(candidates, *obj)
+		}
+	} else {
+		// Fetch one more than the cap to detect overflow
+		all, err := s.dbsvc.GetObjectsByPrefix(ctx, s.cfg.S3.Bucket, folder, maxObjects+1, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list folder: %w", err)
+		}
+		for _, obj := range all {
+			// The catalog matches prefixes with LIKE; re-check to ignore wildcard matches
+			if !obj.IsFolder && strings.HasPrefix(obj.Key, folder) {
+				candidates = append(candidates, obj)
+			}
+		}
+		if len(candidates) > maxObjects {
+			return nil, fmt.Errorf("%w: folder holds more than %d objects", ErrArchiveTooManyObjects, maxObjects)
+		}
+	}
+
+	var listed map[string]s3svc.ObjectInfo
+	if prefix, recursive, ok := archivedFolder(folder, len(keys) > 0, candidates); ok {
+		var err error
+		listed, err = s.s3svc.ListRestoreStatus(ctx, prefix, recursive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read restore status: %w", err)
+		}
+	}
+
+	var included []dto.S3Object
+	var total int64
+	for _, obj := range candidates {
+		if obj.IsFolder {
+			continue
+		}
+		if reason := s.archiveSkipReason(ctx, obj, listed); reason != "" {
+			manifest.Skip(obj.Key, reason)
+			continue
+		}
+		total += obj.Size
+		included = append(included, obj)
+	}
+
+	if len(included) == 0 {
+		return nil, ErrArchiveEmpty
+	}
+	if total > s.cfg.Archive.MaxTotalBytes {
+		return nil, fmt.Errorf("%w: %d bytes requested, limit is %d bytes",
+			ErrArchiveTooLarge, total, s.cfg.Archive.MaxTotalBytes)
+	}
+	return included, nil
+}
+
+// archivedFolder returns the folder to list for the restore status of the GLACIER and DEEP_ARCHIVE
+// candidates: the archived folder, or the deepest folder holding every selected archived key, listed
+// recursively only when these keys are in different folders. ok is false when none is archived.
+func archivedFolder(folder string, selected bool, candidates []dto.S3Object) (prefix string, recursive, ok bool) {
+	var keys []string
+	for _, obj := range candidates {
+		if s3svc.IsArchiveStorageClass(obj.StorageClass) {
+			keys = append(keys, obj.Key)
+		}
+	}
+	if len(keys) == 0 {
+		return "", false, false
+	}
+	if !selected {
+		return folder, true, true
+	}
+
+	prefix = keys[0]
+	for _, key := range keys[1:] {
+		for !strings.HasPrefix(key, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	prefix = prefix[:strings.LastIndex(prefix, "/")+1]
+	for _, key := range keys {
+		if strings.Contains(key[len(prefix):], "/") {
+			return prefix, true, true
+		}
+	}
+	return prefix, false, true
+}
+
+// archiveSkipReason returns why an object cannot be archived, or "" when it can be read.
+// The catalog has the storage class and listed the restore status of archived objects, so only
+// Intelligent-Tiering objects, which may sit in an archive access tier, are checked with HeadObject.
+func (s *App) archiveSkipReason(ctx context.Context, obj dto.S3Object, listed map[string]s3svc.ObjectInfo) string {
+	switch {
+	case obj.StorageClass == string(types.StorageClassIntelligentTiering):
+		info, err := s.s3svc.StatObject(ctx, obj.Key)
+		if err != nil {
+			return "metadata unavailable: " + err.Error()
+		}
+		return restoreSkipReason(*info)
+	case s3svc.IsArchiveStorageClass(obj.StorageClass):
+		info, ok := listed[obj.Key]
+		if !ok {
+			return "no longer in the bucket"
+		}
+		return restoreSkipReason(info)
+	}
+	return ""
+}
+
+// restoreSkipReason returns why an object cannot be read until it is restored, or "".
+func restoreSkipReason(info s3svc.ObjectInfo) string {
+	switch {
+	case info.IsRestoring:
+		return "Glacier restore in progress"
+	case !info.IsDownloadable:
+		return "archived in " + info.StorageClass + ", not restored"
+	}
+	return ""
+}
+
+// streamArchive writes every object and then the manifest into the archive.
+// Objects that fail to open are recorded in the manifest; a write failure aborts the stream.
+func (s *App) streamArchive(
+	ctx context.Context, w http.ResponseWriter, format archive.Format,
+	folder string, objects []dto.S3Object, manifest *archive.Manifest,
+) error {
+	aw, err := archive.NewWriter(format, w)
+	if err != nil {
+		return fmt.Errorf("failed to create archive writer: %w", err)
+	}
+
+	for _, obj := range objects {
+		o, err := s.s3svc.GetObjectStream(ctx, obj.Key, s3svc.GetObjectOptions{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("archive cancelled: %w", ctx.Err())
+			}
+			manifest.Skip(obj.Key, "read failed: "+err.Error())
+			continue
+		}
+		err = aw.AddFile(archive.EntryName(obj.Key, folder), o.ContentLength, o.LastModified, o.Body)
+		_ = o.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", obj.Key, err)
+		}
+		manifest.Included++
+		manifest.Bytes += o.ContentLength
+	}
+
+	content := manifest.String()
+	if err := aw.AddFile(archive.ManifestName, int64(len(content)), manifest.CreatedAt,
+		strings.NewReader(content)); err != nil {
+		return fmt.Errorf("failed to add manifest: %w", err)
+	}
+	if err := aw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return nil
+}
+
+// archiveBaseName names the downloaded archive after the folder (or the bucket at the root).
+func archiveBaseName(folder, bucket string, selection bool) string {
+	name := path.Base(strings.TrimSuffix(folder, "/"))
+	if folder == "" || name == "." || name == "/" {
+		name = bucket
+	}
+	if selection {
+		name += "-selection"
+	}
+	return name
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchivedFolder(t *testing.T) {
+	objects := func(keys ...string) []dto.S3Object {
+		var objs []dto.S3Object
+		for _, key := range keys {
+			objs = append(objs, dto.S3Object{Key: key, StorageClass: "GLACIER"})
+		}
+		return objs
+	}
+	tests := []struct {
+		name       string
+		folder     string
+		selected   bool
+		candidates []dto.S3Object
+		prefix     string
+		recursive  bool
+		ok         bool
+	}{
+		{"nothing archived", "logs/", false, []dto.S3Object{{Key: "logs/a.txt", StorageClass: "STANDARD"}}, "", false, false},
+		{"folder", "logs/", false, objects("logs/2020/a.tar"), "logs/", true, true},
+		{"keys in one folder", "", true, objects("logs/2020/a.tar", "logs/2020/b.tar"), "logs/2020/", false, true},
+		{"keys in subfolders", "", true, objects("logs/2020/a.tar", "logs/2021/b.tar"), "logs/", true, true},
+		{"keys sharing a name prefix", "", true, objects("logs/ab.tar", "logs/ac.tar"), "logs/", false, true},
+		{"keys at the root", "", true, objects("a.tar", "b/c.tar"), "", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, recursive, ok := archivedFolder(tt.folder, tt.selected, tt.candidates)
+			assert.Equal(t, tt.prefix, prefix)
+			assert.Equal(t, tt.recursive, recursive)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestArchiveSkipReason_HeadsOnlyIntelligentTiering(t *testing.T) {
+	app, fake := newRestoreTestApp(t)
+	fake.restoreHeader["logs/tiered.bin"] = ""
+
+	listed := map[string]s3svc.ObjectInfo{
+		"logs/cold.tar":     {StorageClass: "GLACIER"},
+		"logs/restored.tar": {StorageClass: "GLACIER", IsDownloadable: true},
+		"logs/running.tar":  {StorageClass: "DEEP_ARCHIVE", IsRestoring: true},
+	}
+	tests := []struct {
+		obj    dto.S3Object
+		reason string
+	}{
+		{dto.S3Object{Key: "logs/a.txt", StorageClass: "STANDARD_IA"}, ""},
+		{dto.S3Object{Key: "logs/cold.tar", StorageClass: "GLACIER"}, "archived in GLACIER, not restored"},
+		{dto.S3Object{Key: "logs/restored.tar", StorageClass: "GLACIER"}, ""},
+		{dto.S3Object{Key: "logs/running.tar", StorageClass: "DEEP_ARCHIVE"}, "Glacier restore in progress"},
+		{dto.S3Object{Key: "logs/gone.tar", StorageClass: "GLACIER"}, "no longer in the bucket"},
+		// The fake answers GLACIER, as for an object in the archive access tier
+		{dto.S3Object{Key: "logs/tiered.bin", StorageClass: "INTELLIGENT_TIERING"}, "archived in GLACIER, not restored"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.reason, app.archiveSkipReason(context.Background(), tt.obj, listed), tt.obj.Key)
+	}
+	assert.Equal(t, 1, fake.heads, "only the Intelligent-Tiering object is checked with HeadObject")
+}
@@ -0,0 +1,339 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Conflict policies for batch uploads, applied when a key already exists in the catalog.
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+// Batch upload item statuses.
+const (
+	batchUploaded = "uploaded"
+	batchSkipped  = "skipped"
+	batchRenamed  = "renamed"
+	batchFailed   = "failed"
+)
+
+const (
+	// maxConflictPaths bounds one conflict check request
+	maxConflictPaths = 10000
+	// maxBatchFiles bounds the files of one batch upload
+	maxBatchFiles = 1000
+	// maxRenameAttempts bounds the "name (n).ext" candidates tried when renaming
+	maxRenameAttempts = 100
+)
+
+var (
+	// ErrInvalidRelativePath is returned for an empty, absolute or escaping relative path.
+	ErrInvalidRelativePath = errors.New("invalid relative path")
+	// ErrInvalidConflictPolicy is returned for an unknown conflict policy.
+	ErrInvalidConflictPolicy = errors.New("invalid conflict policy")
+	// ErrNoFreeName is returned when no free name is found to rename an upload.
+	ErrNoFreeName = errors.New("no free name found")
+	// ErrTooManyFiles is returned for the files of a batch upload beyond maxBatchFiles.
+	ErrTooManyFiles = errors.New("too many files in one batch")
+)
+
+// batchUploadItem reports what happened to one file of a batch upload.
+type batchUploadItem struct {
+	Path   string `json:"path"`
+	Key    string `json:"key,omitempty"`
+	Status string `json:"status"`
+	Size   int64  `json:"size,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// batchUploadResult is the response of POST /upload/batch.
+type batchUploadResult struct {
+	Items []batchUploadItem `json:"items"`
+}
+
+// conflictRequest is the body of POST /api/uploads/conflicts.
+type conflictRequest struct {
+	Folder string   `json:"folder"`
+	Paths  []string `json:"paths"`
+}
+
+// uploadConflict is an upload path whose key already exists in the catalog.
+type uploadConflict struct {
+	Path         string    `json:"path"`
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// UploadConflictsHandler reports which relative paths would overwrite existing objects
+// (POST /api/uploads/conflicts), so the browser can ask how to handle them before uploading.
+func (s *App) UploadConflictsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.uploadSessionsAvailable(w) {
+		return
+	}
+
+	var req conflictRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeJSONError(w, http.StatusBadRequest, ErrParseUploadRequest.Error())
+		return
+	}
+	if len(req.Paths) > maxConflictPaths {
+		s.writeJSONError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("too many paths (max %d)", maxConflictPaths))
+		return
+	}
+
+	folder := s.getValidatedFolder(req.Folder)
+	keys := make([]string, 0, len(req.Paths))
+	pathByKey := make(map[string]string, len(req.Paths))
+	for _, p := range req.Paths {
+		rel, err := cleanRelativePath(p)
+		if err != nil {
+			continue // rejected again, with a reason, when uploaded
+		}
+		keys = append(keys, folder+rel)
+		pathByKey[folder+rel] = p
+	}
+
+	existing, err := s.dbsvc.GetObjectsByKeys(ctx, s.cfg.S3.Bucket, keys)
+	if err != nil {
+		s.log.Error("Failed to check upload conflicts", slog.String("error", err.Error()))
+		s.writeJSONError(w, http.StatusInternalServerError, "failed to check conflicts")
+		return
+	}
+
+	conflicts := make([]uploadConflict, 0, len(existing))
+	for _, key := range keys {
+		if obj, ok := existing[key]; ok && !obj.IsFolder {
+			conflicts = append(conflicts, uploadConflict{
+				Path: pathByKey[key], Key: key, Size: obj.Size, LastModified: obj.LastModified,
+			})
+		}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"conflicts": conflicts})
+}
+
+// BatchUploadHandler streams any number of files to S3 in one request (POST /upload/batch).
+// The multipart form holds "folder" and "conflict" fields, then for each file a "path" field
+// with its path relative to folder followed by its "file" part. Relative paths become key
+// prefixes under folder, and every object (and new folder) is synced to the catalog.
+// Upload.MaxSize caps each file, and the body to maxBatchFiles files of that size.
+func (s *App) BatchUploadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.uploadSessionsAvailable(w) {
+		return
+	}
+	if s.cfg.Upload.MaxSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBatchFiles*(s.cfg.Upload.MaxSize+uploadFormOverhead))
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		s.writeJSONError(w, http.StatusBadRequest, ErrParseUploadRequest.Error())
+		return
+	}
+
+	folder := ""
+	policy := ConflictSkip
+	relPath := ""
+	var result batchUploadResult
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			s.log.Error("Failed to read batch upload", slog.String("error", err.Error()))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				s.writeJSONError(w, http.StatusRequestEntityTooLarge, ErrFileTooLarge.Error())
+				return
+			}
+			s.writeJSONError(w, http.StatusBadRequest, ErrParseUploadRequest.Error())
+			return
+		}
+
+		switch part.FormName() {
+		case "folder":
+			folder = readFormField(part)
+		case "conflict":
+			policy = readFormField(part)
+			if policy != ConflictSkip && policy != ConflictOverwrite && policy != ConflictRename {
+				_ = part.Close()
+				s.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("%s: %q", ErrInvalidConflictPolicy, policy))
+				return
+			}
+		case "path":
+			relPath = readFormField(part)
+		case "file":
+			if relPath == "" {
+				relPath = part.FileName()
+			}
+			if len(result.Items) >= maxBatchFiles {
+				result.Items = append(result.Items,
+					batchUploadItem{Path: relPath, Status: batchFailed, Error: ErrTooManyFiles.Error()})
+			} else {
+				result.Items = append(result.Items,
+					s.uploadBatchFile(ctx, s.getValidatedFolder(folder), relPath, policy, part))
+			}
+			relPath = ""
+		}
+		_ = part.Close()
+	}
+
+	if len(result.Items) == 0 {
+		s.writeJSONError(w, http.StatusBadRequest, ErrNoFileUploaded.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// uploadBatchFile uploads one file of a batch, applying the conflict policy.
+func (s *App) uploadBatchFile(
+	ctx context.Context, folder, relPath, policy string, part *multipart.Part,
+) batchUploadItem {
+	item := batchUploadItem{Path: relPath}
+	rel, err := cleanRelativePath(relPath)
+	if err != nil {
+		item.Status, item.Error = batchFailed, err.Error()
+		return item
+	}
+
+	key := folder + rel
+	if !s.validateKeyPrefix(key) {
+		item.Status, item.Error = batchFailed, ErrUploadOutsidePrefix.Error()
+		return item
+	}
+
+	item.Status = batchUploaded
+	existing, err := s.dbsvc.GetObjectsByKeys(ctx, s.cfg.S3.Bucket, []string{key})
+	if err != nil {
+		item.Status, item.Error = batchFailed, "failed to check conflicts"
+		return item
+	}
+	if _, exists := existing[key]; exists {
+		switch policy {
+		case ConflictSkip:
+			item.Key, item.Status = key, batchSkipped
+			return item
+		case ConflictRename:
+			if key, err = s.freeKey(ctx, key); err != nil {
+				item.Status, item.Error = batchFailed, err.Error()
+				return item
+			}
+			item.Status = batchRenamed
+		}
+	}
+	item.Key = key
+
+	var body io.Reader = part
+	if s.cfg.Upload.MaxSize > 0 {
+		body = &sizeLimitedReader{r: part, remaining: s.cfg.Upload.MaxSize}
+	}
+	contentType := s.detectContentType(key, part.Header.Get("Content-Type"))
+	uploaded, err := s.s3svc.StreamUpload(ctx, key, body, contentType, s.cfg.Upload.PartSize, s.cfg.Upload.Concurrency)
+	if err != nil {
+		s.log.Error("Batch upload failed", slog.String("key", key), slog.String("error", err.Error()))
+		item.Status, item.Error = batchFailed, "upload failed"
+		if errors.Is(err, ErrFileTooLarge) {
+			item.Error = fmt.Sprintf("%s (max %d bytes)", ErrFileTooLarge, s.cfg.Upload.MaxSize)
+		}
+		return item
+	}
+	item.Size = uploaded.Size
+
+	if err := s.dbsvc.SyncUploadedFolders(ctx, s.cfg.S3.Bucket, folder, key); err != nil {
+		s.log.Error("Failed to sync uploaded folders", slog.String("key", key), slog.String("error", err.Error()))
+	}
+	if err := s.dbsvc.SyncUploadedObject(ctx, s.cfg.S3.Bucket, key, uploaded.Size, uploaded.ETag, "STANDARD"); err != nil {
+		s.log.Error("Failed to sync upload to database", slog.String("key", key), slog.String("error", err.Error()))
+	}
+
+	s.log.Info("Batch upload", slog.String("key", key), slog.String("status", item.Status), slog.Int64("size", item.Size))
+	return item
+}
+
+// sizeLimitedReader fails with ErrFileTooLarge once more than remaining bytes are read, so the
+// upload of an oversized file is aborted instead of stored truncated.
+type sizeLimitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrFileTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrFileTooLarge
+	}
+	return n, err //nolint:wrapcheck // io.Reader errors are returned as is
+}
+
+// freeKey returns the first "name (n).ext" variant of key that is not in the catalog.
+func (s *App) freeKey(ctx context.Context, key string) (string, error) {
+	dir, file := path.Split(key)
+	ext := path.Ext(file)
+	base := strings.TrimSuffix(file, ext)
+
+	candidates := make([]string, maxRenameAttempts)
+	for i := range candidates {
+		candidates[i] = fmt.Sprintf("%s%s (%d)%s", dir, base, i+1, ext)
+	}
+	existing, err := s.dbsvc.GetObjectsByKeys(ctx, s.cfg.S3.Bucket, candidates)
+	if err != nil {
+		return "", fmt.Errorf("failed to find a free name: %w", err)
+	}
+	for _, candidate := range candidates {
+		if _, taken := existing[candidate]; !taken {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w for %s", ErrNoFreeName, key)
+}
+
+// cleanRelativePath normalises a browser-supplied relative path ("dir/sub/file.txt").
+// Absolute paths, ".." segments and empty names are rejected so keys stay under the target folder.
+func cleanRelativePath(p string) (string, error) {
+	p = strings.ReplaceAll(p, "\\", "/")
+	if p == "" || strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidRelativePath, p)
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("%w: %q", ErrInvalidRelativePath, p)
+		}
+	}
+	cleaned := path.Clean(p)
+	if cleaned == "." || strings.HasSuffix(p, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidRelativePath, p)
+	}
+	return cleaned, nil
+}
+
+// readFormField reads a small multipart form field value.
+func readFormField(part *multipart.Part) string {
+	value, err := io.ReadAll(io.LimitReader(part, maxFolderFieldSize))
+	if err != nil {
+		return ""
+	}
+	return string(value)
+}
@@ -1,9 +1,14 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
 	"github.com/sgaunet/s3xplorer/pkg/views"
 )
 
@@ -49,3 +54,68 @@ func (s *App) BucketListingHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// BucketConfigHandler shows the configuration of a bucket: versioning, lifecycle rules, replication,
+// encryption, object lock, CORS, policy, public access block, tags and event notifications.
+// It is read from the cache filled by each scan, or from S3 when the bucket was not scanned yet.
+func (s *App) BucketConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucket, err := s.requestedBucket(ctx, r.URL.Query().Get("bucket"))
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	var bucketConfig *dto.BucketConfig
+	if s.dbsvc != nil && s.IsDatabaseHealthy() {
+		if bucketConfig, err = s.dbsvc.GetBucketConfig(ctx, bucket); err != nil {
+			s.log.Warn("Failed to read cached bucket configuration", slog.String("bucket", bucket), slog.String("error", err.Error()))
+		}
+	}
+	if bucketConfig == nil {
+		bucketConfig = s.fetchBucketConfig(ctx, bucket)
+	}
+
+	if err := views.RenderBucketConfig(*bucketConfig, s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Error rendering bucket configuration page", slog.String("error", err.Error()))
+		http.Error(w, "Failed to render bucket configuration page", http.StatusInternalServerError)
+	}
+}
+
+// RefreshBucketConfigHandler reads the configuration of a bucket from S3 again, without waiting for the next scan.
+func (s *App) RefreshBucketConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucket, err := s.requestedBucket(ctx, r.PostFormValue("bucket"))
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+	s.fetchBucketConfig(ctx, bucket)
+	http.Redirect(w, r, "/buckets/config?bucket="+url.QueryEscape(bucket), http.StatusSeeOther)
+}
+
+// requestedBucket returns the bucket a request asks for: the current bucket by default, or another
+// bucket of the catalog when bucket changes are allowed.
+func (s *App) requestedBucket(ctx context.Context, bucket string) (string, error) {
+	if bucket == "" || bucket == s.cfg.S3.Bucket {
+		return s.cfg.S3.Bucket, nil
+	}
+	if s.cfg.S3.BucketLocked {
+		return "", ErrBucketLocked
+	}
+	if !s.catalogsBucket(ctx, config.DefaultConnection, bucket) {
+		return "", fmt.Errorf("%w: %s", ErrBucketNotAccessible, bucket)
+	}
+	return bucket, nil
+}
+
+// fetchBucketConfig reads the configuration of bucket from S3, and caches it when the bucket is in the catalog.
+func (s *App) fetchBucketConfig(ctx context.Context, bucket string) *dto.BucketConfig {
+	bucketConfig := s.s3svc.ForBucket(bucket).GetBucketConfig(ctx)
+	if s.catalogsBucket(ctx, config.DefaultConnection, bucket) {
+		if err := s.dbsvc.SaveBucketConfig(ctx, bucketConfig); err != nil {
+			s.log.Warn("Failed to cache bucket configuration", slog.String("bucket", bucket), slog.String("error", err.Error()))
+		}
+	}
+	return &bucketConfig
+}
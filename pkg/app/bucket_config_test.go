@@ -0,0 +1,46 @@
+package app
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestedBucket(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+
+	bucket, err := app.requestedBucket(t.Context(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "bucket", bucket)
+
+	// Without the database no other bucket is known
+	_, err = app.requestedBucket(t.Context(), "other")
+	require.ErrorIs(t, err, ErrBucketNotAccessible)
+
+	app.cfg.S3.BucketLocked = true
+	_, err = app.requestedBucket(t.Context(), "other")
+	require.ErrorIs(t, err, ErrBucketLocked)
+}
+
+func TestBucketConfigHandler_WithoutDatabase(t *testing.T) {
+	// S3 answers 501 Not Implemented to every bucket configuration call, like MinIO for unsupported features
+	app := newFakeS3App(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotImplemented)
+		_, _ = io.WriteString(w, `<Error><Code>NotImplemented</Code><Message>not implemented</Message></Error>`)
+	}), config.Config{S3: config.S3Config{Bucket: "bucket"}})
+
+	rec := httptest.NewRecorder()
+	app.BucketConfigHandler(rec, httptest.NewRequest(http.MethodGet, "/buckets/config", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "Lifecycle rules")
+	assert.Contains(t, body, dto.BucketConfigNotSupported)
+	assert.NotContains(t, body, ">"+dto.BucketConfigFailed+"<")
+}
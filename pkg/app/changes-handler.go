@@ -0,0 +1,198 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/dbsvc"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+const (
+	// maxListedScans caps the scans of the What changed page.
+	maxListedScans = 30
+	// maxListedChanges caps the changes of the What changed page.
+	maxListedChanges = 500
+	// maxDiffChanges caps the changes returned by the diff API.
+	maxDiffChanges = 10000
+	// changesPurgeBatch is the number of expired changes the scheduled purge deletes at once
+	changesPurgeBatch = 10000
+)
+
+// ErrInvalidScanJob is returned for a scan job parameter that is not a scan job ID.
+var ErrInvalidScanJob = errors.New("invalid scan job")
+
+// ChangesHandler shows the latest scans of the bucket with the number of changes each one found under
+// ?prefix=, and the files changed between two of them: ?from= and ?to=, by default the last two scans.
+func (s *App) ChangesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	if prefix == "" {
+		prefix = s.cfg.S3.Prefix
+	}
+	if !s.validateKeyPrefix(prefix) {
+		s.renderErrorPage(ctx, w, fmt.Sprintf("%s: %s", ErrInvalidKey, prefix))
+		return
+	}
+
+	scans, err := s.dbsvc.ListScanChanges(ctx, s.cfg.S3.Bucket, prefix, maxListedScans)
+	if err != nil {
+		s.log.Error("Failed to list scan changes", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to list the scans of the bucket")
+		return
+	}
+
+	var diff *dto.ScanDiff
+	if len(scans) > 0 {
+		from, to, err := scanRange(query, scans)
+		if err != nil {
+			s.renderErrorPage(ctx, w, err.Error())
+			return
+		}
+		diff, err = s.dbsvc.DiffScanJobs(ctx, s.cfg.S3.Bucket, from, to, prefix, maxListedChanges)
+		if errors.Is(err, dbsvc.ErrScanJobNotFound) || errors.Is(err, dbsvc.ErrInvalidScanRange) {
+			s.renderErrorPage(ctx, w, err.Error())
+			return
+		}
+		if err != nil {
+			s.log.Error("Failed to diff scans", slog.String("error", err.Error()))
+			s.renderErrorPage(ctx, w, "Failed to compare the scans")
+			return
+		}
+	}
+
+	if err := views.RenderChanges(scans, diff, prefix, s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render changes", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// ScanDiffHandler returns, as JSON, the files changed between the end of two scan jobs of a bucket:
+// ?from= (0 for an empty catalog) and ?to=, optionally under ?prefix= and for another ?bucket=.
+func (s *App) ScanDiffHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.writeJSONError(w, http.StatusServiceUnavailable, ErrDatabaseUnavailable.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	bucket, err := s.requestedBucket(ctx, query.Get("bucket"))
+	if errors.Is(err, ErrBucketNotAccessible) {
+		s.writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.writeJSONError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	prefix := query.Get("prefix")
+	if prefix == "" {
+		prefix = s.cfg.S3.Prefix
+	}
+	if !s.validateKeyPrefix(prefix) {
+		s.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("%s: %s", ErrInvalidKey, prefix))
+		return
+	}
+	from, errFrom := parseScanJobID(query.Get("from"))
+	to, errTo := parseScanJobID(query.Get("to"))
+	if err := errors.Join(errFrom, errTo); err != nil || to == 0 {
+		s.writeJSONError(w, http.StatusBadRequest, "from and to must be scan job IDs")
+		return
+	}
+
+	diff, err := s.dbsvc.DiffScanJobs(ctx, bucket, from, to, prefix, maxDiffChanges)
+	switch {
+	case errors.Is(err, dbsvc.ErrScanJobNotFound):
+		s.writeJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, dbsvc.ErrInvalidScanRange):
+		s.writeJSONError(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		s.log.Error("Failed to diff scans", slog.String("error", err.Error()))
+		s.writeJSONError(w, http.StatusInternalServerError, "failed to compare the scans")
+	default:
+		s.writeJSON(w, http.StatusOK, diff)
+	}
+}
+
+// scanRange returns the scans compared by the What changed page: ?from= and ?to= when set, otherwise
+// the latest scan and the one before it. scans are latest first.
+func scanRange(query url.Values, scans []dto.ScanChanges) (int32, int32, error) {
+	to := scans[0].ScanJobID
+	var from int32
+	if len(scans) > 1 {
+		from = scans[1].ScanJobID
+	}
+	if query.Has("to") {
+		id, err := parseScanJobID(query.Get("to"))
+		if err != nil {
+			return 0, 0, err
+		}
+		to = id
+		// The scan before the chosen one, unless from is chosen too
+		from = 0
+		for _, scan := range scans {
+			if scan.ScanJobID < to {
+				from = scan.ScanJobID
+				break
+			}
+		}
+	}
+	if query.Has("from") {
+		id, err := parseScanJobID(query.Get("from"))
+		if err != nil {
+			return 0, 0, err
+		}
+		from = id
+	}
+	return from, to, nil
+}
+
+// parseScanJobID parses a scan job ID parameter, 0 when empty.
+func parseScanJobID(value string) (int32, error) {
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidScanJob, value)
+	}
+	return int32(id), nil
+}
+
+// PurgeExpiredChanges deletes the changes recorded by scans longer ago than the retention, in batches.
+func (s *App) PurgeExpiredChanges(ctx context.Context) {
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.log.Warn("Skipping change history purge - database unavailable")
+		return
+	}
+
+	before := time.Now().Add(-s.cfg.Changes.Retention())
+	var purged int64
+	for ctx.Err() == nil {
+		n, err := s.dbsvc.PurgeObjectChanges(ctx, before, changesPurgeBatch)
+		if err != nil {
+			s.log.Error("Failed to purge change history", slog.String("error", err.Error()))
+			return
+		}
+		purged += n
+		if n < changesPurgeBatch {
+			break
+		}
+	}
+
+	s.log.Info("Change history purge completed", slog.Int64("purged", purged))
+}
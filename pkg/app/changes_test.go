@@ -0,0 +1,68 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangesHandler_WithoutDatabase(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.ChangesHandler(rec, httptest.NewRequest(http.MethodGet, "/changes", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestScanDiffHandler_WithoutDatabase(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.ScanDiffHandler(rec, httptest.NewRequest(http.MethodGet, "/api/changes?from=1&to=2", nil))
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, ErrDatabaseUnavailable.Error(), body["error"])
+}
+
+func TestScanRange(t *testing.T) {
+	scans := []dto.ScanChanges{{ScanJobID: 9}, {ScanJobID: 7}, {ScanJobID: 4}}
+	tests := []struct {
+		name     string
+		query    url.Values
+		from, to int32
+		wantErr  bool
+	}{
+		{name: "latest two scans", query: url.Values{}, from: 7, to: 9},
+		{name: "scan before to", query: url.Values{"to": {"7"}}, from: 4, to: 7},
+		{name: "first scan", query: url.Values{"to": {"4"}}, from: 0, to: 4},
+		{name: "explicit range", query: url.Values{"from": {"4"}, "to": {"9"}}, from: 4, to: 9},
+		{name: "invalid scan", query: url.Values{"to": {"latest"}}, wantErr: true},
+		{name: "negative scan", query: url.Values{"from": {"-1"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := scanRange(tt.query, scans)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidScanJob)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.from, from)
+			assert.Equal(t, tt.to, to)
+		})
+	}
+
+	from, to, err := scanRange(url.Values{}, scans[:1])
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), from, "a single scan is compared with an empty catalog")
+	assert.Equal(t, int32(9), to)
+}
@@ -0,0 +1,350 @@
+package app
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+const (
+	// compareEntriesPerPage is the number of differing files per page of a comparison.
+	compareEntriesPerPage = 50
+	// compareExportBatch is the number of differing files read at once by an export.
+	compareExportBatch = 1000
+)
+
+var (
+	// ErrSameComparison is returned when both sides of a comparison are the same files.
+	ErrSameComparison = errors.New("both sides of the comparison are the same")
+	// ErrInvalidDifference is returned for an unknown comparison difference.
+	ErrInvalidDifference = errors.New("invalid difference")
+	// ErrInvalidExportFormat is returned for an unknown export format.
+	ErrInvalidExportFormat = errors.New("invalid export format")
+)
+
+// comparison is a comparison requested by the comparison page, its export or its fix.
+type comparison struct {
+	source     dto.CompareSide
+	target     dto.CompareSide
+	difference string
+}
+
+// CompareHandler compares the catalog rows of two (bucket, prefix) pairs: ?source_bucket=, ?source_prefix=,
+// ?target_bucket= and ?target_prefix=. It shows the number of files with each difference and a page of the
+// differing files, only those with ?difference= when set. Without a target bucket only the form is shown.
+func (s *App) CompareHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	var buckets []string
+	if catalog, err := s.dbsvc.GetBuckets(ctx); err == nil {
+		for _, b := range catalog {
+			buckets = append(buckets, b.Name)
+		}
+	} else {
+		s.log.Warn("Failed to list catalog buckets", slog.String("error", err.Error()))
+	}
+
+	query := r.URL.Query()
+	if !query.Has("target_bucket") {
+		source := dto.CompareSide{Bucket: s.cfg.S3.Bucket, Prefix: s.cfg.S3.Prefix}
+		page := views.RenderCompare(source, dto.CompareSide{}, "", buckets, nil, nil, dto.PaginationInfo{}, s.cfg)
+		if err := page.Render(ctx, w); err != nil {
+			s.log.Error("Failed to render comparison", slog.String("error", err.Error()))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	cmp, err := s.comparisonFromValues(ctx, query)
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+	page, err := ParsePaginationParams(r)
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	summary, err := s.dbsvc.SummarizeComparison(ctx, cmp.source, cmp.target)
+	if err != nil {
+		s.log.Error("Failed to compare catalogs", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to compare the files: both buckets must have been scanned")
+		return
+	}
+	paging := dto.NewPaginationInfo(summary.Count(cmp.difference), compareEntriesPerPage, page)
+	offset := (paging.CurrentPage - 1) * compareEntriesPerPage
+	entries, err := s.dbsvc.CompareCatalogs(ctx, cmp.source, cmp.target, cmp.difference, "", offset, compareEntriesPerPage)
+	if err != nil {
+		s.log.Error("Failed to compare catalogs", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to compare the files")
+		return
+	}
+
+	view := views.RenderCompare(cmp.source, cmp.target, cmp.difference, buckets, &summary, entries, paging, s.cfg)
+	if err := view.Render(ctx, w); err != nil {
+		s.log.Error("Failed to render comparison", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// CompareExportHandler downloads every differing file of a comparison, with the parameters of
+// CompareHandler, as CSV or, with ?format=json, as JSON with the summary of the comparison.
+func (s *App) CompareExportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+	query := r.URL.Query()
+	cmp, err := s.comparisonFromValues(ctx, query)
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+	format := query.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		s.renderErrorPage(ctx, w, fmt.Sprintf("%s: %s", ErrInvalidExportFormat, format))
+		return
+	}
+
+	// Read the first batch before answering, so that a failed comparison is still reported as a page
+	summary, err := s.dbsvc.SummarizeComparison(ctx, cmp.source, cmp.target)
+	if err != nil {
+		s.log.Error("Failed to compare catalogs", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to compare the files: both buckets must have been scanned")
+		return
+	}
+	entries, err := s.dbsvc.CompareCatalogs(ctx, cmp.source, cmp.target, cmp.difference, "", 0, compareExportBatch)
+	if err != nil {
+		s.log.Error("Failed to compare catalogs", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to compare the files")
+		return
+	}
+
+	filename := fmt.Sprintf("compare-%s-%s.%s", cmp.source.Bucket, cmp.target.Bucket, format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	var export compareExporter
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		export = &compareJSONExporter{w: w}
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		export = &compareCSVExporter{w: csv.NewWriter(w)}
+	}
+
+	if err := export.begin(summary); err != nil {
+		s.log.Warn("Comparison export interrupted", slog.String("error", err.Error()))
+		return
+	}
+	for len(entries) > 0 {
+		if err := export.write(entries); err != nil {
+			s.log.Warn("Comparison export interrupted", slog.String("error", err.Error()))
+			return
+		}
+		if len(entries) < compareExportBatch {
+			break
+		}
+		after := entries[len(entries)-1].Key
+		entries, err = s.dbsvc.CompareCatalogs(ctx, cmp.source, cmp.target, cmp.difference, after, 0, compareExportBatch)
+		if err != nil {
+			// The response has started: the truncated file is all the client gets
+			s.log.Error("Failed to compare catalogs", slog.String("error", err.Error()))
+			return
+		}
+	}
+	if err := export.end(); err != nil {
+		s.log.Warn("Comparison export interrupted", slog.String("error", err.Error()))
+	}
+}
+
+// CompareFixHandler starts a background copy of the files missing from the target side of a comparison,
+// through the copy job of "Copy to...": existing target files are skipped.
+func (s *App) CompareFixHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableUpload {
+		s.renderErrorPage(ctx, w, "Upload functionality is disabled")
+		return
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.renderErrorPage(ctx, w, "Invalid request")
+		return
+	}
+	cmp, err := s.comparisonFromValues(ctx, r.PostForm)
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	options, err := json.Marshal(copyJobOptions{Overwrite: overwriteSkip, PreserveMetadata: true})
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+	job, err := s.dbsvc.CreateJob(ctx, dto.Job{
+		Kind:             dto.JobKindCopy,
+		Bucket:           cmp.source.Bucket,
+		Source:           cmp.source.Prefix,
+		TargetConnection: config.DefaultConnection,
+		TargetBucket:     cmp.target.Bucket,
+		Destination:      cmp.target.Prefix,
+		Options:          string(options),
+		DryRun:           r.PostFormValue("dry_run") != "",
+	})
+	if err != nil {
+		s.log.Error("Failed to create copy job", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to start the copy")
+		return
+	}
+	s.log.Info("Copy of missing files started",
+		slog.Int("job", int(job.ID)),
+		slog.String("source", cmp.source.Bucket+"/"+cmp.source.Prefix),
+		slog.String("target", cmp.target.Bucket+"/"+cmp.target.Prefix),
+		slog.Bool("dryRun", job.DryRun))
+
+	go s.runCopyJob(s.backgroundContext(), *job)
+	http.Redirect(w, r, fmt.Sprintf("/jobs/%d", job.ID), http.StatusSeeOther)
+}
+
+// comparisonFromValues returns the comparison described by the source_bucket, source_prefix,
+// target_bucket, target_prefix and difference values. Buckets default to the browsed bucket.
+func (s *App) comparisonFromValues(ctx context.Context, values url.Values) (comparison, error) {
+	var cmp comparison
+	var err error
+	if cmp.source, err = s.compareSide(ctx, values.Get("source_bucket"), values.Get("source_prefix")); err != nil {
+		return cmp, err
+	}
+	if cmp.target, err = s.compareSide(ctx, values.Get("target_bucket"), values.Get("target_prefix")); err != nil {
+		return cmp, err
+	}
+	if cmp.source == cmp.target {
+		return cmp, ErrSameComparison
+	}
+	cmp.difference = values.Get("difference")
+	if cmp.difference != "" && !slices.Contains(dto.Differences, cmp.difference) {
+		return cmp, fmt.Errorf("%w: %s", ErrInvalidDifference, cmp.difference)
+	}
+	return cmp, nil
+}
+
+// compareSide returns a side of a comparison, its prefix as a folder. The configured prefix
+// only applies to the browsed bucket.
+func (s *App) compareSide(ctx context.Context, bucket, prefix string) (dto.CompareSide, error) {
+	bucket, err := s.requestedBucket(ctx, strings.TrimSpace(bucket))
+	if err != nil {
+		return dto.CompareSide{}, err
+	}
+	prefix = strings.TrimLeft(strings.TrimSpace(prefix), "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	if bucket == s.cfg.S3.Bucket && !s.validateKeyPrefix(prefix) {
+		return dto.CompareSide{}, fmt.Errorf("%w: does not have required prefix '%s'", ErrInvalidKey, s.cfg.S3.Prefix)
+	}
+	return dto.CompareSide{Bucket: bucket, Prefix: prefix}, nil
+}
+
+// compareExporter writes the differing files of a comparison in an export format.
+type compareExporter interface {
+	begin(summary dto.CompareSummary) error
+	write(entries []dto.CompareEntry) error
+	end() error
+}
+
+// compareCSVExporter writes a comparison as CSV, one row per differing file.
+type compareCSVExporter struct {
+	w *csv.Writer
+}
+
+func (e *compareCSVExporter) begin(dto.CompareSummary) error {
+	return e.w.Write([]string{ //nolint:wrapcheck // written to the response
+		"key", "difference",
+		"source_size", "source_etag", "source_storage_class",
+		"target_size", "target_etag", "target_storage_class",
+	})
+}
+
+func (e *compareCSVExporter) write(entries []dto.CompareEntry) error {
+	size := func(size *int64) string {
+		if size == nil {
+			return ""
+		}
+		return strconv.FormatInt(*size, 10)
+	}
+	for _, entry := range entries {
+		if err := e.w.Write([]string{
+			entry.Key, entry.Difference,
+			size(entry.SourceSize), entry.SourceETag, entry.SourceStorageClass,
+			size(entry.TargetSize), entry.TargetETag, entry.TargetStorageClass,
+		}); err != nil {
+			return err //nolint:wrapcheck // written to the response
+		}
+	}
+	e.w.Flush()
+	return e.w.Error() //nolint:wrapcheck // written to the response
+}
+
+func (e *compareCSVExporter) end() error {
+	e.w.Flush()
+	return e.w.Error() //nolint:wrapcheck // written to the response
+}
+
+// compareJSONExporter writes a comparison as a JSON object holding its summary and its differing files.
+type compareJSONExporter struct {
+	w       http.ResponseWriter
+	written bool
+}
+
+func (e *compareJSONExporter) begin(summary dto.CompareSummary) error {
+	encoded, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("cannot encode summary: %w", err)
+	}
+	_, err = fmt.Fprintf(e.w, `{"summary":%s,"differences":[`, encoded)
+	return err //nolint:wrapcheck // written to the response
+}
+
+func (e *compareJSONExporter) write(entries []dto.CompareEntry) error {
+	for _, entry := range entries {
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("cannot encode %s: %w", entry.Key, err)
+		}
+		if e.written {
+			encoded = append([]byte{','}, encoded...)
+		}
+		e.written = true
+		if _, err := e.w.Write(encoded); err != nil {
+			return err //nolint:wrapcheck // written to the response
+		}
+	}
+	return nil
+}
+
+func (e *compareJSONExporter) end() error {
+	_, err := e.w.Write([]byte("]}\n"))
+	return err //nolint:wrapcheck // written to the response
+}
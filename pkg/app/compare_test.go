@@ -0,0 +1,115 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareHandler_WithoutDatabase(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.CompareHandler(rec, httptest.NewRequest(http.MethodGet, "/compare?target_bucket=bucket&target_prefix=dr/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestCompareFixHandler_UploadDisabled(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.CompareFixHandler(rec, organizeRequest("/compare/fix", url.Values{"target_prefix": {"dr/"}}))
+
+	assert.Contains(t, rec.Body.String(), "Upload functionality is disabled")
+}
+
+func TestComparisonFromValues(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+
+	cmp, err := app.comparisonFromValues(t.Context(), url.Values{
+		"source_prefix": {"/data"}, "target_bucket": {"bucket"}, "target_prefix": {"dr/data/"}, "difference": {"size"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, dto.CompareSide{Bucket: "bucket", Prefix: "data/"}, cmp.source)
+	assert.Equal(t, dto.CompareSide{Bucket: "bucket", Prefix: "dr/data/"}, cmp.target)
+	assert.Equal(t, dto.DifferenceSize, cmp.difference)
+
+	_, err = app.comparisonFromValues(t.Context(), url.Values{"source_prefix": {"data"}, "target_prefix": {"data/"}})
+	require.ErrorIs(t, err, ErrSameComparison)
+
+	_, err = app.comparisonFromValues(t.Context(), url.Values{"target_prefix": {"dr/"}, "difference": {"owner"}})
+	require.ErrorIs(t, err, ErrInvalidDifference)
+
+	// Without the database no other bucket is known
+	_, err = app.comparisonFromValues(t.Context(), url.Values{"target_bucket": {"dr-data"}})
+	require.ErrorIs(t, err, ErrBucketNotAccessible)
+
+	app.cfg.S3.Prefix = "data/"
+	_, err = app.comparisonFromValues(t.Context(), url.Values{"source_prefix": {"data/"}, "target_prefix": {"dr/"}})
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestCompareExporters(t *testing.T) {
+	size := int64(42)
+	summary := dto.CompareSummary{
+		Source:        dto.CompareSide{Bucket: "prod-data"},
+		Target:        dto.CompareSide{Bucket: "dr-data"},
+		SourceObjects: 2,
+		MissingTarget: 1,
+	}
+	entries := []dto.CompareEntry{
+		{Key: "a.bin", Difference: dto.DifferenceMissingTarget, SourceSize: &size, SourceETag: `"abc"`, SourceStorageClass: "STANDARD"},
+		{Key: "b,c.bin", Difference: dto.DifferenceMissingSource, TargetSize: &size, TargetETag: `"def"`, TargetStorageClass: "GLACIER"},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		export := &compareCSVExporter{w: csv.NewWriter(rec)}
+		require.NoError(t, export.begin(summary))
+		require.NoError(t, export.write(entries[:1]))
+		require.NoError(t, export.write(entries[1:]))
+		require.NoError(t, export.end())
+
+		records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"key", "difference", "source_size", "source_etag", "source_storage_class", "target_size", "target_etag", "target_storage_class"},
+			{"a.bin", "missing-target", "42", `"abc"`, "STANDARD", "", "", ""},
+			{"b,c.bin", "missing-source", "", "", "", "42", `"def"`, "GLACIER"},
+		}, records)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		export := &compareJSONExporter{w: rec}
+		require.NoError(t, export.begin(summary))
+		require.NoError(t, export.write(entries[:1]))
+		require.NoError(t, export.write(entries[1:]))
+		require.NoError(t, export.end())
+
+		var decoded struct {
+			Summary     dto.CompareSummary `json:"summary"`
+			Differences []dto.CompareEntry `json:"differences"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded))
+		assert.Equal(t, summary, decoded.Summary)
+		assert.Equal(t, entries, decoded.Differences)
+	})
+
+	t.Run("json without differences", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		export := &compareJSONExporter{w: rec}
+		require.NoError(t, export.begin(dto.CompareSummary{}))
+		require.NoError(t, export.end())
+		assert.True(t, json.Valid(rec.Body.Bytes()), rec.Body.String())
+	})
+}
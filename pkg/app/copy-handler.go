@@ -0,0 +1,439 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+// Overwrite policies of a copy, applied to target keys that already exist.
+const (
+	overwriteSkip   = "skip"
+	overwriteAlways = "overwrite"
+	overwriteNewer  = "newer"
+)
+
+var (
+	// ErrUnknownConnection is returned for a connection missing from the configuration or not initialized.
+	ErrUnknownConnection = errors.New("unknown connection")
+	// ErrMissingTargetBucket is returned when a copy has no target bucket.
+	ErrMissingTargetBucket = errors.New("target bucket is required")
+	// ErrInvalidOverwrite is returned for an unknown overwrite policy.
+	ErrInvalidOverwrite = errors.New("invalid overwrite policy")
+	// ErrCopyIncomplete is returned when some objects could not be copied.
+	ErrCopyIncomplete = errors.New("some objects could not be copied")
+)
+
+// copyJobOptions are the options of a copy job, stored as JSON in the job row.
+type copyJobOptions struct {
+	Overwrite            string `json:"overwrite"`
+	PreserveMetadata     bool   `json:"preserveMetadata"`
+	PreserveStorageClass bool   `json:"preserveStorageClass"`
+}
+
+// copyItem is an object a copy job has to write.
+type copyItem struct {
+	src    s3svc.ObjectInfo
+	dstKey string
+}
+
+// CopyFormHandler shows the form to copy an object or a folder to another bucket or connection.
+func (s *App) CopyFormHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableUpload {
+		s.renderErrorPage(ctx, w, "Upload functionality is disabled")
+		return
+	}
+
+	key, err := s.extractAndValidateKey(r)
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	// Catalog buckets are only suggestions: any bucket name can be typed
+	var buckets []string
+	if s.dbsvc != nil && s.IsDatabaseHealthy() {
+		if catalog, err := s.dbsvc.GetBuckets(ctx); err == nil {
+			for _, b := range catalog {
+				buckets = append(buckets, b.Name)
+			}
+		} else {
+			s.log.Warn("Failed to list catalog buckets", slog.String("error", err.Error()))
+		}
+	}
+
+	form := views.RenderCopyForm(key, parentFolder(key), s.connectionNames(), buckets, s.cfg)
+	if err := form.Render(ctx, w); err != nil {
+		s.log.Error("Failed to render form", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// CopyHandler starts a background job copying an object or a folder into a folder
+// of a bucket, possibly on another connection.
+func (s *App) CopyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableUpload {
+		s.renderErrorPage(ctx, w, "Upload functionality is disabled")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.renderErrorPage(ctx, w, "Invalid request")
+		return
+	}
+
+	job, err := s.copyJobFromForm(r)
+	if err == nil {
+		err = s.validateCopy(job)
+	}
+	if err != nil {
+		s.log.Warn("Copy rejected", slog.String("src", job.Source), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+	created, err := s.dbsvc.CreateJob(ctx, job)
+	if err != nil {
+		s.log.Error("Failed to create copy job", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to start the copy")
+		return
+	}
+	s.log.Info("Copy started",
+		slog.Int("job", int(created.ID)),
+		slog.String("src", job.Source),
+		slog.String("connection", job.TargetConnection),
+		slog.String("bucket", job.TargetBucket),
+		slog.String("dst", job.Destination),
+		slog.Bool("dryRun", job.DryRun))
+
+	go s.runCopyJob(s.backgroundContext(), *created)
+	http.Redirect(w, r, fmt.Sprintf("/jobs/%d", created.ID), http.StatusSeeOther)
+}
+
+// copyJobFromForm builds the copy job described by the submitted form.
+func (s *App) copyJobFromForm(r *http.Request) (dto.Job, error) {
+	src := r.PostFormValue("key")
+	job := dto.Job{
+		Kind:             dto.JobKindCopy,
+		Bucket:           s.cfg.S3.Bucket,
+		Source:           src,
+		TargetConnection: r.PostFormValue("connection"),
+		TargetBucket:     strings.TrimSpace(r.PostFormValue("bucket")),
+		DryRun:           r.PostFormValue("dry_run") != "",
+	}
+	if job.TargetConnection == "" {
+		job.TargetConnection = config.DefaultConnection
+	}
+
+	destination := strings.Trim(r.PostFormValue("destination"), "/")
+	if destination != "" {
+		destination += "/"
+	}
+	job.Destination = destination + path.Base(src)
+	if strings.HasSuffix(src, "/") {
+		job.Destination += "/"
+	}
+
+	opts := copyJobOptions{
+		Overwrite:            r.PostFormValue("overwrite"),
+		PreserveMetadata:     r.PostFormValue("preserve_metadata") != "",
+		PreserveStorageClass: r.PostFormValue("preserve_storage_class") != "",
+	}
+	if opts.Overwrite == "" {
+		opts.Overwrite = overwriteSkip
+	}
+	encoded, err := json.Marshal(opts)
+	if err != nil {
+		return job, fmt.Errorf("cannot encode copy options: %w", err)
+	}
+	job.Options = string(encoded)
+	return job, nil
+}
+
+// validateCopy checks the source, target and options of a copy job.
+func (s *App) validateCopy(job dto.Job) error {
+	if job.Source == "" {
+		return ErrMissingKeyParam
+	}
+	if !s.validateKeyPrefix(job.Source) {
+		return fmt.Errorf("%w: does not have required prefix '%s'", ErrInvalidKey, s.cfg.S3.Prefix)
+	}
+	if job.TargetBucket == "" {
+		return ErrMissingTargetBucket
+	}
+	if _, err := parseCopyOptions(job.Options); err != nil {
+		return err
+	}
+	if _, _, err := s.copyTarget(job.TargetConnection, job.TargetBucket); err != nil {
+		return err
+	}
+
+	// Within the browsed bucket, a copy follows the rules of a move
+	if job.TargetConnection == config.DefaultConnection && job.TargetBucket == s.cfg.S3.Bucket {
+		if !s.validateKeyPrefix(job.Destination) {
+			return fmt.Errorf("%w: does not have required prefix '%s'", ErrInvalidKey, s.cfg.S3.Prefix)
+		}
+		if job.Source == job.Destination {
+			return ErrDestinationExists
+		}
+		if strings.HasSuffix(job.Source, "/") && strings.HasPrefix(job.Destination, job.Source) {
+			return ErrMoveIntoItself
+		}
+	}
+	return nil
+}
+
+// parseCopyOptions decodes and validates the options of a copy job.
+func parseCopyOptions(raw string) (copyJobOptions, error) {
+	var opts copyJobOptions
+	if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+		return opts, fmt.Errorf("invalid copy options: %w", err)
+	}
+	switch opts.Overwrite {
+	case overwriteSkip, overwriteAlways, overwriteNewer:
+		return opts, nil
+	default:
+		return opts, fmt.Errorf("%w: %q", ErrInvalidOverwrite, opts.Overwrite)
+	}
+}
+
+// connectionNames returns the connections objects can be copied to, the default one first.
+func (s *App) connectionNames() []string {
+	names := []string{config.DefaultConnection}
+	for _, conn := range s.cfg.Connections {
+		if s.connections[conn.Name] != nil {
+			names = append(names, conn.Name)
+		}
+	}
+	return names
+}
+
+// copyTarget returns a service bound to bucket on the named connection, and whether
+// objects of the browsed bucket can be copied to it server-side.
+func (s *App) copyTarget(connection, bucket string) (*s3svc.Service, bool, error) {
+	conn, ok := s.cfg.ConnectionByName(connection)
+	if !ok {
+		return nil, false, fmt.Errorf("%w: %s", ErrUnknownConnection, connection)
+	}
+	if conn.Name == config.DefaultConnection {
+		return s.s3svc.ForBucket(bucket), true, nil
+	}
+
+	client := s.connections[conn.Name]
+	if client == nil {
+		return nil, false, fmt.Errorf("%w: %s is not initialized", ErrUnknownConnection, connection)
+	}
+	cfg := s.cfg
+	cfg.S3 = conn.S3Config()
+	cfg.S3.Bucket = bucket
+	svc := s3svc.NewS3Svc(cfg, client)
+	svc.SetLogger(s.log)
+	return svc, conn.SameEndpoint(s.cfg.S3.Connection()), nil
+}
+
+// runCopyJob copies the objects of job and records the outcome.
+func (s *App) runCopyJob(ctx context.Context, job dto.Job) {
+	err := s.copyObjects(ctx, job)
+	if err != nil {
+		s.log.Error("Copy failed", slog.Int("job", int(job.ID)), slog.String("error", err.Error()))
+	} else {
+		s.log.Info("Copy completed", slog.Int("job", int(job.ID)), slog.Bool("dryRun", job.DryRun))
+	}
+
+	// Record the outcome even if the job was cancelled by a shutdown
+	if finishErr := s.dbsvc.FinishJob(context.WithoutCancel(ctx), job.ID, err); finishErr != nil {
+		s.log.Error("Failed to finish job", slog.Int("job", int(job.ID)), slog.String("error", finishErr.Error()))
+	}
+}
+
+// copyObjects plans the copy against the objects already in the target, then copies
+// each object, saving progress and updating the target catalog as objects land.
+// A dry run stops after the plan, recording what would be copied and skipped.
+func (s *App) copyObjects(ctx context.Context, job dto.Job) error {
+	opts, err := parseCopyOptions(job.Options)
+	if err != nil {
+		return err
+	}
+	target, serverSide, err := s.copyTarget(job.TargetConnection, job.TargetBucket)
+	if err != nil {
+		return err
+	}
+	source := s.s3svc.ForBucket(job.Bucket)
+
+	objects, err := s.copySourceObjects(ctx, source, job.Source)
+	if err != nil {
+		return err
+	}
+	existing, err := target.ListAllObjects(ctx, job.Destination)
+	if err != nil {
+		return fmt.Errorf("cannot list target %s: %w", job.Destination, err)
+	}
+
+	items, skipped := planCopy(objects, existing, job.Source, job.Destination, opts.Overwrite)
+	var totalBytes int64
+	for _, item := range items {
+		totalBytes += item.src.Size
+	}
+	if err := s.dbsvc.StartJob(ctx, job.ID, len(objects), totalBytes); err != nil {
+		return err
+	}
+
+	progress := dto.JobProgress{SkippedItems: int32(skipped)} //nolint:gosec // bounded by the object count
+	if job.DryRun {
+		progress.DoneItems = int32(len(items)) //nolint:gosec // bounded by the object count
+		progress.DoneBytes = totalBytes
+		return s.dbsvc.UpdateJobProgress(ctx, job.ID, progress)
+	}
+
+	syncCatalog := s.catalogsBucket(ctx, job.TargetConnection, job.TargetBucket)
+	copyOpts := s3svc.CopyOptions{
+		PreserveMetadata:     opts.PreserveMetadata,
+		PreserveStorageClass: opts.PreserveStorageClass,
+	}
+	lastSaved := time.Now()
+	for _, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		etag, err := s.copyItem(ctx, source, target, serverSide, item, copyOpts)
+		if err != nil {
+			s.log.Error("Failed to copy object", slog.String("key", item.src.Key), slog.String("error", err.Error()))
+			progress.FailedItems++
+		} else {
+			progress.DoneItems++
+			progress.DoneBytes += item.src.Size
+			if syncCatalog {
+				s.syncCopiedObject(context.WithoutCancel(ctx), job.TargetBucket, item, etag, opts.PreserveStorageClass)
+			}
+		}
+
+		if time.Since(lastSaved) >= jobProgressInterval {
+			lastSaved = time.Now()
+			if err := s.dbsvc.UpdateJobProgress(ctx, job.ID, progress); err != nil {
+				s.log.Warn("Failed to save job progress", slog.String("error", err.Error()))
+			}
+		}
+	}
+	if err := s.dbsvc.UpdateJobProgress(context.WithoutCancel(ctx), job.ID, progress); err != nil {
+		s.log.Warn("Failed to save job progress", slog.String("error", err.Error()))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("copy interrupted after %d of %d objects: %w", progress.DoneItems, len(items), err)
+	}
+	if progress.FailedItems > 0 {
+		return fmt.Errorf("%w: %d of %d", ErrCopyIncomplete, progress.FailedItems, len(items))
+	}
+	return nil
+}
+
+// copySourceObjects returns the objects to copy: the object itself, or everything under a folder,
+// the empty key being the bucket root.
+func (s *App) copySourceObjects(ctx context.Context, source *s3svc.Service, key string) ([]s3svc.ObjectInfo, error) {
+	if key == "" || strings.HasSuffix(key, "/") {
+		objects, err := source.ListAllObjects(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list %s: %w", key, err)
+		}
+		return objects, nil
+	}
+	info, err := source.StatObject(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot copy %s: %w", key, err)
+	}
+	return []s3svc.ObjectInfo{*info}, nil
+}
+
+// copyItem copies one object server-side or by streaming it, and returns the new ETag when known.
+func (s *App) copyItem(
+	ctx context.Context, source, target *s3svc.Service, serverSide bool, item copyItem, opts s3svc.CopyOptions,
+) (string, error) {
+	if serverSide {
+		return "", target.CopyObjectFrom(ctx, source.GetBucketName(), item.src, item.dstKey, opts) //nolint:wrapcheck // already wrapped
+	}
+	result, err := target.StreamCopyFrom(ctx, source, item.src.Key, item.dstKey, opts,
+		s.cfg.Upload.PartSize, s.cfg.Upload.Concurrency)
+	if err != nil {
+		return "", err //nolint:wrapcheck // already wrapped
+	}
+	return result.ETag, nil
+}
+
+// catalogsBucket reports whether the catalog tracks bucket, so copied objects can be recorded.
+// Only buckets of the default connection are scanned into the catalog.
+func (s *App) catalogsBucket(ctx context.Context, connection, bucket string) bool {
+	if s.dbsvc == nil || connection != config.DefaultConnection {
+		return false
+	}
+	buckets, err := s.dbsvc.GetBuckets(ctx)
+	if err != nil {
+		s.log.Warn("Failed to list catalog buckets", slog.String("error", err.Error()))
+		return false
+	}
+	for _, b := range buckets {
+		if b.Name == bucket {
+			return true
+		}
+	}
+	return false
+}
+
+// syncCopiedObject records a copied object and its parent folders in the catalog.
+// Errors are only logged: the next scan fixes the catalog.
+func (s *App) syncCopiedObject(ctx context.Context, bucket string, item copyItem, etag string, keepClass bool) {
+	storageClass := ""
+	if keepClass {
+		storageClass = item.src.StorageClass
+	}
+	if err := s.dbsvc.SyncUploadedObject(ctx, bucket, item.dstKey, item.src.Size, etag, storageClass); err != nil {
+		s.log.Error("Failed to sync copy to database", slog.String("key", item.dstKey), slog.String("error", err.Error()))
+		return
+	}
+	// The configured prefix only applies to the browsed bucket
+	base := ""
+	if bucket == s.cfg.S3.Bucket {
+		base = s.cfg.S3.Prefix
+	}
+	if err := s.dbsvc.SyncUploadedFolders(ctx, bucket, base, item.dstKey); err != nil {
+		s.log.Error("Failed to sync destination folders", slog.String("key", item.dstKey), slog.String("error", err.Error()))
+	}
+}
+
+// planCopy maps each object under src to its key under dst and drops those the
+// overwrite policy keeps. It returns the objects to copy and the number skipped.
+func planCopy(objects, existing []s3svc.ObjectInfo, src, dst, overwrite string) ([]copyItem, int) {
+	present := make(map[string]s3svc.ObjectInfo, len(existing))
+	for _, obj := range existing {
+		present[obj.Key] = obj
+	}
+
+	items := make([]copyItem, 0, len(objects))
+	skipped := 0
+	for _, obj := range objects {
+		dstKey := dst + strings.TrimPrefix(obj.Key, src)
+		if current, ok := present[dstKey]; ok {
+			if overwrite == overwriteSkip || (overwrite == overwriteNewer && !obj.LastModified.After(current.LastModified)) {
+				skipped++
+				continue
+			}
+		}
+		items = append(items, copyItem{src: obj, dstKey: dstKey})
+	}
+	return items, skipped
+}
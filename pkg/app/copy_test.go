@@ -0,0 +1,73 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanCopy(t *testing.T) {
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := old.Add(24 * time.Hour)
+	objects := []s3svc.ObjectInfo{
+		{Key: "photos/", LastModified: old},
+		{Key: "photos/a.jpg", Size: 10, LastModified: recent},
+		{Key: "photos/b.jpg", Size: 20, LastModified: old},
+	}
+	existing := []s3svc.ObjectInfo{
+		{Key: "backup/photos/a.jpg", LastModified: old},
+		{Key: "backup/photos/b.jpg", LastModified: recent},
+	}
+
+	dstKeys := func(items []copyItem) []string {
+		keys := []string{}
+		for _, item := range items {
+			keys = append(keys, item.dstKey)
+		}
+		return keys
+	}
+
+	items, skipped := planCopy(objects, existing, "photos/", "backup/photos/", overwriteSkip)
+	assert.Equal(t, []string{"backup/photos/"}, dstKeys(items))
+	assert.Equal(t, 2, skipped)
+
+	items, skipped = planCopy(objects, existing, "photos/", "backup/photos/", overwriteNewer)
+	assert.Equal(t, []string{"backup/photos/", "backup/photos/a.jpg"}, dstKeys(items))
+	assert.Equal(t, 1, skipped)
+
+	items, skipped = planCopy(objects, existing, "photos/", "backup/photos/", overwriteAlways)
+	assert.Len(t, items, 3)
+	assert.Zero(t, skipped)
+}
+
+func TestParseCopyOptions(t *testing.T) {
+	opts, err := parseCopyOptions(`{"overwrite":"newer","preserveMetadata":true}`)
+	require.NoError(t, err)
+	assert.Equal(t, copyJobOptions{Overwrite: overwriteNewer, PreserveMetadata: true}, opts)
+
+	_, err = parseCopyOptions(`{"overwrite":"always"}`)
+	assert.ErrorIs(t, err, ErrInvalidOverwrite)
+}
+
+func TestValidateCopy(t *testing.T) {
+	app, _ := newOrganizeTestApp(t, map[string]string{})
+	app.cfg.Connections = []config.ConnectionConfig{{Name: "offsite", Endpoint: "http://offsite:9000"}}
+	job := func(src, connection, bucket, dst string) dto.Job {
+		return dto.Job{
+			Source: src, TargetConnection: connection, TargetBucket: bucket, Destination: dst,
+			Options: `{"overwrite":"skip"}`,
+		}
+	}
+
+	require.NoError(t, app.validateCopy(job("photos/", config.DefaultConnection, "archive", "photos/")))
+	assert.ErrorIs(t, app.validateCopy(job("photos/", config.DefaultConnection, "bucket", "photos/2024/photos/")), ErrMoveIntoItself)
+	assert.ErrorIs(t, app.validateCopy(job("a.txt", config.DefaultConnection, "", "a.txt")), ErrMissingTargetBucket)
+	// Configured but without a client: its initialization failed
+	assert.ErrorIs(t, app.validateCopy(job("a.txt", "offsite", "archive", "a.txt")), ErrUnknownConnection)
+	assert.ErrorIs(t, app.validateCopy(job("a.txt", "missing", "archive", "a.txt")), ErrUnknownConnection)
+}
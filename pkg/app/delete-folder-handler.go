@@ -0,0 +1,289 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+const (
+	// deletePreviewSample is the number of keys listed on the delete preview
+	deletePreviewSample = 20
+	// deleteBatchAttempts is how many times a batch, or the keys S3 failed to delete, are sent
+	deleteBatchAttempts = 3
+	// deleteFailuresReported is the number of failed keys named in the job error
+	deleteFailuresReported = 5
+)
+
+// deleteRetryDelay is the wait before the first retry of a batch, doubled for each next one.
+// It is a variable so tests do not wait.
+var deleteRetryDelay = time.Second
+
+var (
+	// ErrNotAFolder is returned when a recursive delete targets a key without a trailing "/".
+	ErrNotAFolder = errors.New("not a folder")
+	// ErrDeleteRoot is returned when deleting the bucket root or the configured prefix.
+	ErrDeleteRoot = errors.New("cannot delete the root folder")
+	// ErrDeleteTrashFolder is returned when deleting a folder holding the trash, as it is enabled.
+	ErrDeleteTrashFolder = errors.New("cannot delete the folder holding the trash")
+	// ErrConfirmationMismatch is returned when the typed confirmation does not match the folder name.
+	ErrConfirmationMismatch = errors.New("confirmation does not match the folder name")
+	// ErrDeleteIncomplete is returned when some objects could not be deleted.
+	ErrDeleteIncomplete = errors.New("some objects could not be deleted")
+)
+
+// DeleteFolderPreviewHandler shows what a recursive folder delete would remove
+// and asks for a typed confirmation.
+func (s *App) DeleteFolderPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableDelete {
+		s.renderErrorPage(ctx, w, "Delete functionality is disabled")
+		return
+	}
+
+	key, err := s.extractAndValidateKey(r)
+	if err == nil {
+		err = s.validateFolderDelete(key)
+	}
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	summary, err := s.dbsvc.GetFolderSummary(ctx, s.cfg.S3.Bucket, key, deletePreviewSample)
+	if err != nil {
+		s.log.Error("Failed to summarize folder", slog.String("key", key), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to load the folder contents")
+		return
+	}
+
+	if err := views.RenderDeleteFolderPreview(*summary, parentFolder(key), s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render delete preview", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// DeleteFolderHandler starts a background job deleting a folder and everything below it.
+func (s *App) DeleteFolderHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableDelete {
+		s.renderErrorPage(ctx, w, "Delete functionality is disabled")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.renderErrorPage(ctx, w, "Invalid request")
+		return
+	}
+
+	key := r.PostFormValue("key")
+	err := s.validateFolderDelete(key)
+	if err == nil && strings.TrimSpace(r.PostFormValue("confirm")) != path.Base(strings.TrimSuffix(key, "/")) {
+		err = ErrConfirmationMismatch
+	}
+	if err != nil {
+		s.log.Warn("Folder delete rejected", slog.String("key", key), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+	job, err := s.dbsvc.CreateJob(ctx, dto.Job{Kind: dto.JobKindDelete, Bucket: s.cfg.S3.Bucket, Source: key})
+	if err != nil {
+		s.log.Error("Failed to create delete job", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to start the delete")
+		return
+	}
+	s.log.Info("Folder delete started", slog.Int("job", int(job.ID)), slog.String("key", key))
+
+	go s.runDeleteJob(s.backgroundContext(), *job, s.requestUser(r))
+	http.Redirect(w, r, fmt.Sprintf("/jobs/%d", job.ID), http.StatusSeeOther)
+}
+
+// validateFolderDelete checks that key is a folder that can be deleted recursively.
+func (s *App) validateFolderDelete(key string) error {
+	if key == "" {
+		return ErrMissingKeyParam
+	}
+	if !strings.HasSuffix(key, "/") {
+		return fmt.Errorf("%w: %s", ErrNotAFolder, key)
+	}
+	if !s.validateKeyPrefix(key) {
+		return ErrDeleteOutsidePrefix
+	}
+	if key == "/" || key == s.cfg.S3.Prefix {
+		return ErrDeleteRoot
+	}
+	// The objects moved to the trash would be listed, then deleted for good
+	if s.cfg.Trash.Enable && s.trashBucket(s.cfg.S3.Bucket) == s.cfg.S3.Bucket && strings.HasPrefix(s.cfg.Trash.Prefix, key) {
+		return ErrDeleteTrashFolder
+	}
+	return nil
+}
+
+// runDeleteJob deletes every object under job.Source for user and records the outcome.
+func (s *App) runDeleteJob(ctx context.Context, job dto.Job, user string) {
+	err := s.deleteFolder(ctx, job, user)
+	if err != nil {
+		s.log.Error("Folder delete failed", slog.Int("job", int(job.ID)), slog.String("error", err.Error()))
+	} else {
+		s.log.Info("Folder delete completed", slog.Int("job", int(job.ID)))
+	}
+
+	// Record the outcome even if the job was cancelled by a shutdown
+	if finishErr := s.dbsvc.FinishJob(context.WithoutCancel(ctx), job.ID, err); finishErr != nil {
+		s.log.Error("Failed to finish job", slog.Int("job", int(job.ID)), slog.String("error", finishErr.Error()))
+	}
+}
+
+// deleteFolder deletes the folder page by page while it is listed, each page of at most
+// s3svc.MaxDeleteBatch keys in one batch, removing each batch from the catalog once S3 confirmed it.
+// When the trash is enabled the batches are moved to the trash for user instead, and the job stops at
+// the first object that cannot be moved. As the folder is not listed upfront, the totals of the job
+// are those of the catalog.
+func (s *App) deleteFolder(ctx context.Context, job dto.Job, user string) error {
+	svc := s.s3svc.ForBucket(job.Bucket)
+
+	summary, err := s.dbsvc.GetFolderSummary(ctx, job.Bucket, job.Source, 0)
+	if err != nil {
+		return fmt.Errorf("cannot count %s: %w", job.Source, err)
+	}
+	if err := s.dbsvc.StartJob(ctx, job.ID, int(summary.Objects), summary.Size); err != nil {
+		return err
+	}
+
+	var progress dto.JobProgress
+	var failures []s3svc.DeleteFailure
+	err = svc.WalkObjects(ctx, job.Source, func(page []s3svc.ObjectInfo) error {
+		batch := make([]string, 0, len(page))
+		sizes := make(map[string]int64, len(page))
+		for _, obj := range page {
+			batch = append(batch, obj.Key)
+			sizes[obj.Key] = obj.Size
+		}
+
+		var deleted []string
+		var failed []s3svc.DeleteFailure
+		var trashErr error
+		if s.cfg.Trash.Enable {
+			deleted, trashErr = s.moveToTrash(ctx, batch, user)
+		} else {
+			deleted, failed = s.deleteBatch(ctx, svc, batch)
+		}
+		failures = append(failures, failed...)
+		progress.FailedItems += int32(len(failed)) //nolint:gosec // bounded by the batch size
+		progress.DoneItems += int32(len(deleted))  //nolint:gosec // bounded by the batch size
+		for _, key := range deleted {
+			progress.DoneBytes += sizes[key]
+		}
+
+		if len(deleted) > 0 {
+			if err := s.dbsvc.SyncDeletedBatch(context.WithoutCancel(ctx), job.Bucket, deleted); err != nil {
+				s.log.Error("Failed to sync delete to database", slog.String("error", err.Error()))
+			}
+		}
+		if err := s.dbsvc.UpdateJobProgress(context.WithoutCancel(ctx), job.ID, progress); err != nil {
+			s.log.Warn("Failed to save job progress", slog.String("error", err.Error()))
+		}
+		if trashErr != nil {
+			return trashErr
+		}
+		return ctx.Err()
+	})
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("delete interrupted after %d objects: %w", progress.DoneItems, ctxErr)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot delete %s: %w", job.Source, err)
+	}
+	if len(failures) > 0 {
+		return deleteFailuresError(failures, int(progress.DoneItems+progress.FailedItems))
+	}
+
+	// Also drop the folders the catalog inferred from keys, which have no marker to delete
+	if err := s.dbsvc.SyncDeletedTree(context.WithoutCancel(ctx), job.Bucket, job.Source); err != nil {
+		s.log.Error("Failed to sync deleted folder", slog.String("key", job.Source), slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// deleteBatch deletes keys with one DeleteObjects request, then retries the keys S3 reported
+// as failed, or the whole batch if the request failed, with a growing delay.
+// It returns the deleted keys and the failures of the last attempt.
+func (s *App) deleteBatch(
+	ctx context.Context, svc *s3svc.Service, keys []string,
+) ([]string, []s3svc.DeleteFailure) {
+	deleted := make([]string, 0, len(keys))
+	pending := keys
+	var failures []s3svc.DeleteFailure
+	delay := deleteRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		failed, err := svc.DeleteObjectBatch(ctx, pending)
+		switch {
+		case err != nil:
+			failures = make([]s3svc.DeleteFailure, 0, len(pending))
+			for _, key := range pending {
+				failures = append(failures, s3svc.DeleteFailure{Key: key, Message: err.Error()})
+			}
+		default:
+			failures = failed
+			retry := make(map[string]bool, len(failed))
+			for _, f := range failed {
+				retry[f.Key] = true
+			}
+			next := make([]string, 0, len(failed))
+			for _, key := range pending {
+				if retry[key] {
+					next = append(next, key)
+				} else {
+					deleted = append(deleted, key)
+				}
+			}
+			pending = next
+		}
+
+		if len(failures) == 0 || attempt == deleteBatchAttempts {
+			return deleted, failures
+		}
+		s.log.Warn("Retrying objects that failed to delete",
+			slog.Int("keys", len(pending)),
+			slog.Int("attempt", attempt))
+		select {
+		case <-ctx.Done():
+			return deleted, failures
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// deleteFailuresError summarizes the keys left after the retries, naming the first ones.
+func deleteFailuresError(failures []s3svc.DeleteFailure, total int) error {
+	named := make([]string, 0, deleteFailuresReported)
+	for _, f := range failures[:min(len(failures), deleteFailuresReported)] {
+		reason := f.Code
+		if reason == "" {
+			reason = f.Message
+		}
+		named = append(named, fmt.Sprintf("%s (%s)", f.Key, reason))
+	}
+	return fmt.Errorf("%w: %d of %d, including %s",
+		ErrDeleteIncomplete, len(failures), total, strings.Join(named, ", "))
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -76,19 +77,28 @@ func (s *App) processDelete(ctx context.Context, w http.ResponseWriter, r *http.
 		return err
 	}
 
-	// Delete from S3
-	if err := s.performS3Delete(ctx, keys); err != nil {
-		s.log.Error("Failed to delete from S3", slog.String("error", err.Error()))
-		return fmt.Errorf("delete failed: %w", err)
-	}
+	// Move to the trash, or delete from S3
+	deleted, err := s.deleteOrTrash(ctx, r, keys)
 
 	// Sync to database (log errors but don't fail)
-	if err := s.performDatabaseDeleteSync(ctx, keys); err != nil {
-		s.log.Error("Failed to sync delete to database", slog.String("error", err.Error()))
+	if len(deleted) > 0 && s.dbsvc != nil {
+		if err := s.performDatabaseDeleteSync(ctx, deleted); err != nil {
+			s.log.Error("Failed to sync delete to database", slog.String("error", err.Error()))
+		}
+	}
+	if err != nil {
+		s.log.Error("Failed to delete from S3", slog.String("error", err.Error()))
+		return fmt.Errorf("delete failed: %w", err)
 	}
 
-	// Redirect back to folder
+	// Redirect back to folder, or to the duplicates report the files were chosen from
 	redirectURL := fmt.Sprintf("/?folder=%s&page=1", url.QueryEscape(folder))
+	if r.FormValue("from") == "duplicates" {
+		redirectURL = "/duplicates"
+		if page, err := strconv.Atoi(r.FormValue("page")); err == nil && page > 1 {
+			redirectURL += "?page=" + strconv.Itoa(page)
+		}
+	}
 	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 	return nil
 }
@@ -125,6 +135,18 @@ func (s *App) validateDeleteKeys(keys []string) error {
 	return nil
 }
 
+// deleteOrTrash moves keys to the trash when it is enabled, else deletes them from S3.
+// It returns the keys gone from the bucket, which can be some of them when it fails.
+func (s *App) deleteOrTrash(ctx context.Context, r *http.Request, keys []string) ([]string, error) {
+	if s.cfg.Trash.Enable {
+		return s.moveToTrash(ctx, keys, s.requestUser(r))
+	}
+	if err := s.performS3Delete(ctx, keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
 // performS3Delete deletes objects from S3 (single or bulk).
 func (s *App) performS3Delete(ctx context.Context, keys []string) error {
 	if len(keys) == 1 {
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteBatch_RetriesFailedKeys(t *testing.T) {
+	deleteRetryDelay = time.Millisecond
+	t.Cleanup(func() { deleteRetryDelay = time.Second })
+
+	app, fake := newOrganizeTestApp(t, map[string]string{"d/a": "a", "d/b": "b", "d/c": "c"})
+	// b fails once then succeeds, c fails on every attempt
+	fake.deleteFailures = map[string]int{"d/b": 1, "d/c": deleteBatchAttempts}
+
+	deleted, failures := app.deleteBatch(context.Background(), app.s3svc, []string{"d/a", "d/b", "d/c"})
+
+	assert.ElementsMatch(t, []string{"d/a", "d/b"}, deleted)
+	require.Len(t, failures, 1)
+	assert.Equal(t, s3svc.DeleteFailure{Key: "d/c", Code: "SlowDown", Message: "retry"}, failures[0])
+	assert.Equal(t, map[string]string{"d/c": "c"}, fake.objects)
+}
+
+func TestDeleteFolderHandler_Validation(t *testing.T) {
+	app, fake := newOrganizeTestApp(t, map[string]string{"photos/a.jpg": "a"})
+
+	tests := []struct {
+		name string
+		form url.Values
+		want error
+	}{
+		{"not a folder", url.Values{"key": {"photos/a.jpg"}, "confirm": {"a.jpg"}}, ErrNotAFolder},
+		{"root", url.Values{"key": {"/"}, "confirm": {"/"}}, ErrDeleteRoot},
+		{"wrong confirmation", url.Values{"key": {"photos/"}, "confirm": {"photo"}}, ErrConfirmationMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			app.DeleteFolderHandler(rec, organizeRequest("/delete/folder", tt.form))
+			assert.NotEqual(t, http.StatusSeeOther, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.want.Error())
+		})
+	}
+	assert.Contains(t, fake.objects, "photos/a.jpg")
+}
+
+func TestDeleteFailuresError(t *testing.T) {
+	failures := []s3svc.DeleteFailure{
+		{Key: "a", Code: "AccessDenied"},
+		{Key: "b", Message: "connection reset"},
+	}
+	err := deleteFailuresError(failures, 10)
+	require.ErrorIs(t, err, ErrDeleteIncomplete)
+	assert.Contains(t, err.Error(), "2 of 10, including a (AccessDenied), b (connection reset)")
+}
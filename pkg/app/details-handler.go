@@ -0,0 +1,218 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+// S3 limits on object tags and user metadata.
+const (
+	maxObjectTags      = 10
+	maxTagKeyLength    = 128
+	maxTagValueLength  = 256
+	maxUserMetadataLen = 2048
+)
+
+var (
+	// ErrTooManyTags is returned when more tags than S3 accepts are submitted.
+	ErrTooManyTags = errors.New("an object can have at most 10 tags")
+	// ErrInvalidTag is returned when a tag key or value is too long or a key is repeated.
+	ErrInvalidTag = errors.New("invalid tag")
+	// ErrInvalidMetadata is returned when a user metadata key or value cannot be sent as a header.
+	ErrInvalidMetadata = errors.New("invalid metadata")
+	// ErrMetadataTooLarge is returned when the user metadata exceeds the 2 KB S3 allows.
+	ErrMetadataTooLarge = errors.New("user metadata is limited to 2 KB")
+)
+
+var metadataKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// ObjectDetailsHandler shows the headers, encryption, checksums, retention, metadata and tags of an object.
+func (s *App) ObjectDetailsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key, err := s.extractAndValidateKey(r)
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	details, err := s.s3svc.GetObjectDetails(ctx, key)
+	if err != nil {
+		s.log.Error("Failed to read object details", slog.String("key", key), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to read the object details")
+		return
+	}
+
+	if err := views.RenderObjectDetails(details, parentFolder(key), s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render object details", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// UpdateTagsHandler replaces the tags of an object.
+func (s *App) UpdateTagsHandler(w http.ResponseWriter, r *http.Request) {
+	s.handleDetailsWrite(w, r, func(ctx context.Context, key string) error {
+		tags, err := parseTagsForm(r.PostForm)
+		if err != nil {
+			return err
+		}
+		if err := s.s3svc.PutObjectTags(ctx, key, tags); err != nil {
+			return fmt.Errorf("cannot update the tags: %w", err)
+		}
+		s.log.Info("Object tags updated", slog.String("key", key), slog.Int("tags", len(tags)))
+
+		// Sync the tag index (log errors but don't fail)
+		if s.dbsvc != nil && s.cfg.Scan.IndexTags {
+			indexed := make(map[string]string, len(tags))
+			for _, t := range tags {
+				indexed[t.Key] = t.Value
+			}
+			if err := s.dbsvc.SyncObjectTags(ctx, s.cfg.S3.Bucket, key, indexed); err != nil {
+				s.log.Error("Failed to sync object tags", slog.String("key", key), slog.String("error", err.Error()))
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateMetadataHandler replaces the content type, cache control and user metadata of an object.
+func (s *App) UpdateMetadataHandler(w http.ResponseWriter, r *http.Request) {
+	s.handleDetailsWrite(w, r, func(ctx context.Context, key string) error {
+		update, err := parseMetadataForm(r.PostForm)
+		if err != nil {
+			return err
+		}
+		etag, err := s.s3svc.ReplaceObjectMetadata(ctx, key, update)
+		if err != nil {
+			return fmt.Errorf("cannot update the metadata: %w", err)
+		}
+		s.log.Info("Object metadata updated", slog.String("key", key))
+
+		// The copy in place changes the ETag and modification date (log errors but don't fail)
+		if s.dbsvc != nil {
+			info, err := s.s3svc.StatObject(ctx, key)
+			if err == nil {
+				err = s.dbsvc.SyncUploadedObject(ctx, s.cfg.S3.Bucket, key, info.Size, etag, info.StorageClass)
+			}
+			if err != nil {
+				s.log.Error("Failed to sync object metadata", slog.String("key", key), slog.String("error", err.Error()))
+			}
+		}
+		return nil
+	})
+}
+
+// handleDetailsWrite checks a form posting "key", applies action to it and returns to its details.
+func (s *App) handleDetailsWrite(
+	w http.ResponseWriter, r *http.Request, action func(ctx context.Context, key string) error,
+) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableUpload {
+		s.renderErrorPage(ctx, w, "Upload functionality is disabled")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.renderErrorPage(ctx, w, "Invalid request")
+		return
+	}
+
+	key := r.PostFormValue("key")
+	err := s.validatePostedKey(key)
+	if err == nil {
+		err = action(ctx, key)
+	}
+	if err != nil {
+		s.log.Warn("Object update failed", slog.String("key", key), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	http.Redirect(w, r, "/object?key="+url.QueryEscape(key), http.StatusSeeOther)
+}
+
+// parseTagsForm reads the tag_key/tag_value pairs of the tags form. Rows with a blank key are ignored.
+func parseTagsForm(form url.Values) ([]s3svc.Tag, error) {
+	keys, values := form["tag_key"], form["tag_value"]
+	tags := []s3svc.Tag{}
+	seen := map[string]bool{}
+	for i, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		value := ""
+		if i < len(values) {
+			value = strings.TrimSpace(values[i])
+		}
+		switch {
+		case len(key) > maxTagKeyLength:
+			return nil, fmt.Errorf("%w: key %q is longer than %d characters", ErrInvalidTag, key, maxTagKeyLength)
+		case len(value) > maxTagValueLength:
+			return nil, fmt.Errorf("%w: value of %q is longer than %d characters", ErrInvalidTag, key, maxTagValueLength)
+		case seen[key]:
+			return nil, fmt.Errorf("%w: key %q is repeated", ErrInvalidTag, key)
+		}
+		seen[key] = true
+		tags = append(tags, s3svc.Tag{Key: key, Value: value})
+	}
+	if len(tags) > maxObjectTags {
+		return nil, ErrTooManyTags
+	}
+	return tags, nil
+}
+
+// parseMetadataForm reads the content type, cache control and meta_key/meta_value pairs of the
+// metadata form. Keys are lowercased, as S3 does, and may be given with their x-amz-meta- prefix.
+func parseMetadataForm(form url.Values) (s3svc.MetadataUpdate, error) {
+	update := s3svc.MetadataUpdate{
+		ContentType:  strings.TrimSpace(form.Get("content_type")),
+		CacheControl: strings.TrimSpace(form.Get("cache_control")),
+		Metadata:     map[string]string{},
+	}
+
+	keys, values := form["meta_key"], form["meta_value"]
+	size := 0
+	for i, key := range keys {
+		key = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(key)), "x-amz-meta-")
+		if key == "" {
+			continue
+		}
+		value := ""
+		if i < len(values) {
+			value = strings.TrimSpace(values[i])
+		}
+		if !metadataKeyPattern.MatchString(key) {
+			return update, fmt.Errorf("%w: key %q may only contain letters, digits, '.', '_' and '-'", ErrInvalidMetadata, key)
+		}
+		if _, ok := update.Metadata[key]; ok {
+			return update, fmt.Errorf("%w: key %q is repeated", ErrInvalidMetadata, key)
+		}
+		if !isPrintableASCII(value) {
+			return update, fmt.Errorf("%w: value of %q must be printable ASCII", ErrInvalidMetadata, key)
+		}
+		update.Metadata[key] = value
+		size += len(key) + len(value)
+	}
+	if size > maxUserMetadataLen {
+		return update, ErrMetadataTooLarge
+	}
+	return update, nil
+}
+
+// isPrintableASCII reports whether value can be sent unencoded in an HTTP header.
+func isPrintableASCII(value string) bool {
+	for _, c := range value {
+		if c < ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTagsForm(t *testing.T) {
+	tags, err := parseTagsForm(url.Values{
+		"tag_key":   {" team ", "", "env"},
+		"tag_value": {"data", "ignored", " prod "},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []s3svc.Tag{{Key: "team", Value: "data"}, {Key: "env", Value: "prod"}}, tags)
+
+	_, err = parseTagsForm(url.Values{"tag_key": {"a", "a"}, "tag_value": {"1", "2"}})
+	require.ErrorIs(t, err, ErrInvalidTag)
+
+	_, err = parseTagsForm(url.Values{"tag_key": {strings.Repeat("k", 129)}})
+	require.ErrorIs(t, err, ErrInvalidTag)
+
+	keys := make([]string, 11)
+	for i := range keys {
+		keys[i] = string(rune('a' + i))
+	}
+	_, err = parseTagsForm(url.Values{"tag_key": keys})
+	require.ErrorIs(t, err, ErrTooManyTags)
+}
+
+func TestParseMetadataForm(t *testing.T) {
+	update, err := parseMetadataForm(url.Values{
+		"content_type":  {" text/plain "},
+		"cache_control": {"max-age=60"},
+		"meta_key":      {"X-Amz-Meta-Owner", "", "project"},
+		"meta_value":    {"alice", "", "s3"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, s3svc.MetadataUpdate{
+		ContentType:  "text/plain",
+		CacheControl: "max-age=60",
+		Metadata:     map[string]string{"owner": "alice", "project": "s3"},
+	}, update)
+
+	for _, form := range []url.Values{
+		{"meta_key": {"bad key"}, "meta_value": {"v"}},
+		{"meta_key": {"a", "A"}, "meta_value": {"1", "2"}},
+		{"meta_key": {"a"}, "meta_value": {"café"}},
+	} {
+		_, err := parseMetadataForm(form)
+		require.ErrorIs(t, err, ErrInvalidMetadata, "form %v", form)
+	}
+
+	_, err = parseMetadataForm(url.Values{"meta_key": {"big"}, "meta_value": {strings.Repeat("v", 2048)}})
+	require.ErrorIs(t, err, ErrMetadataTooLarge)
+}
+
+func TestUpdateMetadataHandler_CopiesInPlace(t *testing.T) {
+	app, fake := newOrganizeTestApp(t, map[string]string{"doc.txt": "hello"})
+
+	rec := httptest.NewRecorder()
+	app.UpdateMetadataHandler(rec, organizeRequest("/object/metadata", url.Values{
+		"key":          {"doc.txt"},
+		"content_type": {"text/plain"},
+		"meta_key":     {"owner"},
+		"meta_value":   {"alice"},
+	}))
+
+	require.Equal(t, http.StatusSeeOther, rec.Code, rec.Body.String())
+	assert.Equal(t, "/object?key=doc.txt", rec.Header().Get("Location"))
+	assert.Equal(t, []string{"bucket/doc.txt"}, fake.copySources)
+	assert.Equal(t, map[string]string{"doc.txt": "hello"}, fake.objects)
+}
+
+func TestUpdateTagsHandler_RequiresUpload(t *testing.T) {
+	app, _ := newOrganizeTestApp(t, map[string]string{"doc.txt": "hello"})
+	app.cfg.S3.EnableUpload = false
+
+	rec := httptest.NewRecorder()
+	app.UpdateTagsHandler(rec, organizeRequest("/object/tags", url.Values{"key": {"doc.txt"}, "tag_key": {"a"}}))
+
+	assert.Contains(t, rec.Body.String(), "Upload functionality is disabled")
+}
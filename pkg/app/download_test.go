@@ -0,0 +1,135 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testObjectETag    = `"0123456789abcdef"`
+	testObjectContent = "0123456789abcdefghijklmnopqrstuvwxyz"
+)
+
+// newDownloadTestApp returns an App whose S3 client talks to a fake S3 endpoint.
+// The fake serves a single object with http.ServeContent, which implements Range
+// and conditional requests the same way S3 does for a single range.
+func newDownloadTestApp(t *testing.T) *App {
+	t.Helper()
+
+	modTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	fakeS3 := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bucket/dir/report v1 é.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", testObjectETag)
+		w.Header().Set("Content-Type", "text/plain")
+		http.ServeContent(w, r, "", modTime, strings.NewReader(testObjectContent))
+	})
+	return newFakeS3App(t, fakeS3, config.Config{S3: config.S3Config{Bucket: "bucket"}})
+}
+
+func doDownload(app *App, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/download?key=dir%2Freport+v1+%C3%A9.txt", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	app.DownloadFile(rec, req)
+	return rec
+}
+
+func TestDownloadFile_FullObject(t *testing.T) {
+	rec := doDownload(newDownloadTestApp(t), nil)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, testObjectContent, rec.Body.String())
+	assert.Equal(t, "36", rec.Header().Get("Content-Length"))
+	assert.Equal(t, testObjectETag, rec.Header().Get("ETag"))
+	assert.Equal(t, "Thu, 02 Jan 2025 03:04:05 GMT", rec.Header().Get("Last-Modified"))
+	assert.Equal(t, "bytes", rec.Header().Get("Accept-Ranges"))
+	assert.Equal(t, `attachment; filename="report v1 _.txt"; filename*=UTF-8''report%20v1%20%C3%A9.txt`,
+		rec.Header().Get("Content-Disposition"))
+}
+
+func TestDownloadFile_Range(t *testing.T) {
+	rec := doDownload(newDownloadTestApp(t), map[string]string{"Range": "bytes=10-15"})
+
+	require.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "abcdef", rec.Body.String())
+	assert.Equal(t, "bytes 10-15/36", rec.Header().Get("Content-Range"))
+	assert.Equal(t, "6", rec.Header().Get("Content-Length"))
+}
+
+func TestDownloadFile_RangeNotSatisfiable(t *testing.T) {
+	rec := doDownload(newDownloadTestApp(t), map[string]string{"Range": "bytes=100-200"})
+
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code)
+	assert.Equal(t, "bytes */36", rec.Header().Get("Content-Range"))
+}
+
+func TestDownloadFile_Conditional(t *testing.T) {
+	app := newDownloadTestApp(t)
+
+	rec := doDownload(app, map[string]string{"If-None-Match": testObjectETag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, testObjectETag, rec.Header().Get("ETag"))
+	assert.Equal(t, "Thu, 02 Jan 2025 03:04:05 GMT", rec.Header().Get("Last-Modified"))
+
+	rec = doDownload(app, map[string]string{"If-Modified-Since": "Fri, 03 Jan 2025 00:00:00 GMT"})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = doDownload(app, map[string]string{"If-None-Match": `"other"`})
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestDownloadFile_IfRangeMismatchReturnsFullObject(t *testing.T) {
+	rec := doDownload(newDownloadTestApp(t), map[string]string{
+		"Range":    "bytes=0-3",
+		"If-Range": `"stale-etag"`,
+	})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	body, _ := io.ReadAll(rec.Body)
+	assert.True(t, bytes.Equal([]byte(testObjectContent), body))
+}
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{"Plain ASCII", "report.pdf", `attachment; filename="report.pdf"`},
+		{"Quote is replaced", `a"b.txt`, `attachment; filename="a_b.txt"; filename*=UTF-8''a%22b.txt`},
+		{"Non-ASCII", "résumé.pdf", `attachment; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, contentDisposition("attachment", tt.filename))
+		})
+	}
+}
+
+func TestDownloadOptionsFromRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/download?key=a", nil)
+	req.Header.Set("Range", "bytes=0-1,5-6")
+	req.Header.Set("If-None-Match", testObjectETag)
+	req.Header.Set("If-Modified-Since", "Fri, 03 Jan 2025 00:00:00 GMT")
+
+	opts := downloadOptionsFromRequest(req)
+	assert.Empty(t, opts.Range, "multi-range requests should fall back to the full object")
+	assert.Equal(t, testObjectETag, opts.IfNoneMatch)
+	assert.Nil(t, opts.IfModifiedSince, "If-Modified-Since is ignored when If-None-Match is set")
+}
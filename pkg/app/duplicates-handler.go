@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+const (
+	// duplicateGroupsPerPage is the number of duplicate groups per page of the duplicates report.
+	duplicateGroupsPerPage = 25
+	// maxListedDuplicatePrefixes caps the prefixes of the duplicates report.
+	maxListedDuplicatePrefixes = 20
+)
+
+// ErrDuplicatesDisabled is returned when the duplicates report is not enabled.
+var ErrDuplicatesDisabled = errors.New("the duplicates report is disabled")
+
+// DuplicatesHandler shows a page of the duplicates report of the bucket, as computed by ComputeDuplicates.
+func (s *App) DuplicatesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.Duplicates.Enable {
+		s.renderErrorPage(ctx, w, ErrDuplicatesDisabled.Error())
+		return
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	page, err := ParsePaginationParams(r)
+	if err != nil {
+		http.Redirect(w, r, "/duplicates", http.StatusSeeOther)
+		return
+	}
+	offset := (page - 1) * duplicateGroupsPerPage
+	report, err := s.dbsvc.GetDuplicateReport(ctx, s.cfg.S3.Bucket, s.cfg.S3.Prefix,
+		offset, duplicateGroupsPerPage, maxListedDuplicatePrefixes)
+	if err != nil {
+		s.log.Error("Failed to read duplicates report", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to read the duplicates report")
+		return
+	}
+
+	var paging dto.PaginationInfo
+	if report != nil {
+		paging = dto.NewPaginationInfo(report.Groups, duplicateGroupsPerPage, page)
+		if paging.CurrentPage != page {
+			http.Redirect(w, r, "/duplicates", http.StatusSeeOther)
+			return
+		}
+	}
+	if err := views.RenderDuplicates(report, paging, s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render duplicates report", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// ComputeDuplicates recomputes the duplicates report of every bucket of the catalog. It is run by the scheduler.
+func (s *App) ComputeDuplicates(ctx context.Context) {
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.log.Warn("Skipping duplicates report - database unavailable")
+		return
+	}
+
+	buckets, err := s.dbsvc.GetBuckets(ctx)
+	if err != nil {
+		s.log.Error("Failed to list buckets for the duplicates report", slog.String("error", err.Error()))
+		return
+	}
+	for _, bucket := range buckets {
+		if ctx.Err() != nil {
+			return
+		}
+		start := time.Now()
+		if err := s.dbsvc.RefreshDuplicateReport(ctx, bucket.Name, s.cfg.S3.Prefix, s.cfg.Duplicates.MinSize); err != nil {
+			s.log.Error("Failed to compute duplicates report",
+				slog.String("bucket", bucket.Name),
+				slog.String("error", err.Error()))
+			continue
+		}
+		s.log.Info("Duplicates report computed",
+			slog.String("bucket", bucket.Name),
+			slog.Duration("duration", time.Since(start)))
+	}
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicatesHandler_Disabled(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.DuplicatesHandler(rec, httptest.NewRequest(http.MethodGet, "/duplicates", nil))
+
+	assert.Contains(t, rec.Body.String(), ErrDuplicatesDisabled.Error())
+}
+
+func TestDuplicatesHandler_WithoutDatabase(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+	app.cfg.Duplicates.Enable = true
+
+	rec := httptest.NewRecorder()
+	app.DuplicatesHandler(rec, httptest.NewRequest(http.MethodGet, "/duplicates", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestDeleteHandler_ReturnsToDuplicates(t *testing.T) {
+	var mu sync.Mutex
+	var deleted []string
+	app := newFakeS3App(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}), config.Config{S3: config.S3Config{Bucket: "bucket", EnableDelete: true}})
+
+	rec := httptest.NewRecorder()
+	app.DeleteHandler(rec, organizeRequest("/delete", url.Values{
+		"keys": {"copy/a.bin"}, "from": {"duplicates"}, "page": {"3"},
+	}))
+
+	require.Equal(t, http.StatusSeeOther, rec.Code, rec.Body.String())
+	assert.Equal(t, "/duplicates?page=3", rec.Header().Get("Location"))
+	assert.Equal(t, []string{"/bucket/copy/a.bin"}, deleted)
+}
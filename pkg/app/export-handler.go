@@ -0,0 +1,159 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/export"
+)
+
+var (
+	// ErrExportTagFilters is returned when a catalog export is filtered by tags or metadata.
+	ErrExportTagFilters = errors.New("exports can only be filtered by name, not by tags or metadata")
+	// ErrBucketNotScanned is returned when a bucket has no catalog yet.
+	ErrBucketNotScanned = errors.New("the bucket has not been scanned yet")
+)
+
+// ExportHandler downloads the files of the catalog of ?bucket= (the browsed bucket by default), under
+// ?prefix= and with ?search= in their key, as ?format=csv, ndjson or parquet. The files are streamed
+// from a database cursor, so exports of any size use the same memory.
+func (s *App) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	filter, format, err := s.exportFromValues(ctx, r.URL.Query())
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+	if !s.catalogsBucket(ctx, config.DefaultConnection, filter.Bucket) {
+		s.renderErrorPage(ctx, w, fmt.Sprintf("%s: %s", ErrBucketNotScanned, filter.Bucket))
+		return
+	}
+
+	filename := fmt.Sprintf("%s-catalog.%s", filter.Bucket, format)
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	start := time.Now()
+	count, err := export.Catalog(ctx, s.dbsvc, filter, format, w)
+	if err != nil {
+		s.log.Warn("Catalog export interrupted", slog.String("bucket", filter.Bucket), slog.String("error", err.Error()))
+		return
+	}
+	s.log.Info("Catalog exported",
+		slog.String("bucket", filter.Bucket),
+		slog.String("format", format),
+		slog.Int64("files", count),
+		slog.Duration("duration", time.Since(start)))
+}
+
+// exportFromValues reads the filter and format of a catalog export. The prefix of the browsed bucket
+// defaults to the configured prefix and cannot leave it.
+func (s *App) exportFromValues(ctx context.Context, values url.Values) (dto.CatalogFilter, string, error) {
+	bucket, err := s.requestedBucket(ctx, values.Get("bucket"))
+	if err != nil {
+		return dto.CatalogFilter{}, "", err
+	}
+	prefix := values.Get("prefix")
+	if bucket == s.cfg.S3.Bucket {
+		if prefix == "" {
+			prefix = s.cfg.S3.Prefix
+		}
+		if !s.validateKeyPrefix(prefix) {
+			return dto.CatalogFilter{}, "", fmt.Errorf("%w: %s", ErrInvalidKey, prefix)
+		}
+	}
+
+	search, filters := dto.ParseSearchQuery(values.Get("search"))
+	if len(filters) > 0 {
+		return dto.CatalogFilter{}, "", ErrExportTagFilters
+	}
+
+	format := values.Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if !slices.Contains(export.Formats, format) {
+		return dto.CatalogFilter{}, "", fmt.Errorf("%w: %s", ErrInvalidExportFormat, format)
+	}
+	return dto.CatalogFilter{Bucket: bucket, Prefix: prefix, Search: search, Exclude: s.cfg.ExportPrefix(bucket)}, format, nil
+}
+
+// ExportCatalogs writes an export of the catalog of every bucket to the configured bucket and prefix,
+// like an S3 Inventory report. It is run by the scheduler.
+func (s *App) ExportCatalogs(ctx context.Context) {
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.log.Warn("Skipping catalog export - database unavailable")
+		return
+	}
+	format := s.cfg.Export.Format
+	if !slices.Contains(export.Formats, format) {
+		s.log.Error("Skipping catalog export", slog.String("error", fmt.Sprintf("%s: %s", ErrInvalidExportFormat, format)))
+		return
+	}
+	destination := s.cfg.ExportBucket()
+
+	buckets, err := s.dbsvc.GetBuckets(ctx)
+	if err != nil {
+		s.log.Error("Failed to list buckets for the catalog export", slog.String("error", err.Error()))
+		return
+	}
+	for _, bucket := range buckets {
+		if ctx.Err() != nil {
+			return
+		}
+		filter := dto.CatalogFilter{Bucket: bucket.Name, Exclude: s.cfg.ExportPrefix(bucket.Name)}
+		if bucket.Name == s.cfg.S3.Bucket {
+			filter.Prefix = s.cfg.S3.Prefix
+		}
+		start := time.Now()
+		key := fmt.Sprintf("%s%s/%s.%s", s.cfg.Export.Prefix, bucket.Name, start.UTC().Format("20060102T150405Z"), format)
+		count, err := s.uploadExport(ctx, filter, format, destination, key)
+		if err != nil {
+			s.log.Error("Failed to export catalog",
+				slog.String("bucket", bucket.Name),
+				slog.String("error", err.Error()))
+			continue
+		}
+		s.log.Info("Catalog exported",
+			slog.String("bucket", bucket.Name),
+			slog.String("destination", destination+"/"+key),
+			slog.Int64("files", count),
+			slog.Duration("duration", time.Since(start)))
+	}
+}
+
+// uploadExport streams the export of filter to key of bucket, without holding the export in memory
+// or on disk. A failed export aborts the upload, so no partial file is left behind.
+func (s *App) uploadExport(ctx context.Context, filter dto.CatalogFilter, format, bucket, key string) (int64, error) {
+	pr, pw := io.Pipe()
+	exported := make(chan int64, 1)
+	go func() {
+		count, err := export.Catalog(ctx, s.dbsvc, filter, format, pw)
+		pw.CloseWithError(err)
+		exported <- count
+	}()
+
+	_, err := s.s3svc.ForBucket(bucket).StreamUpload(ctx, key, pr, export.ContentType(format),
+		s.cfg.Upload.PartSize, s.cfg.Upload.Concurrency)
+	// Unblock the export when the upload stopped reading
+	_ = pr.Close()
+	count := <-exported
+	if err != nil {
+		return count, fmt.Errorf("failed to upload export: %w", err)
+	}
+	return count, nil
+}
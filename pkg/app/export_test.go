@@ -0,0 +1,59 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportHandler_WithoutDatabase(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.ExportHandler(rec, httptest.NewRequest(http.MethodGet, "/export?format=parquet", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestExportFromValues(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+
+	filter, format, err := app.exportFromValues(t.Context(), url.Values{"search": {"report"}})
+	require.NoError(t, err)
+	assert.Equal(t, dto.CatalogFilter{Bucket: "bucket", Search: "report"}, filter)
+	assert.Equal(t, "csv", format)
+
+	_, format, err = app.exportFromValues(t.Context(), url.Values{"format": {"parquet"}})
+	require.NoError(t, err)
+	assert.Equal(t, "parquet", format)
+
+	_, _, err = app.exportFromValues(t.Context(), url.Values{"format": {"xlsx"}})
+	require.ErrorIs(t, err, ErrInvalidExportFormat)
+
+	_, _, err = app.exportFromValues(t.Context(), url.Values{"search": {"tag:team=data"}})
+	require.ErrorIs(t, err, ErrExportTagFilters)
+
+	// The scheduled exports written to the bucket are left out
+	app.cfg.Export = config.ExportConfig{Enable: true, Prefix: "s3xplorer-exports/"}
+	filter, _, err = app.exportFromValues(t.Context(), url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, "s3xplorer-exports/", filter.Exclude)
+
+	// Without the database no other bucket is known
+	_, _, err = app.exportFromValues(t.Context(), url.Values{"bucket": {"dr-data"}})
+	require.ErrorIs(t, err, ErrBucketNotAccessible)
+
+	// The browsed bucket is exported under the configured prefix
+	app.cfg.S3.Prefix = "data/"
+	filter, _, err = app.exportFromValues(t.Context(), url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, "data/", filter.Prefix)
+	_, _, err = app.exportFromValues(t.Context(), url.Values{"prefix": {"logs/"}})
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
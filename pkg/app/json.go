@@ -0,0 +1,26 @@
+package app
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// jsonError is the body of JSON API error responses.
+type jsonError struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func (s *App) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.log.Error("Failed to encode JSON response", slog.String("error", err.Error()))
+	}
+}
+
+// writeJSONError writes an error message as a JSON response.
+func (s *App) writeJSONError(w http.ResponseWriter, status int, message string) {
+	s.writeJSON(w, status, jsonError{Error: message})
+}
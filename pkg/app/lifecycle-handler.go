@@ -0,0 +1,344 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dbsvc"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+const (
+	// lifecycleSimulationDays is the window of the upcoming counts of a lifecycle simulation.
+	lifecycleSimulationDays = 7
+	// lifecycleTransitionRows is the number of transitions offered by the lifecycle form.
+	lifecycleTransitionRows = 3
+	// minTransitionSize is the size under which S3 does not transition objects, unless a rule asks for it.
+	minTransitionSize = 128 * 1024
+	day               = 24 * time.Hour
+)
+
+var (
+	// ErrLifecycleEditDisabled is returned when lifecycle editing is not enabled.
+	ErrLifecycleEditDisabled = errors.New("lifecycle editing is disabled")
+	// ErrLifecycleRuleNotFound is returned for a lifecycle rule the bucket does not have.
+	ErrLifecycleRuleNotFound = errors.New("lifecycle rule not found")
+)
+
+// LifecycleHandler lists the lifecycle rules of the bucket.
+func (s *App) LifecycleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableLifecycleEdit {
+		s.renderErrorPage(ctx, w, ErrLifecycleEditDisabled.Error())
+		return
+	}
+	rules, err := s.s3svc.GetLifecycleRules(ctx)
+	if err != nil {
+		s.log.Error("Failed to read lifecycle rules", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to read the lifecycle rules")
+		return
+	}
+
+	converted := make([]dto.LifecycleRule, 0, len(rules))
+	for _, rule := range rules {
+		converted = append(converted, s3svc.LifecycleRuleFromS3(rule))
+	}
+	if err := views.RenderLifecycleRules(converted, s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render lifecycle rules", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// LifecycleRuleFormHandler shows the form to create a lifecycle rule, or to edit the rule ?id=.
+func (s *App) LifecycleRuleFormHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableLifecycleEdit {
+		s.renderErrorPage(ctx, w, ErrLifecycleEditDisabled.Error())
+		return
+	}
+
+	rule := dto.LifecycleRule{Enabled: true, Prefix: s.cfg.S3.Prefix, Editable: true}
+	id := r.URL.Query().Get("id")
+	if id != "" {
+		rules, err := s.s3svc.GetLifecycleRules(ctx)
+		if err != nil {
+			s.log.Error("Failed to read lifecycle rules", slog.String("error", err.Error()))
+			s.renderErrorPage(ctx, w, "Failed to read the lifecycle rules")
+			return
+		}
+		i := slices.IndexFunc(rules, func(r types.LifecycleRule) bool { return aws.ToString(r.ID) == id })
+		if i < 0 {
+			s.renderErrorPage(ctx, w, fmt.Sprintf("%s: %s", ErrLifecycleRuleNotFound, id))
+			return
+		}
+		rule = s3svc.LifecycleRuleFromS3(rules[i])
+	}
+	s.renderLifecycleRuleForm(ctx, w, rule, id, "")
+}
+
+// LifecycleRuleHandler simulates a rule posted from the lifecycle form against the catalog and asks for
+// confirmation. Once confirmed, the rule is saved to the lifecycle configuration of the bucket.
+func (s *App) LifecycleRuleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableLifecycleEdit {
+		s.renderErrorPage(ctx, w, ErrLifecycleEditDisabled.Error())
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.renderErrorPage(ctx, w, "Invalid request")
+		return
+	}
+
+	originalID := r.PostFormValue("original_id")
+	rule, err := parseLifecycleRuleForm(r.PostForm)
+	if err == nil && !s.validateKeyPrefix(rule.Prefix) {
+		err = fmt.Errorf("%w: the prefix must start with '%s'", s3svc.ErrInvalidLifecycleRule, s.cfg.S3.Prefix)
+	}
+	if err == nil {
+		err = s3svc.ValidateLifecycleRule(rule)
+	}
+	var rules []types.LifecycleRule
+	if err == nil {
+		if rules, err = s.s3svc.GetLifecycleRules(ctx); err == nil {
+			rules, err = replaceLifecycleRule(rules, originalID, rule)
+		}
+	}
+	if err != nil || r.PostFormValue("edit") != "" {
+		message := ""
+		if err != nil {
+			s.log.Warn("Lifecycle rule rejected", slog.String("id", rule.ID), slog.String("error", err.Error()))
+			message = err.Error()
+		}
+		s.renderLifecycleRuleForm(ctx, w, rule, originalID, message)
+		return
+	}
+
+	if r.PostFormValue("confirm") == "" {
+		simulation := s.simulateLifecycleRule(ctx, s.cfg.S3.Bucket, rule, time.Now())
+		if err := views.RenderLifecycleConfirm(rule, originalID, simulation, s.cfg).Render(ctx, w); err != nil {
+			s.log.Error("Failed to render lifecycle confirmation", slog.String("error", err.Error()))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := s.s3svc.PutLifecycleRules(ctx, rules); err != nil {
+		s.log.Error("Failed to save lifecycle rule", slog.String("id", rule.ID), slog.String("error", err.Error()))
+		s.renderLifecycleRuleForm(ctx, w, rule, originalID, "Failed to save the lifecycle rule: "+err.Error())
+		return
+	}
+	s.log.Info("Lifecycle rule saved",
+		slog.String("bucket", s.cfg.S3.Bucket),
+		slog.String("id", rule.ID),
+		slog.String("user", s.requestUser(r)))
+	http.Redirect(w, r, "/lifecycle", http.StatusSeeOther)
+}
+
+// DeleteLifecycleRuleHandler removes a rule from the lifecycle configuration of the bucket.
+func (s *App) DeleteLifecycleRuleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableLifecycleEdit {
+		s.renderErrorPage(ctx, w, ErrLifecycleEditDisabled.Error())
+		return
+	}
+
+	id := r.PostFormValue("id")
+	rules, err := s.s3svc.GetLifecycleRules(ctx)
+	if err != nil {
+		s.log.Error("Failed to read lifecycle rules", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to read the lifecycle rules")
+		return
+	}
+	kept := slices.DeleteFunc(rules, func(r types.LifecycleRule) bool { return aws.ToString(r.ID) == id })
+	if len(kept) == len(rules) {
+		s.renderErrorPage(ctx, w, fmt.Sprintf("%s: %s", ErrLifecycleRuleNotFound, id))
+		return
+	}
+	if err := s.s3svc.PutLifecycleRules(ctx, kept); err != nil {
+		s.log.Error("Failed to delete lifecycle rule", slog.String("id", id), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to delete the lifecycle rule")
+		return
+	}
+	s.log.Info("Lifecycle rule deleted",
+		slog.String("bucket", s.cfg.S3.Bucket),
+		slog.String("id", id),
+		slog.String("user", s.requestUser(r)))
+	http.Redirect(w, r, "/lifecycle", http.StatusSeeOther)
+}
+
+func (s *App) renderLifecycleRuleForm(ctx context.Context, w http.ResponseWriter, rule dto.LifecycleRule, originalID, message string) {
+	classes := make([]string, 0, len(s3svc.LifecycleTransitionClasses))
+	for _, c := range s3svc.LifecycleTransitionClasses {
+		classes = append(classes, string(c))
+	}
+	transitions := rule.Transitions
+	for len(transitions) < lifecycleTransitionRows {
+		transitions = append(transitions, dto.LifecycleTransition{})
+	}
+	form := views.RenderLifecycleRuleForm(rule, transitions, originalID, classes, message, s.cfg)
+	if err := form.Render(ctx, w); err != nil {
+		s.log.Error("Failed to render lifecycle form", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// parseLifecycleRuleForm reads a rule of the lifecycle form. Transition rows without a storage class are ignored.
+func parseLifecycleRuleForm(form url.Values) (dto.LifecycleRule, error) {
+	rule := dto.LifecycleRule{
+		ID:       strings.TrimSpace(form.Get("id")),
+		Enabled:  form.Get("enabled") != "",
+		Prefix:   form.Get("prefix"),
+		Editable: true,
+	}
+	var err error
+	days := func(name, value string) int32 {
+		if value = strings.TrimSpace(value); value == "" || err != nil {
+			return 0
+		}
+		n, parseErr := strconv.ParseInt(value, 10, 32)
+		if parseErr != nil {
+			err = fmt.Errorf("%w: %s must be a number of days", s3svc.ErrInvalidLifecycleRule, name)
+		}
+		return int32(n)
+	}
+
+	classes, transitionDays := form["transition_class"], form["transition_days"]
+	for i, class := range classes {
+		if class == "" {
+			continue
+		}
+		value := ""
+		if i < len(transitionDays) {
+			value = transitionDays[i]
+		}
+		rule.Transitions = append(rule.Transitions, dto.LifecycleTransition{
+			Days:         days("the transition to "+class, value),
+			StorageClass: class,
+		})
+	}
+	rule.ExpirationDays = days("the expiration", form.Get("expiration_days"))
+	rule.NoncurrentExpirationDays = days("the noncurrent version expiration", form.Get("noncurrent_days"))
+	rule.AbortIncompleteUploadDays = days("the abort of incomplete uploads", form.Get("abort_days"))
+	return rule, err
+}
+
+// replaceLifecycleRule returns rules with the rule originalID replaced by rule, or with rule added when
+// originalID is empty. Rules the form cannot edit are not replaced.
+func replaceLifecycleRule(rules []types.LifecycleRule, originalID string, rule dto.LifecycleRule) ([]types.LifecycleRule, error) {
+	index := len(rules)
+	if originalID != "" {
+		index = slices.IndexFunc(rules, func(r types.LifecycleRule) bool { return aws.ToString(r.ID) == originalID })
+		if index < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrLifecycleRuleNotFound, originalID)
+		}
+		if !s3svc.LifecycleRuleFromS3(rules[index]).Editable {
+			return nil, fmt.Errorf("%w: %s uses settings the form cannot edit", s3svc.ErrInvalidLifecycleRule, originalID)
+		}
+	}
+	for i, r := range rules {
+		if i != index && aws.ToString(r.ID) == rule.ID {
+			return nil, fmt.Errorf("%w: another rule is called %s", s3svc.ErrInvalidLifecycleRule, rule.ID)
+		}
+	}
+
+	replaced := slices.Clone(rules)
+	if index == len(rules) {
+		return append(replaced, s3svc.LifecycleRuleToS3(rule)), nil
+	}
+	replaced[index] = s3svc.LifecycleRuleToS3(rule)
+	return replaced, nil
+}
+
+// simulateLifecycleRule counts the files of the catalog each action of rule applies to now, and within
+// the next lifecycleSimulationDays. Each file is counted once, by the latest action its age reaches.
+func (s *App) simulateLifecycleRule(ctx context.Context, bucket string, rule dto.LifecycleRule, now time.Time) dto.LifecycleSimulation {
+	simulation := dto.LifecycleSimulation{WindowDays: lifecycleSimulationDays}
+	if !rule.Enabled {
+		simulation.Notes = append(simulation.Notes, "The rule is disabled: it does nothing until it is enabled. "+
+			"The counts below are what it would do once enabled.")
+	}
+	if rule.NoncurrentExpirationDays > 0 {
+		simulation.Notes = append(simulation.Notes, fmt.Sprintf("The catalog only tracks current versions: "+
+			"the expiration of noncurrent versions after %d days is not simulated.", rule.NoncurrentExpirationDays))
+	}
+	if rule.AbortIncompleteUploadDays > 0 {
+		simulation.Notes = append(simulation.Notes, fmt.Sprintf("Incomplete multipart uploads are not in the catalog: "+
+			"aborting them after %d days is not simulated.", rule.AbortIncompleteUploadDays))
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() || !s.catalogsBucket(ctx, config.DefaultConnection, bucket) {
+		simulation.Notes = append(simulation.Notes, "The bucket is not in the catalog: the impact of the rule was not simulated.")
+		return simulation
+	}
+
+	ageBefore := func(days int32) time.Time { return now.Add(-time.Duration(days) * day) }
+	window := time.Duration(lifecycleSimulationDays) * day
+	// A file reaching a later action is counted by that action only
+	limits := make([]int32, 0, len(rule.Transitions)+1)
+	for _, t := range rule.Transitions {
+		limits = append(limits, t.Days)
+	}
+	if rule.ExpirationDays > 0 {
+		limits = append(limits, rule.ExpirationDays)
+	}
+
+	scopes := make([]dbsvc.LifecycleScope, 0, len(limits))
+	actions := make([]string, 0, len(limits))
+	for i, days := range limits {
+		scope := dbsvc.LifecycleScope{
+			Bucket:         bucket,
+			Prefix:         rule.Prefix,
+			DueBefore:      ageBefore(days),
+			UpcomingBefore: ageBefore(days).Add(window),
+		}
+		if i+1 < len(limits) {
+			scope.NotBefore = ageBefore(limits[i+1])
+		}
+		action := "Expire"
+		if i < len(rule.Transitions) {
+			class := rule.Transitions[i].StorageClass
+			action = "Transition to " + class
+			scope.MinSize = minTransitionSize
+			scope.StorageClasses = transitionSourceClasses(class)
+		}
+		scopes = append(scopes, scope)
+		actions = append(actions, action)
+	}
+
+	for i, scope := range scopes {
+		impact, err := s.dbsvc.SimulateLifecycleAction(ctx, scope)
+		if err != nil {
+			s.log.Error("Failed to simulate lifecycle action", slog.String("action", actions[i]), slog.String("error", err.Error()))
+			simulation.Notes = append(simulation.Notes, fmt.Sprintf("%s could not be simulated.", actions[i]))
+			continue
+		}
+		impact.Action, impact.Days = actions[i], limits[i]
+		simulation.Impacts = append(simulation.Impacts, impact)
+	}
+	return simulation
+}
+
+// transitionSourceClasses returns the storage classes a lifecycle transition to target moves objects
+// from: the more expensive ones.
+func transitionSourceClasses(target string) []string {
+	sources := []string{string(types.StorageClassReducedRedundancy)}
+	for _, c := range s3svc.TransitionStorageClasses {
+		if string(c) == target {
+			break
+		}
+		sources = append(sources, string(c))
+	}
+	return sources
+}
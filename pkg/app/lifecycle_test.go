@@ -0,0 +1,124 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLifecycleRuleForm(t *testing.T) {
+	rule, err := parseLifecycleRuleForm(url.Values{
+		"id":               {" logs "},
+		"enabled":          {"1"},
+		"prefix":           {"logs/"},
+		"transition_class": {"STANDARD_IA", "", "GLACIER"},
+		"transition_days":  {"30", "45", "90"},
+		"expiration_days":  {"365"},
+		"noncurrent_days":  {""},
+		"abort_days":       {"7"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, dto.LifecycleRule{
+		ID:      "logs",
+		Enabled: true,
+		Prefix:  "logs/",
+		Transitions: []dto.LifecycleTransition{
+			{Days: 30, StorageClass: "STANDARD_IA"},
+			{Days: 90, StorageClass: "GLACIER"},
+		},
+		ExpirationDays:            365,
+		AbortIncompleteUploadDays: 7,
+		Editable:                  true,
+	}, rule)
+
+	_, err = parseLifecycleRuleForm(url.Values{"id": {"logs"}, "expiration_days": {"a year"}})
+	require.ErrorIs(t, err, s3svc.ErrInvalidLifecycleRule)
+}
+
+func TestReplaceLifecycleRule(t *testing.T) {
+	tagged := types.LifecycleRule{
+		ID:         aws.String("tagged"),
+		Status:     types.ExpirationStatusEnabled,
+		Filter:     &types.LifecycleRuleFilter{Tag: &types.Tag{Key: aws.String("team"), Value: aws.String("data")}},
+		Expiration: &types.LifecycleExpiration{Days: aws.Int32(30)},
+	}
+	logs := s3svc.LifecycleRuleToS3(dto.LifecycleRule{ID: "logs", Enabled: true, ExpirationDays: 30})
+	rules := []types.LifecycleRule{tagged, logs}
+
+	added, err := replaceLifecycleRule(rules, "", dto.LifecycleRule{ID: "tmp", ExpirationDays: 1})
+	require.NoError(t, err)
+	require.Len(t, added, 3)
+	assert.Equal(t, "tmp", aws.ToString(added[2].ID))
+
+	renamed, err := replaceLifecycleRule(rules, "logs", dto.LifecycleRule{ID: "old-logs", ExpirationDays: 90})
+	require.NoError(t, err)
+	require.Len(t, renamed, 2)
+	assert.Equal(t, tagged, renamed[0], "other rules are kept as they are")
+	assert.Equal(t, "old-logs", aws.ToString(renamed[1].ID))
+	assert.Equal(t, "logs", aws.ToString(rules[1].ID), "the rules read from S3 are not modified")
+
+	_, err = replaceLifecycleRule(rules, "", dto.LifecycleRule{ID: "logs", ExpirationDays: 1})
+	require.ErrorIs(t, err, s3svc.ErrInvalidLifecycleRule)
+	_, err = replaceLifecycleRule(rules, "tagged", dto.LifecycleRule{ID: "tagged", ExpirationDays: 1})
+	require.ErrorIs(t, err, s3svc.ErrInvalidLifecycleRule)
+	_, err = replaceLifecycleRule(rules, "missing", dto.LifecycleRule{ID: "missing", ExpirationDays: 1})
+	require.ErrorIs(t, err, ErrLifecycleRuleNotFound)
+}
+
+func TestTransitionSourceClasses(t *testing.T) {
+	assert.Equal(t, []string{"REDUCED_REDUNDANCY", "STANDARD", "INTELLIGENT_TIERING", "STANDARD_IA", "ONEZONE_IA"},
+		transitionSourceClasses("GLACIER_IR"))
+}
+
+func TestSimulateLifecycleRule_WithoutDatabase(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+
+	simulation := app.simulateLifecycleRule(t.Context(), "bucket", dto.LifecycleRule{
+		ID:                        "logs",
+		ExpirationDays:            30,
+		NoncurrentExpirationDays:  7,
+		AbortIncompleteUploadDays: 2,
+	}, time.Now())
+
+	assert.Equal(t, lifecycleSimulationDays, simulation.WindowDays)
+	assert.Empty(t, simulation.Impacts)
+	require.Len(t, simulation.Notes, 4)
+	assert.Contains(t, simulation.Notes[0], "disabled")
+	assert.Contains(t, simulation.Notes[3], "not in the catalog")
+}
+
+func TestLifecycleHandlers_Disabled(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.LifecycleRuleHandler(rec, organizeRequest("/lifecycle/rule", url.Values{
+		"id": {"logs"}, "expiration_days": {"1"}, "confirm": {"1"},
+	}))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrLifecycleEditDisabled.Error())
+}
+
+func TestLifecycleRuleHandler_RejectsInvalidRule(t *testing.T) {
+	app, _ := newRestoreTestApp(t)
+	app.cfg.S3.EnableLifecycleEdit = true
+
+	rec := httptest.NewRecorder()
+	app.LifecycleRuleHandler(rec, organizeRequest("/lifecycle/rule", url.Values{
+		"id": {"logs"}, "transition_class": {"STANDARD_IA"}, "transition_days": {"10"}, "confirm": {"1"},
+	}))
+
+	// The form is shown again with the reason, before S3 is called
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "at least 30 days old")
+}
@@ -0,0 +1,416 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/gorilla/mux"
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dbsvc"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+const (
+	// folderContentType is the content type of the zero-byte "prefix/" folder markers
+	folderContentType = "application/x-directory"
+	// jobProgressInterval is how often a running job saves its counters
+	jobProgressInterval = time.Second
+)
+
+var (
+	// ErrOrganizeDisabled is returned when creating, renaming or moving needs upload and delete enabled.
+	ErrOrganizeDisabled = errors.New("creating, renaming and moving requires uploads and deletes to be enabled")
+	// ErrInvalidName is returned for an empty name, "." or "..", or a name containing "/".
+	ErrInvalidName = errors.New("invalid name")
+	// ErrDestinationExists is returned when a rename or move would overwrite existing data.
+	ErrDestinationExists = errors.New("destination already exists")
+	// ErrMoveIntoItself is returned when moving a folder inside itself.
+	ErrMoveIntoItself = errors.New("cannot move a folder inside itself")
+	// ErrMoveRoot is returned when renaming or moving the bucket root or the configured prefix.
+	ErrMoveRoot = errors.New("cannot rename or move the root folder")
+	// ErrMoveIncomplete is returned when some objects of a folder could not be moved.
+	ErrMoveIncomplete = errors.New("some objects could not be moved")
+	// ErrDatabaseUnavailable is returned when a feature needs the database and it is down.
+	ErrDatabaseUnavailable = errors.New("database is unavailable")
+)
+
+// CreateFolderHandler creates a zero-byte "prefix/" folder marker in the current folder.
+func (s *App) CreateFolderHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableUpload {
+		s.renderErrorPage(ctx, w, "Upload functionality is disabled")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.renderErrorPage(ctx, w, "Invalid request")
+		return
+	}
+
+	folder := s.getValidatedFolder(r.PostFormValue("folder"))
+	name, err := validateObjectName(r.PostFormValue("name"))
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+	key := folder + name + "/"
+
+	if err := s.s3svc.UploadObject(ctx, key, strings.NewReader(""), folderContentType, 0); err != nil {
+		s.log.Error("Failed to create folder", slog.String("key", key), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, fmt.Sprintf("Failed to create folder: %v", err))
+		return
+	}
+	s.log.Info("Folder created", slog.String("key", key))
+
+	if s.dbsvc != nil {
+		if err := s.dbsvc.SyncUploadedObject(ctx, s.cfg.S3.Bucket, key, 0, "", ""); err != nil {
+			s.log.Error("Failed to sync folder to database", slog.String("key", key), slog.String("error", err.Error()))
+		}
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/?folder=%s&page=1", url.QueryEscape(folder)), http.StatusSeeOther)
+}
+
+// RenameFormHandler shows the form to rename an object or a folder.
+func (s *App) RenameFormHandler(w http.ResponseWriter, r *http.Request) {
+	s.renderOrganizeForm(w, r, views.RenderRenameForm)
+}
+
+// MoveFormHandler shows the form to move an object or a folder to another folder.
+func (s *App) MoveFormHandler(w http.ResponseWriter, r *http.Request) {
+	s.renderOrganizeForm(w, r, views.RenderMoveForm)
+}
+
+// renderOrganizeForm validates the key of a rename or move form and renders it.
+func (s *App) renderOrganizeForm(
+	w http.ResponseWriter, r *http.Request, form func(key, folder string, cfg config.Config) templ.Component,
+) {
+	ctx := r.Context()
+	if !s.organizeEnabled() {
+		s.renderErrorPage(ctx, w, ErrOrganizeDisabled.Error())
+		return
+	}
+
+	key, err := s.extractAndValidateKey(r)
+	if err == nil {
+		err = s.validateMoveSource(key)
+	}
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	if err := form(key, parentFolder(key), s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render form", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// RenameHandler renames an object or a folder inside its parent folder.
+func (s *App) RenameHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := r.ParseForm(); err != nil {
+		s.renderErrorPage(ctx, w, "Invalid request")
+		return
+	}
+
+	src := r.PostFormValue("key")
+	name, err := validateObjectName(r.PostFormValue("name"))
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	dst := parentFolder(src) + name
+	if strings.HasSuffix(src, "/") {
+		dst += "/"
+	}
+	s.relocate(w, r, src, dst)
+}
+
+// MoveHandler moves an object or a folder into another folder, keeping its name.
+func (s *App) MoveHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := r.ParseForm(); err != nil {
+		s.renderErrorPage(ctx, w, "Invalid request")
+		return
+	}
+
+	src := r.PostFormValue("key")
+	destination := strings.Trim(r.PostFormValue("destination"), "/")
+	if destination != "" {
+		destination += "/"
+	}
+
+	dst := destination + path.Base(src)
+	if strings.HasSuffix(src, "/") {
+		dst += "/"
+	}
+	s.relocate(w, r, src, dst)
+}
+
+// relocate moves src to dst. Objects are moved right away; folders are moved
+// by a background job whose progress page the browser is redirected to.
+func (s *App) relocate(w http.ResponseWriter, r *http.Request, src, dst string) {
+	ctx := r.Context()
+	if !s.organizeEnabled() {
+		s.renderErrorPage(ctx, w, ErrOrganizeDisabled.Error())
+		return
+	}
+
+	if err := s.validateMove(ctx, src, dst); err != nil {
+		s.log.Warn("Move rejected", slog.String("src", src), slog.String("dst", dst), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	if !strings.HasSuffix(src, "/") {
+		if err := s.moveSingleObject(ctx, src, dst); err != nil {
+			s.renderErrorPage(ctx, w, err.Error())
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/?folder=%s&page=1", url.QueryEscape(extractFolder(dst))), http.StatusSeeOther)
+		return
+	}
+
+	// Folder moves can touch any number of objects: they run as a job
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+	job, err := s.dbsvc.CreateJob(ctx, dto.Job{Kind: dto.JobKindMove, Bucket: s.cfg.S3.Bucket, Source: src, Destination: dst})
+	if err != nil {
+		s.log.Error("Failed to create move job", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to start the move")
+		return
+	}
+	s.log.Info("Folder move started", slog.Int("job", int(job.ID)), slog.String("src", src), slog.String("dst", dst))
+
+	go s.runMoveJob(s.backgroundContext(), *job)
+	http.Redirect(w, r, fmt.Sprintf("/jobs/%d", job.ID), http.StatusSeeOther)
+}
+
+// validateMove checks that src can be moved to dst without leaving the prefix or overwriting data.
+func (s *App) validateMove(ctx context.Context, src, dst string) error {
+	if err := s.validateMoveSource(src); err != nil {
+		return err
+	}
+	if !s.validateKeyPrefix(dst) {
+		return fmt.Errorf("%w: does not have required prefix '%s'", ErrInvalidKey, s.cfg.S3.Prefix)
+	}
+	if src == dst {
+		return ErrDestinationExists
+	}
+	if strings.HasSuffix(src, "/") && strings.HasPrefix(dst, src) {
+		return ErrMoveIntoItself
+	}
+
+	exists, err := s.s3svc.ObjectExists(ctx, dst)
+	if err != nil {
+		return fmt.Errorf("cannot check destination: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", ErrDestinationExists, dst)
+	}
+	return nil
+}
+
+// validateMoveSource checks that key can be renamed or moved.
+func (s *App) validateMoveSource(key string) error {
+	if key == "" {
+		return ErrMissingKeyParam
+	}
+	if !s.validateKeyPrefix(key) {
+		return fmt.Errorf("%w: does not have required prefix '%s'", ErrInvalidKey, s.cfg.S3.Prefix)
+	}
+	if key == "/" || key == s.cfg.S3.Prefix {
+		return ErrMoveRoot
+	}
+	return nil
+}
+
+// moveSingleObject moves one object and updates the catalog.
+func (s *App) moveSingleObject(ctx context.Context, src, dst string) error {
+	info, err := s.s3svc.StatObject(ctx, src)
+	if err != nil {
+		return fmt.Errorf("cannot move %s: %w", src, err)
+	}
+	if err := s.s3svc.MoveObject(ctx, src, dst, info.Size); err != nil {
+		s.log.Error("Failed to move object", slog.String("src", src), slog.String("error", err.Error()))
+		return fmt.Errorf("move failed: %w", err)
+	}
+	s.log.Info("Object moved", slog.String("src", src), slog.String("dst", dst))
+
+	s.syncMovedTree(ctx, s.cfg.S3.Bucket, src, dst)
+	return nil
+}
+
+// syncMovedTree updates the catalog after src was moved to dst, creating the destination's
+// parent folders. Errors are only logged: the next scan fixes the catalog.
+func (s *App) syncMovedTree(ctx context.Context, bucket, src, dst string) {
+	if s.dbsvc == nil {
+		return
+	}
+	if err := s.dbsvc.SyncMovedObject(ctx, bucket, src, dst); err != nil {
+		s.log.Error("Failed to sync move to database", slog.String("src", src), slog.String("error", err.Error()))
+		return
+	}
+	if err := s.dbsvc.SyncUploadedFolders(ctx, bucket, s.cfg.S3.Prefix, dst); err != nil {
+		s.log.Error("Failed to sync destination folders", slog.String("dst", dst), slog.String("error", err.Error()))
+	}
+}
+
+// runMoveJob moves every object under job.Source to job.Destination and records the outcome.
+func (s *App) runMoveJob(ctx context.Context, job dto.Job) {
+	err := s.moveFolder(ctx, job)
+	if err != nil {
+		s.log.Error("Folder move failed", slog.Int("job", int(job.ID)), slog.String("error", err.Error()))
+	} else {
+		s.log.Info("Folder move completed", slog.Int("job", int(job.ID)))
+	}
+
+	// Record the outcome even if the job was cancelled by a shutdown
+	if finishErr := s.dbsvc.FinishJob(context.WithoutCancel(ctx), job.ID, err); finishErr != nil {
+		s.log.Error("Failed to finish job", slog.Int("job", int(job.ID)), slog.String("error", finishErr.Error()))
+	}
+}
+
+// moveFolder copies then deletes each object of the folder, saving progress as it goes.
+// When every object moved, the catalog subtree is rewritten in one transaction;
+// otherwise only the moved objects are.
+func (s *App) moveFolder(ctx context.Context, job dto.Job) error {
+	svc := s.s3svc.ForBucket(job.Bucket)
+
+	objects, err := svc.ListAllObjects(ctx, job.Source)
+	if err != nil {
+		return fmt.Errorf("cannot list %s: %w", job.Source, err)
+	}
+	var totalBytes int64
+	for _, obj := range objects {
+		totalBytes += obj.Size
+	}
+	if err := s.dbsvc.StartJob(ctx, job.ID, len(objects), totalBytes); err != nil {
+		return err
+	}
+
+	var progress dto.JobProgress
+	moved := make([]string, 0, len(objects))
+	lastSaved := time.Now()
+	for _, obj := range objects {
+		if ctx.Err() != nil {
+			break
+		}
+		dst := job.Destination + strings.TrimPrefix(obj.Key, job.Source)
+		if err := svc.MoveObject(ctx, obj.Key, dst, obj.Size); err != nil {
+			s.log.Error("Failed to move object", slog.String("key", obj.Key), slog.String("error", err.Error()))
+			progress.FailedItems++
+		} else {
+			moved = append(moved, obj.Key)
+			progress.DoneItems++
+			progress.DoneBytes += obj.Size
+		}
+
+		if time.Since(lastSaved) >= jobProgressInterval {
+			lastSaved = time.Now()
+			if err := s.dbsvc.UpdateJobProgress(ctx, job.ID, progress); err != nil {
+				s.log.Warn("Failed to save job progress", slog.String("error", err.Error()))
+			}
+		}
+	}
+	if err := s.dbsvc.UpdateJobProgress(context.WithoutCancel(ctx), job.ID, progress); err != nil {
+		s.log.Warn("Failed to save job progress", slog.String("error", err.Error()))
+	}
+
+	syncCtx := context.WithoutCancel(ctx)
+	if len(moved) == len(objects) {
+		s.syncMovedTree(syncCtx, job.Bucket, job.Source, job.Destination)
+		return ctx.Err()
+	}
+	for _, key := range moved {
+		s.syncMovedTree(syncCtx, job.Bucket, key, job.Destination+strings.TrimPrefix(key, job.Source))
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("move interrupted after %d of %d objects: %w", len(moved), len(objects), err)
+	}
+	return fmt.Errorf("%w: %d of %d", ErrMoveIncomplete, len(objects)-len(moved), len(objects))
+}
+
+// JobHandler shows the progress page of a background job.
+func (s *App) JobHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	job, err := s.loadJob(r)
+	if err != nil {
+		s.renderJobError(w, r, err)
+		return
+	}
+	if err := views.RenderJob(*job, s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render job", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// JobStatusHandler returns a background job as JSON, polled by the progress page.
+func (s *App) JobStatusHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := s.loadJob(r)
+	switch {
+	case errors.Is(err, dbsvc.ErrJobNotFound):
+		s.writeJSONError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		s.writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		s.writeJSON(w, http.StatusOK, job)
+	}
+}
+
+// loadJob returns the job named by the {id} route variable.
+func (s *App) loadJob(r *http.Request) (*dto.Job, error) {
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		return nil, ErrDatabaseUnavailable
+	}
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		return nil, dbsvc.ErrJobNotFound
+	}
+	job, err := s.dbsvc.GetJob(r.Context(), int32(id))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load job: %w", err)
+	}
+	return job, nil
+}
+
+// renderJobError renders the page matching a loadJob error.
+func (s *App) renderJobError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrDatabaseUnavailable) {
+		s.renderDatabaseUnavailablePage(r.Context(), w)
+		return
+	}
+	s.renderErrorPage(r.Context(), w, err.Error())
+}
+
+// organizeEnabled reports whether renames and moves are allowed: they write and delete objects.
+func (s *App) organizeEnabled() bool {
+	return s.cfg.S3.EnableUpload && s.cfg.S3.EnableDelete
+}
+
+// validateObjectName checks a single path segment typed by the user.
+func validateObjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return name, nil
+}
+
+// parentFolder returns the folder containing key, which may itself be a folder.
+func parentFolder(key string) string {
+	return extractFolder(strings.TrimSuffix(key, "/"))
+}
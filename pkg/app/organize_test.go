@@ -0,0 +1,200 @@
+package app
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOrganizeS3 is an in-memory bucket answering ListObjectsV2, HeadObject, CopyObject,
+// DeleteObject and DeleteObjects, plus ListObjectVersions with a canned answer.
+type fakeOrganizeS3 struct {
+	mu          sync.Mutex
+	objects     map[string]string // key -> content
+	copySources []string
+	// deleteFailures is the number of DeleteObjects calls each key still fails
+	deleteFailures map[string]int
+	// versionsXML is the body returned to ListObjectVersions
+	versionsXML string
+}
+
+func (f *fakeOrganizeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		f.list(w, r.URL.Query().Get("prefix"))
+	case r.Method == http.MethodGet && r.URL.Query().Has("versions"):
+		fmt.Fprint(w, f.versionsXML)
+	case r.Method == http.MethodPost && r.URL.Query().Has("delete"):
+		f.deleteObjects(w, r)
+	case r.Method == http.MethodHead:
+		content, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		source := r.Header.Get("X-Amz-Copy-Source")
+		f.copySources = append(f.copySources, source)
+		srcKey, err := url.PathUnescape(strings.TrimPrefix(strings.SplitN(source, "?", 2)[0], "bucket/"))
+		content, ok := f.objects[srcKey]
+		if err != nil || !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.objects[key] = content
+		fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+	case r.Method == http.MethodPut:
+		f.objects[key] = ""
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeOrganizeS3) list(w http.ResponseWriter, prefix string) {
+	keys := []string{}
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(`<ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated>`)
+	for _, key := range keys {
+		fmt.Fprintf(&b, `<Contents><Key>%s</Key><Size>%d</Size><ETag>"etag"</ETag></Contents>`, key, len(f.objects[key]))
+	}
+	fmt.Fprintf(&b, `<KeyCount>%d</KeyCount></ListBucketResult>`, len(keys))
+	fmt.Fprint(w, b.String())
+}
+
+func (f *fakeOrganizeS3) deleteObjects(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Objects []struct {
+			Key string `xml:"Key"`
+		} `xml:"Object"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(`<DeleteResult>`)
+	for _, obj := range body.Objects {
+		if f.deleteFailures[obj.Key] > 0 {
+			f.deleteFailures[obj.Key]--
+			fmt.Fprintf(&b, `<Error><Key>%s</Key><Code>SlowDown</Code><Message>retry</Message></Error>`, obj.Key)
+			continue
+		}
+		delete(f.objects, obj.Key)
+		fmt.Fprintf(&b, `<Deleted><Key>%s</Key></Deleted>`, obj.Key)
+	}
+	b.WriteString(`</DeleteResult>`)
+	fmt.Fprint(w, b.String())
+}
+
+func newOrganizeTestApp(t *testing.T, objects map[string]string) (*App, *fakeOrganizeS3) {
+	t.Helper()
+
+	fake := &fakeOrganizeS3{objects: objects}
+	cfg := config.Config{S3: config.S3Config{Bucket: "bucket", EnableUpload: true, EnableDelete: true}}
+	return newFakeS3App(t, fake, cfg), fake
+}
+
+func organizeRequest(target string, form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestMoveHandler_MovesObject(t *testing.T) {
+	app, fake := newOrganizeTestApp(t, map[string]string{"docs/a+b c.txt": "hello"})
+
+	rec := httptest.NewRecorder()
+	app.MoveHandler(rec, organizeRequest("/move", url.Values{"key": {"docs/a+b c.txt"}, "destination": {"archive/2024"}}))
+
+	require.Equal(t, http.StatusSeeOther, rec.Code, rec.Body.String())
+	assert.Equal(t, "/?folder=archive%2F2024%2F&page=1", rec.Header().Get("Location"))
+	assert.Equal(t, map[string]string{"archive/2024/a+b c.txt": "hello"}, fake.objects)
+	// '+' must be escaped in the copy source, S3 decodes it as a space
+	assert.Equal(t, []string{"bucket/docs/a%2Bb%20c.txt"}, fake.copySources)
+}
+
+func TestRenameHandler_RefusesExistingDestination(t *testing.T) {
+	app, fake := newOrganizeTestApp(t, map[string]string{"a.txt": "a", "b.txt": "b"})
+
+	rec := httptest.NewRecorder()
+	app.RenameHandler(rec, organizeRequest("/rename", url.Values{"key": {"a.txt"}, "name": {"b.txt"}}))
+
+	assert.Contains(t, rec.Body.String(), ErrDestinationExists.Error())
+	assert.Equal(t, map[string]string{"a.txt": "a", "b.txt": "b"}, fake.objects)
+}
+
+func TestMoveHandler_FolderIntoItself(t *testing.T) {
+	app, fake := newOrganizeTestApp(t, map[string]string{"photos/": "", "photos/a.jpg": "a"})
+
+	rec := httptest.NewRecorder()
+	app.MoveHandler(rec, organizeRequest("/move", url.Values{"key": {"photos/"}, "destination": {"photos/old"}}))
+
+	assert.Contains(t, rec.Body.String(), ErrMoveIntoItself.Error())
+	assert.Len(t, fake.objects, 2)
+}
+
+func TestRenameHandler_OutsidePrefix(t *testing.T) {
+	app, fake := newOrganizeTestApp(t, map[string]string{"private/a.txt": "a"})
+	app.cfg.S3.Prefix = "public/"
+
+	rec := httptest.NewRecorder()
+	app.RenameHandler(rec, organizeRequest("/rename", url.Values{"key": {"private/a.txt"}, "name": {"b.txt"}}))
+
+	assert.Contains(t, rec.Body.String(), ErrInvalidKey.Error())
+	assert.Contains(t, fake.objects, "private/a.txt")
+}
+
+func TestCreateFolderHandler(t *testing.T) {
+	app, fake := newOrganizeTestApp(t, map[string]string{})
+
+	rec := httptest.NewRecorder()
+	app.CreateFolderHandler(rec, organizeRequest("/folders", url.Values{"folder": {"docs/"}, "name": {" reports "}}))
+
+	require.Equal(t, http.StatusSeeOther, rec.Code, rec.Body.String())
+	assert.Contains(t, fake.objects, "docs/reports/")
+}
+
+func TestValidateObjectName(t *testing.T) {
+	for _, name := range []string{"", "  ", ".", "..", "a/b"} {
+		_, err := validateObjectName(name)
+		assert.ErrorIs(t, err, ErrInvalidName, "name %q", name)
+	}
+	got, err := validateObjectName(" report (1).pdf ")
+	require.NoError(t, err)
+	assert.Equal(t, "report (1).pdf", got)
+}
+
+func TestParentFolder(t *testing.T) {
+	assert.Equal(t, "", parentFolder("a.txt"))
+	assert.Equal(t, "", parentFolder("photos/"))
+	assert.Equal(t, "photos/", parentFolder("photos/2024/"))
+	assert.Equal(t, "photos/2024/", parentFolder("photos/2024/a.jpg"))
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+
+	"github.com/sgaunet/s3xplorer/pkg/dto"
 )
 
 var (
@@ -13,6 +15,15 @@ var (
 
 	// ErrInvalidPageValue is returned when the page parameter is less than 1.
 	ErrInvalidPageValue = errors.New("invalid page parameter: must be >= 1")
+
+	// ErrInvalidSortField is returned when the sort parameter is not a supported field.
+	ErrInvalidSortField = errors.New("invalid sort parameter: must be one of name, size, date, storage")
+
+	// ErrInvalidSortOrder is returned when the order parameter is not asc or desc.
+	ErrInvalidSortOrder = errors.New("invalid order parameter: must be asc or desc")
+
+	// ErrMissingCursor is returned when a page past the first has no after or before cursor.
+	ErrMissingCursor = errors.New("missing cursor: pages past the first are reached from a neighbouring page")
 )
 
 // ParsePaginationParams extracts and validates the page number from HTTP request query parameters.
@@ -73,3 +84,66 @@ func ValidatePageNumber(page, maxPages int) int {
 	}
 	return page
 }
+
+// ParseSortParams extracts and validates the sort field and order from HTTP request query parameters.
+//
+// Behavior:
+//   - Missing sort: Returns the default sort (name, asc)
+//   - Missing order: Uses the field's natural order (desc for size and date, asc otherwise)
+//   - Unknown sort or order: Returns the default sort and an error
+func ParseSortParams(r *http.Request) (dto.SortOptions, error) {
+	query := r.URL.Query()
+	field := dto.SortField(query.Get("sort"))
+	order := dto.SortOrder(query.Get("order"))
+
+	if field == "" {
+		field = dto.SortByName
+	}
+	if !dto.ValidSortField(field) {
+		return dto.DefaultSort(), fmt.Errorf("%w: %q", ErrInvalidSortField, field)
+	}
+
+	if order == "" {
+		order = dto.DefaultOrderFor(field)
+	}
+	if !dto.ValidSortOrder(order) {
+		return dto.DefaultSort(), fmt.Errorf("%w: %q", ErrInvalidSortOrder, order)
+	}
+
+	return dto.SortOptions{Field: field, Order: order}, nil
+}
+
+// ParseCursorParams extracts the cursor of the requested listing page from the after or before
+// query parameter, set by the Next and Previous links.
+//
+// Behavior:
+//   - Neither parameter: Returns the zero cursor (first page), or an error when page is past the first
+//   - Invalid cursor: Returns the zero cursor and an error
+func ParseCursorParams(r *http.Request, page int) (dto.PageCursor, error) {
+	after, err := cursorParam(r, "after")
+	if err != nil {
+		return dto.PageCursor{}, err
+	}
+	before, err := cursorParam(r, "before")
+	if err != nil {
+		return dto.PageCursor{}, err
+	}
+
+	if page > 1 && after == nil && before == nil {
+		return dto.PageCursor{}, ErrMissingCursor
+	}
+	return dto.PageCursor{After: after, Before: before}, nil
+}
+
+// cursorParam decodes the listing cursor of a query parameter, nil when it is absent.
+func cursorParam(r *http.Request, name string) (*dto.ListingCursor, error) {
+	token := r.URL.Query().Get(name)
+	if token == "" {
+		return nil, nil //nolint:nilnil // An absent cursor is not an error
+	}
+	cursor, err := dto.DecodeListingCursor(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %w", name, err)
+	}
+	return &cursor, nil
+}
@@ -1,9 +1,12 @@
 package app
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/dto"
 )
 
 func TestParsePaginationParams(t *testing.T) {
@@ -239,3 +242,71 @@ func TestValidatePageNumber_BoundaryConditions(t *testing.T) {
 		t.Errorf("ValidatePageNumber(0, 10) = %d, want 1", got)
 	}
 }
+
+func TestParseSortParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		queryURL string
+		want     dto.SortOptions
+		wantErr  error
+	}{
+		{"Missing parameters", "/", dto.DefaultSort(), nil},
+		{"Name ascending", "/?sort=name&order=asc", dto.SortOptions{Field: dto.SortByName, Order: dto.SortAsc}, nil},
+		{"Size without order defaults to desc", "/?sort=size", dto.SortOptions{Field: dto.SortBySize, Order: dto.SortDesc}, nil},
+		{"Date ascending", "/?sort=date&order=asc", dto.SortOptions{Field: dto.SortByDate, Order: dto.SortAsc}, nil},
+		{"Storage descending", "/?sort=storage&order=desc", dto.SortOptions{Field: dto.SortByStorage, Order: dto.SortDesc}, nil},
+		{"Order without sort applies to name", "/?order=desc", dto.SortOptions{Field: dto.SortByName, Order: dto.SortDesc}, nil},
+		{"Unknown field", "/?sort=etag", dto.DefaultSort(), ErrInvalidSortField},
+		{"SQL in field", "/?sort=key%20DESC", dto.DefaultSort(), ErrInvalidSortField},
+		{"Unknown order", "/?sort=size&order=up", dto.DefaultSort(), ErrInvalidSortOrder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.queryURL, nil)
+			got, err := ParseSortParams(req)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseSortParams() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSortParams() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCursorParams(t *testing.T) {
+	token := dto.ListingCursor{Key: "logs/app.log", Size: 42}.Encode()
+	tests := []struct {
+		name       string
+		queryURL   string
+		page       int
+		wantAfter  bool
+		wantBefore bool
+		wantErr    error
+	}{
+		{"First page without cursor", "/", 1, false, false, nil},
+		{"Next page", "/?page=2&after=" + token, 2, true, false, nil},
+		{"Previous page", "/?page=3&before=" + token, 3, false, true, nil},
+		{"Page without cursor", "/?page=4", 4, false, false, ErrMissingCursor},
+		{"Invalid cursor", "/?page=2&after=garbage", 2, false, false, dto.ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.queryURL, nil)
+			got, err := ParseCursorParams(req, tt.page)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseCursorParams() error = %v, want %v", err, tt.wantErr)
+			}
+			if (got.After != nil) != tt.wantAfter || (got.Before != nil) != tt.wantBefore {
+				t.Errorf("ParseCursorParams() = %+v, want after %v, before %v", got, tt.wantAfter, tt.wantBefore)
+			}
+			if got.After != nil && got.After.Key != "logs/app.log" {
+				t.Errorf("ParseCursorParams() after key = %q, want %q", got.After.Key, "logs/app.log")
+			}
+		})
+	}
+}
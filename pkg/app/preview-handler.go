@@ -0,0 +1,238 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/sgaunet/s3xplorer/pkg/preview"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+const (
+	// csvPreviewRows is the number of CSV/TSV rows shown per preview page.
+	csvPreviewRows = 100
+	// csvHeaderProbeBytes is how much is read from the start of a CSV file to recover its header row.
+	csvHeaderProbeBytes = 64 * 1024
+)
+
+// ErrPreviewNotInline is returned when the raw preview endpoint is asked for a non image/PDF object.
+var ErrPreviewNotInline = errors.New("only images and PDFs can be displayed inline")
+
+// PreviewHandler renders an inline preview of an object.
+// Text-based formats only fetch the first Preview.MaxTextBytes bytes with a byte-range GET.
+func (s *App) PreviewHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	key, err := s.extractAndValidateKey(r)
+	if err != nil {
+		s.log.Error("PreviewHandler: key validation failed", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	info, err := s.s3svc.StatObject(ctx, key)
+	if err != nil {
+		s.log.Error("PreviewHandler: error getting object metadata", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to load object: "+err.Error())
+		return
+	}
+
+	result := preview.Result{
+		Key:         key,
+		Name:        path.Base(key),
+		Folder:      extractFolder(key),
+		Kind:        preview.DetectKind(key, info.ContentType),
+		Size:        info.Size,
+		ContentType: info.ContentType,
+	}
+
+	if err := s.buildPreview(ctx, r, info, &result); err != nil {
+		s.log.Error("PreviewHandler: error building preview", slog.String("key", key), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to preview object: "+err.Error())
+		return
+	}
+
+	if err := views.RenderPreview(result, s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render preview page", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error rendering preview", http.StatusInternalServerError)
+	}
+}
+
+// buildPreview fills result with the content to display for the object's preview kind.
+func (s *App) buildPreview(ctx context.Context, r *http.Request, info *s3svc.ObjectInfo, result *preview.Result) error {
+	switch {
+	case info.IsRestoring:
+		result.Notice = "This object is being restored from Glacier. Try again once the restore has completed."
+		return nil
+	case !info.IsDownloadable:
+		result.Notice = fmt.Sprintf("This object is archived in %s and must be restored before it can be previewed.",
+			info.StorageClass)
+		return nil
+	}
+
+	switch result.Kind {
+	case preview.KindImage, preview.KindPDF:
+		if info.Size > s.cfg.Preview.MaxInlineBytes {
+			result.Notice = fmt.Sprintf("This file is larger than the inline preview limit (%d bytes). Download it instead.",
+				s.cfg.Preview.MaxInlineBytes)
+		}
+		return nil
+	case preview.KindText, preview.KindJSON:
+		return s.buildTextPreview(ctx, info, result)
+	case preview.KindCSV:
+		return s.buildCSVPreview(ctx, r, info, result)
+	case preview.KindUnsupported:
+		result.Notice = "No preview is available for this file type."
+	}
+	return nil
+}
+
+// buildTextPreview highlights the first bytes of a text file; complete JSON documents become a collapsible tree.
+func (s *App) buildTextPreview(ctx context.Context, info *s3svc.ObjectInfo, result *preview.Result) error {
+	chunk, err := s.s3svc.GetObjectRange(ctx, info.Key, 0, s.cfg.Preview.MaxTextBytes, info.Size)
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+
+	if preview.LooksBinary(chunk.Data) {
+		result.Notice = "This file looks like binary data and cannot be previewed as text."
+		return nil
+	}
+
+	data := preview.TrimToValidUTF8(chunk.Data)
+	result.Truncated = chunk.Truncated
+	result.BytesShown = int64(len(data))
+
+	if result.Kind == preview.KindJSON && !chunk.Truncated {
+		tree, err := preview.ParseJSONTree(data)
+		if err == nil {
+			result.JSON = tree
+			return nil
+		}
+		s.log.Debug("JSON preview falling back to text", slog.String("key", info.Key), slog.String("error", err.Error()))
+	}
+
+	source := string(data)
+	if result.Kind == preview.KindJSON && !chunk.Truncated {
+		// Invalid JSON: still try to indent it for readability
+		if pretty, err := preview.PrettyJSON(data); err == nil {
+			source = pretty
+		}
+	}
+
+	highlighted, err := preview.Highlight(result.Name, source)
+	if err != nil {
+		return fmt.Errorf("failed to highlight source: %w", err)
+	}
+	result.HighlightedHTML = highlighted
+	return nil
+}
+
+// buildCSVPreview reads one page of rows starting at the byte offset given by the "offset" query parameter.
+func (s *App) buildCSVPreview(ctx context.Context, r *http.Request, info *s3svc.ObjectInfo, result *preview.Result) error {
+	offset, _ := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	firstRow, _ := strconv.ParseInt(r.URL.Query().Get("row"), 10, 64)
+	if offset < 0 || offset > info.Size {
+		offset = 0
+	}
+	if offset == 0 || firstRow < 1 {
+		firstRow = 1
+	}
+
+	delimiter := preview.Delimiter(info.Key)
+	chunk, err := s.s3svc.GetObjectRange(ctx, info.Key, offset, s.cfg.Preview.MaxTextBytes, info.Size)
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+
+	page := preview.ReadCSVPage(chunk.Data, delimiter, csvPreviewRows, offset == 0, chunk.Truncated)
+	if offset > 0 {
+		head, err := s.s3svc.GetObjectRange(ctx, info.Key, 0, min(csvHeaderProbeBytes, s.cfg.Preview.MaxTextBytes), info.Size)
+		if err != nil {
+			return fmt.Errorf("failed to read header row: %w", err)
+		}
+		page.Header = preview.ReadCSVHeader(head.Data, delimiter)
+	}
+
+	if len(page.Rows) == 0 && page.Consumed == 0 && chunk.Truncated {
+		result.Notice = fmt.Sprintf("A single row is larger than the preview limit (%d bytes). Download the file instead.",
+			s.cfg.Preview.MaxTextBytes)
+		return nil
+	}
+
+	result.CSVHeader = page.Header
+	result.CSVRows = page.Rows
+	result.CSVOffset = offset
+	result.CSVFirstRow = firstRow
+	result.CSVNextOffset = offset + page.Consumed
+	result.CSVHasNext = result.CSVNextOffset < info.Size && page.Consumed > 0
+	return nil
+}
+
+// PreviewRawHandler streams an image or PDF inline so the browser can display it.
+func (s *App) PreviewRawHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	key, err := s.extractAndValidateKey(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	info, err := s.s3svc.StatObject(ctx, key)
+	if err != nil {
+		s.log.Error("PreviewRawHandler: error getting object metadata", slog.String("error", err.Error()))
+		http.Error(w, "Object not found", http.StatusNotFound)
+		return
+	}
+
+	kind := preview.DetectKind(key, info.ContentType)
+	switch {
+	case kind != preview.KindImage && kind != preview.KindPDF:
+		http.Error(w, ErrPreviewNotInline.Error(), http.StatusUnsupportedMediaType)
+		return
+	case !info.IsDownloadable:
+		http.Error(w, "Object is archived and must be restored first", http.StatusConflict)
+		return
+	case info.Size > s.cfg.Preview.MaxInlineBytes:
+		http.Error(w, "Object exceeds the inline preview size limit", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	body, err := s.s3svc.OpenObject(ctx, key)
+	if err != nil {
+		s.log.Error("PreviewRawHandler: error opening object", slog.String("error", err.Error()))
+		http.Error(w, "Failed to read object", http.StatusBadGateway)
+		return
+	}
+	defer body.Close() //nolint:errcheck
+
+	w.Header().Set("Content-Type", preview.InlineContentType(key, info.ContentType))
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	w.Header().Set("Content-Disposition", contentDisposition("inline", path.Base(key)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if preview.Extension(key) == "svg" {
+		// SVG can carry scripts: never let it run when opened directly
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	}
+
+	if _, err := io.Copy(w, body); err != nil {
+		s.log.Error("PreviewRawHandler: error streaming object", slog.String("error", err.Error()))
+	}
+}
+
+// extractFolder returns the folder part of a key ("a/b/c.txt" -> "a/b/"), or "" at the root.
+func extractFolder(key string) string {
+	dir := path.Dir(key)
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	return dir + "/"
+}
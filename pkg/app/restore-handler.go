@@ -0,0 +1,379 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+const (
+	// maxListedRestores caps the Restores page.
+	maxListedRestores = 500
+	// restorePollBatch is the number of pending restores checked per poll.
+	restorePollBatch = 500
+)
+
+var (
+	// ErrRestoreDisabled is returned when Glacier restores are not enabled.
+	ErrRestoreDisabled = errors.New("glacier restore is disabled")
+	// ErrInvalidRestoreDays is returned when the number of days to keep a restored copy is not a positive integer.
+	ErrInvalidRestoreDays = errors.New("restore days must be a positive number")
+	// ErrRestoreIncomplete is returned when some objects of a folder could not be restored.
+	ErrRestoreIncomplete = errors.New("some objects could not be restored")
+)
+
+// restoreJobOptions are the options of a folder restore job, stored as JSON in the job row.
+type restoreJobOptions struct {
+	Tier        string `json:"tier"`
+	Days        int32  `json:"days"`
+	RequestedBy string `json:"requestedBy"`
+}
+
+// RestoreFormHandler shows the form to restore an archived object, or every archived object of a folder.
+func (s *App) RestoreFormHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableGlacierRestore {
+		s.renderErrorPage(ctx, w, ErrRestoreDisabled.Error())
+		return
+	}
+
+	key, err := s.extractAndValidateKey(r)
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	form := views.RenderRestoreForm(key, parentFolder(key), s.s3svc.DefaultRestoreDays(), s.cfg)
+	if err := form.Render(ctx, w); err != nil {
+		s.log.Error("Failed to render form", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// RestoreHandler requests the restore of an archived object and tracks it until the copy is available.
+// Folders are restored by a background job.
+func (s *App) RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableGlacierRestore {
+		s.renderErrorPage(ctx, w, ErrRestoreDisabled.Error())
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.renderErrorPage(ctx, w, "Invalid request")
+		return
+	}
+
+	key := r.PostFormValue("key")
+	opts, err := parseRestoreForm(r)
+	if err == nil {
+		err = s.validatePostedKey(key)
+	}
+	if err != nil {
+		s.log.Warn("Restore rejected", slog.String("key", key), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+	user := s.requestUser(r)
+
+	if strings.HasSuffix(key, "/") {
+		s.startRestoreJob(w, r, key, opts, user)
+		return
+	}
+
+	if err := s.s3svc.RestoreObject(ctx, key, opts); err != nil && !errors.Is(err, s3svc.ErrRestoreInProgress) {
+		s.log.Error("Failed to restore object", slog.String("key", key), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to restore the object")
+		return
+	}
+	s.log.Info("Restore requested",
+		slog.String("key", key),
+		slog.String("tier", string(opts.Tier)),
+		slog.Int("days", int(opts.Days)),
+		slog.String("user", user))
+
+	// Without the database the restore is not tracked: the listing shows its progress
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		http.Redirect(w, r, fmt.Sprintf("/?folder=%s&page=1", url.QueryEscape(parentFolder(key))), http.StatusSeeOther)
+		return
+	}
+	if err := s.trackRestore(ctx, s.s3svc.GetBucketName(), key, opts, user); err != nil {
+		s.log.Error("Failed to record restore request", slog.String("key", key), slog.String("error", err.Error()))
+	}
+	http.Redirect(w, r, "/restores", http.StatusSeeOther)
+}
+
+// parseRestoreForm returns the retrieval tier and number of days of the submitted form.
+func parseRestoreForm(r *http.Request) (s3svc.RestoreOptions, error) {
+	var opts s3svc.RestoreOptions
+	tier, err := s3svc.ParseRestoreTier(r.PostFormValue("tier"))
+	if err != nil {
+		return opts, err //nolint:wrapcheck // already wrapped
+	}
+	opts.Tier = tier
+
+	if raw := strings.TrimSpace(r.PostFormValue("days")); raw != "" {
+		days, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || days < 1 {
+			return opts, fmt.Errorf("%w: %q", ErrInvalidRestoreDays, raw)
+		}
+		opts.Days = int32(days) //nolint:gosec // parsed as 32 bits
+	}
+	return opts, nil
+}
+
+// trackRestore records a restore request, unless the object already has a pending one.
+func (s *App) trackRestore(ctx context.Context, bucket, key string, opts s3svc.RestoreOptions, user string) error {
+	pending, err := s.dbsvc.GetPendingRestoreRequest(ctx, bucket, key)
+	if err != nil {
+		return err //nolint:wrapcheck // already wrapped
+	}
+	if pending != nil {
+		return nil
+	}
+	if opts.Days <= 0 {
+		opts.Days = s.s3svc.DefaultRestoreDays()
+	}
+	_, err = s.dbsvc.CreateRestoreRequest(ctx, dto.RestoreRequest{
+		Bucket:      bucket,
+		Key:         key,
+		Tier:        string(opts.Tier),
+		Days:        opts.Days,
+		RequestedBy: user,
+	})
+	return err //nolint:wrapcheck // already wrapped
+}
+
+// startRestoreJob starts a background job restoring every archived object of a folder.
+func (s *App) startRestoreJob(w http.ResponseWriter, r *http.Request, key string, opts s3svc.RestoreOptions, user string) {
+	ctx := r.Context()
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	encoded, err := json.Marshal(restoreJobOptions{Tier: string(opts.Tier), Days: opts.Days, RequestedBy: user})
+	if err != nil {
+		s.log.Error("Failed to encode restore options", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to start the restore")
+		return
+	}
+	created, err := s.dbsvc.CreateJob(ctx, dto.Job{
+		Kind:    dto.JobKindRestore,
+		Bucket:  s.cfg.S3.Bucket,
+		Source:  key,
+		Options: string(encoded),
+	})
+	if err != nil {
+		s.log.Error("Failed to create restore job", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to start the restore")
+		return
+	}
+	s.log.Info("Folder restore started",
+		slog.Int("job", int(created.ID)),
+		slog.String("key", key),
+		slog.String("tier", string(opts.Tier)),
+		slog.String("user", user))
+
+	go s.runRestoreJob(s.backgroundContext(), *created)
+	http.Redirect(w, r, fmt.Sprintf("/jobs/%d", created.ID), http.StatusSeeOther)
+}
+
+// runRestoreJob restores the archived objects of a folder and records the outcome.
+func (s *App) runRestoreJob(ctx context.Context, job dto.Job) {
+	err := s.restoreFolder(ctx, job)
+	if err != nil {
+		s.log.Error("Folder restore failed", slog.Int("job", int(job.ID)), slog.String("error", err.Error()))
+	} else {
+		s.log.Info("Folder restore requested", slog.Int("job", int(job.ID)))
+	}
+
+	// Record the outcome even if the job was cancelled by a shutdown
+	if finishErr := s.dbsvc.FinishJob(context.WithoutCancel(ctx), job.ID, err); finishErr != nil {
+		s.log.Error("Failed to finish job", slog.Int("job", int(job.ID)), slog.String("error", finishErr.Error()))
+	}
+}
+
+// restoreFolder requests the restore of every archived object under the folder of job.
+// Objects already being restored are skipped; objects in other storage classes are ignored.
+func (s *App) restoreFolder(ctx context.Context, job dto.Job) error {
+	var opts restoreJobOptions
+	if err := json.Unmarshal([]byte(job.Options), &opts); err != nil {
+		return fmt.Errorf("invalid restore options: %w", err)
+	}
+	restoreOpts := s3svc.RestoreOptions{Tier: types.Tier(opts.Tier), Days: opts.Days}
+	svc := s.s3svc.ForBucket(job.Bucket)
+
+	listed, err := svc.ListAllObjects(ctx, job.Source)
+	if err != nil {
+		return fmt.Errorf("cannot list %s: %w", job.Source, err)
+	}
+	var archived []s3svc.ObjectInfo
+	for _, obj := range listed {
+		if s3svc.IsArchiveStorageClass(obj.StorageClass) {
+			archived = append(archived, obj)
+		}
+	}
+	// Restores transfer nothing: progress is counted in objects
+	if err := s.dbsvc.StartJob(ctx, job.ID, len(archived), 0); err != nil {
+		return err //nolint:wrapcheck // already wrapped
+	}
+
+	var progress dto.JobProgress
+	lastSaved := time.Now()
+	for _, obj := range archived {
+		if ctx.Err() != nil {
+			break
+		}
+		err := svc.RestoreObject(ctx, obj.Key, restoreOpts)
+		switch {
+		case errors.Is(err, s3svc.ErrRestoreInProgress):
+			progress.SkippedItems++
+		case err != nil:
+			s.log.Error("Failed to restore object", slog.String("key", obj.Key), slog.String("error", err.Error()))
+			progress.FailedItems++
+		default:
+			progress.DoneItems++
+			if err := s.trackRestore(context.WithoutCancel(ctx), job.Bucket, obj.Key, restoreOpts, opts.RequestedBy); err != nil {
+				s.log.Warn("Failed to record restore request", slog.String("key", obj.Key), slog.String("error", err.Error()))
+			}
+		}
+
+		if time.Since(lastSaved) >= jobProgressInterval {
+			lastSaved = time.Now()
+			if err := s.dbsvc.UpdateJobProgress(ctx, job.ID, progress); err != nil {
+				s.log.Warn("Failed to save job progress", slog.String("error", err.Error()))
+			}
+		}
+	}
+	if err := s.dbsvc.UpdateJobProgress(context.WithoutCancel(ctx), job.ID, progress); err != nil {
+		s.log.Warn("Failed to save job progress", slog.String("error", err.Error()))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("restore interrupted after %d of %d objects: %w", progress.DoneItems, len(archived), err)
+	}
+	if progress.FailedItems > 0 {
+		return fmt.Errorf("%w: %d of %d", ErrRestoreIncomplete, progress.FailedItems, len(archived))
+	}
+	return nil
+}
+
+// RestoresHandler lists the restore requests and their status.
+func (s *App) RestoresHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableGlacierRestore {
+		s.renderErrorPage(ctx, w, ErrRestoreDisabled.Error())
+		return
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	requests, err := s.dbsvc.ListRestoreRequests(ctx, maxListedRestores)
+	if err != nil {
+		s.log.Error("Failed to list restore requests", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to list the restores")
+		return
+	}
+
+	if err := views.RenderRestores(requests, time.Now(), s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render restores", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// RestoreNotificationsHandler returns the restores of the current user that completed since the
+// last call. Each completed restore is returned once.
+func (s *App) RestoreNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.S3.EnableGlacierRestore {
+		s.writeJSONError(w, http.StatusNotFound, ErrRestoreDisabled.Error())
+		return
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.writeJSONError(w, http.StatusServiceUnavailable, "database unavailable")
+		return
+	}
+
+	restored, err := s.dbsvc.TakeRestoreNotifications(r.Context(), s.requestUser(r))
+	if err != nil {
+		s.log.Error("Failed to load restore notifications", slog.String("error", err.Error()))
+		s.writeJSONError(w, http.StatusInternalServerError, "failed to load notifications")
+		return
+	}
+	if restored == nil {
+		restored = []dto.RestoreRequest{}
+	}
+	s.writeJSON(w, http.StatusOK, restored)
+}
+
+// PollRestores checks the pending restore requests with HeadObject and records the ones whose
+// copy is available, and marks the restored copies past their expiry as expired.
+// It is run by the scheduler.
+func (s *App) PollRestores(ctx context.Context) {
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.log.Warn("Skipping restore poll - database unavailable")
+		return
+	}
+
+	if n, err := s.dbsvc.ExpireRestoreRequests(ctx); err != nil {
+		s.log.Error("Failed to expire restore requests", slog.String("error", err.Error()))
+	} else if n > 0 {
+		s.log.Info("Restored copies expired", slog.Int64("count", n))
+	}
+
+	pending, err := s.dbsvc.ListPendingRestoreRequests(ctx, restorePollBatch)
+	if err != nil {
+		s.log.Error("Failed to list pending restore requests", slog.String("error", err.Error()))
+		return
+	}
+	restored := 0
+	for _, req := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+		status, expiresAt, message := s.checkRestore(ctx, req)
+		if status == dto.RestoreStatusRestored {
+			restored++
+		}
+		if err := s.dbsvc.UpdateRestoreRequest(ctx, req.ID, status, expiresAt, message); err != nil {
+			s.log.Error("Failed to update restore request", slog.Int("id", int(req.ID)), slog.String("error", err.Error()))
+		}
+	}
+	s.log.Debug("Restore poll completed", slog.Int("checked", len(pending)), slog.Int("restored", restored))
+}
+
+// checkRestore returns the status of a pending restore request, with the expiry of the restored
+// copy and an error message for failed requests.
+func (s *App) checkRestore(ctx context.Context, req dto.RestoreRequest) (string, *time.Time, string) {
+	info, err := s.s3svc.ForBucket(req.Bucket).StatObject(ctx, req.Key)
+	switch {
+	case errors.Is(err, s3svc.ErrObjectNotFound):
+		return dto.RestoreStatusFailed, nil, "the object no longer exists"
+	case err != nil:
+		// Transient errors keep the request pending until the next poll
+		s.log.Warn("Failed to check restore", slog.String("key", req.Key), slog.String("error", err.Error()))
+		return dto.RestoreStatusPending, nil, err.Error()
+	case info.IsRestoring:
+		return dto.RestoreStatusPending, nil, ""
+	case info.IsDownloadable && !info.RestoreExpiry.IsZero():
+		return dto.RestoreStatusRestored, &info.RestoreExpiry, ""
+	case info.IsDownloadable:
+		// The object left the archive classes, for instance after being copied over
+		return dto.RestoreStatusRestored, nil, ""
+	default:
+		return dto.RestoreStatusExpired, nil, ""
+	}
+}
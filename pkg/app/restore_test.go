@@ -0,0 +1,158 @@
+package app
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRestoreS3 answers RestoreObject, recording the request bodies, and HeadObject with
+// the configured x-amz-restore header.
+type fakeRestoreS3 struct {
+	mu       sync.Mutex
+	restores map[string]string // key -> RestoreRequest XML
+	// status is the HTTP status of RestoreObject, 0 for 202 Accepted
+	status int
+	// restoreHeader is the x-amz-restore header of HeadObject; missing keys answer 404
+	restoreHeader map[string]string
+	// heads counts the HeadObject requests
+	heads int
+}
+
+func (f *fakeRestoreS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := r.URL.Path[len("/bucket/"):]
+	switch {
+	case r.Method == http.MethodPost && r.URL.Query().Has("restore"):
+		body, _ := io.ReadAll(r.Body)
+		f.restores[key] = string(body)
+		if f.status == http.StatusConflict {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = io.WriteString(w, `<Error><Code>RestoreAlreadyInProgress</Code><Message>running</Message></Error>`)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodHead:
+		f.heads++
+		header, ok := f.restoreHeader[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-Amz-Storage-Class", "GLACIER")
+		if header != "" {
+			w.Header().Set("X-Amz-Restore", header)
+		}
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func newRestoreTestApp(t *testing.T) (*App, *fakeRestoreS3) {
+	t.Helper()
+
+	fake := &fakeRestoreS3{restores: map[string]string{}, restoreHeader: map[string]string{}}
+	cfg := config.Config{S3: config.S3Config{Bucket: "bucket", EnableGlacierRestore: true, RestoreDays: 3}}
+	return newFakeS3App(t, fake, cfg), fake
+}
+
+func TestRestoreHandler_RestoresObject(t *testing.T) {
+	app, fake := newRestoreTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.RestoreHandler(rec, organizeRequest("/restore", url.Values{"key": {"logs/2020.tar"}, "tier": {"Bulk"}, "days": {"7"}}))
+
+	require.Equal(t, http.StatusSeeOther, rec.Code, rec.Body.String())
+	// Without the database the restore is not tracked: back to the listing
+	assert.Equal(t, "/?folder=logs%2F&page=1", rec.Header().Get("Location"))
+	require.Contains(t, fake.restores, "logs/2020.tar")
+	assert.Contains(t, fake.restores["logs/2020.tar"], "<Days>7</Days>")
+	assert.Contains(t, fake.restores["logs/2020.tar"], "<Tier>Bulk</Tier>")
+}
+
+func TestRestoreHandler_AlreadyInProgress(t *testing.T) {
+	app, fake := newRestoreTestApp(t)
+	fake.status = http.StatusConflict
+
+	rec := httptest.NewRecorder()
+	app.RestoreHandler(rec, organizeRequest("/restore", url.Values{"key": {"a.tar"}}))
+
+	assert.Equal(t, http.StatusSeeOther, rec.Code, rec.Body.String())
+}
+
+func TestRestoreHandler_Rejected(t *testing.T) {
+	tests := []struct {
+		name string
+		form url.Values
+		want string
+	}{
+		{"unknown tier", url.Values{"key": {"a.tar"}, "tier": {"Instant"}}, s3svc.ErrInvalidRestoreTier.Error()},
+		{"zero days", url.Values{"key": {"a.tar"}, "days": {"0"}}, ErrInvalidRestoreDays.Error()},
+		{"missing key", url.Values{"tier": {"Bulk"}}, "is missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, fake := newRestoreTestApp(t)
+
+			rec := httptest.NewRecorder()
+			app.RestoreHandler(rec, organizeRequest("/restore", tt.form))
+
+			assert.Contains(t, rec.Body.String(), tt.want)
+			assert.Empty(t, fake.restores)
+		})
+	}
+}
+
+func TestRestoreHandler_Disabled(t *testing.T) {
+	app, fake := newRestoreTestApp(t)
+	app.cfg.S3.EnableGlacierRestore = false
+
+	rec := httptest.NewRecorder()
+	app.RestoreHandler(rec, organizeRequest("/restore", url.Values{"key": {"a.tar"}}))
+
+	assert.Contains(t, rec.Body.String(), ErrRestoreDisabled.Error())
+	assert.Empty(t, fake.restores)
+}
+
+func TestCheckRestore(t *testing.T) {
+	app, fake := newRestoreTestApp(t)
+	expiry := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	fake.restoreHeader = map[string]string{
+		"running.tar":  `ongoing-request="true"`,
+		"restored.tar": `ongoing-request="false", expiry-date="` + expiry.Format(http.TimeFormat) + `"`,
+		"expired.tar":  "",
+	}
+
+	tests := []struct {
+		key        string
+		wantStatus string
+	}{
+		{"running.tar", dto.RestoreStatusPending},
+		{"restored.tar", dto.RestoreStatusRestored},
+		{"expired.tar", dto.RestoreStatusExpired},
+		{"deleted.tar", dto.RestoreStatusFailed},
+	}
+	for _, tt := range tests {
+		status, expiresAt, _ := app.checkRestore(context.Background(), dto.RestoreRequest{Bucket: "bucket", Key: tt.key})
+		assert.Equal(t, tt.wantStatus, status, tt.key)
+		if tt.wantStatus == dto.RestoreStatusRestored {
+			require.NotNil(t, expiresAt)
+			assert.True(t, expiry.Equal(*expiresAt))
+		} else {
+			assert.Nil(t, expiresAt, tt.key)
+		}
+	}
+}
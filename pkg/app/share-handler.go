@@ -0,0 +1,325 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sgaunet/s3xplorer/pkg/dbsvc"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+const (
+	// shareTokenBytes is the amount of randomness in a share token (encoded to 32 characters)
+	shareTokenBytes = 24
+	// maxListedShares caps the "My shares" page
+	maxListedShares = 200
+	// anonymousUser identifies requests made without an authenticating proxy header
+	anonymousUser = "anonymous"
+)
+
+var (
+	// ErrSharingDisabled is returned when share links are not enabled.
+	ErrSharingDisabled = errors.New("sharing is disabled")
+	// ErrShareProxyDisabled is returned when a proxied share is requested but not enabled.
+	ErrShareProxyDisabled = errors.New("proxied share links are disabled")
+	// ErrInvalidShareExpiry is returned for a missing, invalid or too long share expiry.
+	ErrInvalidShareExpiry = errors.New("invalid share expiry")
+	// ErrShareNotDownloadable is returned when sharing an object that cannot be downloaded.
+	ErrShareNotDownloadable = errors.New("object is archived and cannot be shared until restored")
+)
+
+// ShareFormHandler shows the form to create a share link for an object.
+func (s *App) ShareFormHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.Share.Enable {
+		s.renderErrorPage(ctx, w, ErrSharingDisabled.Error())
+		return
+	}
+
+	key, err := s.extractAndValidateKey(r)
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	if err := views.RenderShareForm(key, extractFolder(key), s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render share form", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// CreateShareHandler creates a presigned or proxied share link and shows it.
+func (s *App) CreateShareHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.Share.Enable {
+		s.renderErrorPage(ctx, w, ErrSharingDisabled.Error())
+		return
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	share, err := s.newShareFromForm(r)
+	if err != nil {
+		s.log.Warn("Share request rejected", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	created, err := s.dbsvc.CreateShare(ctx, *share)
+	if err != nil {
+		s.log.Error("Failed to record share", slog.String("key", share.Key), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to create share link")
+		return
+	}
+
+	s.log.Info("Share created",
+		slog.String("key", created.Key),
+		slog.String("creator", created.Creator),
+		slog.Bool("proxied", created.Proxied),
+		slog.Time("expires_at", created.ExpiresAt))
+
+	if err := views.RenderShareCreated(*created, s.shareLink(r, *created), s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render share", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// newShareFromForm validates the share form and presigns the URL for presigned shares.
+func (s *App) newShareFromForm(r *http.Request) (*dto.Share, error) {
+	ctx := r.Context()
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("invalid share request: %w", err)
+	}
+
+	key := r.PostFormValue("key")
+	if key == "" {
+		return nil, ErrMissingKeyParam
+	}
+	if s.cfg.S3.Prefix != "" && !strings.HasPrefix(key, s.cfg.S3.Prefix) {
+		return nil, fmt.Errorf("%w: does not have required prefix '%s'", ErrInvalidKey, s.cfg.S3.Prefix)
+	}
+
+	expiry, err := time.ParseDuration(r.PostFormValue("expiry"))
+	if err != nil || expiry <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidShareExpiry, r.PostFormValue("expiry"))
+	}
+	if maxExpiry := s.cfg.Share.MaxExpiryDuration(); expiry > maxExpiry {
+		return nil, fmt.Errorf("%w: %s is longer than the maximum of %s", ErrInvalidShareExpiry, expiry, maxExpiry)
+	}
+
+	proxied := r.PostFormValue("proxied") == "on"
+	if proxied && !s.cfg.Share.EnableProxy {
+		return nil, ErrShareProxyDisabled
+	}
+
+	info, err := s.s3svc.StatObject(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot share %s: %w", key, err)
+	}
+	if !info.IsDownloadable {
+		return nil, ErrShareNotDownloadable
+	}
+
+	token, err := newRandomToken(shareTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	share := &dto.Share{
+		Token:     token,
+		Bucket:    s.cfg.S3.Bucket,
+		Key:       key,
+		Creator:   s.requestUser(r),
+		Proxied:   proxied,
+		ExpiresAt: time.Now().Add(expiry),
+	}
+	if !proxied {
+		share.URL, err = s.s3svc.PresignGetObject(ctx, key, expiry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign share link: %w", err)
+		}
+	}
+	return share, nil
+}
+
+// MySharesHandler lists the shares created by the current user.
+func (s *App) MySharesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.Share.Enable {
+		s.renderErrorPage(ctx, w, ErrSharingDisabled.Error())
+		return
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	creator := s.requestUser(r)
+	shares, err := s.dbsvc.ListShares(ctx, creator, maxListedShares)
+	if err != nil {
+		s.log.Error("Failed to list shares", slog.String("creator", creator), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to list shares")
+		return
+	}
+
+	links := make(map[int32]string, len(shares))
+	for _, share := range shares {
+		links[share.ID] = s.shareLink(r, share)
+	}
+
+	if err := views.RenderShares(shares, links, creator, time.Now(), s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render shares", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// RevokeShareHandler revokes one of the current user's shares.
+// Proxied links stop working immediately; presigned URLs stay valid at S3 until they expire.
+func (s *App) RevokeShareHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.Share.Enable {
+		s.renderErrorPage(ctx, w, ErrSharingDisabled.Error())
+		return
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PostFormValue("id"), 10, 32)
+	if err != nil {
+		s.renderErrorPage(ctx, w, "Invalid share id")
+		return
+	}
+
+	creator := s.requestUser(r)
+	if err := s.dbsvc.RevokeShare(ctx, int32(id), creator); err != nil {
+		s.log.Warn("Failed to revoke share", slog.Int64("id", id), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	s.log.Info("Share revoked", slog.Int64("id", id), slog.String("creator", creator))
+	http.Redirect(w, r, "/shares", http.StatusSeeOther)
+}
+
+// SharedObjectHandler serves a proxied share (/s/{token}) and counts its downloads.
+func (s *App) SharedObjectHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.Share.Enable || !s.cfg.Share.EnableProxy {
+		http.NotFound(w, r)
+		return
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	share, err := s.dbsvc.GetShareByToken(ctx, mux.Vars(r)["token"])
+	if err != nil || !share.Proxied {
+		if err != nil && !errors.Is(err, dbsvc.ErrShareNotFound) {
+			s.log.Error("Failed to look up share", slog.String("error", err.Error()))
+		}
+		http.NotFound(w, r)
+		return
+	}
+	if !share.Active(time.Now()) {
+		w.WriteHeader(http.StatusGone)
+		s.renderErrorPage(ctx, w, "This share link has "+share.Status(time.Now()))
+		return
+	}
+
+	// Count a download once, not for every resumed range or revalidation
+	if isFirstDownloadRequest(r) {
+		if err := s.dbsvc.RecordShareDownload(ctx, share.ID); err != nil {
+			s.log.Warn("Failed to record share download", slog.Int("id", int(share.ID)), slog.String("error", err.Error()))
+		}
+	}
+
+	sw := &startedWriter{ResponseWriter: w}
+	if err := s.downloadS3Object(ctx, sw, r, s.s3svc.ForBucket(share.Bucket), share.Key, ""); err != nil {
+		s.log.Error("Shared download failed", slog.String("key", share.Key), slog.String("error", err.Error()))
+		// Once the download has started, an error page would be appended to the file
+		if !sw.started {
+			s.renderErrorPage(ctx, w, "The shared file is no longer available")
+		}
+	}
+}
+
+// startedWriter records whether the response has started, after which its status cannot change.
+type startedWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *startedWriter) WriteHeader(statusCode int) {
+	w.started = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *startedWriter) Write(b []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the wrapped writer.
+func (w *startedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// isFirstDownloadRequest reports whether r starts a download rather than resuming or revalidating one.
+func isFirstDownloadRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+		return false
+	}
+	rng := r.Header.Get("Range")
+	return rng == "" || strings.HasPrefix(rng, "bytes=0-")
+}
+
+// requestUser identifies the user from the header set by the authenticating reverse proxy.
+func (s *App) requestUser(r *http.Request) string {
+	if user := strings.TrimSpace(r.Header.Get(s.cfg.Server.UserHeader)); user != "" {
+		return user
+	}
+	return anonymousUser
+}
+
+// shareLink returns the URL handed to recipients: the presigned URL, or the absolute /s/{token} link.
+func (s *App) shareLink(r *http.Request, share dto.Share) string {
+	if !share.Proxied {
+		return share.URL
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	host := r.Host
+	if fwd := r.Header.Get("X-Forwarded-Host"); fwd != "" {
+		host = fwd
+	}
+	return scheme + "://" + host + path.Join("/s", share.Token)
+}
+
+// newRandomToken returns an unguessable URL-safe token built from size random bytes.
+func newRandomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newShareTestApp(t *testing.T, share config.ShareConfig) *App {
+	t.Helper()
+	app := newDownloadTestApp(t)
+	app.cfg.Share = share
+	app.cfg.Server.UserHeader = "X-Forwarded-User"
+	return app
+}
+
+func shareFormRequest(values url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/share", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Forwarded-User", "alice")
+	return req
+}
+
+func TestNewShareFromForm_Presigned(t *testing.T) {
+	app := newShareTestApp(t, config.ShareConfig{Enable: true, MaxExpiry: "48h"})
+
+	before := time.Now()
+	share, err := app.newShareFromForm(shareFormRequest(url.Values{
+		"key":    {"dir/report v1 é.txt"},
+		"expiry": {"2h0m0s"},
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, "alice", share.Creator)
+	assert.Equal(t, "bucket", share.Bucket)
+	assert.False(t, share.Proxied)
+	assert.Len(t, share.Token, 32)
+	assert.WithinDuration(t, before.Add(2*time.Hour), share.ExpiresAt, time.Minute)
+	assert.Contains(t, share.URL, "X-Amz-Expires=7200")
+	assert.Contains(t, share.URL, "X-Amz-Signature=")
+}
+
+func TestNewShareFromForm_Validation(t *testing.T) {
+	app := newShareTestApp(t, config.ShareConfig{Enable: true, MaxExpiry: "24h"})
+
+	tests := []struct {
+		name    string
+		values  url.Values
+		wantErr error
+	}{
+		{"missing key", url.Values{"expiry": {"1h"}}, ErrMissingKeyParam},
+		{"invalid expiry", url.Values{"key": {"dir/report v1 é.txt"}, "expiry": {"tomorrow"}}, ErrInvalidShareExpiry},
+		{"expiry above max", url.Values{"key": {"dir/report v1 é.txt"}, "expiry": {"48h"}}, ErrInvalidShareExpiry},
+		{"proxy disabled", url.Values{"key": {"dir/report v1 é.txt"}, "expiry": {"1h"}, "proxied": {"on"}}, ErrShareProxyDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := app.newShareFromForm(shareFormRequest(tt.values))
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestShareLink(t *testing.T) {
+	app := &App{log: emptyLogger()}
+
+	req := httptest.NewRequest(http.MethodGet, "/shares", nil)
+	req.Host = "files.example.com"
+	assert.Equal(t, "https://s3.example/presigned", app.shareLink(req, dto.Share{URL: "https://s3.example/presigned"}))
+	assert.Equal(t, "http://files.example.com/s/tok", app.shareLink(req, dto.Share{Proxied: true, Token: "tok"}))
+
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "public.example.com")
+	assert.Equal(t, "https://public.example.com/s/tok", app.shareLink(req, dto.Share{Proxied: true, Token: "tok"}))
+}
+
+func TestRequestUser(t *testing.T) {
+	app := &App{cfg: config.Config{Server: config.ServerConfig{UserHeader: "X-Remote-User"}}}
+
+	req := httptest.NewRequest(http.MethodGet, "/shares", nil)
+	assert.Equal(t, anonymousUser, app.requestUser(req))
+
+	req.Header.Set("X-Remote-User", " bob ")
+	assert.Equal(t, "bob", app.requestUser(req))
+}
+
+func TestIsFirstDownloadRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want    bool
+	}{
+		{"plain GET", http.MethodGet, nil, true},
+		{"range from start", http.MethodGet, map[string]string{"Range": "bytes=0-1023"}, true},
+		{"resumed range", http.MethodGet, map[string]string{"Range": "bytes=1024-"}, false},
+		{"revalidation", http.MethodGet, map[string]string{"If-None-Match": `"abc"`}, false},
+		{"HEAD", http.MethodHead, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/s/token", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.want, isFirstDownloadRequest(req))
+		})
+	}
+}
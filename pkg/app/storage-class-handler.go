@@ -0,0 +1,340 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+const (
+	// minBillableIASize is the smallest size billed by the infrequent access and Glacier Instant Retrieval classes.
+	minBillableIASize = 128 * 1024
+	// archiveIndexOverhead is the index data billed with each object of Glacier Flexible Retrieval and Deep Archive.
+	archiveIndexOverhead = 40 * 1024
+	// bytesPerGB converts sizes to the GB of storage prices.
+	bytesPerGB = 1024 * 1024 * 1024
+)
+
+// ErrStorageClassIncomplete is returned when some objects could not be moved to the new storage class.
+var ErrStorageClassIncomplete = errors.New("some objects could not change storage class")
+
+// storageClassJobOptions are the options of a storage class job, stored as JSON in the job row.
+// Keys lists the selected objects of the Source folder; without keys the whole Source is changed.
+type storageClassJobOptions struct {
+	StorageClass string   `json:"storageClass"`
+	Keys         []string `json:"keys,omitempty"`
+}
+
+// StorageClassFormHandler shows the form to change the storage class of an object, a folder or the
+// files selected in a folder. Once a class is chosen it shows the estimate of the change.
+func (s *App) StorageClassFormHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableUpload {
+		s.renderErrorPage(ctx, w, "Upload functionality is disabled")
+		return
+	}
+
+	query := r.URL.Query()
+	source, keys := query.Get("key"), query["keys"]
+	if len(keys) > 0 {
+		source = query.Get("folder")
+	}
+	if err := s.validateStorageClassSource(source, keys); err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	var estimate *dto.StorageClassEstimate
+	target := query.Get("class")
+	if target != "" {
+		class, err := s3svc.ParseStorageClass(target)
+		if err != nil {
+			s.renderErrorPage(ctx, w, err.Error())
+			return
+		}
+		objects, err := s.storageClassObjects(ctx, s.s3svc, source, keys)
+		if err != nil {
+			s.log.Error("Failed to list objects", slog.String("key", source), slog.String("error", err.Error()))
+			s.renderErrorPage(ctx, w, "Failed to list the objects")
+			return
+		}
+		e := estimateStorageClassChange(objects, string(class), s.cfg.S3.StoragePrices)
+		estimate = &e
+	}
+
+	classes := make([]string, 0, len(s3svc.TransitionStorageClasses))
+	for _, c := range s3svc.TransitionStorageClasses {
+		classes = append(classes, string(c))
+	}
+	folder := source
+	if !strings.HasSuffix(source, "/") {
+		folder = parentFolder(source)
+	}
+	form := views.RenderStorageClassForm(source, keys, folder, classes, target, estimate, s.cfg)
+	if err := form.Render(ctx, w); err != nil {
+		s.log.Error("Failed to render form", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// StorageClassHandler starts a background job moving an object, a folder or selected files to another
+// storage class.
+func (s *App) StorageClassHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableUpload {
+		s.renderErrorPage(ctx, w, "Upload functionality is disabled")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.renderErrorPage(ctx, w, "Invalid request")
+		return
+	}
+
+	source, keys := r.PostFormValue("key"), r.PostForm["keys"]
+	class, err := s3svc.ParseStorageClass(r.PostFormValue("class"))
+	if err == nil {
+		err = s.validateStorageClassSource(source, keys)
+	}
+	if err != nil {
+		s.log.Warn("Storage class change rejected", slog.String("key", source), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+	encoded, err := json.Marshal(storageClassJobOptions{StorageClass: string(class), Keys: keys})
+	if err != nil {
+		s.log.Error("Failed to encode storage class options", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to start the storage class change")
+		return
+	}
+	created, err := s.dbsvc.CreateJob(ctx, dto.Job{
+		Kind:    dto.JobKindStorageClass,
+		Bucket:  s.cfg.S3.Bucket,
+		Source:  source,
+		Options: string(encoded),
+	})
+	if err != nil {
+		s.log.Error("Failed to create storage class job", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to start the storage class change")
+		return
+	}
+	s.log.Info("Storage class change started",
+		slog.Int("job", int(created.ID)),
+		slog.String("src", source),
+		slog.Int("keys", len(keys)),
+		slog.String("class", string(class)))
+
+	go s.runStorageClassJob(s.backgroundContext(), *created)
+	http.Redirect(w, r, fmt.Sprintf("/jobs/%d", created.ID), http.StatusSeeOther)
+}
+
+// validateStorageClassSource checks the object or folder of a storage class change, and the selected
+// keys which must be files of that folder.
+func (s *App) validateStorageClassSource(source string, keys []string) error {
+	if len(keys) == 0 {
+		return s.validatePostedKey(source)
+	}
+	if !s.validateKeyPrefix(source) {
+		return fmt.Errorf("%w: does not have required prefix '%s'", ErrInvalidKey, s.cfg.S3.Prefix)
+	}
+	for _, key := range keys {
+		if !strings.HasPrefix(key, source) || strings.HasSuffix(key, "/") {
+			return fmt.Errorf("%w: %s is not a file of %s", ErrInvalidKey, key, source)
+		}
+	}
+	return nil
+}
+
+// storageClassObjects returns the objects a storage class change applies to: the selected keys,
+// every file under a folder, or the object itself.
+func (s *App) storageClassObjects(
+	ctx context.Context, svc *s3svc.Service, source string, keys []string,
+) ([]s3svc.ObjectInfo, error) {
+	if len(keys) == 0 && strings.HasSuffix(source, "/") {
+		listed, err := svc.ListAllObjects(ctx, source)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list %s: %w", source, err)
+		}
+		// Folder markers are empty objects, not worth a copy
+		return slices.DeleteFunc(listed, func(o s3svc.ObjectInfo) bool { return strings.HasSuffix(o.Key, "/") }), nil
+	}
+	if len(keys) == 0 {
+		keys = []string{source}
+	}
+
+	objects := make([]s3svc.ObjectInfo, 0, len(keys))
+	for _, key := range keys {
+		info, err := svc.StatObject(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %w", key, err)
+		}
+		objects = append(objects, *info)
+	}
+	return objects, nil
+}
+
+// estimateStorageClassChange computes the bytes a storage class change copies and its effect on
+// the monthly storage cost. prices are per GB-month and storage class.
+func estimateStorageClassChange(objects []s3svc.ObjectInfo, target string, prices map[string]float64) dto.StorageClassEstimate {
+	estimate := dto.StorageClassEstimate{Target: target}
+	usage := map[string]*dto.StorageClassUsage{}
+	for _, obj := range objects {
+		class := storageClassOf(obj)
+		switch {
+		case class == target:
+			estimate.Unchanged++
+			continue
+		case s3svc.IsArchiveStorageClass(class):
+			estimate.Archived++
+			estimate.ArchivedBytes += obj.Size
+			continue
+		}
+
+		estimate.Objects++
+		estimate.Bytes += obj.Size
+		estimate.CurrentMonthlyCost += monthlyCost(class, obj.Size, prices)
+		estimate.TargetMonthlyCost += monthlyCost(target, obj.Size, prices)
+		if usage[class] == nil {
+			usage[class] = &dto.StorageClassUsage{Class: class}
+		}
+		usage[class].Objects++
+		usage[class].Bytes += obj.Size
+	}
+
+	for _, u := range usage {
+		estimate.Current = append(estimate.Current, *u)
+	}
+	slices.SortFunc(estimate.Current, func(a, b dto.StorageClassUsage) int { return strings.Compare(a.Class, b.Class) })
+	return estimate
+}
+
+// storageClassOf returns the storage class of an object; S3 omits it for STANDARD.
+func storageClassOf(obj s3svc.ObjectInfo) string {
+	if obj.StorageClass == "" {
+		return string(types.StorageClassStandard)
+	}
+	return obj.StorageClass
+}
+
+// monthlyCost returns the monthly storage price of an object of the given size and class.
+// Classes without a known price cost nothing.
+func monthlyCost(class string, size int64, prices map[string]float64) float64 {
+	return float64(billableBytes(class, size)) / bytesPerGB * prices[class]
+}
+
+// billableBytes returns the size S3 bills for an object: the infrequent access classes bill at
+// least 128 KB, and the archive classes add 40 KB of index data per object.
+func billableBytes(class string, size int64) int64 {
+	switch types.StorageClass(class) {
+	case types.StorageClassStandardIa, types.StorageClassOnezoneIa, types.StorageClassGlacierIr:
+		return max(size, minBillableIASize)
+	case types.StorageClassGlacier, types.StorageClassDeepArchive:
+		return size + archiveIndexOverhead
+	default:
+		return size
+	}
+}
+
+// runStorageClassJob changes the storage class of the objects of job and records the outcome.
+func (s *App) runStorageClassJob(ctx context.Context, job dto.Job) {
+	err := s.changeStorageClass(ctx, job)
+	if err != nil {
+		s.log.Error("Storage class change failed", slog.Int("job", int(job.ID)), slog.String("error", err.Error()))
+	} else {
+		s.log.Info("Storage class change completed", slog.Int("job", int(job.ID)))
+	}
+
+	// Record the outcome even if the job was cancelled by a shutdown
+	if finishErr := s.dbsvc.FinishJob(context.WithoutCancel(ctx), job.ID, err); finishErr != nil {
+		s.log.Error("Failed to finish job", slog.Int("job", int(job.ID)), slog.String("error", finishErr.Error()))
+	}
+}
+
+// changeStorageClass copies each object of job in place with the new storage class, saving progress
+// and updating the catalog as objects are copied. Objects already in the class, and archived objects
+// that are not restored, are skipped.
+func (s *App) changeStorageClass(ctx context.Context, job dto.Job) error {
+	var opts storageClassJobOptions
+	if err := json.Unmarshal([]byte(job.Options), &opts); err != nil {
+		return fmt.Errorf("invalid storage class options: %w", err)
+	}
+	class, err := s3svc.ParseStorageClass(opts.StorageClass)
+	if err != nil {
+		return err //nolint:wrapcheck // already wrapped
+	}
+	svc := s.s3svc.ForBucket(job.Bucket)
+
+	objects, err := s.storageClassObjects(ctx, svc, job.Source, opts.Keys)
+	if err != nil {
+		return err
+	}
+	pending := slices.DeleteFunc(slices.Clone(objects), func(o s3svc.ObjectInfo) bool {
+		return storageClassOf(o) == opts.StorageClass
+	})
+	var totalBytes int64
+	for _, obj := range pending {
+		totalBytes += obj.Size
+	}
+	if err := s.dbsvc.StartJob(ctx, job.ID, len(objects), totalBytes); err != nil {
+		return err //nolint:wrapcheck // already wrapped
+	}
+
+	progress := dto.JobProgress{SkippedItems: int32(len(objects) - len(pending))} //nolint:gosec // bounded by the object count
+	syncCatalog := s.catalogsBucket(ctx, config.DefaultConnection, job.Bucket)
+	lastSaved := time.Now()
+	for _, obj := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		etag, err := svc.ChangeStorageClass(ctx, obj.Key, class)
+		switch {
+		case errors.Is(err, s3svc.ErrObjectArchived):
+			progress.SkippedItems++
+		case err != nil:
+			s.log.Error("Failed to change storage class", slog.String("key", obj.Key), slog.String("error", err.Error()))
+			progress.FailedItems++
+		default:
+			progress.DoneItems++
+			progress.DoneBytes += obj.Size
+			if syncCatalog {
+				if err := s.dbsvc.SyncUploadedObject(context.WithoutCancel(ctx), job.Bucket, obj.Key, obj.Size, etag, opts.StorageClass); err != nil {
+					s.log.Warn("Failed to sync storage class to catalog", slog.String("key", obj.Key), slog.String("error", err.Error()))
+				}
+			}
+		}
+
+		if time.Since(lastSaved) >= jobProgressInterval {
+			lastSaved = time.Now()
+			if err := s.dbsvc.UpdateJobProgress(ctx, job.ID, progress); err != nil {
+				s.log.Warn("Failed to save job progress", slog.String("error", err.Error()))
+			}
+		}
+	}
+	if err := s.dbsvc.UpdateJobProgress(context.WithoutCancel(ctx), job.ID, progress); err != nil {
+		s.log.Warn("Failed to save job progress", slog.String("error", err.Error()))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("storage class change interrupted after %d of %d objects: %w", progress.DoneItems, len(pending), err)
+	}
+	if progress.FailedItems > 0 {
+		return fmt.Errorf("%w: %d of %d", ErrStorageClassIncomplete, progress.FailedItems, len(pending))
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateStorageClassChange(t *testing.T) {
+	prices := map[string]float64{"STANDARD": 0.02, "STANDARD_IA": 0.01, "GLACIER": 0.004}
+	objects := []s3svc.ObjectInfo{
+		{Key: "big.bin", Size: 2 * bytesPerGB},
+		{Key: "small.txt", Size: 1024},
+		{Key: "moved.bin", Size: bytesPerGB, StorageClass: "STANDARD_IA"},
+		{Key: "archive.tar", Size: 5 * bytesPerGB, StorageClass: "GLACIER"},
+	}
+
+	estimate := estimateStorageClassChange(objects, "STANDARD_IA", prices)
+
+	assert.Equal(t, 2, estimate.Objects)
+	assert.Equal(t, int64(2*bytesPerGB+1024), estimate.Bytes)
+	assert.Equal(t, 1, estimate.Unchanged)
+	assert.Equal(t, 1, estimate.Archived)
+	assert.Equal(t, int64(5*bytesPerGB), estimate.ArchivedBytes)
+	assert.Equal(t, []dto.StorageClassUsage{{Class: "STANDARD", Objects: 2, Bytes: 2*bytesPerGB + 1024}}, estimate.Current)
+
+	// small.txt is billed 128 KB once in STANDARD_IA
+	wantCurrent := float64(2*bytesPerGB+1024) / bytesPerGB * 0.02
+	wantTarget := float64(2*bytesPerGB+minBillableIASize) / bytesPerGB * 0.01
+	assert.InDelta(t, wantCurrent, estimate.CurrentMonthlyCost, 1e-9)
+	assert.InDelta(t, wantTarget, estimate.TargetMonthlyCost, 1e-9)
+	assert.Negative(t, estimate.MonthlyCostDelta())
+}
+
+func TestBillableBytes(t *testing.T) {
+	tests := []struct {
+		class string
+		size  int64
+		want  int64
+	}{
+		{"STANDARD", 10, 10},
+		{"ONEZONE_IA", 10, minBillableIASize},
+		{"GLACIER_IR", 2 * minBillableIASize, 2 * minBillableIASize},
+		{"DEEP_ARCHIVE", 10, 10 + archiveIndexOverhead},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, billableBytes(tt.class, tt.size), tt.class)
+	}
+}
+
+func TestValidateStorageClassSource(t *testing.T) {
+	app := &App{cfg: config.Config{S3: config.S3Config{Prefix: "data/"}}, log: emptyLogger()}
+
+	require.NoError(t, app.validateStorageClassSource("data/logs/", nil))
+	require.NoError(t, app.validateStorageClassSource("data/a.txt", nil))
+	require.NoError(t, app.validateStorageClassSource("data/logs/", []string{"data/logs/a.txt"}))
+
+	require.ErrorIs(t, app.validateStorageClassSource("other/a.txt", nil), ErrInvalidKey)
+	require.ErrorIs(t, app.validateStorageClassSource("data/logs/", []string{"data/other/a.txt"}), ErrInvalidKey)
+	require.ErrorIs(t, app.validateStorageClassSource("data/logs/", []string{"data/logs/sub/"}), ErrInvalidKey)
+}
+
+func TestStorageClassHandler_Rejected(t *testing.T) {
+	tests := []struct {
+		name string
+		form url.Values
+		want string
+	}{
+		{"unknown class", url.Values{"key": {"a.txt"}, "class": {"REDUCED_REDUNDANCY"}}, s3svc.ErrInvalidStorageClass.Error()},
+		{"missing key", url.Values{"class": {"STANDARD_IA"}}, "is missing"},
+		{"database unavailable", url.Values{"key": {"a.txt"}, "class": {"STANDARD_IA"}}, "Database"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{cfg: config.Config{S3: config.S3Config{EnableUpload: true}}, log: emptyLogger()}
+
+			rec := httptest.NewRecorder()
+			app.StorageClassHandler(rec, organizeRequest("/storage-class", tt.form))
+
+			assert.NotEqual(t, http.StatusSeeOther, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.want)
+		})
+	}
+}
+
+func TestStorageClassFormHandler_Estimate(t *testing.T) {
+	app, fake := newRestoreTestApp(t)
+	app.cfg.S3.EnableUpload = true
+	app.cfg.S3.StoragePrices = config.DefaultStoragePrices
+	fake.restoreHeader["a.tar"] = ""
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/storage-class?key=a.tar&class=DEEP_ARCHIVE", nil)
+	app.StorageClassFormHandler(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	// The fake answers a GLACIER object that is not restored
+	assert.Contains(t, body, "1 archived objects")
+	assert.Contains(t, body, "Move to DEEP_ARCHIVE")
+}
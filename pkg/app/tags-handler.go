@@ -0,0 +1,41 @@
+package app
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+// maxListedTagValues caps the Tags page.
+const maxListedTagValues = 500
+
+// ErrTagIndexDisabled is returned when tag indexing is not enabled.
+var ErrTagIndexDisabled = errors.New("tag indexing is disabled")
+
+// TagsHandler shows the number and size of the objects per indexed tag value.
+func (s *App) TagsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.Scan.IndexTags {
+		s.renderErrorPage(ctx, w, ErrTagIndexDisabled.Error())
+		return
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	key := r.URL.Query().Get("key")
+	summaries, indexed, total, err := s.dbsvc.SummarizeTags(ctx, s.cfg.S3.Bucket, key, maxListedTagValues)
+	if err != nil {
+		s.log.Error("Failed to summarize tags", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to list the tags")
+		return
+	}
+
+	if err := views.RenderTags(summaries, key, indexed, total, s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render tags", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,342 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+const (
+	// maxListedTrashItems caps the Trash page
+	maxListedTrashItems = 500
+	// trashPurgeBatch is the number of expired items the scheduled purge loads at once
+	trashPurgeBatch = 100
+)
+
+var (
+	// ErrTrashDisabled is returned when the trash is not enabled.
+	ErrTrashDisabled = errors.New("trash is disabled")
+	// ErrTrashUnavailable is returned when deleting while the trash is enabled but the database is down.
+	ErrTrashUnavailable = errors.New("the trash needs the database, which is unavailable: nothing was deleted")
+)
+
+// trashCopyOptions keeps everything about an object moved in or out of the trash.
+var trashCopyOptions = s3svc.CopyOptions{PreserveMetadata: true, PreserveStorageClass: true}
+
+// TrashHandler lists the objects in the trash.
+func (s *App) TrashHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.Trash.Enable {
+		s.renderErrorPage(ctx, w, ErrTrashDisabled.Error())
+		return
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	items, err := s.dbsvc.ListTrashItems(ctx, maxListedTrashItems)
+	if err != nil {
+		s.log.Error("Failed to list trash items", slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to list the trash")
+		return
+	}
+
+	if err := views.RenderTrash(items, s.cfg.Trash.Retention(), s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render trash", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// RestoreTrashHandler puts a trashed object back at its original key.
+func (s *App) RestoreTrashHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.S3.EnableDelete {
+		s.renderErrorPage(r.Context(), w, "Delete functionality is disabled")
+		return
+	}
+	s.handleTrashItem(w, r, "restore", s.restoreTrashItem)
+}
+
+// PurgeTrashHandler permanently deletes a trashed object.
+func (s *App) PurgeTrashHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.S3.EnableDelete {
+		s.renderErrorPage(r.Context(), w, "Delete functionality is disabled")
+		return
+	}
+	s.handleTrashItem(w, r, "purge", s.purgeTrashItem)
+}
+
+// handleTrashItem applies action to the trash item posted as "id", then returns to the Trash page.
+func (s *App) handleTrashItem(
+	w http.ResponseWriter, r *http.Request, name string, action func(context.Context, dto.TrashItem) error,
+) {
+	ctx := r.Context()
+	if !s.cfg.Trash.Enable {
+		s.renderErrorPage(ctx, w, ErrTrashDisabled.Error())
+		return
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.renderDatabaseUnavailablePage(ctx, w)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PostFormValue("id"), 10, 32)
+	if err != nil {
+		s.renderErrorPage(ctx, w, "Invalid trash item id")
+		return
+	}
+	item, err := s.dbsvc.GetTrashItem(ctx, int32(id))
+	if err == nil {
+		err = action(ctx, *item)
+	}
+	if err != nil {
+		s.log.Warn("Trash "+name+" failed", slog.Int64("id", id), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	s.log.Info("Trash "+name+" completed",
+		slog.String("bucket", item.Bucket),
+		slog.String("key", item.OriginalKey),
+		slog.String("user", s.requestUser(r)))
+	http.Redirect(w, r, "/trash", http.StatusSeeOther)
+}
+
+// moveToTrash moves keys of the current bucket to the trash and records them.
+// It stops at the first failure and returns the keys already moved.
+func (s *App) moveToTrash(ctx context.Context, keys []string, user string) ([]string, error) {
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		return nil, ErrTrashUnavailable
+	}
+
+	versioned, err := s.s3svc.VersioningEnabled(ctx)
+	if err != nil {
+		// Copying to the trash works whatever the versioning state
+		s.log.Warn("Cannot read bucket versioning, copying to the trash", slog.String("error", err.Error()))
+	}
+
+	trashed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		item, err := s.trashObject(ctx, key, versioned, user)
+		if err != nil {
+			return trashed, err
+		}
+		trashed = append(trashed, key)
+		if item == nil {
+			continue
+		}
+		if _, err := s.dbsvc.CreateTrashItem(context.WithoutCancel(ctx), *item); err != nil {
+			s.log.Error("Trashed object not recorded",
+				slog.String("key", key),
+				slog.String("trashKey", item.TrashKey),
+				slog.String("deleteMarker", item.DeleteMarkerID))
+			return trashed, fmt.Errorf("%s was moved to the trash but could not be recorded: %w", key, err)
+		}
+	}
+	return trashed, nil
+}
+
+// trashObject removes key from the current bucket and returns the trash item to record.
+// Versioned buckets only get a delete marker; otherwise the object is copied to the trash first.
+// Objects already in the trash are deleted for good, and no item is returned.
+func (s *App) trashObject(ctx context.Context, key string, versioned bool, user string) (*dto.TrashItem, error) {
+	bucket := s.cfg.S3.Bucket
+	if s.inTrash(bucket, key) {
+		if err := s.s3svc.DeleteObject(ctx, key); err != nil {
+			return nil, fmt.Errorf("cannot delete %s: %w", key, err)
+		}
+		return nil, nil //nolint:nilnil // Nothing to record for a purged trash copy
+	}
+
+	info, err := s.s3svc.StatObject(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot move %s to the trash: %w", key, err)
+	}
+	item := &dto.TrashItem{Bucket: bucket, OriginalKey: key, Size: info.Size, DeletedBy: user}
+
+	if versioned {
+		item.VersionID = info.VersionID
+		item.DeleteMarkerID, err = s.s3svc.DeleteObjectWithMarker(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot move %s to the trash: %w", key, err)
+		}
+		return item, nil
+	}
+
+	item.TrashBucket = s.trashBucket(bucket)
+	item.TrashKey = trashKey(s.cfg.Trash.Prefix, key, time.Now())
+	if err := s.s3svc.ForBucket(item.TrashBucket).CopyObjectFrom(ctx, bucket, *info, item.TrashKey, trashCopyOptions); err != nil {
+		return nil, fmt.Errorf("cannot move %s to the trash: %w", key, err)
+	}
+	if err := s.s3svc.DeleteObject(ctx, key); err != nil {
+		// The original is still in place: drop the copy rather than keep a trash item nobody deleted
+		if cleanupErr := s.s3svc.ForBucket(item.TrashBucket).DeleteObject(ctx, item.TrashKey); cleanupErr != nil {
+			s.log.Warn("Failed to remove trash copy", slog.String("key", item.TrashKey), slog.String("error", cleanupErr.Error()))
+		}
+		return nil, fmt.Errorf("cannot delete %s: %w", key, err)
+	}
+	return item, nil
+}
+
+// restoreTrashItem puts item back at its original key, forgets it and updates the catalog.
+func (s *App) restoreTrashItem(ctx context.Context, item dto.TrashItem) error {
+	if err := s.restoreObject(ctx, item); err != nil {
+		return err
+	}
+	if err := s.dbsvc.DeleteTrashItem(context.WithoutCancel(ctx), item.ID); err != nil {
+		return err
+	}
+	s.syncRestoredObject(context.WithoutCancel(ctx), item)
+	return nil
+}
+
+// restoreObject undoes trashObject, refusing to overwrite an object created since at the original key.
+func (s *App) restoreObject(ctx context.Context, item dto.TrashItem) error {
+	svc := s.s3svc.ForBucket(item.Bucket)
+	exists, err := svc.ObjectExists(ctx, item.OriginalKey)
+	if err != nil {
+		return fmt.Errorf("cannot check %s: %w", item.OriginalKey, err)
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", ErrDestinationExists, item.OriginalKey)
+	}
+
+	if item.Versioned() {
+		// Removing the delete marker makes the deleted version current again
+		if err := svc.DeleteObjectVersion(ctx, item.OriginalKey, item.DeleteMarkerID); err != nil {
+			return fmt.Errorf("cannot restore %s: %w", item.OriginalKey, err)
+		}
+		return nil
+	}
+
+	trash := s.s3svc.ForBucket(item.TrashBucket)
+	info, err := trash.StatObject(ctx, item.TrashKey)
+	if err != nil {
+		return fmt.Errorf("cannot restore %s: %w", item.OriginalKey, err)
+	}
+	if err := svc.CopyObjectFrom(ctx, item.TrashBucket, *info, item.OriginalKey, trashCopyOptions); err != nil {
+		return fmt.Errorf("cannot restore %s: %w", item.OriginalKey, err)
+	}
+	if err := trash.DeleteObject(ctx, item.TrashKey); err != nil {
+		// The object is back; the leftover copy is only wasted space
+		s.log.Warn("Failed to remove restored object from the trash",
+			slog.String("key", item.TrashKey),
+			slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// syncRestoredObject records a restored object and its parent folders in the catalog.
+// Errors are only logged: the next scan fixes the catalog.
+func (s *App) syncRestoredObject(ctx context.Context, item dto.TrashItem) {
+	if !s.catalogsBucket(ctx, config.DefaultConnection, item.Bucket) {
+		return
+	}
+	info, err := s.s3svc.ForBucket(item.Bucket).StatObject(ctx, item.OriginalKey)
+	if err != nil {
+		s.log.Warn("Failed to read restored object", slog.String("key", item.OriginalKey), slog.String("error", err.Error()))
+		return
+	}
+	if err := s.dbsvc.SyncUploadedObject(ctx, item.Bucket, item.OriginalKey, info.Size, info.ETag, info.StorageClass); err != nil {
+		s.log.Error("Failed to sync restored object", slog.String("key", item.OriginalKey), slog.String("error", err.Error()))
+		return
+	}
+	if err := s.dbsvc.SyncUploadedFolders(ctx, item.Bucket, "", item.OriginalKey); err != nil {
+		s.log.Error("Failed to sync restored folders", slog.String("key", item.OriginalKey), slog.String("error", err.Error()))
+	}
+}
+
+// purgeTrashItem permanently deletes item and forgets it.
+func (s *App) purgeTrashItem(ctx context.Context, item dto.TrashItem) error {
+	if err := s.purgeObject(ctx, item); err != nil {
+		return err
+	}
+	return s.dbsvc.DeleteTrashItem(context.WithoutCancel(ctx), item.ID)
+}
+
+// purgeObject deletes the trashed data of item: the trash copy, or the hidden version and its marker.
+func (s *App) purgeObject(ctx context.Context, item dto.TrashItem) error {
+	if !item.Versioned() {
+		if err := s.s3svc.ForBucket(item.TrashBucket).DeleteObject(ctx, item.TrashKey); err != nil {
+			return fmt.Errorf("cannot purge %s: %w", item.OriginalKey, err)
+		}
+		return nil
+	}
+
+	svc := s.s3svc.ForBucket(item.Bucket)
+	if item.VersionID != "" {
+		if err := svc.DeleteObjectVersion(ctx, item.OriginalKey, item.VersionID); err != nil {
+			return fmt.Errorf("cannot purge %s: %w", item.OriginalKey, err)
+		}
+	}
+	if err := svc.DeleteObjectVersion(ctx, item.OriginalKey, item.DeleteMarkerID); err != nil {
+		return fmt.Errorf("cannot purge %s: %w", item.OriginalKey, err)
+	}
+	return nil
+}
+
+// PurgeExpiredTrash permanently deletes the trash items older than the retention.
+// It is run by the scheduler; items that fail are retried on the next run.
+func (s *App) PurgeExpiredTrash(ctx context.Context) {
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.log.Warn("Skipping trash purge - database unavailable")
+		return
+	}
+
+	before := time.Now().Add(-s.cfg.Trash.Retention())
+	var purged, failed int
+	for ctx.Err() == nil {
+		items, err := s.dbsvc.ListTrashItemsDeletedBefore(ctx, before, trashPurgeBatch)
+		if err != nil {
+			s.log.Error("Failed to list expired trash items", slog.String("error", err.Error()))
+			return
+		}
+
+		batchFailed := 0
+		for _, item := range items {
+			if err := s.purgeTrashItem(ctx, item); err != nil {
+				s.log.Error("Failed to purge trash item", slog.Int("id", int(item.ID)), slog.String("error", err.Error()))
+				batchFailed++
+				continue
+			}
+			purged++
+		}
+		failed += batchFailed
+
+		// Failed items stay listed first, so stop rather than load them again
+		if len(items) < trashPurgeBatch || batchFailed > 0 {
+			break
+		}
+	}
+
+	s.log.Info("Trash purge completed", slog.Int("purged", purged), slog.Int("failed", failed))
+}
+
+// trashBucket returns the bucket receiving objects trashed from bucket.
+func (s *App) trashBucket(bucket string) string {
+	if s.cfg.Trash.Bucket != "" {
+		return s.cfg.Trash.Bucket
+	}
+	return bucket
+}
+
+// inTrash reports whether key of bucket is a trashed copy.
+func (s *App) inTrash(bucket, key string) bool {
+	return bucket == s.trashBucket(bucket) && strings.HasPrefix(key, s.cfg.Trash.Prefix)
+}
+
+// trashKey returns where key is copied in the trash. The timestamp keeps successive
+// deletes of the same key apart.
+func trashKey(prefix, key string, now time.Time) string {
+	return prefix + strconv.FormatInt(now.UnixNano(), 10) + "/" + key
+}
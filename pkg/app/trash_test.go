@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTrashTestApp(t *testing.T, objects map[string]string) (*App, *fakeOrganizeS3) {
+	t.Helper()
+	app, fake := newOrganizeTestApp(t, objects)
+	app.cfg.Trash = config.TrashConfig{Enable: true, Prefix: ".trash/", RetentionDays: 30}
+	return app, fake
+}
+
+func TestTrashObject_CopiesThenRestores(t *testing.T) {
+	app, fake := newTrashTestApp(t, map[string]string{"docs/a.txt": "hello"})
+	ctx := context.Background()
+
+	item, err := app.trashObject(ctx, "docs/a.txt", false, "alice")
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	assert.Equal(t, "bucket", item.Bucket)
+	assert.Equal(t, "docs/a.txt", item.OriginalKey)
+	assert.Equal(t, "bucket", item.TrashBucket)
+	assert.Regexp(t, `^\.trash/\d+/docs/a\.txt$`, item.TrashKey)
+	assert.Equal(t, int64(5), item.Size)
+	assert.Equal(t, "alice", item.DeletedBy)
+	assert.False(t, item.Versioned())
+	assert.Equal(t, map[string]string{item.TrashKey: "hello"}, fake.objects)
+
+	require.NoError(t, app.restoreObject(ctx, *item))
+	assert.Equal(t, map[string]string{"docs/a.txt": "hello"}, fake.objects)
+}
+
+func TestRestoreObject_RefusesToOverwrite(t *testing.T) {
+	app, fake := newTrashTestApp(t, map[string]string{"a.txt": "old"})
+	ctx := context.Background()
+
+	item, err := app.trashObject(ctx, "a.txt", false, "alice")
+	require.NoError(t, err)
+	fake.objects["a.txt"] = "new"
+
+	err = app.restoreObject(ctx, *item)
+	require.ErrorIs(t, err, ErrDestinationExists)
+	assert.Equal(t, "new", fake.objects["a.txt"])
+	assert.Equal(t, "old", fake.objects[item.TrashKey])
+}
+
+func TestPurgeObject_DeletesTrashCopy(t *testing.T) {
+	app, fake := newTrashTestApp(t, map[string]string{".trash/1/a.txt": "old", "b.txt": "keep"})
+
+	item := dto.TrashItem{Bucket: "bucket", OriginalKey: "a.txt", TrashBucket: "bucket", TrashKey: ".trash/1/a.txt"}
+	require.NoError(t, app.purgeObject(context.Background(), item))
+	assert.Equal(t, map[string]string{"b.txt": "keep"}, fake.objects)
+}
+
+func TestTrashObject_DeletesFromTrashForGood(t *testing.T) {
+	app, fake := newTrashTestApp(t, map[string]string{".trash/1/a.txt": "old"})
+
+	item, err := app.trashObject(context.Background(), ".trash/1/a.txt", false, "alice")
+	require.NoError(t, err)
+	assert.Nil(t, item)
+	assert.Empty(t, fake.objects)
+}
+
+func TestInTrash(t *testing.T) {
+	app := &App{cfg: config.Config{Trash: config.TrashConfig{Prefix: ".trash/"}}}
+	assert.True(t, app.inTrash("photos", ".trash/1/a.jpg"))
+	assert.False(t, app.inTrash("photos", "a.jpg"))
+
+	app.cfg.Trash.Bucket = "trash"
+	assert.True(t, app.inTrash("trash", ".trash/1/a.jpg"))
+	assert.False(t, app.inTrash("photos", ".trash/1/a.jpg"))
+	assert.Equal(t, "trash", app.trashBucket("photos"))
+}
+
+func TestRestoreTrashHandler_NeedsDelete(t *testing.T) {
+	app, _ := newTrashTestApp(t, nil)
+	app.cfg.S3.EnableDelete = false
+
+	rec := httptest.NewRecorder()
+	app.RestoreTrashHandler(rec, organizeRequest("/trash/restore", url.Values{"id": {"1"}}))
+	assert.Contains(t, rec.Body.String(), "Delete functionality is disabled")
+}
+
+func TestValidateFolderDelete_TrashFolder(t *testing.T) {
+	app, _ := newTrashTestApp(t, nil)
+	app.cfg.Trash.Prefix = "archive/.trash/"
+
+	require.ErrorIs(t, app.validateFolderDelete("archive/"), ErrDeleteTrashFolder)
+	require.ErrorIs(t, app.validateFolderDelete("archive/.trash/"), ErrDeleteTrashFolder)
+	require.NoError(t, app.validateFolderDelete("photos/"))
+
+	// Trashed copies kept in another bucket are not listed with the folder
+	app.cfg.Trash.Bucket = "trash"
+	require.NoError(t, app.validateFolderDelete("archive/"))
+}
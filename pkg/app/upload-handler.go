@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"mime"
 	"mime/multipart"
@@ -14,8 +15,11 @@ import (
 )
 
 const (
-	// MaxUploadSize is the maximum file size allowed (100 MB).
-	MaxUploadSize = 100 * 1024 * 1024 // 100 MB
+	// uploadFormOverhead is the room left for multipart headers and form fields
+	// on top of the configured maximum file size.
+	uploadFormOverhead = 1 << 20
+	// maxFolderFieldSize bounds the folder form field read before the file part.
+	maxFolderFieldSize = 4096
 )
 
 var (
@@ -52,36 +56,49 @@ func (s *App) UploadHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// processUpload handles the actual upload processing logic.
+// processUpload streams the uploaded file straight into an S3 multipart upload.
+// The multipart form is read part by part instead of with ParseMultipartForm, so
+// nothing is buffered to memory or temp files beyond the parts in flight to S3.
+// The folder field must precede the file field, as it does in the upload form.
 func (s *App) processUpload(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	// Limit request body size to prevent memory exhaustion
-	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadSize+1<<20)
-
-	// Parse multipart form
-	if err := r.ParseMultipartForm(MaxUploadSize); err != nil {
-		s.log.Error("Failed to parse multipart form", slog.String("error", err.Error()))
-		return ErrParseUploadRequest
+	if s.cfg.Upload.MaxSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.cfg.Upload.MaxSize+uploadFormOverhead)
 	}
 
-	// Get and validate folder
-	folder := s.getValidatedFolder(r)
-
-	// Get uploaded file
-	file, header, err := r.FormFile("file")
+	reader, err := r.MultipartReader()
 	if err != nil {
-		s.log.Error("Failed to get uploaded file", slog.String("error", err.Error()))
-		return ErrNoFileUploaded
+		s.log.Error("Failed to read multipart form", slog.String("error", err.Error()))
+		return ErrParseUploadRequest
 	}
-	defer file.Close() //nolint:errcheck
 
-	// Validate file size
-	if header.Size > MaxUploadSize {
-		const bytesPerMB = 1024 * 1024
-		return fmt.Errorf("%w (max %d MB)", ErrFileTooLarge, MaxUploadSize/bytesPerMB)
+	folder := ""
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return ErrNoFileUploaded
+		}
+		if err != nil {
+			s.log.Error("Failed to read multipart form", slog.String("error", err.Error()))
+			return ErrParseUploadRequest
+		}
+
+		switch {
+		case part.FormName() == "folder":
+			folder = readFormField(part)
+		case part.FormName() == "file" && part.FileName() != "":
+			defer part.Close() //nolint:errcheck
+			return s.streamUploadedFile(ctx, w, r, s.getValidatedFolder(folder), part)
+		}
+		_ = part.Close()
 	}
+}
 
+// streamUploadedFile uploads one file part to S3, syncs it to the catalog and redirects to its folder.
+func (s *App) streamUploadedFile(
+	ctx context.Context, w http.ResponseWriter, r *http.Request, folder string, part *multipart.Part,
+) error {
 	// Construct and validate S3 key
-	key := folder + header.Filename
+	key := folder + part.FileName()
 	if !s.validateKeyPrefix(key) {
 		s.log.Warn("Upload attempt outside configured prefix",
 			slog.String("key", key),
@@ -90,22 +107,29 @@ func (s *App) processUpload(ctx context.Context, w http.ResponseWriter, r *http.
 	}
 
 	// Detect content type
-	contentType := s.detectContentType(header)
+	contentType := s.detectContentType(part.FileName(), part.Header.Get("Content-Type"))
 
 	s.log.Info("Upload request",
 		slog.String("key", key),
 		slog.String("contentType", contentType),
-		slog.Int64("size", header.Size))
+		slog.Int64("contentLength", r.ContentLength))
 
-	// Upload to S3
-	if err := s.s3svc.UploadObject(ctx, key, file, contentType, header.Size); err != nil {
-		s.log.Error("Failed to upload to S3", slog.String("error", err.Error()))
+	// Stream to S3; a failed upload is aborted by the uploader
+	result, err := s.s3svc.StreamUpload(ctx, key, part, contentType, s.cfg.Upload.PartSize, s.cfg.Upload.Concurrency)
+	if err != nil {
+		s.log.Error("Failed to upload to S3", slog.String("key", key), slog.String("error", err.Error()))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return fmt.Errorf("%w (max %d bytes)", ErrFileTooLarge, s.cfg.Upload.MaxSize)
+		}
 		return fmt.Errorf("upload failed: %w", err)
 	}
 
 	// Sync to database (log errors but don't fail)
-	if err := s.dbsvc.SyncUploadedObject(ctx, s.cfg.S3.Bucket, key, header.Size, "", "STANDARD"); err != nil {
-		s.log.Error("Failed to sync upload to database", slog.String("error", err.Error()))
+	if s.dbsvc != nil {
+		if err := s.dbsvc.SyncUploadedObject(ctx, s.cfg.S3.Bucket, key, result.Size, result.ETag, "STANDARD"); err != nil {
+			s.log.Error("Failed to sync upload to database", slog.String("error", err.Error()))
+		}
 	}
 
 	// Redirect back to folder
@@ -114,9 +138,8 @@ func (s *App) processUpload(ctx context.Context, w http.ResponseWriter, r *http.
 	return nil
 }
 
-// getValidatedFolder extracts and validates the folder parameter from form data.
-func (s *App) getValidatedFolder(r *http.Request) string {
-	folder := r.FormValue("folder")
+// getValidatedFolder validates the folder form value against the configured prefix.
+func (s *App) getValidatedFolder(folder string) string {
 	if folder == "" {
 		folder = s.cfg.S3.Prefix
 	}
@@ -140,15 +163,14 @@ func (s *App) validateKeyPrefix(key string) bool {
 	return strings.HasPrefix(key, s.cfg.S3.Prefix)
 }
 
-// detectContentType determines the content type from the file header.
-func (s *App) detectContentType(header *multipart.FileHeader) string {
-	contentType := header.Header.Get("Content-Type")
-	if contentType != "" {
-		return contentType
+// detectContentType determines the content type from the declared type or the file name.
+func (s *App) detectContentType(filename, declared string) string {
+	if declared != "" {
+		return declared
 	}
 
 	// Fallback to detection based on file extension
-	contentType = mime.TypeByExtension(filepath.Ext(header.Filename))
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
 	if contentType != "" {
 		return contentType
 	}
@@ -0,0 +1,304 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sgaunet/s3xplorer/pkg/dbsvc"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+)
+
+const (
+	// uploadSessionIDBytes is the amount of randomness in an upload session ID
+	uploadSessionIDBytes = 24
+	// staleUploadBatch is the number of idle sessions aborted per janitor run
+	staleUploadBatch = 100
+	// uploadJanitorInterval is how often idle upload sessions are looked for
+	uploadJanitorInterval = 15 * time.Minute
+	// defaultUploadSessionTTL is used when upload.session_ttl cannot be parsed
+	defaultUploadSessionTTL = 24 * time.Hour
+)
+
+var (
+	// ErrUploadSessionClosed is returned when using a completed or aborted upload session.
+	ErrUploadSessionClosed = errors.New("upload session is no longer active")
+	// ErrInvalidUploadPart is returned for a part number or size that does not match the session.
+	ErrInvalidUploadPart = errors.New("invalid upload part")
+	// ErrUploadIncomplete is returned when completing a session with missing parts.
+	ErrUploadIncomplete = errors.New("upload has missing parts")
+)
+
+// createUploadRequest is the body of POST /api/uploads.
+type createUploadRequest struct {
+	Folder      string `json:"folder"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType"`
+}
+
+// CreateUploadSessionHandler starts a resumable upload (POST /api/uploads).
+// The response tells the browser the part size to use; parts can then be sent
+// in parallel and in any order, and GET /api/uploads/{id} lists those already stored.
+func (s *App) CreateUploadSessionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.uploadSessionsAvailable(w) {
+		return
+	}
+
+	var req createUploadRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFolderFieldSize*2)).Decode(&req); err != nil {
+		s.writeJSONError(w, http.StatusBadRequest, ErrParseUploadRequest.Error())
+		return
+	}
+
+	filename := path.Base(req.Filename)
+	if req.Filename == "" || filename == "." || filename == "/" {
+		s.writeJSONError(w, http.StatusBadRequest, ErrNoFileUploaded.Error())
+		return
+	}
+	if req.Size < 0 || (s.cfg.Upload.MaxSize > 0 && req.Size > s.cfg.Upload.MaxSize) {
+		s.writeJSONError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("%s (max %d bytes)", ErrFileTooLarge, s.cfg.Upload.MaxSize))
+		return
+	}
+
+	key := s.getValidatedFolder(req.Folder) + filename
+	if !s.validateKeyPrefix(key) {
+		s.writeJSONError(w, http.StatusForbidden, ErrUploadOutsidePrefix.Error())
+		return
+	}
+	contentType := s.detectContentType(filename, req.ContentType)
+
+	id, err := newRandomToken(uploadSessionIDBytes)
+	if err != nil {
+		s.writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	uploadID, err := s.s3svc.CreateMultipartUpload(ctx, key, contentType)
+	if err != nil {
+		s.log.Error("Failed to start multipart upload", slog.String("key", key), slog.String("error", err.Error()))
+		s.writeJSONError(w, http.StatusBadGateway, "failed to start upload")
+		return
+	}
+
+	session, err := s.dbsvc.CreateUploadSession(ctx, dto.UploadSession{
+		ID:          id,
+		Bucket:      s.cfg.S3.Bucket,
+		Key:         key,
+		UploadID:    uploadID,
+		ContentType: contentType,
+		Size:        req.Size,
+		PartSize:    s3svc.SessionPartSize(req.Size, s.cfg.Upload.PartSize),
+	})
+	if err != nil {
+		s.log.Error("Failed to record upload session", slog.String("key", key), slog.String("error", err.Error()))
+		if abortErr := s.s3svc.AbortMultipartUpload(ctx, key, uploadID); abortErr != nil {
+			s.log.Warn("Failed to abort multipart upload", slog.String("error", abortErr.Error()))
+		}
+		s.writeJSONError(w, http.StatusInternalServerError, "failed to start upload")
+		return
+	}
+
+	s.log.Info("Upload session started",
+		slog.String("id", session.ID),
+		slog.String("key", key),
+		slog.Int64("size", session.Size),
+		slog.Int("parts", int(session.PartCount)))
+	s.writeJSON(w, http.StatusCreated, session)
+}
+
+// GetUploadSessionHandler returns a session and the parts already uploaded (GET /api/uploads/{id}).
+func (s *App) GetUploadSessionHandler(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.loadUploadSession(w, r, false)
+	if !ok {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, session)
+}
+
+// UploadPartHandler streams one part to S3 (PUT /api/uploads/{id}/parts/{number}).
+// The body must be exactly the expected part size; uploading a part again replaces it.
+func (s *App) UploadPartHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	session, ok := s.loadUploadSession(w, r, true)
+	if !ok {
+		return
+	}
+
+	number, err := strconv.ParseInt(mux.Vars(r)["number"], 10, 32)
+	if err != nil || number < 1 || int32(number) > session.PartCount {
+		s.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("%s: number %q", ErrInvalidUploadPart, mux.Vars(r)["number"]))
+		return
+	}
+	partNumber := int32(number)
+	expected := session.ExpectedPartSize(partNumber)
+	if r.ContentLength != expected {
+		s.writeJSONError(w, http.StatusBadRequest,
+			fmt.Sprintf("%s: part %d must be %d bytes, got %d", ErrInvalidUploadPart, partNumber, expected, r.ContentLength))
+		return
+	}
+
+	svc := s.s3svc.ForBucket(session.Bucket)
+	body := http.MaxBytesReader(w, r.Body, expected)
+	etag, err := svc.UploadPart(ctx, session.Key, session.UploadID, partNumber, body, expected)
+	if err != nil {
+		s.log.Error("Failed to upload part",
+			slog.String("id", session.ID), slog.Int("part", int(partNumber)), slog.String("error", err.Error()))
+		s.writeJSONError(w, http.StatusBadGateway, "failed to upload part")
+		return
+	}
+
+	part := dto.UploadPart{Number: partNumber, ETag: etag, Size: expected}
+	if err := s.dbsvc.RecordUploadPart(ctx, session.ID, part); err != nil {
+		s.log.Error("Failed to record part", slog.String("id", session.ID), slog.String("error", err.Error()))
+		s.writeJSONError(w, http.StatusInternalServerError, "failed to record part")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, part)
+}
+
+// CompleteUploadSessionHandler assembles the parts into the object (POST /api/uploads/{id}/complete).
+func (s *App) CompleteUploadSessionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	session, ok := s.loadUploadSession(w, r, true)
+	if !ok {
+		return
+	}
+	if !session.Complete() {
+		s.writeJSONError(w, http.StatusConflict,
+			fmt.Sprintf("%s: %d of %d parts uploaded", ErrUploadIncomplete, len(session.Parts), session.PartCount))
+		return
+	}
+
+	svc := s.s3svc.ForBucket(session.Bucket)
+	etag, err := svc.CompleteMultipartUpload(ctx, session.Key, session.UploadID, session.Parts)
+	if err != nil {
+		s.log.Error("Failed to complete upload", slog.String("id", session.ID), slog.String("error", err.Error()))
+		s.writeJSONError(w, http.StatusBadGateway, "failed to complete upload")
+		return
+	}
+
+	if err := s.dbsvc.SetUploadSessionStatus(ctx, session.ID, dto.UploadStatusCompleted); err != nil {
+		s.log.Error("Failed to mark upload completed", slog.String("id", session.ID), slog.String("error", err.Error()))
+	}
+	if err := s.dbsvc.SyncUploadedObject(ctx, session.Bucket, session.Key, session.Size, etag, "STANDARD"); err != nil {
+		s.log.Error("Failed to sync upload to database", slog.String("error", err.Error()))
+	}
+
+	s.log.Info("Upload session completed", slog.String("id", session.ID), slog.String("key", session.Key))
+	session.Status = dto.UploadStatusCompleted
+	s.writeJSON(w, http.StatusOK, session)
+}
+
+// AbortUploadSessionHandler cancels an upload and discards its parts (DELETE /api/uploads/{id}).
+func (s *App) AbortUploadSessionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	session, ok := s.loadUploadSession(w, r, true)
+	if !ok {
+		return
+	}
+
+	if err := s.abortUploadSession(ctx, *session); err != nil {
+		s.log.Error("Failed to abort upload", slog.String("id", session.ID), slog.String("error", err.Error()))
+		s.writeJSONError(w, http.StatusBadGateway, "failed to abort upload")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// uploadSessionsAvailable writes an error response when resumable uploads cannot be used.
+func (s *App) uploadSessionsAvailable(w http.ResponseWriter) bool {
+	if !s.cfg.S3.EnableUpload {
+		s.writeJSONError(w, http.StatusForbidden, "Upload functionality is disabled")
+		return false
+	}
+	if s.dbsvc == nil || !s.IsDatabaseHealthy() {
+		s.writeJSONError(w, http.StatusServiceUnavailable, "database is unavailable")
+		return false
+	}
+	return true
+}
+
+// loadUploadSession fetches the session named in the URL, writing an error response on failure.
+// When active is set, completed and aborted sessions are rejected.
+func (s *App) loadUploadSession(w http.ResponseWriter, r *http.Request, active bool) (*dto.UploadSession, bool) {
+	if !s.uploadSessionsAvailable(w) {
+		return nil, false
+	}
+
+	session, err := s.dbsvc.GetUploadSession(r.Context(), mux.Vars(r)["id"])
+	if errors.Is(err, dbsvc.ErrUploadSessionNotFound) {
+		s.writeJSONError(w, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+	if err != nil {
+		s.log.Error("Failed to load upload session", slog.String("error", err.Error()))
+		s.writeJSONError(w, http.StatusInternalServerError, "failed to load upload session")
+		return nil, false
+	}
+	if active && session.Status != dto.UploadStatusActive {
+		s.writeJSONError(w, http.StatusConflict, ErrUploadSessionClosed.Error())
+		return nil, false
+	}
+	return session, true
+}
+
+// abortUploadSession aborts the S3 multipart upload and marks the session aborted.
+func (s *App) abortUploadSession(ctx context.Context, session dto.UploadSession) error {
+	svc := s.s3svc.ForBucket(session.Bucket)
+	if err := svc.AbortMultipartUpload(ctx, session.Key, session.UploadID); err != nil {
+		return fmt.Errorf("failed to abort upload %s: %w", session.ID, err)
+	}
+	if err := s.dbsvc.SetUploadSessionStatus(ctx, session.ID, dto.UploadStatusAborted); err != nil {
+		return fmt.Errorf("failed to mark upload %s aborted: %w", session.ID, err)
+	}
+	return nil
+}
+
+// abortStaleUploadSessions aborts sessions idle for longer than upload.session_ttl,
+// so abandoned browser uploads do not keep billing for their stored parts.
+func (s *App) abortStaleUploadSessions(ctx context.Context) {
+	ttl, err := time.ParseDuration(s.cfg.Upload.SessionTTL)
+	if err != nil || ttl <= 0 {
+		ttl = defaultUploadSessionTTL
+	}
+
+	sessions, err := s.dbsvc.ListStaleUploadSessions(ctx, time.Now().Add(-ttl), staleUploadBatch)
+	if err != nil {
+		s.log.Error("Failed to list stale upload sessions", slog.String("error", err.Error()))
+		return
+	}
+	for _, session := range sessions {
+		if err := s.abortUploadSession(ctx, session); err != nil {
+			s.log.Warn("Failed to abort stale upload", slog.String("error", err.Error()))
+			continue
+		}
+		s.log.Info("Aborted stale upload session", slog.String("id", session.ID), slog.String("key", session.Key))
+	}
+}
+
+// runUploadJanitor periodically aborts stale upload sessions until ctx is cancelled.
+func (s *App) runUploadJanitor(ctx context.Context) {
+	ticker := time.NewTicker(uploadJanitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.IsDatabaseHealthy() {
+				s.abortStaleUploadSessions(ctx)
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUploadS3 records single PutObject uploads and counts multipart aborts.
+type fakeUploadS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	aborts  int
+}
+
+func (f *fakeUploadS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPut && !r.URL.Query().Has("uploadId"):
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = body
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodDelete && r.URL.Query().Has("uploadId"):
+		f.aborts++
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func newUploadTestApp(t *testing.T, upload config.UploadConfig) (*App, *fakeUploadS3) {
+	t.Helper()
+
+	fake := &fakeUploadS3{objects: map[string][]byte{}}
+	cfg := config.Config{
+		S3:     config.S3Config{Bucket: "bucket", EnableUpload: true},
+		Upload: upload,
+	}
+	return newFakeS3App(t, fake, cfg), fake
+}
+
+func uploadRequest(t *testing.T, folder, filename string, content []byte) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("folder", folder))
+	fw, err := mw.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = fw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestUploadHandler_StreamsToS3(t *testing.T) {
+	app, fake := newUploadTestApp(t, config.UploadConfig{PartSize: s3svc.MinPartSize, Concurrency: 2})
+
+	rec := httptest.NewRecorder()
+	app.UploadHandler(rec, uploadRequest(t, "docs/", "notes.txt", []byte("hello upload")))
+
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "/?folder=docs%2F&page=1", rec.Header().Get("Location"))
+	assert.Equal(t, "hello upload", string(fake.objects["/bucket/docs/notes.txt"]))
+}
+
+func TestUploadHandler_MaxSize(t *testing.T) {
+	app, fake := newUploadTestApp(t, config.UploadConfig{PartSize: s3svc.MinPartSize, Concurrency: 1, MaxSize: 16})
+
+	rec := httptest.NewRecorder()
+	content := bytes.Repeat([]byte("x"), 2*uploadFormOverhead)
+	app.UploadHandler(rec, uploadRequest(t, "", "big.bin", content))
+
+	assert.Contains(t, rec.Body.String(), ErrFileTooLarge.Error())
+	assert.Empty(t, fake.objects)
+}
+
+func TestUploadHandler_OutsidePrefix(t *testing.T) {
+	app, fake := newUploadTestApp(t, config.UploadConfig{PartSize: s3svc.MinPartSize, Concurrency: 1})
+	app.cfg.S3.Prefix = "allowed/"
+
+	rec := httptest.NewRecorder()
+	app.UploadHandler(rec, uploadRequest(t, "allowed/", "../../escape.txt", []byte("x")))
+
+	// multipart.Part.FileName strips directories, so the key stays under the prefix
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Contains(t, fake.objects, "/bucket/allowed/escape.txt")
+}
+
+func TestUploadHandler_NoFile(t *testing.T) {
+	app, _ := newUploadTestApp(t, config.UploadConfig{PartSize: s3svc.MinPartSize, Concurrency: 1})
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("folder", "docs/"))
+	require.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(body.String()))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	app.UploadHandler(rec, req)
+	assert.Contains(t, rec.Body.String(), ErrNoFileUploaded.Error())
+}
+
+func TestSizeLimitedReader(t *testing.T) {
+	data, err := io.ReadAll(&sizeLimitedReader{r: strings.NewReader("0123456789"), remaining: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(data))
+
+	_, err = io.ReadAll(&sizeLimitedReader{r: strings.NewReader("0123456789"), remaining: 9})
+	assert.ErrorIs(t, err, ErrFileTooLarge)
+}
+
+func TestCleanRelativePath(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"file.txt", "file.txt", false},
+		{"photos/2024/a.jpg", "photos/2024/a.jpg", false},
+		{"photos//./a.jpg", "photos/a.jpg", false},
+		{`photos\win\a.jpg`, "photos/win/a.jpg", false},
+		{"", "", true},
+		{"/etc/passwd", "", true},
+		{"../escape.txt", "", true},
+		{"photos/../../escape.txt", "", true},
+		{"photos/", "", true},
+		{".", "", true},
+	}
+	for _, tt := range tests {
+		got, err := cleanRelativePath(tt.in)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidRelativePath, "input %q", tt.in)
+			continue
+		}
+		require.NoError(t, err, "input %q", tt.in)
+		assert.Equal(t, tt.want, got, "input %q", tt.in)
+	}
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/sgaunet/s3xplorer/pkg/views"
+)
+
+var (
+	// ErrVersionsDisabled is returned when version history is not enabled.
+	ErrVersionsDisabled = errors.New("version history is disabled")
+	// ErrMissingVersion is returned when no version ID is given.
+	ErrMissingVersion = errors.New("missing version parameter")
+	// ErrNotDeleted is returned when restoring a deleted object whose latest version is not a delete marker.
+	ErrNotDeleted = errors.New("object is not deleted")
+	// ErrNoVersionToRestore is returned when a deleted object has no version left to restore.
+	ErrNoVersionToRestore = errors.New("no version left to restore")
+)
+
+// VersionsHandler lists the versions and delete markers of an object.
+func (s *App) VersionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableVersions {
+		s.renderErrorPage(ctx, w, ErrVersionsDisabled.Error())
+		return
+	}
+
+	key, err := s.extractAndValidateKey(r)
+	if err != nil {
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	versions, err := s.s3svc.ListObjectVersions(ctx, key)
+	if err != nil {
+		s.log.Error("Failed to list object versions", slog.String("key", key), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to list the versions")
+		return
+	}
+
+	if err := views.RenderVersions(key, versions, parentFolder(key), s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render versions", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// DeletedObjectsHandler lists the objects of a folder whose latest version is a delete marker.
+func (s *App) DeletedObjectsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableVersions {
+		s.renderErrorPage(ctx, w, ErrVersionsDisabled.Error())
+		return
+	}
+
+	folder := s.getDeleteValidatedFolder(r)
+	deleted, err := s.s3svc.ListDeletedObjects(ctx, folder)
+	if err != nil {
+		s.log.Error("Failed to list deleted objects", slog.String("folder", folder), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, "Failed to list the deleted files")
+		return
+	}
+
+	if err := views.RenderDeletedObjects(folder, deleted, s.cfg).Render(ctx, w); err != nil {
+		s.log.Error("Failed to render deleted objects", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// PromoteVersionHandler makes an older version current again.
+func (s *App) PromoteVersionHandler(w http.ResponseWriter, r *http.Request) {
+	s.handleVersionWrite(w, r, func(ctx context.Context, key string) error {
+		versionID := r.PostFormValue("version")
+		if versionID == "" {
+			return ErrMissingVersion
+		}
+		return s.promoteVersion(ctx, key, versionID)
+	})
+}
+
+// RestoreDeletedHandler brings back a deleted object from its latest version.
+func (s *App) RestoreDeletedHandler(w http.ResponseWriter, r *http.Request) {
+	s.handleVersionWrite(w, r, s.restoreDeleted)
+}
+
+// handleVersionWrite checks a form posting "key", applies action to it and returns to its versions.
+func (s *App) handleVersionWrite(
+	w http.ResponseWriter, r *http.Request, action func(ctx context.Context, key string) error,
+) {
+	ctx := r.Context()
+	if !s.cfg.S3.EnableVersions {
+		s.renderErrorPage(ctx, w, ErrVersionsDisabled.Error())
+		return
+	}
+	if !s.cfg.S3.EnableUpload {
+		s.renderErrorPage(ctx, w, "Upload functionality is disabled")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.renderErrorPage(ctx, w, "Invalid request")
+		return
+	}
+
+	key := r.PostFormValue("key")
+	err := s.validatePostedKey(key)
+	if err == nil {
+		err = action(ctx, key)
+	}
+	if err != nil {
+		s.log.Warn("Version change failed", slog.String("key", key), slog.String("error", err.Error()))
+		s.renderErrorPage(ctx, w, err.Error())
+		return
+	}
+
+	http.Redirect(w, r, "/versions?key="+url.QueryEscape(key), http.StatusSeeOther)
+}
+
+// validatePostedKey checks that a key posted by a form can be changed.
+func (s *App) validatePostedKey(key string) error {
+	if key == "" {
+		return ErrMissingKeyParam
+	}
+	if !s.validateKeyPrefix(key) {
+		return fmt.Errorf("%w: does not have required prefix '%s'", ErrInvalidKey, s.cfg.S3.Prefix)
+	}
+	return nil
+}
+
+// promoteVersion copies versionID of key over the object, which makes it the current version.
+// The newer versions are kept.
+func (s *App) promoteVersion(ctx context.Context, key, versionID string) error {
+	// HEAD fails on delete markers, which have no content to promote
+	info, err := s.s3svc.StatObjectVersion(ctx, key, versionID)
+	if err != nil {
+		return fmt.Errorf("cannot read version %s of %s: %w", versionID, key, err)
+	}
+	opts := s3svc.CopyOptions{PreserveMetadata: true, PreserveStorageClass: true}
+	if err := s.s3svc.CopyObjectFrom(ctx, s.cfg.S3.Bucket, *info, key, opts); err != nil {
+		s.log.Error("Failed to promote version", slog.String("key", key), slog.String("error", err.Error()))
+		return fmt.Errorf("promote failed: %w", err)
+	}
+	s.log.Info("Version promoted", slog.String("key", key), slog.String("version", versionID))
+
+	// Sync to database (log errors but don't fail)
+	if s.dbsvc != nil {
+		if err := s.dbsvc.SyncUploadedObject(ctx, s.cfg.S3.Bucket, key, info.Size, info.ETag, info.StorageClass); err != nil {
+			s.log.Error("Failed to sync promoted version", slog.String("key", key), slog.String("error", err.Error()))
+		} else if err := s.dbsvc.SyncUploadedFolders(ctx, s.cfg.S3.Bucket, s.cfg.S3.Prefix, key); err != nil {
+			s.log.Error("Failed to sync promoted version folders", slog.String("key", key), slog.String("error", err.Error()))
+		}
+	}
+	return nil
+}
+
+// restoreDeleted promotes the newest version of a deleted key. The delete marker is kept,
+// so a trash item hiding the same version still purges only that version.
+func (s *App) restoreDeleted(ctx context.Context, key string) error {
+	versions, err := s.s3svc.ListObjectVersions(ctx, key)
+	if err != nil {
+		return fmt.Errorf("cannot list versions of %s: %w", key, err)
+	}
+	deleted := false
+	for _, v := range versions {
+		if v.IsLatest {
+			deleted = v.IsDeleteMarker
+		}
+	}
+	if !deleted {
+		return fmt.Errorf("%w: %s", ErrNotDeleted, key)
+	}
+	for _, v := range versions {
+		if !v.IsDeleteMarker {
+			return s.promoteVersion(ctx, key, v.VersionID)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNoVersionToRestore, key)
+}
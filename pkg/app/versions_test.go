@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// docVersionsXML lists doc.txt, deleted after two versions, and doc.txt.bak sharing its prefix.
+const docVersionsXML = `<ListVersionsResult><Name>bucket</Name><IsTruncated>false</IsTruncated>
+<Version><Key>doc.txt</Key><VersionId>v1</VersionId><IsLatest>false</IsLatest><LastModified>2026-01-01T10:00:00.000Z</LastModified><Size>3</Size></Version>
+<Version><Key>doc.txt</Key><VersionId>v2</VersionId><IsLatest>false</IsLatest><LastModified>2026-01-02T10:00:00.000Z</LastModified><Size>5</Size></Version>
+<Version><Key>doc.txt.bak</Key><VersionId>b1</VersionId><IsLatest>true</IsLatest><LastModified>2026-01-04T10:00:00.000Z</LastModified><Size>1</Size></Version>
+<DeleteMarker><Key>doc.txt</Key><VersionId>m1</VersionId><IsLatest>true</IsLatest><LastModified>2026-01-03T10:00:00.000Z</LastModified></DeleteMarker>
+</ListVersionsResult>`
+
+func TestListObjectVersions_ExactKeyNewestFirst(t *testing.T) {
+	app, fake := newOrganizeTestApp(t, map[string]string{})
+	fake.versionsXML = docVersionsXML
+
+	versions, err := app.s3svc.ListObjectVersions(context.Background(), "doc.txt")
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, "m1", versions[0].VersionID)
+	assert.True(t, versions[0].IsDeleteMarker)
+	assert.True(t, versions[0].IsLatest)
+	assert.Equal(t, "v2", versions[1].VersionID)
+	assert.Equal(t, int64(5), versions[1].Size)
+	assert.Equal(t, "v1", versions[2].VersionID)
+}
+
+func TestRestoreDeleted_PromotesNewestVersion(t *testing.T) {
+	app, fake := newOrganizeTestApp(t, map[string]string{"doc.txt": "hello"})
+	app.cfg.S3.EnableVersions = true
+	fake.versionsXML = docVersionsXML
+
+	rec := httptest.NewRecorder()
+	app.RestoreDeletedHandler(rec, organizeRequest("/versions/restore", url.Values{"key": {"doc.txt"}}))
+
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "/versions?key=doc.txt", rec.Header().Get("Location"))
+	assert.Equal(t, []string{"bucket/doc.txt?versionId=v2"}, fake.copySources)
+}
+
+func TestVersionHandlers_Validation(t *testing.T) {
+	app, fake := newOrganizeTestApp(t, map[string]string{"doc.txt": "hello"})
+
+	rec := httptest.NewRecorder()
+	app.PromoteVersionHandler(rec, organizeRequest("/versions/promote", url.Values{"key": {"doc.txt"}, "version": {"v1"}}))
+	assert.Contains(t, rec.Body.String(), ErrVersionsDisabled.Error())
+
+	app.cfg.S3.EnableVersions = true
+	rec = httptest.NewRecorder()
+	app.PromoteVersionHandler(rec, organizeRequest("/versions/promote", url.Values{"key": {"doc.txt"}}))
+	assert.Contains(t, rec.Body.String(), ErrMissingVersion.Error())
+
+	app.cfg.S3.Prefix = "docs/"
+	rec = httptest.NewRecorder()
+	app.PromoteVersionHandler(rec, organizeRequest("/versions/promote", url.Values{"key": {"doc.txt"}, "version": {"v1"}}))
+	assert.Contains(t, rec.Body.String(), ErrInvalidKey.Error())
+
+	assert.Empty(t, fake.copySources)
+}
@@ -0,0 +1,143 @@
+// Package archive streams ZIP and tar.gz archives without temporary files.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrUnsupportedFormat is returned for an unknown archive format.
+var ErrUnsupportedFormat = errors.New("unsupported archive format")
+
+// Format is an archive container format.
+type Format string
+
+// Supported archive formats.
+const (
+	FormatZip   Format = "zip"
+	FormatTarGz Format = "tar.gz"
+)
+
+// ParseFormat validates an archive format name; an empty name selects ZIP.
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case "", FormatZip:
+		return FormatZip, nil
+	case FormatTarGz, "tgz":
+		return FormatTarGz, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, name)
+}
+
+// Extension returns the file extension of the format, without the leading dot.
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	if f == FormatTarGz {
+		return "application/gzip"
+	}
+	return "application/zip"
+}
+
+// Writer adds files to an archive as they are streamed.
+type Writer interface {
+	// AddFile writes one file; size must be the exact number of bytes r yields.
+	AddFile(name string, size int64, modTime time.Time, r io.Reader) error
+	// Close finishes the archive (central directory, tar trailer, gzip footer).
+	Close() error
+}
+
+// NewWriter returns an archive writer of the given format streaming to w.
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatZip:
+		return &zipWriter{zw: zip.NewWriter(w)}, nil
+	case FormatTarGz:
+		gz := gzip.NewWriter(w)
+		return &tarGzWriter{gz: gz, tw: tar.NewWriter(gz)}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// zipWriter stores entries without compression: most bulk S3 content (media, archives,
+// parquet) is already compressed, and Store keeps CPU use flat. archive/zip switches to
+// ZIP64 records automatically for entries over 4 GiB or more than 65535 entries.
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipWriter) AddFile(name string, _ int64, modTime time.Time, r io.Reader) error {
+	hdr := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: modTime,
+	}
+
+	w, err := z.zw.CreateHeader(hdr)
+	if err != nil {
+		return fmt.Errorf("failed to create zip entry %s: %w", name, err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("failed to write zip entry %s: %w", name, err)
+	}
+	return nil
+}
+
+func (z *zipWriter) Close() error {
+	if err := z.zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish zip archive: %w", err)
+	}
+	return nil
+}
+
+type tarGzWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func (t *tarGzWriter) AddFile(name string, size int64, modTime time.Time, r io.Reader) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644, //nolint:mnd // rw-r--r--
+		ModTime:  modTime,
+		Format:   tar.FormatPAX, // long names and sizes over 8 GiB
+	}
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write tar header %s: %w", name, err)
+	}
+	if _, err := io.CopyN(t.tw, r, size); err != nil {
+		return fmt.Errorf("failed to write tar entry %s: %w", name, err)
+	}
+	return nil
+}
+
+func (t *tarGzWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish tar archive: %w", err)
+	}
+	if err := t.gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish gzip stream: %w", err)
+	}
+	return nil
+}
+
+// EntryName returns the archive path of key relative to base.
+// It never starts with "/" and never contains ".." segments, so extracting the
+// archive cannot write outside the target directory.
+func EntryName(key, base string) string {
+	name := strings.TrimPrefix(key, base)
+	name = path.Clean("/" + name) // resolves any ".." against the root
+	return strings.TrimPrefix(name, "/")
+}
@@ -0,0 +1,157 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testFiles = map[string]string{
+	"a.txt":         "hello",
+	"sub/b.csv":     "x,y\n1,2\n",
+	"sub/deep/c.md": "# title",
+}
+
+func writeArchive(t *testing.T, format Format) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	if err != nil {
+		t.Fatalf("NewWriter(%q) error: %v", format, err)
+	}
+	mod := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for name, content := range testFiles {
+		if err := w.AddFile(name, int64(len(content)), mod, strings.NewReader(content)); err != nil {
+			t.Fatalf("AddFile(%q) error: %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestZipRoundTrip(t *testing.T) {
+	data := writeArchive(t, FormatZip)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader error: %v", err)
+	}
+	if len(zr.File) != len(testFiles) {
+		t.Fatalf("got %d entries, want %d", len(zr.File), len(testFiles))
+	}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		got, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		if string(got) != testFiles[f.Name] {
+			t.Errorf("%s = %q, want %q", f.Name, got, testFiles[f.Name])
+		}
+	}
+}
+
+func TestTarGzRoundTrip(t *testing.T) {
+	data := writeArchive(t, FormatTarGz)
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip.NewReader error: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	count := 0
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar Next error: %v", err)
+		}
+		got, _ := io.ReadAll(tr)
+		if string(got) != testFiles[hdr.Name] {
+			t.Errorf("%s = %q, want %q", hdr.Name, got, testFiles[hdr.Name])
+		}
+		count++
+	}
+	if count != len(testFiles) {
+		t.Errorf("got %d entries, want %d", count, len(testFiles))
+	}
+}
+
+func TestTarGzShortReader(t *testing.T) {
+	w, _ := NewWriter(FormatTarGz, io.Discard)
+	err := w.AddFile("short.txt", 10, time.Now(), strings.NewReader("abc"))
+	if err == nil {
+		t.Error("expected an error when the reader yields fewer bytes than the declared size")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{"", FormatZip, false},
+		{"zip", FormatZip, false},
+		{"tar.gz", FormatTarGz, false},
+		{"tgz", FormatTarGz, false},
+		{"rar", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr && !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("ParseFormat(%q) error = %v, want ErrUnsupportedFormat", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEntryName(t *testing.T) {
+	tests := []struct {
+		key, base, want string
+	}{
+		{"photos/2024/a.jpg", "photos/", "2024/a.jpg"},
+		{"photos/a.jpg", "", "photos/a.jpg"},
+		{"other/a.jpg", "photos/", "other/a.jpg"},
+		{"photos/../../etc/passwd", "photos/", "etc/passwd"},
+		{"/abs/key", "", "abs/key"},
+		{"photos/./x//y.txt", "photos/", "x/y.txt"},
+	}
+	for _, tt := range tests {
+		if got := EntryName(tt.key, tt.base); got != tt.want {
+			t.Errorf("EntryName(%q, %q) = %q, want %q", tt.key, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestManifestString(t *testing.T) {
+	m := &Manifest{Source: "bucket/photos/", CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Included: 2, Bytes: 42}
+	m.Skip("photos/cold.jpg", "archived in GLACIER, not restored")
+
+	out := m.String()
+	for _, want := range []string{
+		"Source:   bucket/photos/",
+		"Included: 2 objects, 42 bytes",
+		"Skipped:  1 objects",
+		"photos/cold.jpg\tarchived in GLACIER, not restored",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("manifest missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package archive
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ManifestName is the name of the manifest entry appended to every archive.
+const ManifestName = "MANIFEST.txt"
+
+// Manifest records what went into an archive and what was left out.
+type Manifest struct {
+	Source    string
+	CreatedAt time.Time
+	Included  int
+	Bytes     int64
+	Skipped   []ManifestEntry
+}
+
+// ManifestEntry is an object left out of the archive and why.
+type ManifestEntry struct {
+	Key    string
+	Reason string
+}
+
+// Skip records an object that was not added to the archive.
+func (m *Manifest) Skip(key, reason string) {
+	m.Skipped = append(m.Skipped, ManifestEntry{Key: key, Reason: reason})
+}
+
+// String renders the manifest as plain text.
+func (m *Manifest) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "s3xplorer archive manifest\n")
+	fmt.Fprintf(&b, "Source:   %s\n", m.Source)
+	fmt.Fprintf(&b, "Created:  %s\n", m.CreatedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Included: %d objects, %d bytes\n", m.Included, m.Bytes)
+	fmt.Fprintf(&b, "Skipped:  %d objects\n", len(m.Skipped))
+	if len(m.Skipped) > 0 {
+		b.WriteString("\nSkipped objects:\n")
+		for _, e := range m.Skipped {
+			fmt.Fprintf(&b, "  %s\t%s\n", e.Key, e.Reason)
+		}
+	}
+	return b.String()
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -24,13 +26,78 @@ type S3Config struct {
 	Prefix           string `yaml:"prefix"`
 	RestoreDays      int    `yaml:"restore_days"`
 	EnableGlacierRestore bool `yaml:"enable_glacier_restore"`
+	// RestorePollSchedule is when pending restore requests are checked (cron with seconds, needs the database)
+	RestorePollSchedule string `yaml:"restore_poll_schedule"`
 	SkipBucketValidation bool `yaml:"skip_bucket_validation"`
 	EnableUpload     bool `yaml:"enable_upload"`
 	EnableDelete     bool `yaml:"enable_delete"`
+	EnableVersions   bool `yaml:"enable_versions"`
+	// EnableLifecycleEdit allows creating, editing and deleting the lifecycle rules of the bucket
+	EnableLifecycleEdit bool `yaml:"enable_lifecycle_edit"`
+	// StoragePrices is the monthly price of a GB per storage class, used to estimate storage class changes
+	StoragePrices map[string]float64 `yaml:"storage_prices"`
 	// Not serialized, but used to track whether bucket was explicitly set in config
 	BucketLocked     bool   `yaml:"-"`
 }
 
+// DefaultStoragePrices are the AWS us-east-1 prices of a GB-month per storage class, in USD.
+// Intelligent-Tiering is priced as its frequent access tier.
+var DefaultStoragePrices = map[string]float64{
+	"STANDARD":            0.023,
+	"INTELLIGENT_TIERING": 0.023,
+	"STANDARD_IA":         0.0125,
+	"ONEZONE_IA":          0.01,
+	"GLACIER_IR":          0.004,
+	"GLACIER":             0.0036,
+	"DEEP_ARCHIVE":        0.00099,
+	"REDUCED_REDUNDANCY":  0.024,
+}
+
+// DefaultConnection is the name of the connection described by the s3 section.
+const DefaultConnection = "default"
+
+// Connection returns the endpoint and credentials of the s3 section as the default connection.
+func (c S3Config) Connection() ConnectionConfig {
+	return ConnectionConfig{
+		Name:          DefaultConnection,
+		Endpoint:      c.Endpoint,
+		Region:        c.Region,
+		AccessKey:     c.AccessKey,
+		APIKey:        c.APIKey,
+		SsoAwsProfile: c.SsoAwsProfile,
+	}
+}
+
+// ConnectionConfig describes an additional S3 endpoint objects can be copied to.
+type ConnectionConfig struct {
+	Name          string `yaml:"name"`
+	Endpoint      string `yaml:"endpoint"`
+	Region        string `yaml:"region"`
+	AccessKey     string `yaml:"access_key"`
+	APIKey        string `yaml:"api_key"`
+	SsoAwsProfile string `yaml:"sso_aws_profile"`
+}
+
+// S3Config returns the connection as an s3 section, to build a client with the same code path.
+func (c ConnectionConfig) S3Config() S3Config {
+	return S3Config{
+		Endpoint:      c.Endpoint,
+		Region:        c.Region,
+		AccessKey:     c.AccessKey,
+		APIKey:        c.APIKey,
+		SsoAwsProfile: c.SsoAwsProfile,
+	}
+}
+
+// SameEndpoint reports whether both connections reach the same S3 service,
+// so objects can be copied between them server-side.
+func (c ConnectionConfig) SameEndpoint(other ConnectionConfig) bool {
+	normalize := func(endpoint string) string {
+		return strings.ToLower(strings.TrimRight(endpoint, "/"))
+	}
+	return normalize(c.Endpoint) == normalize(other.Endpoint) && c.Region == other.Region
+}
+
 // DatabaseConfig contains database-related configuration.
 type DatabaseConfig struct {
 	URL              string `yaml:"url"`
@@ -40,12 +107,51 @@ type DatabaseConfig struct {
 	ConnMaxIdleTime  string `yaml:"conn_max_idle_time"`
 }
 
+// Catalog database drivers, selected by the scheme of the database URL.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Driver returns the catalog database driver of the URL: DriverSQLite for sqlite: and sqlite3: URLs,
+// DriverPostgres otherwise.
+func (c DatabaseConfig) Driver() string {
+	scheme, _, _ := strings.Cut(c.URL, ":")
+	switch strings.ToLower(scheme) {
+	case "sqlite", "sqlite3":
+		return DriverSQLite
+	default:
+		return DriverPostgres
+	}
+}
+
 // ScanConfig contains scanning-related configuration.
 type ScanConfig struct {
 	EnableBackgroundScan bool   `yaml:"enable_background_scan"`
 	CronSchedule         string `yaml:"cron_schedule"`
 	EnableInitialScan    bool   `yaml:"enable_initial_scan"`
 	EnableDeletionSync   bool   `yaml:"enable_deletion_sync"`
+	// IndexTags adds a scan phase fetching the tags of files whose ETag changed since the last fetch
+	IndexTags bool `yaml:"index_tags"`
+	// TagRequestsPerSecond limits the S3 calls of the tag phase
+	TagRequestsPerSecond int `yaml:"tag_requests_per_second"`
+	// MetadataKeys are user metadata keys (without x-amz-meta-) indexed along with the tags;
+	// setting any adds a HeadObject per file to the tag phase
+	MetadataKeys []string `yaml:"metadata_keys"`
+	// Inventory reads the files of the buckets from their S3 Inventory reports instead of listing them
+	Inventory InventoryConfig `yaml:"inventory"`
+}
+
+// InventoryConfig locates the S3 Inventory reports read by the scanner.
+type InventoryConfig struct {
+	// Enable reads each bucket from its latest inventory report; buckets without one are still listed
+	Enable bool `yaml:"enable"`
+	// Bucket is the destination bucket of the inventory reports
+	Bucket string `yaml:"bucket"`
+	// Prefix is the destination prefix of the reports, which S3 writes under <prefix><bucket>/<id>/
+	Prefix string `yaml:"prefix"`
+	// ID is the name of the inventory configuration of the buckets
+	ID string `yaml:"id"`
 }
 
 // BucketSyncConfig contains bucket synchronization configuration.
@@ -56,13 +162,180 @@ type BucketSyncConfig struct {
 	MaxRetries      int    `yaml:"max_retries"`
 }
 
+// PreviewConfig contains file preview configuration.
+type PreviewConfig struct {
+	// MaxTextBytes is how much of a text, code, JSON or CSV file is fetched per preview (byte-range GET)
+	MaxTextBytes int64 `yaml:"max_text_bytes"`
+	// MaxInlineBytes is the largest image or PDF that is streamed inline; larger files must be downloaded
+	MaxInlineBytes int64 `yaml:"max_inline_bytes"`
+}
+
+// ArchiveConfig contains folder and multi-selection archive download configuration.
+type ArchiveConfig struct {
+	// MaxTotalBytes caps the summed size of the objects in one archive
+	MaxTotalBytes int64 `yaml:"max_total_bytes"`
+	// MaxObjects caps the number of objects in one archive
+	MaxObjects int `yaml:"max_objects"`
+}
+
+// UploadConfig contains upload configuration.
+type UploadConfig struct {
+	// PartSize is the multipart upload part size in bytes (S3 minimum 5 MB)
+	PartSize int64 `yaml:"part_size"`
+	// Concurrency is the number of parts sent to S3 in parallel for a streamed upload
+	Concurrency int `yaml:"concurrency"`
+	// MaxSize caps a single upload in bytes; 0 means no limit other than S3's own
+	MaxSize int64 `yaml:"max_size"`
+	// SessionTTL is how long an idle resumable upload is kept before it is aborted (Go duration)
+	SessionTTL string `yaml:"session_ttl"`
+}
+
+// ServerConfig contains the web server configuration.
+type ServerConfig struct {
+	// UserHeader is the request header, set by an authenticating reverse proxy, naming the current user.
+	// It attributes shares, deletions, restores and lifecycle edits.
+	UserHeader string `yaml:"user_header"`
+}
+
+// ShareConfig contains share link configuration.
+type ShareConfig struct {
+	Enable bool `yaml:"enable"`
+	// MaxExpiry is the longest lifetime a user may choose for a share link (Go duration)
+	MaxExpiry string `yaml:"max_expiry"`
+	// DefaultExpiry is the lifetime preselected in the share form (Go duration)
+	DefaultExpiry string `yaml:"default_expiry"`
+	// EnableProxy allows /s/{token} links that can be revoked early and count downloads
+	EnableProxy bool `yaml:"enable_proxy"`
+}
+
+// TrashConfig contains soft-delete configuration.
+type TrashConfig struct {
+	// Enable moves deleted objects to the trash instead of deleting them (requires the database)
+	Enable bool `yaml:"enable"`
+	// Bucket receives the trashed copies; empty keeps them in the bucket they were deleted from
+	Bucket string `yaml:"bucket"`
+	// Prefix is the folder trashed copies are stored under
+	Prefix string `yaml:"prefix"`
+	// RetentionDays is how long trashed objects are kept before the scheduled purge removes them
+	RetentionDays int `yaml:"retention_days"`
+	// PurgeSchedule is the cron schedule of the purge of expired trash items
+	PurgeSchedule string `yaml:"purge_schedule"`
+}
+
+// Retention returns how long trashed objects are kept.
+func (c TrashConfig) Retention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// ChangesConfig contains the change history configuration.
+type ChangesConfig struct {
+	// RetentionDays is how long the changes found by scans are kept before the scheduled purge removes them
+	RetentionDays int `yaml:"retention_days"`
+	// PurgeSchedule is the cron schedule of the purge of expired changes
+	PurgeSchedule string `yaml:"purge_schedule"`
+}
+
+// Retention returns how long recorded changes are kept.
+func (c ChangesConfig) Retention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// DuplicatesConfig contains the duplicates report configuration.
+type DuplicatesConfig struct {
+	// Enable computes the report of the files sharing an ETag and a size on schedule (requires the database)
+	Enable bool `yaml:"enable"`
+	// Schedule is the cron schedule of the duplicates report
+	Schedule string `yaml:"schedule"`
+	// MinSize ignores smaller files, so empty files are not reported by default
+	MinSize int64 `yaml:"min_size"`
+}
+
+// ExportConfig contains the scheduled catalog export configuration.
+type ExportConfig struct {
+	// Enable writes an export of the catalog of every bucket to S3 on schedule (requires the database)
+	Enable bool `yaml:"enable"`
+	// Schedule is the cron schedule of the catalog export
+	Schedule string `yaml:"schedule"`
+	// Format is the format of the exported files: csv, ndjson or parquet
+	Format string `yaml:"format"`
+	// Bucket receives the exports; empty means the browsed bucket
+	Bucket string `yaml:"bucket"`
+	// Prefix is the folder of the exports, one file per bucket and run under <prefix><bucket>/
+	Prefix string `yaml:"prefix"`
+}
+
+// Share link expiry fallbacks, used when the configured value is missing or invalid.
+const (
+	defaultShareMaxExpiry     = 7 * 24 * time.Hour // SigV4 presigned URL limit
+	defaultShareDefaultExpiry = 24 * time.Hour
+)
+
+// MaxExpiryDuration returns the parsed MaxExpiry.
+func (c ShareConfig) MaxExpiryDuration() time.Duration {
+	d, err := time.ParseDuration(c.MaxExpiry)
+	if err != nil || d <= 0 {
+		return defaultShareMaxExpiry
+	}
+	return d
+}
+
+// DefaultExpiryDuration returns the parsed DefaultExpiry, never longer than MaxExpiryDuration.
+func (c ShareConfig) DefaultExpiryDuration() time.Duration {
+	d, err := time.ParseDuration(c.DefaultExpiry)
+	if err != nil || d <= 0 {
+		d = defaultShareDefaultExpiry
+	}
+	return min(d, c.MaxExpiryDuration())
+}
+
 // Config is the struct for the configuration.
 type Config struct {
 	S3         S3Config         `yaml:"s3"`
+	Server     ServerConfig     `yaml:"server"`
 	Database   DatabaseConfig   `yaml:"database"`
 	Scan       ScanConfig       `yaml:"scan"`
 	BucketSync BucketSyncConfig `yaml:"bucket_sync"`
-	LogLevel   string           `yaml:"log_level"`
+	Preview    PreviewConfig    `yaml:"preview"`
+	Archive    ArchiveConfig    `yaml:"archive"`
+	Share      ShareConfig      `yaml:"share"`
+	Upload     UploadConfig     `yaml:"upload"`
+	Trash      TrashConfig      `yaml:"trash"`
+	Changes    ChangesConfig    `yaml:"changes"`
+	Duplicates DuplicatesConfig `yaml:"duplicates"`
+	Export     ExportConfig     `yaml:"export"`
+	// Connections are extra S3 endpoints that objects can be copied to
+	Connections []ConnectionConfig `yaml:"connections"`
+	LogLevel    string             `yaml:"log_level"`
+}
+
+// ExportBucket returns the bucket receiving the scheduled exports.
+func (c Config) ExportBucket() string {
+	if c.Export.Bucket != "" {
+		return c.Export.Bucket
+	}
+	return c.S3.Bucket
+}
+
+// ExportPrefix returns the folder of bucket holding the scheduled exports, which scans and exports leave
+// out so that the exports are not cataloged then exported again, or "" when bucket does not receive them.
+func (c Config) ExportPrefix(bucket string) string {
+	if !c.Export.Enable || bucket != c.ExportBucket() {
+		return ""
+	}
+	return c.Export.Prefix
+}
+
+// ConnectionByName returns the named connection; "" and DefaultConnection name the s3 section.
+func (c Config) ConnectionByName(name string) (ConnectionConfig, bool) {
+	if name == "" || name == DefaultConnection {
+		return c.S3.Connection(), true
+	}
+	for _, conn := range c.Connections {
+		if conn.Name == name {
+			return conn, true
+		}
+	}
+	return ConnectionConfig{}, false
 }
 
 // ReadYamlCnxFile reads a yaml file and returns a Config struct.
@@ -108,6 +381,24 @@ func (c *Config) setDefaults() {
 	if c.Scan.CronSchedule == "" {
 		c.Scan.CronSchedule = "0 0 2 * * *" // Daily at 2 AM (with seconds field)
 	}
+	if c.S3.RestorePollSchedule == "" {
+		c.S3.RestorePollSchedule = "0 */5 * * * *" // Every 5 minutes
+	}
+	if c.S3.StoragePrices == nil {
+		c.S3.StoragePrices = map[string]float64{}
+	}
+	for class, price := range DefaultStoragePrices {
+		if _, ok := c.S3.StoragePrices[class]; !ok {
+			c.S3.StoragePrices[class] = price
+		}
+	}
+	if c.Scan.TagRequestsPerSecond <= 0 {
+		c.Scan.TagRequestsPerSecond = 10
+	}
+	for i, key := range c.Scan.MetadataKeys {
+		// S3 returns user metadata keys lowercased
+		c.Scan.MetadataKeys[i] = strings.TrimPrefix(strings.ToLower(key), "x-amz-meta-")
+	}
 	
 	// Set default database URL
 	if c.Database.URL == "" {
@@ -128,6 +419,11 @@ func (c *Config) setDefaults() {
 		c.Database.ConnMaxIdleTime = "1m"
 	}
 
+	// S3 writes inventory reports under the destination prefix followed by a slash
+	if c.Scan.Inventory.Prefix != "" && !strings.HasSuffix(c.Scan.Inventory.Prefix, "/") {
+		c.Scan.Inventory.Prefix += "/"
+	}
+
 	// Set default bucket sync configuration
 	if c.BucketSync.SyncThreshold == "" {
 		c.BucketSync.SyncThreshold = "24h" // Mark as inaccessible after 24 hours
@@ -138,4 +434,86 @@ func (c *Config) setDefaults() {
 	if c.BucketSync.MaxRetries == 0 {
 		c.BucketSync.MaxRetries = 3 // Default to 3 retries for bucket access checks
 	}
+
+	// Set default preview limits
+	if c.Preview.MaxTextBytes <= 0 {
+		c.Preview.MaxTextBytes = 256 * 1024 // 256 KB of text per preview
+	}
+	if c.Preview.MaxInlineBytes <= 0 {
+		c.Preview.MaxInlineBytes = 50 * 1024 * 1024 // 50 MB for inline images and PDFs
+	}
+
+	// Set default archive download limits
+	if c.Archive.MaxTotalBytes <= 0 {
+		c.Archive.MaxTotalBytes = 10 * 1024 * 1024 * 1024 // 10 GB per archive
+	}
+	if c.Archive.MaxObjects <= 0 {
+		c.Archive.MaxObjects = 10000
+	}
+
+	// Set default upload settings
+	if c.Upload.PartSize <= 0 {
+		c.Upload.PartSize = 16 * 1024 * 1024 // 16 MB parts, up to ~156 GB per streamed upload
+	}
+	if c.Upload.Concurrency <= 0 {
+		c.Upload.Concurrency = 4
+	}
+	if c.Upload.SessionTTL == "" {
+		c.Upload.SessionTTL = "24h"
+	}
+
+	// Set default server settings
+	if c.Server.UserHeader == "" {
+		c.Server.UserHeader = "X-Forwarded-User"
+	}
+
+	// Set default share link settings
+	if c.Share.MaxExpiry == "" {
+		c.Share.MaxExpiry = "168h" // 7 days, the longest a presigned URL can live
+	}
+	if c.Share.DefaultExpiry == "" {
+		c.Share.DefaultExpiry = "24h"
+	}
+
+	// Set default trash settings
+	if c.Trash.Prefix == "" {
+		c.Trash.Prefix = ".trash/"
+	} else if !strings.HasSuffix(c.Trash.Prefix, "/") {
+		c.Trash.Prefix += "/"
+	}
+	if c.Trash.RetentionDays <= 0 {
+		c.Trash.RetentionDays = 30
+	}
+	if c.Trash.PurgeSchedule == "" {
+		c.Trash.PurgeSchedule = "0 0 3 * * *" // Daily at 3 AM (with seconds field)
+	}
+
+	// Set default change history settings
+	if c.Changes.RetentionDays <= 0 {
+		c.Changes.RetentionDays = 90
+	}
+	if c.Changes.PurgeSchedule == "" {
+		c.Changes.PurgeSchedule = "0 30 3 * * *" // Daily at 3:30 AM (with seconds field)
+	}
+
+	// Set default duplicates report settings
+	if c.Duplicates.Schedule == "" {
+		c.Duplicates.Schedule = "0 0 4 * * *" // Daily at 4 AM (with seconds field)
+	}
+	if c.Duplicates.MinSize <= 0 {
+		c.Duplicates.MinSize = 1
+	}
+
+	// Set default catalog export settings
+	if c.Export.Schedule == "" {
+		c.Export.Schedule = "0 0 5 * * *" // Daily at 5 AM (with seconds field)
+	}
+	if c.Export.Format == "" {
+		c.Export.Format = "parquet"
+	}
+	if c.Export.Prefix == "" {
+		c.Export.Prefix = "s3xplorer-exports/"
+	} else if !strings.HasSuffix(c.Export.Prefix, "/") {
+		c.Export.Prefix += "/"
+	}
 }
@@ -17,11 +17,30 @@ import (
 	_ "github.com/lib/pq"                                 // PostgreSQL driver
 
 	configapp "github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dbsqlite"
 )
 
-//go:embed migrations
+//go:embed migrations migrations_sqlite
 var migrations embed.FS
 
+// migrationsDir returns the directory of the embedded migrations of a database driver: SQLite
+// databases have their own variant of each PostgreSQL migration, with the same version.
+func migrationsDir(driver string) string {
+	if driver == configapp.DriverSQLite {
+		return "migrations_sqlite"
+	}
+	return "migrations"
+}
+
+// NewQuerier returns the catalog queries of a database driver on db, a connection or a transaction.
+func NewQuerier(driver string, db database.DBTX) database.Querier {
+	if driver == configapp.DriverSQLite {
+		return dbsqlite.New(db)
+	}
+	return database.New(db)
+}
+
 // InitializeDatabase initializes the database using embedded migrations.
 func InitializeDatabase(ctx context.Context, dbConfig configapp.DatabaseConfig, logger *slog.Logger) (*sql.DB, error) {
 	const (
@@ -85,10 +104,12 @@ func initializeDatabaseAttempt(
 		return nil, fmt.Errorf("invalid database URL: %w", err)
 	}
 
-	logger.Debug("Attempting database initialization", slog.String("host", parsedURL.Host))
+	logger.Debug("Attempting database initialization",
+		slog.String("driver", dbConfig.Driver()),
+		slog.String("host", parsedURL.Host))
 
 	// Set up and run migrations
-	err = setupAndRunMigrations(parsedURL, logger)
+	err = setupAndRunMigrations(parsedURL, migrationsDir(dbConfig.Driver()), logger)
 	if err != nil {
 		return nil, err
 	}
@@ -99,8 +120,8 @@ func initializeDatabaseAttempt(
 	return openAndTestConnection(ctx, dbConfig, logger)
 }
 
-// setupAndRunMigrations configures dbmate and runs migrations.
-func setupAndRunMigrations(parsedURL *url.URL, logger *slog.Logger) error {
+// setupAndRunMigrations configures dbmate and runs the migrations of dir.
+func setupAndRunMigrations(parsedURL *url.URL, dir string, logger *slog.Logger) error {
 	// Create dbmate instance
 	db := dbmate.New(parsedURL)
 	db.AutoDumpSchema = false
@@ -108,14 +129,14 @@ func setupAndRunMigrations(parsedURL *url.URL, logger *slog.Logger) error {
 	db.SchemaFile = "schema.sql"
 
 	// Set up embedded filesystem for migrations
-	migrationFS, err := fs.Sub(migrations, "migrations")
+	migrationFS, err := fs.Sub(migrations, dir)
 	if err != nil {
 		return fmt.Errorf("failed to create migration filesystem: %w", err)
 	}
 	db.FS = migrationFS
 
 	// Log available migrations
-	err = logMigrations(logger)
+	err = logMigrations(dir, logger)
 	if err != nil {
 		return err
 	}
@@ -130,9 +151,9 @@ func setupAndRunMigrations(parsedURL *url.URL, logger *slog.Logger) error {
 	return nil
 }
 
-// logMigrations logs the available migration files.
-func logMigrations(logger *slog.Logger) error {
-	migrationFiles, err := fs.ReadDir(migrations, "migrations")
+// logMigrations logs the available migration files of dir.
+func logMigrations(dir string, logger *slog.Logger) error {
+	migrationFiles, err := fs.ReadDir(migrations, dir)
 	if err != nil {
 		return fmt.Errorf("failed to read migration files: %w", err)
 	}
@@ -150,7 +171,16 @@ func logMigrations(logger *slog.Logger) error {
 func openAndTestConnection(
 	ctx context.Context, dbConfig configapp.DatabaseConfig, logger *slog.Logger,
 ) (*sql.DB, error) {
-	sqlDB, err := sql.Open("postgres", dbConfig.URL)
+	driverName, dataSource := "postgres", dbConfig.URL
+	if dbConfig.Driver() == configapp.DriverSQLite {
+		parsedURL, err := url.Parse(dbConfig.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid database URL: %w", err)
+		}
+		driverName, dataSource = "sqlite", sqliteDSN(parsedURL)
+	}
+
+	sqlDB, err := sql.Open(driverName, dataSource)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
@@ -205,10 +235,10 @@ func MigrateDatabase(databaseURL string, logger *slog.Logger) error {
 	// Create dbmate instance
 	db := dbmate.New(parsedURL)
 	db.AutoDumpSchema = false
-	db.MigrationsDir = []string{"migrations"}
+	db.MigrationsDir = []string{"."}
 
 	// Set up embedded filesystem for migrations
-	migrationFS, err := fs.Sub(migrations, "migrations")
+	migrationFS, err := fs.Sub(migrations, migrationsDir(configapp.DatabaseConfig{URL: databaseURL}.Driver()))
 	if err != nil {
 		return fmt.Errorf("failed to create migration filesystem: %w", err)
 	}
@@ -24,8 +24,8 @@ func TestEmbeddedMigrations(t *testing.T) {
 		}
 	}
 
-	// We should have exactly 8 migration files
-	assert.Equal(t, 8, sqlFiles, "Should have exactly 8 SQL migration files embedded")
+	// We should have exactly 10 migration files
+	assert.Equal(t, 22, sqlFiles, "Should have exactly 22 SQL migration files embedded")
 
 	// Check for specific expected migrations
 	expectedMigrations := []string{
@@ -37,6 +37,20 @@ func TestEmbeddedMigrations(t *testing.T) {
 		"20250703000003_allow_global_scan_jobs.sql",
 		"20250704000001_add_composite_indexes.sql",
 		"20251230000001_add_keyset_pagination_index.sql",
+		"20261018000001_add_trigram_search.sql",
+		"20261018000002_add_sort_indexes.sql",
+		"20261018000003_create_shares.sql",
+		"20261018000004_create_upload_sessions.sql",
+		"20261018000005_create_jobs.sql",
+		"20261018000006_add_copy_job_columns.sql",
+		"20261018000007_create_trash_items.sql",
+		"20261018000008_create_object_tags.sql",
+		"20261018000009_create_restore_requests.sql",
+		"20261018000010_create_bucket_configs.sql",
+		"20261018000011_create_duplicate_groups.sql",
+		"20261018000012_create_object_changes.sql",
+		"20261018000013_add_inventory_columns.sql",
+		"20261018000014_add_key_prefix_index.sql",
 	}
 
 	for _, expected := range expectedMigrations {
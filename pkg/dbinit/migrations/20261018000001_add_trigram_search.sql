@@ -0,0 +1,43 @@
+-- migrate:up
+-- Trigram search support for fast substring matching on object keys.
+-- ILIKE '%...%' cannot use the btree indexes from the composite index migrations,
+-- so search degrades to a sequential scan on large catalogs.
+
+-- Derived basename column (last path segment, without the trailing slash for folders)
+-- Used to rank results whose file or folder name matches ahead of path-only matches.
+-- Note: adding a STORED generated column rewrites the table; on very large catalogs
+-- run this migration during a maintenance window.
+ALTER TABLE s3_objects
+  ADD COLUMN basename VARCHAR(1024)
+  GENERATED ALWAYS AS (substring(rtrim(key, '/') from '[^/]*$')) STORED;
+
+-- Enable pg_trgm when the database user is allowed to.
+-- Managed databases may refuse CREATE EXTENSION; the application then falls back
+-- to plain ILIKE search without trigram indexes.
+DO $$
+BEGIN
+  CREATE EXTENSION IF NOT EXISTS pg_trgm;
+EXCEPTION
+  WHEN insufficient_privilege OR undefined_file OR feature_not_supported THEN
+    RAISE NOTICE 'pg_trgm extension not available, trigram search indexes skipped';
+END
+$$;
+
+-- GIN trigram indexes (only created when pg_trgm is installed)
+-- Benefits: SearchS3Objects, SearchS3ObjectsRanked, GetCursorForSearchS3Objects
+-- gin_trgm_ops supports LIKE and ILIKE with leading wildcards
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm') THEN
+    CREATE INDEX IF NOT EXISTS idx_s3_objects_key_trgm
+      ON s3_objects USING GIN (key gin_trgm_ops);
+    CREATE INDEX IF NOT EXISTS idx_s3_objects_basename_trgm
+      ON s3_objects USING GIN (basename gin_trgm_ops);
+  END IF;
+END
+$$;
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_s3_objects_basename_trgm;
+DROP INDEX IF EXISTS idx_s3_objects_key_trgm;
+ALTER TABLE s3_objects DROP COLUMN IF EXISTS basename;
@@ -0,0 +1,24 @@
+-- migrate:up
+-- Indexes for sortable directory listings (GetDirectChildrenSorted)
+-- Folders and files are listed as separate segments, so is_folder is an equality
+-- filter and each index can be scanned forward (asc) or backward (desc).
+-- Name sorting uses idx_s3_objects_keyset (bucket_id, prefix, is_folder, key).
+-- The expressions must match the ORDER BY of db/queries/sorted_children.sql exactly.
+-- Note: CONCURRENTLY removed to allow running inside migration transaction
+
+-- Size sort ("largest first")
+CREATE INDEX IF NOT EXISTS idx_s3_objects_sort_size
+  ON s3_objects (bucket_id, prefix, is_folder, size, key);
+
+-- Last modified sort ("newest first"); NULL dates sort as the epoch
+CREATE INDEX IF NOT EXISTS idx_s3_objects_sort_date
+  ON s3_objects (bucket_id, prefix, is_folder, (COALESCE(last_modified, '1970-01-01 00:00:00+00'::timestamptz)), key);
+
+-- Storage class sort; NULL storage class sorts as empty string
+CREATE INDEX IF NOT EXISTS idx_s3_objects_sort_storage
+  ON s3_objects (bucket_id, prefix, is_folder, (COALESCE(storage_class, '')), key);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_s3_objects_sort_storage;
+DROP INDEX IF EXISTS idx_s3_objects_sort_date;
+DROP INDEX IF EXISTS idx_s3_objects_sort_size;
@@ -0,0 +1,26 @@
+-- migrate:up
+-- Long-running operations started from the UI (folder moves, copies, ...).
+-- Workers update the counters as they go so /jobs/{id} can report progress.
+CREATE TABLE jobs (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,               -- move, copy, ...
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, running, completed, failed
+    bucket_name VARCHAR(255) NOT NULL,
+    source VARCHAR(1024) NOT NULL DEFAULT '',
+    destination VARCHAR(1024) NOT NULL DEFAULT '',
+    total_items INTEGER NOT NULL DEFAULT 0,
+    done_items INTEGER NOT NULL DEFAULT 0,
+    failed_items INTEGER NOT NULL DEFAULT 0,
+    total_bytes BIGINT NOT NULL DEFAULT 0,
+    done_bytes BIGINT NOT NULL DEFAULT 0,
+    error_message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_jobs_status ON jobs(status);
+
+-- migrate:down
+DROP TABLE IF EXISTS jobs;
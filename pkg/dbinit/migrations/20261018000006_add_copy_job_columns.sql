@@ -0,0 +1,17 @@
+-- migrate:up
+-- Cross-bucket copies: the target can be another bucket on another connection,
+-- existing objects may be skipped, and a dry run only reports what would be copied.
+ALTER TABLE jobs
+  ADD COLUMN target_connection VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN target_bucket VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN options TEXT NOT NULL DEFAULT '',
+  ADD COLUMN dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN skipped_items INTEGER NOT NULL DEFAULT 0;
+
+-- migrate:down
+ALTER TABLE jobs
+  DROP COLUMN IF EXISTS skipped_items,
+  DROP COLUMN IF EXISTS dry_run,
+  DROP COLUMN IF EXISTS options,
+  DROP COLUMN IF EXISTS target_bucket,
+  DROP COLUMN IF EXISTS target_connection;
@@ -0,0 +1,20 @@
+-- migrate:up
+-- Tags and indexed user metadata of objects, filled by the scanner tag phase.
+-- source is 'tag' for S3 object tags and 'metadata' for x-amz-meta-* headers.
+CREATE TABLE object_tags (
+    object_id INTEGER NOT NULL REFERENCES s3_objects(id) ON DELETE CASCADE,
+    source VARCHAR(10) NOT NULL DEFAULT 'tag',
+    key TEXT NOT NULL,
+    value TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (object_id, source, key)
+);
+
+-- Tag filters and per-tag grouping
+CREATE INDEX idx_object_tags_key_value ON object_tags(source, key, value);
+
+-- ETag of the object when its tags were last fetched; tags are re-fetched when it differs from etag
+ALTER TABLE s3_objects ADD COLUMN tags_etag VARCHAR(255);
+
+-- migrate:down
+ALTER TABLE s3_objects DROP COLUMN IF EXISTS tags_etag;
+DROP TABLE IF EXISTS object_tags;
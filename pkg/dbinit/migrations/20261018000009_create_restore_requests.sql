@@ -0,0 +1,27 @@
+-- migrate:up
+-- Restores of archived objects. A background poller moves pending requests to
+-- restored (with the expiry of the restored copy), expired or failed; requesters
+-- are notified once of their restored objects.
+CREATE TABLE restore_requests (
+    id SERIAL PRIMARY KEY,
+    bucket_name VARCHAR(255) NOT NULL,
+    key VARCHAR(1024) NOT NULL,
+    tier VARCHAR(20) NOT NULL,
+    days INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, restored, expired, failed
+    requested_by VARCHAR(255) NOT NULL,
+    error_message TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    notified BOOLEAN NOT NULL DEFAULT FALSE,
+    requested_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    checked_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Poller lookup of pending and restored requests
+CREATE INDEX idx_restore_requests_status ON restore_requests(status);
+-- Restores page ordering
+CREATE INDEX idx_restore_requests_requested_at ON restore_requests(requested_at);
+
+-- migrate:down
+DROP TABLE IF EXISTS restore_requests;
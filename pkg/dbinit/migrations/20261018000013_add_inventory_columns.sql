@@ -0,0 +1,8 @@
+-- migrate:up
+-- Object attributes only known from S3 Inventory reports; NULL for files found by listing.
+ALTER TABLE s3_objects ADD COLUMN encryption_status VARCHAR(50);
+ALTER TABLE s3_objects ADD COLUMN tag_count INTEGER;
+
+-- migrate:down
+ALTER TABLE s3_objects DROP COLUMN IF EXISTS tag_count;
+ALTER TABLE s3_objects DROP COLUMN IF EXISTS encryption_status;
@@ -0,0 +1,10 @@
+-- migrate:up
+-- Index for the objects under a folder (key LIKE 'folder/%'): folder summaries, moves and deletes.
+-- UNIQUE(bucket_id, key) follows the database collation, which LIKE can only use with the "C"
+-- collation; text_pattern_ops compares bytes, so the folder becomes a range of this index.
+-- Note: CONCURRENTLY removed to allow running inside migration transaction
+CREATE INDEX IF NOT EXISTS idx_s3_objects_key_prefix
+  ON s3_objects (bucket_id, key text_pattern_ops);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_s3_objects_key_prefix;
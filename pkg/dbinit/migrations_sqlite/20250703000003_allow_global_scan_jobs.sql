@@ -0,0 +1,61 @@
+-- migrate:up
+-- Allow global scan jobs by making bucket_id nullable
+-- Global scan jobs (multi-bucket scans) will have bucket_id = NULL
+-- SQLite cannot drop a NOT NULL constraint, so the table is rebuilt
+CREATE TABLE scan_jobs_new (
+    id INTEGER PRIMARY KEY,
+    bucket_id INTEGER REFERENCES buckets(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, running, completed, failed
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    objects_scanned INTEGER DEFAULT 0,
+    error_message TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    objects_deleted INTEGER DEFAULT 0,
+    objects_updated INTEGER DEFAULT 0,
+    objects_created INTEGER DEFAULT 0,
+    buckets_validated INTEGER DEFAULT 0,
+    buckets_marked_inaccessible INTEGER DEFAULT 0,
+    buckets_cleaned_up INTEGER DEFAULT 0,
+    bucket_validation_errors INTEGER DEFAULT 0
+);
+INSERT INTO scan_jobs_new SELECT
+    id, bucket_id, status, started_at, completed_at, objects_scanned, error_message, created_at, updated_at,
+    objects_deleted, objects_updated, objects_created,
+    buckets_validated, buckets_marked_inaccessible, buckets_cleaned_up, bucket_validation_errors
+FROM scan_jobs;
+DROP TABLE scan_jobs;
+ALTER TABLE scan_jobs_new RENAME TO scan_jobs;
+CREATE INDEX idx_scan_jobs_bucket_id ON scan_jobs(bucket_id);
+CREATE INDEX idx_scan_jobs_status ON scan_jobs(status);
+
+-- migrate:down
+-- Restore NOT NULL constraint (this will fail if there are global scan jobs)
+CREATE TABLE scan_jobs_new (
+    id INTEGER PRIMARY KEY,
+    bucket_id INTEGER NOT NULL REFERENCES buckets(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, running, completed, failed
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    objects_scanned INTEGER DEFAULT 0,
+    error_message TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    objects_deleted INTEGER DEFAULT 0,
+    objects_updated INTEGER DEFAULT 0,
+    objects_created INTEGER DEFAULT 0,
+    buckets_validated INTEGER DEFAULT 0,
+    buckets_marked_inaccessible INTEGER DEFAULT 0,
+    buckets_cleaned_up INTEGER DEFAULT 0,
+    bucket_validation_errors INTEGER DEFAULT 0
+);
+INSERT INTO scan_jobs_new SELECT
+    id, bucket_id, status, started_at, completed_at, objects_scanned, error_message, created_at, updated_at,
+    objects_deleted, objects_updated, objects_created,
+    buckets_validated, buckets_marked_inaccessible, buckets_cleaned_up, bucket_validation_errors
+FROM scan_jobs;
+DROP TABLE scan_jobs;
+ALTER TABLE scan_jobs_new RENAME TO scan_jobs;
+CREATE INDEX idx_scan_jobs_bucket_id ON scan_jobs(bucket_id);
+CREATE INDEX idx_scan_jobs_status ON scan_jobs(status);
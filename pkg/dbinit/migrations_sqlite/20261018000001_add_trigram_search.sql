@@ -0,0 +1,14 @@
+-- migrate:up
+-- Derived basename column (last path segment, without the trailing slash for folders)
+-- Used to rank results whose file or folder name matches ahead of path-only matches.
+-- SQLite can only add VIRTUAL generated columns and has no regular expressions: rtrim() with
+-- every character but '/' strips the basename, leaving the parent path to skip.
+-- There is no pg_trgm here, so search scans the catalog with LIKE.
+ALTER TABLE s3_objects
+  ADD COLUMN basename VARCHAR(1024)
+  GENERATED ALWAYS AS (
+    substr(rtrim(key, '/'), length(rtrim(rtrim(key, '/'), replace(rtrim(key, '/'), '/', ''))) + 1)
+  ) VIRTUAL;
+
+-- migrate:down
+ALTER TABLE s3_objects DROP COLUMN basename;
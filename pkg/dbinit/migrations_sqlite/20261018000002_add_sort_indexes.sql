@@ -0,0 +1,22 @@
+-- migrate:up
+-- Indexes for sortable directory listings (GetDirectChildrenSorted)
+-- Folders and files are listed as separate segments, so is_folder is an equality
+-- filter and each index can be scanned forward (asc) or backward (desc).
+-- Name sorting uses idx_s3_objects_keyset (bucket_id, prefix, is_folder, key).
+
+-- Size sort ("largest first")
+CREATE INDEX IF NOT EXISTS idx_s3_objects_sort_size
+  ON s3_objects (bucket_id, prefix, is_folder, size, key);
+
+-- Last modified sort ("newest first"); NULL dates sort as the epoch
+CREATE INDEX IF NOT EXISTS idx_s3_objects_sort_date
+  ON s3_objects (bucket_id, prefix, is_folder, (COALESCE(last_modified, '1970-01-01 00:00:00+00:00')), key);
+
+-- Storage class sort; NULL storage class sorts as empty string
+CREATE INDEX IF NOT EXISTS idx_s3_objects_sort_storage
+  ON s3_objects (bucket_id, prefix, is_folder, (COALESCE(storage_class, '')), key);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_s3_objects_sort_storage;
+DROP INDEX IF EXISTS idx_s3_objects_sort_date;
+DROP INDEX IF EXISTS idx_s3_objects_sort_size;
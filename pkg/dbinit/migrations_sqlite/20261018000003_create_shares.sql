@@ -0,0 +1,25 @@
+-- migrate:up
+-- Share links created from the "Share" action.
+-- Presigned shares store the signed S3 URL; proxied shares are served through
+-- /s/{token} so they can be revoked before expiry and count their downloads.
+CREATE TABLE shares (
+    id INTEGER PRIMARY KEY,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    bucket_name VARCHAR(255) NOT NULL,
+    key VARCHAR(1024) NOT NULL,
+    creator VARCHAR(255) NOT NULL,
+    proxied BOOLEAN NOT NULL DEFAULT FALSE,
+    url TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    revoked_at TIMESTAMP,
+    download_count INTEGER NOT NULL DEFAULT 0,
+    last_downloaded_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- "My shares" lists a creator's shares, newest first
+CREATE INDEX idx_shares_creator_created ON shares(creator, created_at DESC);
+
+-- migrate:down
+DROP TABLE IF EXISTS shares;
@@ -0,0 +1,32 @@
+-- migrate:up
+-- Resumable browser uploads: one S3 multipart upload per session.
+-- Parts are recorded as they succeed so an interrupted upload can resume
+-- with the missing parts only, and idle sessions can be aborted at S3.
+CREATE TABLE upload_sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    bucket_name VARCHAR(255) NOT NULL,
+    key VARCHAR(1024) NOT NULL,
+    upload_id TEXT NOT NULL,
+    content_type VARCHAR(255) NOT NULL DEFAULT 'application/octet-stream',
+    size BIGINT NOT NULL,
+    part_size BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active', -- active, completed, aborted
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE upload_parts (
+    session_id VARCHAR(64) NOT NULL REFERENCES upload_sessions(id) ON DELETE CASCADE,
+    part_number INTEGER NOT NULL,
+    etag VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (session_id, part_number)
+);
+
+-- Janitor lookup of idle active sessions
+CREATE INDEX idx_upload_sessions_status_updated ON upload_sessions(status, updated_at);
+
+-- migrate:down
+DROP TABLE IF EXISTS upload_parts;
+DROP TABLE IF EXISTS upload_sessions;
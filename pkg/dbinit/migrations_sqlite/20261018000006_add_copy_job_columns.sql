@@ -0,0 +1,15 @@
+-- migrate:up
+-- Cross-bucket copies: the target can be another bucket on another connection,
+-- existing objects may be skipped, and a dry run only reports what would be copied.
+ALTER TABLE jobs ADD COLUMN target_connection VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN target_bucket VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN options TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN dry_run BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE jobs ADD COLUMN skipped_items INTEGER NOT NULL DEFAULT 0;
+
+-- migrate:down
+ALTER TABLE jobs DROP COLUMN skipped_items;
+ALTER TABLE jobs DROP COLUMN dry_run;
+ALTER TABLE jobs DROP COLUMN options;
+ALTER TABLE jobs DROP COLUMN target_bucket;
+ALTER TABLE jobs DROP COLUMN target_connection;
@@ -0,0 +1,22 @@
+-- migrate:up
+-- Objects deleted while the trash is enabled. Unversioned buckets keep a copy
+-- under the trash prefix (trash_bucket/trash_key); versioned buckets keep the
+-- deleted version behind a delete marker (version_id/delete_marker_id).
+CREATE TABLE trash_items (
+    id INTEGER PRIMARY KEY,
+    bucket_name VARCHAR(255) NOT NULL,
+    original_key VARCHAR(1024) NOT NULL,
+    trash_bucket VARCHAR(255) NOT NULL DEFAULT '',
+    trash_key VARCHAR(1024) NOT NULL DEFAULT '',
+    version_id TEXT NOT NULL DEFAULT '',
+    delete_marker_id TEXT NOT NULL DEFAULT '',
+    size BIGINT NOT NULL DEFAULT 0,
+    deleted_by VARCHAR(255) NOT NULL,
+    deleted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Trash page ordering and scheduled purge lookup
+CREATE INDEX idx_trash_items_deleted_at ON trash_items(deleted_at);
+
+-- migrate:down
+DROP TABLE IF EXISTS trash_items;
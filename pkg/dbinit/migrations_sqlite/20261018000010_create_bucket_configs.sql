@@ -0,0 +1,12 @@
+-- migrate:up
+-- Bucket configuration (versioning, lifecycle, replication, encryption, ...) read by
+-- the last scan of each bucket, as the JSON of dto.BucketConfig.
+CREATE TABLE bucket_configs (
+    bucket_id INTEGER PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    scan_job_id INTEGER REFERENCES scan_jobs(id) ON DELETE SET NULL, -- NULL when refreshed from the page
+    config TEXT NOT NULL,
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- migrate:down
+DROP TABLE IF EXISTS bucket_configs;
@@ -0,0 +1,37 @@
+-- migrate:up
+-- Duplicates report of each bucket, computed by a scheduled job: files sharing an ETag and a size.
+CREATE TABLE duplicate_reports (
+    bucket_id INTEGER PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    groups INTEGER NOT NULL,
+    wasted_bytes BIGINT NOT NULL,
+    computed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE duplicate_groups (
+    id INTEGER PRIMARY KEY,
+    bucket_id INTEGER NOT NULL REFERENCES buckets(id) ON DELETE CASCADE,
+    etag VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    objects INTEGER NOT NULL,
+    wasted_bytes BIGINT NOT NULL, -- size of every copy but one
+    multipart BOOLEAN NOT NULL, -- multipart ETags are not an MD5 of the content
+    UNIQUE (bucket_id, etag, size)
+);
+
+-- Copies beyond the oldest file of each group, per folder
+CREATE TABLE duplicate_prefixes (
+    bucket_id INTEGER NOT NULL REFERENCES buckets(id) ON DELETE CASCADE,
+    prefix VARCHAR(1024) NOT NULL,
+    copies INTEGER NOT NULL,
+    wasted_bytes BIGINT NOT NULL,
+    PRIMARY KEY (bucket_id, prefix)
+);
+
+CREATE INDEX idx_duplicate_groups_wasted ON duplicate_groups(bucket_id, wasted_bytes DESC);
+CREATE INDEX idx_s3_objects_etag_size ON s3_objects(bucket_id, etag, size) WHERE is_folder = FALSE;
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_s3_objects_etag_size;
+DROP TABLE IF EXISTS duplicate_prefixes;
+DROP TABLE IF EXISTS duplicate_groups;
+DROP TABLE IF EXISTS duplicate_reports;
@@ -0,0 +1,21 @@
+-- migrate:up
+-- Files created, modified or deleted, as seen by each scan.
+-- old_* columns are NULL for created files, new_* columns for deleted files.
+CREATE TABLE object_changes (
+    id INTEGER PRIMARY KEY,
+    bucket_id INTEGER NOT NULL REFERENCES buckets(id) ON DELETE CASCADE,
+    scan_job_id INTEGER NOT NULL REFERENCES scan_jobs(id) ON DELETE CASCADE,
+    key VARCHAR(1024) NOT NULL,
+    change VARCHAR(10) NOT NULL, -- created, modified, deleted
+    old_size BIGINT,
+    old_etag VARCHAR(255),
+    new_size BIGINT,
+    new_etag VARCHAR(255),
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_object_changes_scan_job ON object_changes(bucket_id, scan_job_id);
+CREATE INDEX idx_object_changes_key ON object_changes(bucket_id, key, id);
+
+-- migrate:down
+DROP TABLE IF EXISTS object_changes;
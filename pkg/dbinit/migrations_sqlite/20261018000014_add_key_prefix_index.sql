@@ -0,0 +1,7 @@
+-- migrate:up
+-- Keys compare as bytes in SQLite (BINARY collation), so the objects under a folder are already
+-- a range of UNIQUE(bucket_id, key): the queries select them with key >= folder AND key < folder || x'F5'.
+SELECT 1;
+
+-- migrate:down
+SELECT 1;
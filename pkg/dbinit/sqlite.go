@@ -0,0 +1,203 @@
+package dbinit
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/amacneil/dbmate/v2/pkg/dbmate"
+	"github.com/amacneil/dbmate/v2/pkg/dbutil"
+	"github.com/lib/pq"
+	_ "modernc.org/sqlite" // SQLite driver, without cgo
+)
+
+// sqlitePragmas are set on every SQLite connection: foreign keys for the ON DELETE CASCADE of the
+// schema, WAL so the web server reads while the scanner writes, and a busy timeout instead of
+// failing on a locked database. Times are written in the format of CURRENT_TIMESTAMP so they compare.
+const sqlitePragmas = "_pragma=foreign_keys(1)&_pragma=journal_mode(wal)&_pragma=busy_timeout(5000)" +
+	"&_time_format=sqlite"
+
+// dbmate ships a SQLite driver, but it needs cgo; this one registers the same schemes on modernc.org/sqlite.
+func init() {
+	dbmate.RegisterDriver(newSQLiteDriver, "sqlite")
+	dbmate.RegisterDriver(newSQLiteDriver, "sqlite3")
+}
+
+// sqlitePath returns the database file of a sqlite: URL. Like dbmate, sqlite:catalog.db is relative
+// to the working directory and sqlite:///var/lib/catalog.db is absolute.
+func sqlitePath(u *url.URL) string {
+	if u.Opaque != "" {
+		return u.Opaque
+	}
+	return "/" + strings.TrimLeft(u.Host+u.Path, "/")
+}
+
+// sqliteDSN returns the modernc.org/sqlite data source of a sqlite: URL, keeping its query parameters.
+func sqliteDSN(u *url.URL) string {
+	dsn := "file:" + sqlitePath(u) + "?" + sqlitePragmas
+	if u.RawQuery != "" {
+		dsn += "&" + u.RawQuery
+	}
+	return dsn
+}
+
+// sqliteDriver is the dbmate driver of SQLite databases.
+type sqliteDriver struct {
+	migrationsTableName string
+	databaseURL         *url.URL
+	log                 io.Writer
+}
+
+func newSQLiteDriver(config dbmate.DriverConfig) dbmate.Driver {
+	return &sqliteDriver{
+		migrationsTableName: config.MigrationsTableName,
+		databaseURL:         config.DatabaseURL,
+		log:                 config.Log,
+	}
+}
+
+// Open opens the database file, creating it when missing.
+func (drv *sqliteDriver) Open() (*sql.DB, error) {
+	db, err := sql.Open("sqlite", sqliteDSN(drv.databaseURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	return db, nil
+}
+
+// DatabaseExists reports whether the database file exists.
+func (drv *sqliteDriver) DatabaseExists() (bool, error) {
+	_, err := os.Stat(sqlitePath(drv.databaseURL))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to stat SQLite database: %w", err)
+	}
+	return true, nil
+}
+
+// CreateDatabase creates the database file.
+func (drv *sqliteDriver) CreateDatabase() error {
+	_, _ = fmt.Fprintf(drv.log, "Creating: %s\n", sqlitePath(drv.databaseURL))
+	return drv.Ping()
+}
+
+// DropDatabase removes the database file, if it exists.
+func (drv *sqliteDriver) DropDatabase() error {
+	path := sqlitePath(drv.databaseURL)
+	_, _ = fmt.Fprintf(drv.log, "Dropping: %s\n", path)
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove SQLite database: %w", err)
+	}
+	return nil
+}
+
+// DumpSchema returns the statements of the schema objects, followed by the applied migrations.
+func (drv *sqliteDriver) DumpSchema(db *sql.DB) ([]byte, error) {
+	statements, err := dbutil.QueryColumn(db,
+		"SELECT sql || ';' FROM sqlite_master WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%' ORDER BY rowid")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SQLite schema: %w", err)
+	}
+	versions, err := dbutil.QueryColumn(db,
+		fmt.Sprintf("SELECT quote(version) FROM %s ORDER BY version", drv.quotedMigrationsTableName()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	var schema strings.Builder
+	schema.WriteString(strings.Join(statements, "\n") + "\n\n-- Dbmate schema migrations\n")
+	if len(versions) > 0 {
+		fmt.Fprintf(&schema, "INSERT INTO %s (version) VALUES\n  (%s);\n",
+			drv.quotedMigrationsTableName(), strings.Join(versions, "),\n  ("))
+	}
+	return []byte(schema.String()), nil
+}
+
+// MigrationsTableExists reports whether the migrations table exists.
+func (drv *sqliteDriver) MigrationsTableExists(db *sql.DB) (bool, error) {
+	var exists bool
+	err := db.QueryRow("SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?",
+		drv.migrationsTableName).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up migrations table: %w", err)
+	}
+	return exists, nil
+}
+
+// CreateMigrationsTable creates the migrations table, with the same layout as dbmate's.
+func (drv *sqliteDriver) CreateMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version VARCHAR(128) PRIMARY KEY)",
+		drv.quotedMigrationsTableName()))
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	return nil
+}
+
+// SelectMigrations returns the applied migrations, newest first, at most limit unless it is negative.
+func (drv *sqliteDriver) SelectMigrations(db *sql.DB, limit int) (map[string]bool, error) {
+	query := fmt.Sprintf("SELECT version FROM %s ORDER BY version DESC", drv.quotedMigrationsTableName())
+	if limit >= 0 {
+		query = fmt.Sprintf("%s LIMIT %d", query, limit)
+	}
+	versions, err := dbutil.QueryColumn(db, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	migrations := make(map[string]bool, len(versions))
+	for _, version := range versions {
+		migrations[version] = true
+	}
+	return migrations, nil
+}
+
+// InsertMigration records an applied migration.
+func (drv *sqliteDriver) InsertMigration(db dbutil.Transaction, version string) error {
+	_, err := db.Exec(fmt.Sprintf("INSERT INTO %s (version) VALUES (?)", drv.quotedMigrationsTableName()), version)
+	if err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", version, err)
+	}
+	return nil
+}
+
+// DeleteMigration removes the record of a rolled back migration.
+func (drv *sqliteDriver) DeleteMigration(db dbutil.Transaction, version string) error {
+	_, err := db.Exec(fmt.Sprintf("DELETE FROM %s WHERE version = ?", drv.quotedMigrationsTableName()), version)
+	if err != nil {
+		return fmt.Errorf("failed to remove migration %s: %w", version, err)
+	}
+	return nil
+}
+
+// Ping opens the database, which creates the file when it does not exist yet.
+func (drv *sqliteDriver) Ping() error {
+	db, err := drv.Open()
+	if err != nil {
+		return err
+	}
+	defer dbutil.MustClose(db)
+
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping SQLite database: %w", err)
+	}
+	return nil
+}
+
+// QueryError wraps the error of a migration statement.
+func (drv *sqliteDriver) QueryError(query string, err error) error {
+	return &dbmate.QueryError{Err: err, Query: query}
+}
+
+func (drv *sqliteDriver) quotedMigrationsTableName() string {
+	return pq.QuoteIdentifier(drv.migrationsTableName)
+}
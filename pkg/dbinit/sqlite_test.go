@@ -0,0 +1,131 @@
+package dbinit
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/amacneil/dbmate/v2/pkg/dbmate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configapp "github.com/sgaunet/s3xplorer/pkg/config"
+)
+
+func TestSQLitePath(t *testing.T) {
+	tests := map[string]string{
+		"sqlite:catalog.db":                        "catalog.db",
+		"sqlite:data/catalog.db":                   "data/catalog.db",
+		"sqlite:/var/lib/s3xplorer/catalog.db":     "/var/lib/s3xplorer/catalog.db",
+		"sqlite:///var/lib/s3xplorer/catalog.db":   "/var/lib/s3xplorer/catalog.db",
+		"sqlite3://var/lib/s3xplorer/catalog.db":   "/var/lib/s3xplorer/catalog.db",
+		"sqlite:catalog.db?_pragma=synchronous(1)": "catalog.db",
+	}
+	for raw, want := range tests {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		assert.Equal(t, want, sqlitePath(u), raw)
+	}
+}
+
+// TestSQLiteMigrationsMirrorPostgres verifies that every PostgreSQL migration has its SQLite variant,
+// so both databases end up at the same schema version.
+func TestSQLiteMigrationsMirrorPostgres(t *testing.T) {
+	names := func(dir string) []string {
+		entries, err := fs.ReadDir(migrations, dir)
+		require.NoError(t, err)
+		var files []string
+		for _, entry := range entries {
+			files = append(files, entry.Name())
+		}
+		return files
+	}
+	assert.Equal(t, names("migrations"), names("migrations_sqlite"))
+}
+
+func sqliteTestConfig(t *testing.T) configapp.DatabaseConfig {
+	t.Helper()
+	return configapp.DatabaseConfig{
+		URL:             "sqlite:" + filepath.Join(t.TempDir(), "catalog.db"),
+		MaxOpenConns:    5,
+		MaxIdleConns:    2,
+		ConnMaxLifetime: "5m",
+		ConnMaxIdleTime: "1m",
+	}
+}
+
+func TestInitializeDatabase_SQLite(t *testing.T) {
+	ctx := context.Background()
+	dbConfig := sqliteTestConfig(t)
+	db, err := InitializeDatabaseWithRetry(ctx, dbConfig, slog.New(slog.DiscardHandler), 0, time.Millisecond)
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	var applied int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&applied))
+	assert.Equal(t, 22, applied)
+
+	_, err = db.ExecContext(ctx, "INSERT INTO buckets (name) VALUES ('prod-data')")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO s3_objects (bucket_id, key, is_folder, prefix) VALUES
+		(1, 'logs/', TRUE, ''), (1, 'logs/2026/app.log', FALSE, 'logs/2026/'), (1, 'README', FALSE, '')`)
+	require.NoError(t, err)
+
+	// The generated basename is the last path segment, without the trailing slash of folders
+	rows, err := db.QueryContext(ctx, "SELECT basename FROM s3_objects ORDER BY id")
+	require.NoError(t, err)
+	var basenames []string
+	for rows.Next() {
+		var basename string
+		require.NoError(t, rows.Scan(&basename))
+		basenames = append(basenames, basename)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"logs", "app.log", "README"}, basenames)
+
+	// Global scan jobs have no bucket
+	_, err = db.ExecContext(ctx, "INSERT INTO scan_jobs (bucket_id, status) VALUES (NULL, 'running')")
+	require.NoError(t, err)
+
+	// Foreign keys are enforced, so deleting a bucket deletes its catalog
+	_, err = db.ExecContext(ctx, "DELETE FROM buckets WHERE name = 'prod-data'")
+	require.NoError(t, err)
+	var objects int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM s3_objects").Scan(&objects))
+	assert.Zero(t, objects)
+
+	// Running the migrations again is a no-op
+	require.NoError(t, MigrateDatabase(dbConfig.URL, slog.New(slog.DiscardHandler)))
+}
+
+func TestSQLiteMigrations_Rollback(t *testing.T) {
+	u, err := url.Parse(sqliteTestConfig(t).URL)
+	require.NoError(t, err)
+	migrationFS, err := fs.Sub(migrations, "migrations_sqlite")
+	require.NoError(t, err)
+
+	db := dbmate.New(u)
+	db.AutoDumpSchema = false
+	db.MigrationsDir = []string{"."}
+	db.FS = migrationFS
+	db.Log = &testWriter{t}
+	require.NoError(t, db.CreateAndMigrate())
+
+	// Every down migration applies, back to an empty database, and the schema can be rebuilt
+	for range 21 {
+		require.NoError(t, db.Rollback())
+	}
+	require.NoError(t, db.Migrate())
+}
+
+// testWriter sends dbmate output to the test log.
+type testWriter struct{ t *testing.T }
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.t.Log(string(p))
+	return len(p), nil
+}
@@ -0,0 +1,151 @@
+// Package dbsqlite runs the catalog queries on SQLite. The queries of db/queries_sqlite are generated into
+// pkg/database/sqlitedb; Queries adapts them to database.Querier, so the services do not depend on the
+// database driver.
+package dbsqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/database/sqlitedb"
+)
+
+// timeLayout is the format modernc.org/sqlite writes times in with _time_format=sqlite. Times are
+// written in UTC, so that they compare with each other and with CURRENT_TIMESTAMP as text.
+const timeLayout = "2006-01-02 15:04:05.999999999-07:00"
+
+var errUnsupportedArgument = errors.New("unsupported query argument")
+
+// Queries runs the catalog queries on a SQLite database.
+type Queries struct {
+	q *sqlitedb.Queries
+}
+
+var _ database.Querier = (*Queries)(nil)
+
+// New returns the queries of a SQLite database connection or transaction.
+func New(db database.DBTX) *Queries {
+	return &Queries{q: sqlitedb.New(utcDB{db: db})}
+}
+
+// utcDB converts the time arguments of the queries to UTC before they are written as text.
+type utcDB struct {
+	db database.DBTX
+}
+
+func (u utcDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return u.db.ExecContext(ctx, query, utcArgs(args)...)
+}
+
+func (u utcDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return u.db.PrepareContext(ctx, query)
+}
+
+func (u utcDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return u.db.QueryContext(ctx, query, utcArgs(args)...)
+}
+
+func (u utcDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return u.db.QueryRowContext(ctx, query, utcArgs(args)...)
+}
+
+func utcArgs(args []interface{}) []interface{} {
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			args[i] = v.UTC()
+		case sql.NullTime:
+			v.Time = v.Time.UTC()
+			args[i] = v
+		}
+	}
+	return args
+}
+
+// jsonArray encodes the array arguments of PostgreSQL queries, which SQLite queries read with json_each.
+func jsonArray[T any](values []T) (string, error) {
+	if values == nil {
+		values = []T{}
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode query argument: %w", err)
+	}
+	return string(b), nil
+}
+
+// hoursArg returns the number of hours passed to the PostgreSQL queries as an untyped interval argument.
+func hoursArg(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	default:
+		return 0, fmt.Errorf("%w: %T hours", errUnsupportedArgument, v)
+	}
+}
+
+// likeUnescaper reverses the backslash escaping of the LIKE prefixes passed to the PostgreSQL queries.
+var likeUnescaper = strings.NewReplacer(`\\`, `\`, `\%`, `%`, `\_`, `_`)
+
+// likePrefix returns the folder of an escaped LIKE prefix, which SQLite matches as a range of keys.
+func likePrefix(pattern string) string {
+	return likeUnescaper.Replace(pattern)
+}
+
+// sqliteTime converts an RFC 3339 time of an S3 Inventory batch to the format of the SQLite catalog.
+func sqliteTime(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return "", fmt.Errorf("invalid last modified time %q: %w", s, err)
+	}
+	return t.UTC().Format(timeLayout), nil
+}
+
+// convertAll converts the rows of a SQLite query to those of database.Querier.
+func convertAll[S, D any](rows []S, err error, convert func(S) D) ([]D, error) {
+	if err != nil {
+		return nil, err
+	}
+	out := make([]D, len(rows))
+	for i, row := range rows {
+		out[i] = convert(row)
+	}
+	return out, nil
+}
+
+func bucket(b sqlitedb.Bucket) database.Bucket { return database.Bucket(b) }
+
+func restoreRequest(r sqlitedb.RestoreRequest) database.RestoreRequest {
+	return database.RestoreRequest(r)
+}
+
+func s3Object(o sqlitedb.S3Object) database.S3Object { return database.S3Object(o) }
+
+func scanJob(j sqlitedb.ScanJob) database.ScanJob { return database.ScanJob(j) }
+
+func share(s sqlitedb.Share) database.Share { return database.Share(s) }
+
+func trashItem(t sqlitedb.TrashItem) database.TrashItem { return database.TrashItem(t) }
+
+func uploadPart(p sqlitedb.UploadPart) database.UploadPart { return database.UploadPart(p) }
+
+func uploadSession(s sqlitedb.UploadSession) database.UploadSession {
+	return database.UploadSession(s)
+}
+
+func listBucketsWithStatusRow(r sqlitedb.ListBucketsWithStatusRow) database.ListBucketsWithStatusRow {
+	return database.ListBucketsWithStatusRow(r)
+}
@@ -0,0 +1,321 @@
+package dbsqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configapp "github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dbinit"
+	"github.com/sgaunet/s3xplorer/pkg/dbsqlite"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// TestQueriesMirrorPostgres checks that every PostgreSQL query has a SQLite version of the same name
+// in the file of the same name. SQLite may run a PostgreSQL query as several, so it can have more.
+func TestQueriesMirrorPostgres(t *testing.T) {
+	queryName := regexp.MustCompile(`(?m)^-- name: (\w+)`)
+	names := func(dir string) []string {
+		files, err := filepath.Glob(filepath.Join("..", "..", "db", dir, "*.sql"))
+		require.NoError(t, err)
+		require.NotEmpty(t, files)
+		var queries []string
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			require.NoError(t, err)
+			for _, match := range queryName.FindAllStringSubmatch(string(content), -1) {
+				queries = append(queries, filepath.Base(file)+": "+match[1])
+			}
+		}
+		return queries
+	}
+	assert.Subset(t, names("queries_sqlite"), names("queries"))
+}
+
+// openCatalog returns the queries of a migrated SQLite catalog with one bucket.
+func openCatalog(t *testing.T) (*sql.DB, *dbsqlite.Queries, database.Bucket) {
+	t.Helper()
+	db, err := dbinit.InitializeDatabase(context.Background(), configapp.DatabaseConfig{
+		URL:             "sqlite:" + filepath.Join(t.TempDir(), "catalog.db"),
+		MaxOpenConns:    5,
+		MaxIdleConns:    2,
+		ConnMaxLifetime: "5m",
+		ConnMaxIdleTime: "1m",
+	}, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	q := dbsqlite.New(db)
+	bucket, err := q.CreateBucket(context.Background(), database.CreateBucketParams{Name: "prod-data"})
+	require.NoError(t, err)
+	return db, q, bucket
+}
+
+func createObject(t *testing.T, q *dbsqlite.Queries, bucketID int32, key string, size int64, lastModified time.Time) {
+	t.Helper()
+	prefix := ""
+	if i := len(key) - 1; i > 0 {
+		for i > 0 && key[i-1] != '/' {
+			i--
+		}
+		prefix = key[:i]
+	}
+	_, err := q.CreateS3Object(context.Background(), database.CreateS3ObjectParams{
+		BucketID:     bucketID,
+		Key:          key,
+		Size:         size,
+		LastModified: sql.NullTime{Time: lastModified, Valid: true},
+		Etag:         sql.NullString{String: `"` + strconv.FormatInt(size, 10) + `"`, Valid: true},
+		IsFolder:     sql.NullBool{Bool: false, Valid: true},
+		Prefix:       sql.NullString{String: prefix, Valid: true},
+	})
+	require.NoError(t, err)
+}
+
+func TestQueries_ListAndSearch(t *testing.T) {
+	ctx := context.Background()
+	_, q, bucket := openCatalog(t)
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	modified := time.Date(2026, 10, 18, 9, 30, 0, 0, paris)
+	createObject(t, q, bucket.ID, "logs/app.log", 42, modified)
+	createObject(t, q, bucket.ID, "logs/db_2026.log", 7, modified)
+	createObject(t, q, bucket.ID, "README", 1, modified)
+
+	// Times are stored in UTC and read back as the same instant
+	obj, err := q.GetS3Object(ctx, database.GetS3ObjectParams{BucketID: bucket.ID, Key: "logs/app.log"})
+	require.NoError(t, err)
+	assert.True(t, obj.LastModified.Time.Equal(modified))
+	assert.Equal(t, "app.log", obj.Basename.String)
+
+	count, err := q.CountS3Objects(ctx, database.CountS3ObjectsParams{BucketID: bucket.ID, Column2: "logs/"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	objects, err := q.ListS3Objects(ctx, database.ListS3ObjectsParams{BucketID: bucket.ID, Column2: "logs/", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "logs/app.log", objects[0].Key)
+	objects, err = q.ListS3Objects(ctx, database.ListS3ObjectsParams{
+		BucketID:       bucket.ID,
+		Column2:        "logs/",
+		Limit:          10,
+		CursorIsFolder: sql.NullBool{Bool: false, Valid: true},
+		CursorKey:      sql.NullString{String: "logs/app.log", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "logs/db_2026.log", objects[0].Key)
+
+	// LIKE wildcards of the escaped pattern match literally, and the search ignores case
+	objects, err = q.SearchS3Objects(ctx, database.SearchS3ObjectsParams{
+		BucketID: bucket.ID, Column2: sql.NullString{String: `DB\_`, Valid: true}, Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "logs/db_2026.log", objects[0].Key)
+
+	installed, err := q.HasTrigramExtension(ctx)
+	require.NoError(t, err)
+	assert.False(t, installed)
+}
+
+func TestQueries_UpsertInventoryObjects(t *testing.T) {
+	ctx := context.Background()
+	db, q, bucket := openCatalog(t)
+	job, err := q.CreateScanJob(ctx, database.CreateScanJobParams{
+		BucketID: sql.NullInt32{Int32: bucket.ID, Valid: true}, Status: "running",
+	})
+	require.NoError(t, err)
+
+	batch := func(keys []string, sizes []int64, recordChanges bool) database.UpsertInventoryObjectsParams {
+		params := database.UpsertInventoryObjectsParams{BucketID: bucket.ID, ScanJobID: job.ID, RecordChanges: recordChanges}
+		for i, key := range keys {
+			params.Keys = append(params.Keys, key)
+			params.Sizes = append(params.Sizes, sizes[i])
+			params.LastModified = append(params.LastModified, "2026-10-18T09:30:00+02:00")
+			params.Etags = append(params.Etags, `"etag"`)
+			params.StorageClasses = append(params.StorageClasses, "GLACIER")
+			params.EncryptionStatuses = append(params.EncryptionStatuses, "SSE-S3")
+			params.TagCounts = append(params.TagCounts, "")
+			params.Prefixes = append(params.Prefixes, "logs/")
+		}
+		params.TagCounts[0] = "3"
+		return params
+	}
+
+	require.NoError(t, q.UpsertInventoryFolders(ctx, database.UpsertInventoryFoldersParams{
+		BucketID: bucket.ID, Keys: []string{"logs/"}, Prefixes: []string{""},
+	}))
+	created, err := q.UpsertInventoryObjects(ctx, batch([]string{"logs/a.log", "logs/b.log"}, []int64{1, 2}, false))
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, true}, created)
+
+	created, err = q.UpsertInventoryObjects(ctx, batch([]string{"logs/b.log", "logs/c.log"}, []int64{5, 3}, true))
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, true}, created)
+
+	obj, err := q.GetS3Object(ctx, database.GetS3ObjectParams{BucketID: bucket.ID, Key: "logs/b.log"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), obj.Size)
+	assert.True(t, obj.LastModified.Time.Equal(time.Date(2026, 10, 18, 7, 30, 0, 0, time.UTC)))
+	assert.Equal(t, "GLACIER", obj.StorageClass.String)
+	assert.Equal(t, "SSE-S3", obj.EncryptionStatus.String)
+	assert.Equal(t, sql.NullInt32{Int32: 3, Valid: true}, obj.TagCount)
+
+	folder, err := q.GetS3Object(ctx, database.GetS3ObjectParams{BucketID: bucket.ID, Key: "logs/"})
+	require.NoError(t, err)
+	assert.True(t, folder.IsFolder.Bool)
+
+	// Only the batch recorded with changes is in the history, with the size it had before
+	rows, err := q.DiffScanJobs(ctx, database.DiffScanJobsParams{
+		Limit: 10, BucketID: bucket.ID, FromJobID: 0, ToJobID: job.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "logs/b.log", rows[0].Key)
+	assert.Equal(t, "modified", rows[0].Change)
+	assert.Equal(t, sql.NullInt64{Int64: 2, Valid: true}, rows[0].OldSize)
+	assert.Equal(t, "logs/c.log", rows[1].Key)
+	assert.Equal(t, "created", rows[1].Change)
+
+	var changes int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM object_changes").Scan(&changes))
+	assert.Equal(t, 2, changes)
+}
+
+func TestQueries_CompareCatalogs(t *testing.T) {
+	ctx := context.Background()
+	_, q, bucket := openCatalog(t)
+	modified := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	createObject(t, q, bucket.ID, "a/same.bin", 1, modified)
+	createObject(t, q, bucket.ID, "a/only.bin", 1, modified)
+	createObject(t, q, bucket.ID, "a/size.bin", 1, modified)
+	createObject(t, q, bucket.ID, "b/same.bin", 1, modified)
+	createObject(t, q, bucket.ID, "b/size.bin", 2, modified)
+	createObject(t, q, bucket.ID, "b/new.bin", 1, modified)
+
+	rows, err := q.CompareCatalogs(ctx, database.CompareCatalogsParams{
+		RowLimit:       10,
+		SourcePrefix:   "a/",
+		SourceBucketID: bucket.ID,
+		TargetPrefix:   "b/",
+		TargetBucketID: bucket.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, "new.bin", rows[0].RelKey)
+	assert.Equal(t, dto.DifferenceMissingSource, rows[0].Difference)
+	assert.False(t, rows[0].SourceSize.Valid)
+	assert.Equal(t, sql.NullInt64{Int64: 1, Valid: true}, rows[0].TargetSize)
+
+	assert.Equal(t, "only.bin", rows[1].RelKey)
+	assert.Equal(t, dto.DifferenceMissingTarget, rows[1].Difference)
+	assert.Equal(t, "STANDARD", rows[1].SourceStorageClass.String)
+	assert.False(t, rows[1].TargetEtag.Valid)
+
+	assert.Equal(t, "size.bin", rows[2].RelKey)
+	assert.Equal(t, "size", rows[2].Difference)
+}
+
+func TestQueries_ListExportObjects(t *testing.T) {
+	ctx := context.Background()
+	_, q, bucket := openCatalog(t)
+	modified := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	for _, key := range []string{"logs/a.log", "logs/b.log", "logs/c.log", "logs/old/d.log", "README"} {
+		createObject(t, q, bucket.ID, key, 1, modified)
+	}
+
+	params := database.ListExportObjectsParams{
+		BucketID: bucket.ID, Prefix: "logs/", Exclude: "logs/old/", BatchSize: 2,
+	}
+	var keys []string
+	for {
+		rows, err := q.ListExportObjects(ctx, params)
+		require.NoError(t, err)
+		if len(rows) == 0 {
+			break
+		}
+		for _, row := range rows {
+			keys = append(keys, row.Key)
+			assert.True(t, row.LastModified.Time.Equal(modified))
+		}
+		params.AfterKey = rows[len(rows)-1].Key
+	}
+	assert.Equal(t, []string{"logs/a.log", "logs/b.log", "logs/c.log"}, keys)
+}
+
+func TestQueries_SimulateLifecycleAction(t *testing.T) {
+	ctx := context.Background()
+	_, q, bucket := openCatalog(t)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	createObject(t, q, bucket.ID, "logs/old.log", 10, now.AddDate(0, 0, -40))
+	createObject(t, q, bucket.ID, "logs/soon.log", 20, now.AddDate(0, 0, -25))
+	createObject(t, q, bucket.ID, "logs/new.log", 40, now.AddDate(0, 0, -1))
+
+	params := database.SimulateLifecycleActionParams{
+		DueBefore:      now.AddDate(0, 0, -30),
+		BucketName:     bucket.Name,
+		Prefix:         "logs/",
+		UpcomingBefore: now.AddDate(0, 0, -23),
+		NotBefore:      now.AddDate(0, 0, -365),
+	}
+	row, err := q.SimulateLifecycleAction(ctx, params)
+	require.NoError(t, err)
+	assert.Equal(t, database.SimulateLifecycleActionRow{
+		DueObjects: 1, DueBytes: 10, UpcomingObjects: 1, UpcomingBytes: 20,
+	}, row)
+
+	params.StorageClasses = []string{"GLACIER"}
+	row, err = q.SimulateLifecycleAction(ctx, params)
+	require.NoError(t, err)
+	assert.Zero(t, row.DueObjects+row.UpcomingObjects)
+}
+
+func TestQueries_SearchS3ObjectsByTags(t *testing.T) {
+	ctx := context.Background()
+	_, q, bucket := openCatalog(t)
+	modified := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	createObject(t, q, bucket.ID, "a.bin", 1, modified)
+	createObject(t, q, bucket.ID, "b.bin", 1, modified)
+	for key, tags := range map[string][][2]string{
+		"a.bin": {{"team", "data"}, {"env", "prod"}},
+		"b.bin": {{"team", "data"}},
+	} {
+		obj, err := q.GetS3Object(ctx, database.GetS3ObjectParams{BucketID: bucket.ID, Key: key})
+		require.NoError(t, err)
+		for _, tag := range tags {
+			require.NoError(t, q.InsertObjectTag(ctx, database.InsertObjectTagParams{
+				ObjectID: obj.ID, Source: "s3", Key: tag[0], Value: tag[1],
+			}))
+		}
+	}
+
+	// Every filter must match, an empty value matches any value of the key
+	objects, err := q.SearchS3ObjectsByTags(ctx, database.SearchS3ObjectsByTagsParams{
+		BucketID:  bucket.ID,
+		Limit:     10,
+		TagKeys:   []string{"team", "env"},
+		Sources:   []string{"s3", "s3"},
+		TagValues: []string{"data", ""},
+	})
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "a.bin", objects[0].Key)
+
+	objects, err = q.SearchS3ObjectsByTags(ctx, database.SearchS3ObjectsByTagsParams{BucketID: bucket.ID, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, objects, 2)
+}
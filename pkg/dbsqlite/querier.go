@@ -0,0 +1,1038 @@
+package dbsqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/database/sqlitedb"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+func (q *Queries) BucketHasFiles(ctx context.Context, bucketID int32) (bool, error) {
+	return q.q.BucketHasFiles(ctx, bucketID)
+}
+
+func (q *Queries) CompareCatalogs(
+	ctx context.Context, arg database.CompareCatalogsParams,
+) ([]database.CompareCatalogsRow, error) {
+	rows, err := q.q.CompareCatalogs(ctx, sqlitedb.CompareCatalogsParams{
+		SourcePrefix:   arg.SourcePrefix,
+		SourceBucketID: arg.SourceBucketID,
+		TargetPrefix:   arg.TargetPrefix,
+		TargetBucketID: arg.TargetBucketID,
+		OnlyDifference: arg.OnlyDifference,
+		AfterKey:       arg.AfterKey,
+		RowOffset:      int64(arg.RowOffset),
+		RowLimit:       int64(arg.RowLimit),
+	})
+	return convertAll(rows, err, func(r sqlitedb.CompareCatalogsRow) database.CompareCatalogsRow {
+		// The columns of the side a file is missing from are NULL in PostgreSQL
+		inSource := r.Difference != dto.DifferenceMissingSource
+		inTarget := r.Difference != dto.DifferenceMissingTarget
+		return database.CompareCatalogsRow{
+			RelKey:             r.RelKey,
+			SourceSize:         sql.NullInt64{Int64: r.SourceSize, Valid: inSource},
+			SourceEtag:         sql.NullString{String: r.SourceEtag, Valid: inSource},
+			SourceStorageClass: sql.NullString{String: r.SourceStorageClass, Valid: inSource},
+			TargetSize:         sql.NullInt64{Int64: r.TargetSize, Valid: inTarget},
+			TargetEtag:         sql.NullString{String: r.TargetEtag, Valid: inTarget},
+			TargetStorageClass: sql.NullString{String: r.TargetStorageClass, Valid: inTarget},
+			Difference:         r.Difference,
+		}
+	})
+}
+
+func (q *Queries) CountDirectChildrenFiles(
+	ctx context.Context, arg database.CountDirectChildrenFilesParams,
+) (int64, error) {
+	return q.q.CountDirectChildrenFiles(ctx, sqlitedb.CountDirectChildrenFilesParams{
+		BucketID: arg.BucketID, Prefix: arg.Column2,
+	})
+}
+
+func (q *Queries) CountDirectChildrenFolders(
+	ctx context.Context, arg database.CountDirectChildrenFoldersParams,
+) (int64, error) {
+	return q.q.CountDirectChildrenFolders(ctx, sqlitedb.CountDirectChildrenFoldersParams{
+		BucketID: arg.BucketID, Prefix: arg.Column2,
+	})
+}
+
+func (q *Queries) CountMarkedBuckets(ctx context.Context) (int64, error) {
+	return q.q.CountMarkedBuckets(ctx)
+}
+
+func (q *Queries) CountMarkedObjects(ctx context.Context, bucketID int32) (int64, error) {
+	return q.q.CountMarkedObjects(ctx, bucketID)
+}
+
+func (q *Queries) CountS3Objects(ctx context.Context, arg database.CountS3ObjectsParams) (int64, error) {
+	return q.q.CountS3Objects(ctx, sqlitedb.CountS3ObjectsParams{BucketID: arg.BucketID, Prefix: arg.Column2})
+}
+
+func (q *Queries) CountTagIndexedObjects(
+	ctx context.Context, bucketID int32,
+) (database.CountTagIndexedObjectsRow, error) {
+	row, err := q.q.CountTagIndexedObjects(ctx, bucketID)
+	return database.CountTagIndexedObjectsRow(row), err
+}
+
+func (q *Queries) CreateBucket(ctx context.Context, arg database.CreateBucketParams) (database.Bucket, error) {
+	row, err := q.q.CreateBucket(ctx, sqlitedb.CreateBucketParams(arg))
+	return database.Bucket(row), err
+}
+
+func (q *Queries) CreateGlobalScanJob(ctx context.Context, status string) (database.ScanJob, error) {
+	row, err := q.q.CreateGlobalScanJob(ctx, status)
+	return database.ScanJob(row), err
+}
+
+func (q *Queries) CreateJob(ctx context.Context, arg database.CreateJobParams) (database.Job, error) {
+	row, err := q.q.CreateJob(ctx, sqlitedb.CreateJobParams(arg))
+	return database.Job(row), err
+}
+
+func (q *Queries) CreateRestoreRequest(
+	ctx context.Context, arg database.CreateRestoreRequestParams,
+) (database.RestoreRequest, error) {
+	row, err := q.q.CreateRestoreRequest(ctx, sqlitedb.CreateRestoreRequestParams(arg))
+	return database.RestoreRequest(row), err
+}
+
+func (q *Queries) CreateS3Object(ctx context.Context, arg database.CreateS3ObjectParams) (database.S3Object, error) {
+	row, err := q.q.CreateS3Object(ctx, sqlitedb.CreateS3ObjectParams(arg))
+	return database.S3Object(row), err
+}
+
+func (q *Queries) CreateScanJob(ctx context.Context, arg database.CreateScanJobParams) (database.ScanJob, error) {
+	row, err := q.q.CreateScanJob(ctx, sqlitedb.CreateScanJobParams(arg))
+	return database.ScanJob(row), err
+}
+
+func (q *Queries) CreateShare(ctx context.Context, arg database.CreateShareParams) (database.Share, error) {
+	row, err := q.q.CreateShare(ctx, sqlitedb.CreateShareParams(arg))
+	return database.Share(row), err
+}
+
+func (q *Queries) CreateTrashItem(ctx context.Context, arg database.CreateTrashItemParams) (database.TrashItem, error) {
+	row, err := q.q.CreateTrashItem(ctx, sqlitedb.CreateTrashItemParams(arg))
+	return database.TrashItem(row), err
+}
+
+func (q *Queries) CreateUploadSession(
+	ctx context.Context, arg database.CreateUploadSessionParams,
+) (database.UploadSession, error) {
+	row, err := q.q.CreateUploadSession(ctx, sqlitedb.CreateUploadSessionParams(arg))
+	return database.UploadSession(row), err
+}
+
+func (q *Queries) DeleteBucket(ctx context.Context, id int32) error {
+	return q.q.DeleteBucket(ctx, id)
+}
+
+func (q *Queries) DeleteDuplicateGroups(ctx context.Context, bucketID int32) error {
+	return q.q.DeleteDuplicateGroups(ctx, bucketID)
+}
+
+func (q *Queries) DeleteDuplicatePrefixes(ctx context.Context, bucketID int32) error {
+	return q.q.DeleteDuplicatePrefixes(ctx, bucketID)
+}
+
+func (q *Queries) DeleteMarkedBuckets(ctx context.Context, dollar_1 interface{}) error {
+	hours, err := hoursArg(dollar_1)
+	if err != nil {
+		return err
+	}
+	return q.q.DeleteMarkedBuckets(ctx, hours)
+}
+
+func (q *Queries) DeleteMarkedObjects(ctx context.Context, bucketID int32) error {
+	return q.q.DeleteMarkedObjects(ctx, bucketID)
+}
+
+func (q *Queries) DeleteObjectTags(ctx context.Context, arg database.DeleteObjectTagsParams) error {
+	return q.q.DeleteObjectTags(ctx, sqlitedb.DeleteObjectTagsParams(arg))
+}
+
+func (q *Queries) DeleteS3Object(ctx context.Context, arg database.DeleteS3ObjectParams) error {
+	return q.q.DeleteS3Object(ctx, sqlitedb.DeleteS3ObjectParams(arg))
+}
+
+func (q *Queries) DeleteS3ObjectTree(ctx context.Context, arg database.DeleteS3ObjectTreeParams) (int64, error) {
+	return q.q.DeleteS3ObjectTree(ctx, sqlitedb.DeleteS3ObjectTreeParams{BucketID: arg.BucketID, Key: arg.Key})
+}
+
+func (q *Queries) DeleteS3ObjectsByBucket(ctx context.Context, bucketID int32) error {
+	return q.q.DeleteS3ObjectsByBucket(ctx, bucketID)
+}
+
+func (q *Queries) DeleteS3ObjectsByKeys(ctx context.Context, arg database.DeleteS3ObjectsByKeysParams) (int64, error) {
+	keys, err := jsonArray(arg.Keys)
+	if err != nil {
+		return 0, err
+	}
+	return q.q.DeleteS3ObjectsByKeys(ctx, sqlitedb.DeleteS3ObjectsByKeysParams{BucketID: arg.BucketID, Keys: keys})
+}
+
+func (q *Queries) DeleteTrashItem(ctx context.Context, id int32) (int64, error) {
+	return q.q.DeleteTrashItem(ctx, id)
+}
+
+func (q *Queries) DiffScanJobs(
+	ctx context.Context, arg database.DiffScanJobsParams,
+) ([]database.DiffScanJobsRow, error) {
+	rows, err := q.q.DiffScanJobs(ctx, sqlitedb.DiffScanJobsParams{
+		Limit:     int64(arg.Limit),
+		BucketID:  arg.BucketID,
+		FromJobID: arg.FromJobID,
+		ToJobID:   arg.ToJobID,
+		KeyPrefix: arg.KeyPrefix,
+	})
+	return convertAll(rows, err, func(r sqlitedb.DiffScanJobsRow) database.DiffScanJobsRow {
+		return database.DiffScanJobsRow(r)
+	})
+}
+
+func (q *Queries) ExpireRestoreRequests(ctx context.Context) (int64, error) {
+	return q.q.ExpireRestoreRequests(ctx)
+}
+
+func (q *Queries) FailInterruptedJobs(ctx context.Context) (int64, error) {
+	return q.q.FailInterruptedJobs(ctx)
+}
+
+func (q *Queries) FinishJob(ctx context.Context, arg database.FinishJobParams) error {
+	return q.q.FinishJob(ctx, sqlitedb.FinishJobParams(arg))
+}
+
+func (q *Queries) GetBreadcrumbPath(
+	ctx context.Context, arg database.GetBreadcrumbPathParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.GetBreadcrumbPath(ctx, sqlitedb.GetBreadcrumbPathParams(arg))
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) GetBucket(ctx context.Context, name string) (database.Bucket, error) {
+	row, err := q.q.GetBucket(ctx, name)
+	return database.Bucket(row), err
+}
+
+func (q *Queries) GetBucketByID(ctx context.Context, id int32) (database.Bucket, error) {
+	row, err := q.q.GetBucketByID(ctx, id)
+	return database.Bucket(row), err
+}
+
+func (q *Queries) GetBucketConfig(ctx context.Context, name string) (database.GetBucketConfigRow, error) {
+	row, err := q.q.GetBucketConfig(ctx, name)
+	return database.GetBucketConfigRow{Config: json.RawMessage(row.Config), FetchedAt: row.FetchedAt}, err
+}
+
+func (q *Queries) GetBucketsMarkedForDeletion(ctx context.Context) ([]database.Bucket, error) {
+	rows, err := q.q.GetBucketsMarkedForDeletion(ctx)
+	return convertAll(rows, err, bucket)
+}
+
+func (q *Queries) GetBucketsToDelete(ctx context.Context, dollar_1 interface{}) ([]database.Bucket, error) {
+	hours, err := hoursArg(dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.q.GetBucketsToDelete(ctx, hours)
+	return convertAll(rows, err, bucket)
+}
+
+func (q *Queries) GetCursorForListS3Objects(
+	ctx context.Context, arg database.GetCursorForListS3ObjectsParams,
+) (database.GetCursorForListS3ObjectsRow, error) {
+	row, err := q.q.GetCursorForListS3Objects(ctx, sqlitedb.GetCursorForListS3ObjectsParams{
+		BucketID: arg.BucketID, Prefix: arg.Column2, Offset: int64(arg.Offset),
+	})
+	return database.GetCursorForListS3ObjectsRow(row), err
+}
+
+func (q *Queries) GetCursorForListS3ObjectsByPrefix(
+	ctx context.Context, arg database.GetCursorForListS3ObjectsByPrefixParams,
+) (database.GetCursorForListS3ObjectsByPrefixRow, error) {
+	row, err := q.q.GetCursorForListS3ObjectsByPrefix(ctx, sqlitedb.GetCursorForListS3ObjectsByPrefixParams{
+		BucketID: arg.BucketID, Prefix: arg.Column2.String, Offset: int64(arg.Offset),
+	})
+	return database.GetCursorForListS3ObjectsByPrefixRow(row), err
+}
+
+func (q *Queries) GetCursorForSearchS3Objects(
+	ctx context.Context, arg database.GetCursorForSearchS3ObjectsParams,
+) (database.GetCursorForSearchS3ObjectsRow, error) {
+	row, err := q.q.GetCursorForSearchS3Objects(ctx, sqlitedb.GetCursorForSearchS3ObjectsParams{
+		BucketID: arg.BucketID, Pattern: arg.Column2.String, Offset: int64(arg.Offset),
+	})
+	return database.GetCursorForSearchS3ObjectsRow(row), err
+}
+
+func (q *Queries) GetDirectChildren(
+	ctx context.Context, arg database.GetDirectChildrenParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.GetDirectChildren(ctx, sqlitedb.GetDirectChildrenParams{
+		BucketID:       arg.BucketID,
+		Prefix:         arg.Column2,
+		CursorIsFolder: arg.CursorIsFolder,
+		CursorKey:      arg.CursorKey,
+		Limit:          int64(arg.Limit),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) GetDuplicateReport(ctx context.Context, name string) (database.DuplicateReport, error) {
+	row, err := q.q.GetDuplicateReport(ctx, name)
+	return database.DuplicateReport(row), err
+}
+
+func (q *Queries) GetInaccessibleBuckets(ctx context.Context, dollar_1 interface{}) ([]database.Bucket, error) {
+	hours, err := hoursArg(dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.q.GetInaccessibleBuckets(ctx, hours)
+	return convertAll(rows, err, bucket)
+}
+
+func (q *Queries) GetJob(ctx context.Context, id int32) (database.Job, error) {
+	row, err := q.q.GetJob(ctx, id)
+	return database.Job(row), err
+}
+
+func (q *Queries) GetLatestScanJob(ctx context.Context, bucketID sql.NullInt32) (database.ScanJob, error) {
+	row, err := q.q.GetLatestScanJob(ctx, bucketID)
+	return database.ScanJob(row), err
+}
+
+func (q *Queries) GetParentFolder(ctx context.Context, arg database.GetParentFolderParams) (database.S3Object, error) {
+	row, err := q.q.GetParentFolder(ctx, sqlitedb.GetParentFolderParams(arg))
+	return database.S3Object(row), err
+}
+
+func (q *Queries) GetPendingRestoreRequest(
+	ctx context.Context, arg database.GetPendingRestoreRequestParams,
+) (database.RestoreRequest, error) {
+	row, err := q.q.GetPendingRestoreRequest(ctx, sqlitedb.GetPendingRestoreRequestParams(arg))
+	return database.RestoreRequest(row), err
+}
+
+func (q *Queries) GetS3Object(ctx context.Context, arg database.GetS3ObjectParams) (database.S3Object, error) {
+	row, err := q.q.GetS3Object(ctx, sqlitedb.GetS3ObjectParams(arg))
+	return database.S3Object(row), err
+}
+
+func (q *Queries) GetS3ObjectTreeStats(
+	ctx context.Context, arg database.GetS3ObjectTreeStatsParams,
+) (database.GetS3ObjectTreeStatsRow, error) {
+	row, err := q.q.GetS3ObjectTreeStats(ctx, sqlitedb.GetS3ObjectTreeStatsParams{
+		BucketID: arg.BucketID, Prefix: likePrefix(arg.PrefixPattern),
+	})
+	return database.GetS3ObjectTreeStatsRow(row), err
+}
+
+func (q *Queries) GetScanJob(ctx context.Context, id int32) (database.ScanJob, error) {
+	row, err := q.q.GetScanJob(ctx, id)
+	return database.ScanJob(row), err
+}
+
+func (q *Queries) GetShareByToken(ctx context.Context, token string) (database.Share, error) {
+	row, err := q.q.GetShareByToken(ctx, token)
+	return database.Share(row), err
+}
+
+func (q *Queries) GetTrashItem(ctx context.Context, id int32) (database.TrashItem, error) {
+	row, err := q.q.GetTrashItem(ctx, id)
+	return database.TrashItem(row), err
+}
+
+func (q *Queries) GetUploadSession(ctx context.Context, id string) (database.UploadSession, error) {
+	row, err := q.q.GetUploadSession(ctx, id)
+	return database.UploadSession(row), err
+}
+
+func (q *Queries) HasTrigramExtension(ctx context.Context) (bool, error) {
+	return q.q.HasTrigramExtension(ctx)
+}
+
+func (q *Queries) InsertDuplicateGroups(ctx context.Context, arg database.InsertDuplicateGroupsParams) error {
+	return q.q.InsertDuplicateGroups(ctx, sqlitedb.InsertDuplicateGroupsParams(arg))
+}
+
+func (q *Queries) InsertDuplicatePrefixes(ctx context.Context, arg database.InsertDuplicatePrefixesParams) error {
+	return q.q.InsertDuplicatePrefixes(ctx, sqlitedb.InsertDuplicatePrefixesParams(arg))
+}
+
+func (q *Queries) InsertObjectChange(ctx context.Context, arg database.InsertObjectChangeParams) error {
+	return q.q.InsertObjectChange(ctx, sqlitedb.InsertObjectChangeParams(arg))
+}
+
+func (q *Queries) InsertObjectTag(ctx context.Context, arg database.InsertObjectTagParams) error {
+	return q.q.InsertObjectTag(ctx, sqlitedb.InsertObjectTagParams(arg))
+}
+
+func (q *Queries) ListAccessibleBuckets(ctx context.Context) ([]database.Bucket, error) {
+	rows, err := q.q.ListAccessibleBuckets(ctx)
+	return convertAll(rows, err, bucket)
+}
+
+func (q *Queries) ListBuckets(ctx context.Context) ([]database.Bucket, error) {
+	rows, err := q.q.ListBuckets(ctx)
+	return convertAll(rows, err, bucket)
+}
+
+func (q *Queries) ListBucketsWithStatus(ctx context.Context) ([]database.ListBucketsWithStatusRow, error) {
+	rows, err := q.q.ListBucketsWithStatus(ctx)
+	return convertAll(rows, err, listBucketsWithStatusRow)
+}
+
+func (q *Queries) ListDirectChildrenByDateAsc(
+	ctx context.Context, arg database.ListDirectChildrenByDateAscParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.ListDirectChildrenByDateAsc(ctx, sqlitedb.ListDirectChildrenByDateAscParams{
+		BucketID:           arg.BucketID,
+		IsFolder:           sql.NullBool{Bool: arg.IsFolder, Valid: true},
+		Prefix:             arg.Prefix,
+		CursorKey:          arg.CursorKey,
+		CursorLastModified: sql.NullTime{Time: arg.CursorLastModified, Valid: true},
+		PageSize:           int64(arg.PageSize),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListDirectChildrenByDateDesc(
+	ctx context.Context, arg database.ListDirectChildrenByDateDescParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.ListDirectChildrenByDateDesc(ctx, sqlitedb.ListDirectChildrenByDateDescParams{
+		BucketID:           arg.BucketID,
+		IsFolder:           sql.NullBool{Bool: arg.IsFolder, Valid: true},
+		Prefix:             arg.Prefix,
+		CursorKey:          arg.CursorKey,
+		CursorLastModified: sql.NullTime{Time: arg.CursorLastModified, Valid: true},
+		PageSize:           int64(arg.PageSize),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListDirectChildrenByNameAsc(
+	ctx context.Context, arg database.ListDirectChildrenByNameAscParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.ListDirectChildrenByNameAsc(ctx, sqlitedb.ListDirectChildrenByNameAscParams{
+		BucketID:  arg.BucketID,
+		IsFolder:  sql.NullBool{Bool: arg.IsFolder, Valid: true},
+		Prefix:    arg.Prefix,
+		CursorKey: arg.CursorKey,
+		PageSize:  int64(arg.PageSize),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListDirectChildrenByNameDesc(
+	ctx context.Context, arg database.ListDirectChildrenByNameDescParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.ListDirectChildrenByNameDesc(ctx, sqlitedb.ListDirectChildrenByNameDescParams{
+		BucketID:  arg.BucketID,
+		IsFolder:  sql.NullBool{Bool: arg.IsFolder, Valid: true},
+		Prefix:    arg.Prefix,
+		CursorKey: arg.CursorKey,
+		PageSize:  int64(arg.PageSize),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListDirectChildrenBySizeAsc(
+	ctx context.Context, arg database.ListDirectChildrenBySizeAscParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.ListDirectChildrenBySizeAsc(ctx, sqlitedb.ListDirectChildrenBySizeAscParams{
+		BucketID:   arg.BucketID,
+		IsFolder:   sql.NullBool{Bool: arg.IsFolder, Valid: true},
+		Prefix:     arg.Prefix,
+		CursorKey:  arg.CursorKey,
+		CursorSize: arg.CursorSize,
+		PageSize:   int64(arg.PageSize),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListDirectChildrenBySizeDesc(
+	ctx context.Context, arg database.ListDirectChildrenBySizeDescParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.ListDirectChildrenBySizeDesc(ctx, sqlitedb.ListDirectChildrenBySizeDescParams{
+		BucketID:   arg.BucketID,
+		IsFolder:   sql.NullBool{Bool: arg.IsFolder, Valid: true},
+		Prefix:     arg.Prefix,
+		CursorKey:  arg.CursorKey,
+		CursorSize: arg.CursorSize,
+		PageSize:   int64(arg.PageSize),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListDirectChildrenByStorageAsc(
+	ctx context.Context, arg database.ListDirectChildrenByStorageAscParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.ListDirectChildrenByStorageAsc(ctx, sqlitedb.ListDirectChildrenByStorageAscParams{
+		BucketID:           arg.BucketID,
+		IsFolder:           sql.NullBool{Bool: arg.IsFolder, Valid: true},
+		Prefix:             arg.Prefix,
+		CursorKey:          arg.CursorKey,
+		CursorStorageClass: arg.CursorStorageClass,
+		PageSize:           int64(arg.PageSize),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListDirectChildrenByStorageDesc(
+	ctx context.Context, arg database.ListDirectChildrenByStorageDescParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.ListDirectChildrenByStorageDesc(ctx, sqlitedb.ListDirectChildrenByStorageDescParams{
+		BucketID:           arg.BucketID,
+		IsFolder:           sql.NullBool{Bool: arg.IsFolder, Valid: true},
+		Prefix:             arg.Prefix,
+		CursorKey:          arg.CursorKey,
+		CursorStorageClass: arg.CursorStorageClass,
+		PageSize:           int64(arg.PageSize),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListDuplicateGroups(
+	ctx context.Context, arg database.ListDuplicateGroupsParams,
+) ([]database.DuplicateGroup, error) {
+	rows, err := q.q.ListDuplicateGroups(ctx, sqlitedb.ListDuplicateGroupsParams{
+		BucketID: arg.BucketID, Offset: int64(arg.Offset), Limit: int64(arg.Limit),
+	})
+	return convertAll(rows, err, func(g sqlitedb.DuplicateGroup) database.DuplicateGroup {
+		return database.DuplicateGroup(g)
+	})
+}
+
+func (q *Queries) ListDuplicateObjects(
+	ctx context.Context, arg database.ListDuplicateObjectsParams,
+) ([]database.ListDuplicateObjectsRow, error) {
+	groupIDs, err := jsonArray(arg.GroupIds)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.q.ListDuplicateObjects(ctx, sqlitedb.ListDuplicateObjectsParams{
+		GroupIds: groupIDs, KeyPrefix: arg.KeyPrefix,
+	})
+	return convertAll(rows, err, func(r sqlitedb.ListDuplicateObjectsRow) database.ListDuplicateObjectsRow {
+		return database.ListDuplicateObjectsRow(r)
+	})
+}
+
+func (q *Queries) ListDuplicatePrefixes(
+	ctx context.Context, arg database.ListDuplicatePrefixesParams,
+) ([]database.ListDuplicatePrefixesRow, error) {
+	rows, err := q.q.ListDuplicatePrefixes(ctx, sqlitedb.ListDuplicatePrefixesParams{
+		BucketID: arg.BucketID, Limit: int64(arg.Limit),
+	})
+	return convertAll(rows, err, func(r sqlitedb.ListDuplicatePrefixesRow) database.ListDuplicatePrefixesRow {
+		return database.ListDuplicatePrefixesRow(r)
+	})
+}
+
+func (q *Queries) ListExportObjects(
+	ctx context.Context, arg database.ListExportObjectsParams,
+) ([]database.ListExportObjectsRow, error) {
+	rows, err := q.q.ListExportObjects(ctx, sqlitedb.ListExportObjectsParams{
+		BucketID:  arg.BucketID,
+		Prefix:    arg.Prefix,
+		Pattern:   arg.Pattern,
+		Exclude:   arg.Exclude,
+		AfterKey:  arg.AfterKey,
+		BatchSize: int64(arg.BatchSize),
+	})
+	return convertAll(rows, err, func(r sqlitedb.ListExportObjectsRow) database.ListExportObjectsRow {
+		return database.ListExportObjectsRow(r)
+	})
+}
+
+func (q *Queries) ListObjectsWithStaleTags(
+	ctx context.Context, arg database.ListObjectsWithStaleTagsParams,
+) ([]database.ListObjectsWithStaleTagsRow, error) {
+	rows, err := q.q.ListObjectsWithStaleTags(ctx, sqlitedb.ListObjectsWithStaleTagsParams{
+		BucketID: arg.BucketID, AfterID: arg.AfterID, Limit: int64(arg.Limit),
+	})
+	return convertAll(rows, err, func(r sqlitedb.ListObjectsWithStaleTagsRow) database.ListObjectsWithStaleTagsRow {
+		return database.ListObjectsWithStaleTagsRow(r)
+	})
+}
+
+func (q *Queries) ListPendingRestoreRequests(ctx context.Context, limit int32) ([]database.RestoreRequest, error) {
+	rows, err := q.q.ListPendingRestoreRequests(ctx, int64(limit))
+	return convertAll(rows, err, restoreRequest)
+}
+
+func (q *Queries) ListRestoreRequests(ctx context.Context, limit int32) ([]database.RestoreRequest, error) {
+	rows, err := q.q.ListRestoreRequests(ctx, int64(limit))
+	return convertAll(rows, err, restoreRequest)
+}
+
+func (q *Queries) ListS3Files(ctx context.Context, arg database.ListS3FilesParams) ([]database.S3Object, error) {
+	rows, err := q.q.ListS3Files(ctx, sqlitedb.ListS3FilesParams{
+		BucketID: arg.BucketID, Prefix: arg.Column2, CursorKey: arg.CursorKey, Limit: int64(arg.Limit),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListS3Folders(ctx context.Context, arg database.ListS3FoldersParams) ([]database.S3Object, error) {
+	rows, err := q.q.ListS3Folders(ctx, sqlitedb.ListS3FoldersParams{
+		BucketID: arg.BucketID, Prefix: arg.Column2, CursorKey: arg.CursorKey, Limit: int64(arg.Limit),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListS3ObjectTreeSample(
+	ctx context.Context, arg database.ListS3ObjectTreeSampleParams,
+) ([]string, error) {
+	return q.q.ListS3ObjectTreeSample(ctx, sqlitedb.ListS3ObjectTreeSampleParams{
+		BucketID: arg.BucketID, Prefix: likePrefix(arg.PrefixPattern), MaxKeys: int64(arg.MaxKeys),
+	})
+}
+
+func (q *Queries) ListS3Objects(ctx context.Context, arg database.ListS3ObjectsParams) ([]database.S3Object, error) {
+	rows, err := q.q.ListS3Objects(ctx, sqlitedb.ListS3ObjectsParams{
+		BucketID:       arg.BucketID,
+		Prefix:         arg.Column2,
+		CursorIsFolder: arg.CursorIsFolder,
+		CursorKey:      arg.CursorKey,
+		Limit:          int64(arg.Limit),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListS3ObjectsByKeys(
+	ctx context.Context, arg database.ListS3ObjectsByKeysParams,
+) ([]database.S3Object, error) {
+	keys, err := jsonArray(arg.Keys)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.q.ListS3ObjectsByKeys(ctx, sqlitedb.ListS3ObjectsByKeysParams{BucketID: arg.BucketID, Keys: keys})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListS3ObjectsByPrefix(
+	ctx context.Context, arg database.ListS3ObjectsByPrefixParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.ListS3ObjectsByPrefix(ctx, sqlitedb.ListS3ObjectsByPrefixParams{
+		BucketID:       arg.BucketID,
+		Prefix:         arg.Column2.String,
+		CursorIsFolder: arg.CursorIsFolder,
+		CursorKey:      arg.CursorKey,
+		Limit:          int64(arg.Limit),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) ListScanJobChangeCounts(
+	ctx context.Context, arg database.ListScanJobChangeCountsParams,
+) ([]database.ListScanJobChangeCountsRow, error) {
+	rows, err := q.q.ListScanJobChangeCounts(ctx, sqlitedb.ListScanJobChangeCountsParams{
+		KeyPrefix: arg.KeyPrefix,
+		BucketID:  sql.NullInt32{Int32: arg.BucketID, Valid: true},
+		Limit:     int64(arg.Limit),
+	})
+	return convertAll(rows, err, func(r sqlitedb.ListScanJobChangeCountsRow) database.ListScanJobChangeCountsRow {
+		return database.ListScanJobChangeCountsRow(r)
+	})
+}
+
+func (q *Queries) ListScanJobs(ctx context.Context, arg database.ListScanJobsParams) ([]database.ScanJob, error) {
+	rows, err := q.q.ListScanJobs(ctx, sqlitedb.ListScanJobsParams{
+		BucketID: arg.BucketID, Offset: int64(arg.Offset), Limit: int64(arg.Limit),
+	})
+	return convertAll(rows, err, scanJob)
+}
+
+func (q *Queries) ListSharesByCreator(
+	ctx context.Context, arg database.ListSharesByCreatorParams,
+) ([]database.Share, error) {
+	rows, err := q.q.ListSharesByCreator(ctx, sqlitedb.ListSharesByCreatorParams{
+		Creator: arg.Creator, Limit: int64(arg.Limit),
+	})
+	return convertAll(rows, err, share)
+}
+
+func (q *Queries) ListStaleUploadSessions(
+	ctx context.Context, arg database.ListStaleUploadSessionsParams,
+) ([]database.UploadSession, error) {
+	rows, err := q.q.ListStaleUploadSessions(ctx, sqlitedb.ListStaleUploadSessionsParams{
+		UpdatedAt: arg.UpdatedAt, Limit: int64(arg.Limit),
+	})
+	return convertAll(rows, err, uploadSession)
+}
+
+func (q *Queries) ListTrashItems(ctx context.Context, limit int32) ([]database.TrashItem, error) {
+	rows, err := q.q.ListTrashItems(ctx, int64(limit))
+	return convertAll(rows, err, trashItem)
+}
+
+func (q *Queries) ListTrashItemsDeletedBefore(
+	ctx context.Context, arg database.ListTrashItemsDeletedBeforeParams,
+) ([]database.TrashItem, error) {
+	rows, err := q.q.ListTrashItemsDeletedBefore(ctx, sqlitedb.ListTrashItemsDeletedBeforeParams{
+		DeletedAt: arg.DeletedAt, Limit: int64(arg.Limit),
+	})
+	return convertAll(rows, err, trashItem)
+}
+
+func (q *Queries) ListUploadParts(ctx context.Context, sessionID string) ([]database.UploadPart, error) {
+	rows, err := q.q.ListUploadParts(ctx, sessionID)
+	return convertAll(rows, err, uploadPart)
+}
+
+func (q *Queries) MarkAllBucketsForDeletion(ctx context.Context) error {
+	return q.q.MarkAllBucketsForDeletion(ctx)
+}
+
+func (q *Queries) MarkAllObjectsForDeletion(ctx context.Context, bucketID int32) error {
+	return q.q.MarkAllObjectsForDeletion(ctx, bucketID)
+}
+
+func (q *Queries) MarkBucketForDeletion(ctx context.Context, id int32) error {
+	return q.q.MarkBucketForDeletion(ctx, id)
+}
+
+func (q *Queries) MoveS3ObjectTree(ctx context.Context, arg database.MoveS3ObjectTreeParams) (int64, error) {
+	return q.q.MoveS3ObjectTree(ctx, sqlitedb.MoveS3ObjectTreeParams{
+		Dst: arg.Dst, Src: arg.Src, DstParent: arg.DstParent, BucketID: arg.BucketID,
+	})
+}
+
+func (q *Queries) PurgeObjectChanges(ctx context.Context, arg database.PurgeObjectChangesParams) (int64, error) {
+	return q.q.PurgeObjectChanges(ctx, sqlitedb.PurgeObjectChangesParams{
+		ChangedBefore: arg.ChangedBefore, MaxRows: int64(arg.MaxRows),
+	})
+}
+
+func (q *Queries) RecordDeletedObjectChanges(ctx context.Context, arg database.RecordDeletedObjectChangesParams) error {
+	return q.q.RecordDeletedObjectChanges(ctx, sqlitedb.RecordDeletedObjectChangesParams(arg))
+}
+
+func (q *Queries) RecordShareDownload(ctx context.Context, id int32) error {
+	return q.q.RecordShareDownload(ctx, id)
+}
+
+func (q *Queries) RevokeShare(ctx context.Context, arg database.RevokeShareParams) (int64, error) {
+	return q.q.RevokeShare(ctx, sqlitedb.RevokeShareParams(arg))
+}
+
+func (q *Queries) SearchS3Objects(
+	ctx context.Context, arg database.SearchS3ObjectsParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.SearchS3Objects(ctx, sqlitedb.SearchS3ObjectsParams{
+		BucketID:       arg.BucketID,
+		Pattern:        arg.Column2.String,
+		CursorIsFolder: arg.CursorIsFolder,
+		CursorKey:      arg.CursorKey,
+		Limit:          int64(arg.Limit),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+// tagFilter is a filter of SearchS3ObjectsByTags.
+type tagFilter struct {
+	Source string `json:"source"`
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+}
+
+func (q *Queries) SearchS3ObjectsByTags(
+	ctx context.Context, arg database.SearchS3ObjectsByTagsParams,
+) ([]database.S3Object, error) {
+	filters := make([]tagFilter, len(arg.TagKeys))
+	for i, key := range arg.TagKeys {
+		filters[i] = tagFilter{Source: arg.Sources[i], Key: key, Value: arg.TagValues[i]}
+	}
+	encoded, err := jsonArray(filters)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.q.SearchS3ObjectsByTags(ctx, sqlitedb.SearchS3ObjectsByTagsParams{
+		BucketID: arg.BucketID, Pattern: arg.Pattern, Filters: encoded, Limit: int64(arg.Limit),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+// SearchS3ObjectsRanked ranks the matches whose basename contains the pattern first, without trigram
+// similarity to the term.
+func (q *Queries) SearchS3ObjectsRanked(
+	ctx context.Context, arg database.SearchS3ObjectsRankedParams,
+) ([]database.S3Object, error) {
+	rows, err := q.q.SearchS3ObjectsRanked(ctx, sqlitedb.SearchS3ObjectsRankedParams{
+		Pattern:     arg.Pattern,
+		BucketID:    arg.BucketID,
+		Candidates:  int64(arg.Candidates),
+		ResultLimit: int64(arg.ResultLimit),
+	})
+	return convertAll(rows, err, s3Object)
+}
+
+func (q *Queries) SetObjectTagsEtag(ctx context.Context, arg database.SetObjectTagsEtagParams) error {
+	return q.q.SetObjectTagsEtag(ctx, sqlitedb.SetObjectTagsEtagParams{TagsEtag: arg.TagsEtag, ID: arg.ID})
+}
+
+func (q *Queries) SetUploadSessionStatus(ctx context.Context, arg database.SetUploadSessionStatusParams) error {
+	return q.q.SetUploadSessionStatus(ctx, sqlitedb.SetUploadSessionStatusParams{Status: arg.Status, ID: arg.ID})
+}
+
+func (q *Queries) SimulateLifecycleAction(
+	ctx context.Context, arg database.SimulateLifecycleActionParams,
+) (database.SimulateLifecycleActionRow, error) {
+	storageClasses, err := jsonArray(arg.StorageClasses)
+	if err != nil {
+		return database.SimulateLifecycleActionRow{}, err
+	}
+	row, err := q.q.SimulateLifecycleAction(ctx, sqlitedb.SimulateLifecycleActionParams{
+		DueBefore:      sql.NullTime{Time: arg.DueBefore, Valid: true},
+		StorageClasses: storageClasses,
+		BucketName:     arg.BucketName,
+		Prefix:         arg.Prefix,
+		MinSize:        arg.MinSize,
+		UpcomingBefore: sql.NullTime{Time: arg.UpcomingBefore, Valid: true},
+		NotBefore:      sql.NullTime{Time: arg.NotBefore, Valid: true},
+	})
+	return database.SimulateLifecycleActionRow(row), err
+}
+
+func (q *Queries) StartJob(ctx context.Context, arg database.StartJobParams) error {
+	return q.q.StartJob(ctx, sqlitedb.StartJobParams{TotalItems: arg.TotalItems, TotalBytes: arg.TotalBytes, ID: arg.ID})
+}
+
+func (q *Queries) SummarizeCatalogComparison(
+	ctx context.Context, arg database.SummarizeCatalogComparisonParams,
+) (database.SummarizeCatalogComparisonRow, error) {
+	row, err := q.q.SummarizeCatalogComparison(ctx, sqlitedb.SummarizeCatalogComparisonParams(arg))
+	return database.SummarizeCatalogComparisonRow(row), err
+}
+
+func (q *Queries) SummarizeObjectTags(
+	ctx context.Context, arg database.SummarizeObjectTagsParams,
+) ([]database.SummarizeObjectTagsRow, error) {
+	rows, err := q.q.SummarizeObjectTags(ctx, sqlitedb.SummarizeObjectTagsParams{
+		BucketID: arg.BucketID, TagKey: arg.TagKey, Limit: int64(arg.Limit),
+	})
+	return convertAll(rows, err, func(r sqlitedb.SummarizeObjectTagsRow) database.SummarizeObjectTagsRow {
+		return database.SummarizeObjectTagsRow(r)
+	})
+}
+
+func (q *Queries) TakeRestoreNotifications(ctx context.Context, requestedBy string) ([]database.RestoreRequest, error) {
+	rows, err := q.q.TakeRestoreNotifications(ctx, requestedBy)
+	return convertAll(rows, err, restoreRequest)
+}
+
+func (q *Queries) TouchUploadSession(ctx context.Context, id string) error {
+	return q.q.TouchUploadSession(ctx, id)
+}
+
+func (q *Queries) UnmarkBucketForDeletion(ctx context.Context, id int32) error {
+	return q.q.UnmarkBucketForDeletion(ctx, id)
+}
+
+func (q *Queries) UnmarkObjectForDeletion(ctx context.Context, arg database.UnmarkObjectForDeletionParams) error {
+	return q.q.UnmarkObjectForDeletion(ctx, sqlitedb.UnmarkObjectForDeletionParams(arg))
+}
+
+func (q *Queries) UpdateBucketAccessError(ctx context.Context, arg database.UpdateBucketAccessErrorParams) error {
+	return q.q.UpdateBucketAccessError(ctx, sqlitedb.UpdateBucketAccessErrorParams{
+		AccessError: arg.AccessError, ID: arg.ID,
+	})
+}
+
+func (q *Queries) UpdateJobProgress(ctx context.Context, arg database.UpdateJobProgressParams) error {
+	return q.q.UpdateJobProgress(ctx, sqlitedb.UpdateJobProgressParams{
+		DoneItems:    arg.DoneItems,
+		FailedItems:  arg.FailedItems,
+		SkippedItems: arg.SkippedItems,
+		DoneBytes:    arg.DoneBytes,
+		ID:           arg.ID,
+	})
+}
+
+func (q *Queries) UpdateRestoreRequestStatus(ctx context.Context, arg database.UpdateRestoreRequestStatusParams) error {
+	return q.q.UpdateRestoreRequestStatus(ctx, sqlitedb.UpdateRestoreRequestStatusParams(arg))
+}
+
+func (q *Queries) UpdateS3Object(ctx context.Context, arg database.UpdateS3ObjectParams) (database.S3Object, error) {
+	row, err := q.q.UpdateS3Object(ctx, sqlitedb.UpdateS3ObjectParams{
+		Size:         arg.Size,
+		LastModified: arg.LastModified,
+		Etag:         arg.Etag,
+		StorageClass: arg.StorageClass,
+		BucketID:     arg.BucketID,
+		Key:          arg.Key,
+	})
+	return database.S3Object(row), err
+}
+
+func (q *Queries) UpdateScanJobBucketStats(
+	ctx context.Context, arg database.UpdateScanJobBucketStatsParams,
+) (database.ScanJob, error) {
+	row, err := q.q.UpdateScanJobBucketStats(ctx, sqlitedb.UpdateScanJobBucketStatsParams{
+		BucketsValidated:          arg.BucketsValidated,
+		BucketsMarkedInaccessible: arg.BucketsMarkedInaccessible,
+		BucketsCleanedUp:          arg.BucketsCleanedUp,
+		BucketValidationErrors:    arg.BucketValidationErrors,
+		ID:                        arg.ID,
+	})
+	return database.ScanJob(row), err
+}
+
+func (q *Queries) UpdateScanJobError(
+	ctx context.Context, arg database.UpdateScanJobErrorParams,
+) (database.ScanJob, error) {
+	row, err := q.q.UpdateScanJobError(ctx, sqlitedb.UpdateScanJobErrorParams{ErrorMessage: arg.ErrorMessage, ID: arg.ID})
+	return database.ScanJob(row), err
+}
+
+func (q *Queries) UpdateScanJobFullStats(
+	ctx context.Context, arg database.UpdateScanJobFullStatsParams,
+) (database.ScanJob, error) {
+	row, err := q.q.UpdateScanJobFullStats(ctx, sqlitedb.UpdateScanJobFullStatsParams{
+		ObjectsScanned:            arg.ObjectsScanned,
+		ObjectsCreated:            arg.ObjectsCreated,
+		ObjectsUpdated:            arg.ObjectsUpdated,
+		ObjectsDeleted:            arg.ObjectsDeleted,
+		BucketsValidated:          arg.BucketsValidated,
+		BucketsMarkedInaccessible: arg.BucketsMarkedInaccessible,
+		BucketsCleanedUp:          arg.BucketsCleanedUp,
+		BucketValidationErrors:    arg.BucketValidationErrors,
+		ID:                        arg.ID,
+	})
+	return database.ScanJob(row), err
+}
+
+func (q *Queries) UpdateScanJobProgress(
+	ctx context.Context, arg database.UpdateScanJobProgressParams,
+) (database.ScanJob, error) {
+	row, err := q.q.UpdateScanJobProgress(ctx, sqlitedb.UpdateScanJobProgressParams{
+		ObjectsScanned: arg.ObjectsScanned, ID: arg.ID,
+	})
+	return database.ScanJob(row), err
+}
+
+func (q *Queries) UpdateScanJobStats(
+	ctx context.Context, arg database.UpdateScanJobStatsParams,
+) (database.ScanJob, error) {
+	row, err := q.q.UpdateScanJobStats(ctx, sqlitedb.UpdateScanJobStatsParams{
+		ObjectsScanned: arg.ObjectsScanned,
+		ObjectsCreated: arg.ObjectsCreated,
+		ObjectsUpdated: arg.ObjectsUpdated,
+		ObjectsDeleted: arg.ObjectsDeleted,
+		ID:             arg.ID,
+	})
+	return database.ScanJob(row), err
+}
+
+func (q *Queries) UpdateScanJobStatus(
+	ctx context.Context, arg database.UpdateScanJobStatusParams,
+) (database.ScanJob, error) {
+	row, err := q.q.UpdateScanJobStatus(ctx, sqlitedb.UpdateScanJobStatusParams{Status: arg.Column2, ID: arg.ID})
+	return database.ScanJob(row), err
+}
+
+func (q *Queries) UpsertBucketConfig(ctx context.Context, arg database.UpsertBucketConfigParams) error {
+	return q.q.UpsertBucketConfig(ctx, sqlitedb.UpsertBucketConfigParams{
+		BucketID: arg.BucketID, ScanJobID: arg.ScanJobID, Config: string(arg.Config),
+	})
+}
+
+func (q *Queries) UpsertDuplicateReport(ctx context.Context, bucketID int32) error {
+	return q.q.UpsertDuplicateReport(ctx, bucketID)
+}
+
+// inventoryFolder is a folder of UpsertInventoryFolders.
+type inventoryFolder struct {
+	Key    string `json:"key"`
+	Prefix string `json:"prefix"`
+}
+
+func (q *Queries) UpsertInventoryFolders(ctx context.Context, arg database.UpsertInventoryFoldersParams) error {
+	folders := make([]inventoryFolder, len(arg.Keys))
+	for i, key := range arg.Keys {
+		folders[i] = inventoryFolder{Key: key, Prefix: arg.Prefixes[i]}
+	}
+	encoded, err := jsonArray(folders)
+	if err != nil {
+		return err
+	}
+	return q.q.UpsertInventoryFolders(ctx, sqlitedb.UpsertInventoryFoldersParams{BucketID: arg.BucketID, Folders: encoded})
+}
+
+// inventoryObject is a file of UpsertInventoryObjects and RecordInventoryChanges.
+type inventoryObject struct {
+	Key              string `json:"key"`
+	Size             int64  `json:"size"`
+	LastModified     string `json:"last_modified"`
+	Etag             string `json:"etag"`
+	StorageClass     string `json:"storage_class"`
+	EncryptionStatus string `json:"encryption_status"`
+	TagCount         string `json:"tag_count"`
+	Prefix           string `json:"prefix"`
+}
+
+// UpsertInventoryObjects runs the statements of the PostgreSQL query one after the other: the files
+// already in the catalog, then the changes of the batch, then the upsert.
+func (q *Queries) UpsertInventoryObjects(
+	ctx context.Context, arg database.UpsertInventoryObjectsParams,
+) ([]bool, error) {
+	objects := make([]inventoryObject, len(arg.Keys))
+	for i, key := range arg.Keys {
+		lastModified, err := sqliteTime(arg.LastModified[i])
+		if err != nil {
+			return nil, err
+		}
+		objects[i] = inventoryObject{
+			Key:              key,
+			Size:             arg.Sizes[i],
+			LastModified:     lastModified,
+			Etag:             arg.Etags[i],
+			StorageClass:     arg.StorageClasses[i],
+			EncryptionStatus: arg.EncryptionStatuses[i],
+			TagCount:         arg.TagCounts[i],
+			Prefix:           arg.Prefixes[i],
+		}
+	}
+	encoded, err := jsonArray(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := q.ListS3ObjectsByKeys(ctx, database.ListS3ObjectsByKeysParams{BucketID: arg.BucketID, Keys: arg.Keys})
+	if err != nil {
+		return nil, err
+	}
+	if arg.RecordChanges {
+		if err := q.q.RecordInventoryChanges(ctx, sqlitedb.RecordInventoryChangesParams{
+			BucketID: arg.BucketID, ScanJobID: arg.ScanJobID, Objects: encoded,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := q.q.UpsertInventoryObjects(ctx, sqlitedb.UpsertInventoryObjectsParams{
+		BucketID: arg.BucketID, Objects: encoded,
+	}); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]struct{}, len(existing))
+	for _, obj := range existing {
+		known[obj.Key] = struct{}{}
+	}
+	created := make([]bool, len(arg.Keys))
+	for i, key := range arg.Keys {
+		_, ok := known[key]
+		created[i] = !ok
+	}
+	return created, nil
+}
+
+func (q *Queries) UpsertUploadPart(ctx context.Context, arg database.UpsertUploadPartParams) error {
+	return q.q.UpsertUploadPart(ctx, sqlitedb.UpsertUploadPartParams(arg))
+}
@@ -0,0 +1,48 @@
+package dbsvc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// GetBucketConfig returns the cached configuration of a bucket, or nil if no scan read it yet.
+func (s *Service) GetBucketConfig(ctx context.Context, bucketName string) (*dto.BucketConfig, error) {
+	row, err := s.queries.GetBucketConfig(ctx, bucketName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil //nolint:nilnil // a bucket not scanned yet is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket configuration: %w", err)
+	}
+	var config dto.BucketConfig
+	if err := json.Unmarshal(row.Config, &config); err != nil {
+		return nil, fmt.Errorf("invalid bucket configuration of %s: %w", bucketName, err)
+	}
+	config.FetchedAt = row.FetchedAt
+	return &config, nil
+}
+
+// SaveBucketConfig caches the configuration of a bucket of the catalog, replacing the one of the last scan.
+func (s *Service) SaveBucketConfig(ctx context.Context, config dto.BucketConfig) error {
+	bucket, err := s.queries.GetBucket(ctx, config.Bucket)
+	if err != nil {
+		return fmt.Errorf("failed to get bucket %s: %w", config.Bucket, err)
+	}
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to encode bucket configuration: %w", err)
+	}
+	if err := s.queries.UpsertBucketConfig(ctx, database.UpsertBucketConfigParams{
+		BucketID: bucket.ID,
+		Config:   encoded,
+	}); err != nil {
+		return fmt.Errorf("failed to save bucket configuration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package dbsvc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+var (
+	// ErrScanJobNotFound is returned for a scan job that does not exist or belongs to another bucket.
+	ErrScanJobNotFound = errors.New("scan job not found")
+	// ErrInvalidScanRange is returned when a diff does not go from an earlier scan job to a later one.
+	ErrInvalidScanRange = errors.New("the first scan job must be earlier than the second")
+)
+
+// ListScanChanges returns the latest scan jobs of a bucket, latest first, with the number of files under
+// keyPrefix each one found created, modified and deleted.
+func (s *Service) ListScanChanges(ctx context.Context, bucketName, keyPrefix string, limit int) ([]dto.ScanChanges, error) {
+	bucket, err := s.queries.GetBucket(ctx, bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("bucket not found: %w", err)
+	}
+	rows, err := s.queries.ListScanJobChangeCounts(ctx, database.ListScanJobChangeCountsParams{
+		BucketID:  bucket.ID,
+		KeyPrefix: keyPrefix,
+		Limit:     safeInt32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scan changes: %w", err)
+	}
+
+	scans := make([]dto.ScanChanges, 0, len(rows))
+	for _, r := range rows {
+		scan := dto.ScanChanges{
+			ScanJobID: r.ID,
+			Status:    r.Status,
+			Created:   r.Created,
+			Modified:  r.Modified,
+			Deleted:   r.Deleted,
+		}
+		if r.StartedAt.Valid {
+			scan.StartedAt = &r.StartedAt.Time
+		}
+		if r.CompletedAt.Valid {
+			scan.CompletedAt = &r.CompletedAt.Time
+		}
+		scans = append(scans, scan)
+	}
+	return scans, nil
+}
+
+// DiffScanJobs returns the net change of the files of a bucket under keyPrefix between the end of scan
+// job from, 0 for an empty catalog, and the end of scan job to. At most limit changes are listed.
+func (s *Service) DiffScanJobs(
+	ctx context.Context, bucketName string, from, to int32, keyPrefix string, limit int,
+) (*dto.ScanDiff, error) {
+	bucket, err := s.queries.GetBucket(ctx, bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("bucket not found: %w", err)
+	}
+	if from >= to {
+		return nil, ErrInvalidScanRange
+	}
+	for _, id := range []int32{from, to} {
+		if id == 0 {
+			continue
+		}
+		job, err := s.queries.GetScanJob(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && job.BucketID.Int32 != bucket.ID) {
+			return nil, fmt.Errorf("%w: %d", ErrScanJobNotFound, id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get scan job %d: %w", id, err)
+		}
+	}
+
+	rows, err := s.queries.DiffScanJobs(ctx, database.DiffScanJobsParams{
+		BucketID:  bucket.ID,
+		FromJobID: from,
+		ToJobID:   to,
+		KeyPrefix: keyPrefix,
+		Limit:     safeInt32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff scan jobs: %w", err)
+	}
+
+	diff := &dto.ScanDiff{Bucket: bucketName, From: from, To: to, Prefix: keyPrefix, Changes: []dto.ObjectChange{}}
+	if len(rows) > limit {
+		rows, diff.Truncated = rows[:limit], true
+	}
+	for _, r := range rows {
+		change := dto.ObjectChange{
+			Key:     r.Key,
+			Change:  r.Change,
+			OldETag: r.OldEtag.String,
+			NewETag: r.NewEtag.String,
+		}
+		if r.OldSize.Valid {
+			change.OldSize = &r.OldSize.Int64
+		}
+		if r.NewSize.Valid {
+			change.NewSize = &r.NewSize.Int64
+		}
+		diff.Changes = append(diff.Changes, change)
+	}
+	return diff, nil
+}
+
+// PurgeObjectChanges deletes up to limit of the changes recorded before the given time, oldest first,
+// and returns how many were deleted.
+func (s *Service) PurgeObjectChanges(ctx context.Context, before time.Time, limit int) (int64, error) {
+	n, err := s.queries.PurgeObjectChanges(ctx, database.PurgeObjectChangesParams{
+		ChangedBefore: before,
+		MaxRows:       safeInt32(limit),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge object changes: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,90 @@
+package dbsvc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// CompareCatalogs returns the files differing between two sides of a comparison, ordered by their key
+// relative to the prefix of each side: at most limit files after offset, or after the key afterKey when set.
+// Only files with the given difference are returned, unless difference is empty.
+func (s *Service) CompareCatalogs(
+	ctx context.Context, source, target dto.CompareSide, difference, afterKey string, offset, limit int,
+) ([]dto.CompareEntry, error) {
+	sourceBucket, err := s.queries.GetBucket(ctx, source.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("bucket %s not found: %w", source.Bucket, err)
+	}
+	targetBucket, err := s.queries.GetBucket(ctx, target.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("bucket %s not found: %w", target.Bucket, err)
+	}
+
+	rows, err := s.queries.CompareCatalogs(ctx, database.CompareCatalogsParams{
+		SourceBucketID: sourceBucket.ID,
+		SourcePrefix:   source.Prefix,
+		TargetBucketID: targetBucket.ID,
+		TargetPrefix:   target.Prefix,
+		OnlyDifference: difference,
+		AfterKey:       afterKey,
+		RowOffset:      safeInt32(offset),
+		RowLimit:       safeInt32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare catalogs: %w", err)
+	}
+
+	entries := make([]dto.CompareEntry, 0, len(rows))
+	for _, r := range rows {
+		entry := dto.CompareEntry{
+			Key:                r.RelKey,
+			Difference:         r.Difference,
+			SourceETag:         r.SourceEtag.String,
+			SourceStorageClass: r.SourceStorageClass.String,
+			TargetETag:         r.TargetEtag.String,
+			TargetStorageClass: r.TargetStorageClass.String,
+		}
+		if r.SourceSize.Valid {
+			entry.SourceSize = &r.SourceSize.Int64
+		}
+		if r.TargetSize.Valid {
+			entry.TargetSize = &r.TargetSize.Int64
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// SummarizeComparison counts the files of each side of a comparison and the files with each difference.
+func (s *Service) SummarizeComparison(ctx context.Context, source, target dto.CompareSide) (dto.CompareSummary, error) {
+	summary := dto.CompareSummary{Source: source, Target: target}
+	sourceBucket, err := s.queries.GetBucket(ctx, source.Bucket)
+	if err != nil {
+		return summary, fmt.Errorf("bucket %s not found: %w", source.Bucket, err)
+	}
+	targetBucket, err := s.queries.GetBucket(ctx, target.Bucket)
+	if err != nil {
+		return summary, fmt.Errorf("bucket %s not found: %w", target.Bucket, err)
+	}
+
+	row, err := s.queries.SummarizeCatalogComparison(ctx, database.SummarizeCatalogComparisonParams{
+		SourceBucketID: sourceBucket.ID,
+		SourcePrefix:   source.Prefix,
+		TargetBucketID: targetBucket.ID,
+		TargetPrefix:   target.Prefix,
+	})
+	if err != nil {
+		return summary, fmt.Errorf("failed to summarize comparison: %w", err)
+	}
+	summary.SourceObjects = row.SourceObjects
+	summary.TargetObjects = row.TargetObjects
+	summary.MissingTarget = row.MissingTarget
+	summary.MissingSource = row.MissingSource
+	summary.SizeMismatches = row.SizeMismatches
+	summary.ETagMismatches = row.EtagMismatches
+	summary.StorageClassMismatches = row.StorageClassMismatches
+	return summary, nil
+}
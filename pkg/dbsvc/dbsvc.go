@@ -10,10 +10,12 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sgaunet/s3xplorer/pkg/config"
 	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dbinit"
 	"github.com/sgaunet/s3xplorer/pkg/dto"
 )
 
@@ -23,16 +25,21 @@ var ErrNoParentFolder = errors.New("no parent folder")
 // Service provides database operations for S3 objects.
 type Service struct {
 	db      *sql.DB
-	queries *database.Queries
+	queries database.Querier
 	cfg     config.Config
 	log     *slog.Logger
+
+	// trigram search availability, detected lazily on first search
+	trgmMu        sync.Mutex
+	trgmChecked   bool
+	trgmAvailable bool
 }
 
 // NewService creates a new database service.
 func NewService(cfg config.Config, db *sql.DB) *Service {
 	return &Service{
 		db:      db,
-		queries: database.New(db),
+		queries: dbinit.NewQuerier(cfg.Database.Driver(), db),
 		cfg:     cfg,
 		log:     slog.New(slog.DiscardHandler),
 	}
@@ -154,7 +161,8 @@ func (s *Service) GetObjects(
 	return s.convertToDTO(objects), nil
 }
 
-// SearchObjects searches for objects matching the query.
+// SearchObjects searches for objects matching the query. Queries with tag:key=value or meta:key=value
+// terms only return objects carrying those indexed tags.
 func (s *Service) SearchObjects(
 	ctx context.Context, bucketName, query string, limit, offset int,
 ) ([]dto.S3Object, error) {
@@ -163,11 +171,33 @@ func (s *Service) SearchObjects(
 		return nil, fmt.Errorf("bucket not found: %w", err)
 	}
 
+	name, filters := dto.ParseSearchQuery(query)
+	if len(filters) > 0 {
+		return s.searchObjectsByTags(ctx, bucket.ID, name, filters, limit)
+	}
+
+	pattern := escapeLikePattern(query)
+
+	if s.trigramSearchAvailable(ctx) {
+		objects, err := s.queries.SearchS3ObjectsRanked(ctx, database.SearchS3ObjectsRankedParams{
+			BucketID:    bucket.ID,
+			Pattern:     pattern,
+			Candidates:  safeInt32(max(limit, rankedSearchCandidates)),
+			Term:        query,
+			ResultLimit: safeInt32(limit),
+		})
+		if err == nil {
+			return s.convertToDTO(objects), nil
+		}
+		s.log.Warn("Trigram search failed, falling back to ILIKE scan", slog.String("error", err.Error()))
+		s.resetTrigramDetection()
+	}
+
 	// Note: offset parameter is deprecated with keyset pagination
 	// Always fetches from beginning (nil cursors) for simplicity
 	objects, err := s.queries.SearchS3Objects(ctx, database.SearchS3ObjectsParams{
 		BucketID:       bucket.ID,
-		Column2:        sql.NullString{String: query, Valid: true},
+		Column2:        sql.NullString{String: pattern, Valid: true},
 		Limit:          safeInt32(limit),
 		CursorIsFolder: sql.NullBool{},   // nil cursor = start from beginning
 		CursorKey:      sql.NullString{}, // nil cursor = start from beginning
@@ -179,6 +209,48 @@ func (s *Service) SearchObjects(
 	return s.convertToDTO(objects), nil
 }
 
+// GetObject returns the catalog entry of a single object.
+func (s *Service) GetObject(ctx context.Context, bucketName, key string) (*dto.S3Object, error) {
+	bucket, err := s.queries.GetBucket(ctx, bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("bucket not found: %w", err)
+	}
+
+	obj, err := s.queries.GetS3Object(ctx, database.GetS3ObjectParams{
+		BucketID: bucket.ID,
+		Key:      key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+
+	return &s.convertToDTO([]database.S3Object{obj})[0], nil
+}
+
+// GetObjectsByKeys returns the catalog entries of the given keys that exist, indexed by key.
+func (s *Service) GetObjectsByKeys(
+	ctx context.Context, bucketName string, keys []string,
+) (map[string]dto.S3Object, error) {
+	bucket, err := s.queries.GetBucket(ctx, bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("bucket not found: %w", err)
+	}
+
+	objects, err := s.queries.ListS3ObjectsByKeys(ctx, database.ListS3ObjectsByKeysParams{
+		BucketID: bucket.ID,
+		Keys:     keys,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get objects by keys: %w", err)
+	}
+
+	result := make(map[string]dto.S3Object, len(objects))
+	for _, obj := range s.convertToDTO(objects) {
+		result[obj.Key] = obj
+	}
+	return result, nil
+}
+
 // GetObjectsByPrefix returns objects with the specified prefix pattern.
 func (s *Service) GetObjectsByPrefix(
 	ctx context.Context, bucketName, prefix string, limit, offset int,
@@ -222,6 +294,41 @@ func (s *Service) CountObjects(ctx context.Context, bucketName, prefix string) (
 	return count, nil
 }
 
+// GetFolderSummary counts the objects the catalog holds under folder, descendants included,
+// and returns the first sampleSize file keys.
+func (s *Service) GetFolderSummary(
+	ctx context.Context, bucketName, folder string, sampleSize int,
+) (*dto.FolderSummary, error) {
+	bucket, err := s.queries.GetBucket(ctx, bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("bucket not found: %w", err)
+	}
+
+	stats, err := s.queries.GetS3ObjectTreeStats(ctx, database.GetS3ObjectTreeStatsParams{
+		BucketID:      bucket.ID,
+		PrefixPattern: escapeLikePattern(folder),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count folder objects: %w", err)
+	}
+
+	sample, err := s.queries.ListS3ObjectTreeSample(ctx, database.ListS3ObjectTreeSampleParams{
+		BucketID:      bucket.ID,
+		PrefixPattern: escapeLikePattern(folder),
+		MaxKeys:       safeInt32(sampleSize),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list folder sample: %w", err)
+	}
+
+	return &dto.FolderSummary{
+		Key:     folder,
+		Objects: stats.ObjectCount,
+		Size:    stats.TotalSize,
+		Sample:  sample,
+	}, nil
+}
+
 // GetDirectChildren returns only immediate children (non-recursive) for hierarchical navigation.
 func (s *Service) GetDirectChildren(
 	ctx context.Context, bucketName, prefix string, limit, offset int,
@@ -279,14 +386,31 @@ func (s *Service) CountDirectChildren(
 	return folderCount, fileCount, nil
 }
 
-// GetDirectChildrenPaginated returns paginated immediate children with folder-first ordering.
+// GetDirectChildrenPaginated returns a page of immediate children with folder-first ordering.
 // It returns separate slices for folders and files, along with total counts for pagination.
 //
 //nolint:nonamedreturns // Named returns improve readability for complex multi-value return signature
 func (s *Service) GetDirectChildrenPaginated(
 	ctx context.Context,
 	bucketName, prefix string,
-	page, pageSize int,
+	pageSize int,
+	cursor dto.PageCursor,
+) (folders, files []dto.S3Object, totalFolders, totalFiles int64, err error) {
+	return s.GetDirectChildrenSorted(ctx, bucketName, prefix, pageSize, dto.DefaultSort(), cursor)
+}
+
+// GetDirectChildrenSorted returns a page of immediate children ordered by the given sort options.
+// Folders always come first (ordered by name), followed by files ordered by the sort field.
+// Pages run over the combined folder+file sequence, so a page may span both groups. The page is
+// the one after or before the entry of cursor, found by keyset pagination, or the first page.
+//
+//nolint:nonamedreturns // Named returns improve readability for complex multi-value return signature
+func (s *Service) GetDirectChildrenSorted(
+	ctx context.Context,
+	bucketName, prefix string,
+	pageSize int,
+	sortOpts dto.SortOptions,
+	cursor dto.PageCursor,
 ) (folders, files []dto.S3Object, totalFolders, totalFiles int64, err error) {
 	// Get bucket ID
 	bucket, err := s.queries.GetBucket(ctx, bucketName)
@@ -300,43 +424,21 @@ func (s *Service) GetDirectChildrenPaginated(
 		return nil, nil, 0, 0, fmt.Errorf("failed to count children: %w", err)
 	}
 
-	// Get cursor for keyset pagination (nil for page 1)
-	cursor, err := s.GetCursorForPage(ctx, int64(bucket.ID), prefix, page, pageSize)
-	if err != nil {
-		return nil, nil, 0, 0, fmt.Errorf("failed to get cursor: %w", err)
-	}
-
-	// Prepare nullable cursor parameters for sqlc
-	var cursorIsFolder sql.NullBool
-	var cursorKey sql.NullString
-	if cursor != nil {
-		cursorIsFolder = sql.NullBool{Bool: cursor.IsFolder, Valid: true}
-		cursorKey = sql.NullString{String: cursor.Key, Valid: true}
+	var folderRows, fileRows []database.S3Object
+	if cursor.Before != nil {
+		folderRows, fileRows, err = s.listDirectChildrenBefore(
+			ctx, bucket.ID, prefix, sortOpts, *cursor.Before, int64(pageSize),
+		)
+	} else {
+		folderRows, fileRows, err = s.listDirectChildrenAfter(
+			ctx, bucket.ID, prefix, sortOpts, cursor.After, int64(pageSize),
+		)
 	}
-
-	// Fetch page using keyset query (single query replaces dual folder/file queries)
-	objects, err := s.queries.GetDirectChildren(ctx, database.GetDirectChildrenParams{
-		BucketID:        bucket.ID,
-		Column2:         prefix,
-		Limit:           safeInt32(pageSize),
-		CursorIsFolder:  cursorIsFolder,
-		CursorKey:       cursorKey,
-	})
 	if err != nil {
-		return nil, nil, 0, 0, fmt.Errorf("failed to list objects: %w", err)
-	}
-
-	// Convert to DTOs and separate folders from files for backward compatibility
-	allObjects := s.convertToDTO(objects)
-	for _, obj := range allObjects {
-		if obj.IsFolder {
-			folders = append(folders, obj)
-		} else {
-			files = append(files, obj)
-		}
+		return nil, nil, 0, 0, err
 	}
 
-	return folders, files, totalFolders, totalFiles, nil
+	return s.convertToDTO(folderRows), s.convertToDTO(fileRows), totalFolders, totalFiles, nil
 }
 
 // GetBreadcrumbPath returns parent folders for breadcrumb navigation.
@@ -476,6 +578,11 @@ func (s *Service) convertToDTO(objects []database.S3Object) []dto.S3Object {
 			StorageClass: obj.StorageClass.String,
 			IsFolder:     obj.IsFolder.Bool,
 			Prefix:       obj.Prefix.String,
+
+			EncryptionStatus: obj.EncryptionStatus.String,
+		}
+		if obj.TagCount.Valid {
+			result[i].TagCount = &obj.TagCount.Int32
 		}
 		
 		// Format size for display
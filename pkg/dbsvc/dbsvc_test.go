@@ -1,7 +1,13 @@
 package dbsvc
 
 import (
+	"context"
+	"database/sql"
+	"slices"
 	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
 )
 
 // TestCountDirectChildren tests the CountDirectChildren method signature and basic structure.
@@ -28,7 +34,7 @@ func TestGetDirectChildrenPaginated(t *testing.T) {
 	var s *Service
 	if s != nil {
 		// This won't run but ensures the signature is correct at compile time
-		_, _, _, _, _ = s.GetDirectChildrenPaginated(nil, "", "", 1, 50)
+		_, _, _, _, _ = s.GetDirectChildrenPaginated(nil, "", "", 50, dto.PageCursor{})
 	}
 }
 
@@ -44,3 +50,48 @@ func TestGetDirectChildrenPaginated(t *testing.T) {
 // These tests are beyond the scope of unit testing and would typically be
 // implemented as part of an integration test suite with docker-compose or
 // similar infrastructure for database setup.
+
+// TestConvertToDTO_InventoryFields checks that the fields only read from inventory reports are mapped.
+func TestConvertToDTO_InventoryFields(t *testing.T) {
+	s := &Service{}
+	objects := s.convertToDTO([]database.S3Object{
+		{Key: "a.txt", EncryptionStatus: sql.NullString{String: "SSE-KMS", Valid: true}, TagCount: sql.NullInt32{Int32: 2, Valid: true}},
+		{Key: "b.txt"},
+	})
+
+	if objects[0].EncryptionStatus != "SSE-KMS" || objects[0].TagCount == nil || *objects[0].TagCount != 2 {
+		t.Errorf("inventory fields = %q, %v", objects[0].EncryptionStatus, objects[0].TagCount)
+	}
+	if objects[1].EncryptionStatus != "" || objects[1].TagCount != nil {
+		t.Errorf("listed file has inventory fields %q, %v", objects[1].EncryptionStatus, objects[1].TagCount)
+	}
+}
+
+// TestGetFolderSummary checks that a folder holds exactly the keys it prefixes: LIKE wildcards in
+// its name match themselves and the match is case-sensitive.
+func TestGetFolderSummary(t *testing.T) {
+	object := func(key string, size int64) database.CreateS3ObjectParams {
+		return database.CreateS3ObjectParams{
+			Key: key, Size: size, IsFolder: sql.NullBool{Bool: key[len(key)-1] == '/', Valid: true},
+		}
+	}
+	s := newSQLiteService(t, []database.CreateS3ObjectParams{
+		object("logs_%/", 0),
+		object("logs_%/a.txt", 10),
+		object("logs_%/sub/b.txt", 20),
+		object("logs_%2/c.txt", 40),
+		object("logsx%/d.txt", 80),
+		object("Logs_%/e.txt", 160),
+	})
+
+	summary, err := s.GetFolderSummary(context.Background(), "prod-data", "logs_%/", 10)
+	if err != nil {
+		t.Fatalf("GetFolderSummary() error = %v", err)
+	}
+	if summary.Objects != 3 || summary.Size != 30 {
+		t.Errorf("summary = %d objects, %d bytes, want 3 objects, 30 bytes", summary.Objects, summary.Size)
+	}
+	if want := []string{"logs_%/a.txt", "logs_%/sub/b.txt"}; !slices.Equal(summary.Sample, want) {
+		t.Errorf("sample = %v, want %v", summary.Sample, want)
+	}
+}
@@ -0,0 +1,134 @@
+package dbsvc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dbinit"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// RefreshDuplicateReport recomputes the duplicates report of a bucket: the files under keyPrefix of at
+// least minSize bytes sharing an ETag and a size. The previous report is replaced in one transaction.
+func (s *Service) RefreshDuplicateReport(ctx context.Context, bucketName, keyPrefix string, minSize int64) error {
+	bucket, err := s.queries.GetBucket(ctx, bucketName)
+	if err != nil {
+		return fmt.Errorf("bucket not found: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	qtx := dbinit.NewQuerier(s.cfg.Database.Driver(), tx)
+
+	if err := qtx.DeleteDuplicatePrefixes(ctx, bucket.ID); err != nil {
+		return fmt.Errorf("failed to clear duplicate prefixes: %w", err)
+	}
+	if err := qtx.DeleteDuplicateGroups(ctx, bucket.ID); err != nil {
+		return fmt.Errorf("failed to clear duplicate groups: %w", err)
+	}
+	if err := qtx.InsertDuplicateGroups(ctx, database.InsertDuplicateGroupsParams{
+		BucketID:  bucket.ID,
+		MinSize:   minSize,
+		KeyPrefix: keyPrefix,
+	}); err != nil {
+		return fmt.Errorf("failed to group duplicates: %w", err)
+	}
+	if err := qtx.InsertDuplicatePrefixes(ctx, database.InsertDuplicatePrefixesParams{
+		BucketID:  bucket.ID,
+		KeyPrefix: keyPrefix,
+	}); err != nil {
+		return fmt.Errorf("failed to sum duplicates per prefix: %w", err)
+	}
+	if err := qtx.UpsertDuplicateReport(ctx, bucket.ID); err != nil {
+		return fmt.Errorf("failed to save duplicates report: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit duplicates report: %w", err)
+	}
+	return nil
+}
+
+// GetDuplicateReport returns a page of the duplicates report of a bucket, the groups wasting the most
+// first, with their files under keyPrefix, and the prefixes wasting the most. It returns nil when the
+// report was never computed.
+func (s *Service) GetDuplicateReport(
+	ctx context.Context, bucketName, keyPrefix string, offset, limit, prefixLimit int,
+) (*dto.DuplicateReport, error) {
+	row, err := s.queries.GetDuplicateReport(ctx, bucketName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil //nolint:nilnil // a report not computed yet is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get duplicates report: %w", err)
+	}
+	report := &dto.DuplicateReport{
+		Bucket:      bucketName,
+		ComputedAt:  row.ComputedAt,
+		Groups:      int64(row.Groups),
+		WastedBytes: row.WastedBytes,
+	}
+
+	groups, err := s.queries.ListDuplicateGroups(ctx, database.ListDuplicateGroupsParams{
+		BucketID: row.BucketID,
+		Limit:    safeInt32(limit),
+		Offset:   safeInt32(offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list duplicate groups: %w", err)
+	}
+	ids := make([]int32, 0, len(groups))
+	index := make(map[int32]int, len(groups))
+	for i, g := range groups {
+		ids = append(ids, g.ID)
+		index[g.ID] = i
+		report.Page = append(report.Page, dto.DuplicateGroup{
+			ID:          g.ID,
+			ETag:        g.Etag,
+			Size:        g.Size,
+			Objects:     int(g.Objects),
+			WastedBytes: g.WastedBytes,
+			Multipart:   g.Multipart,
+		})
+	}
+
+	if len(ids) > 0 {
+		members, err := s.queries.ListDuplicateObjects(ctx, database.ListDuplicateObjectsParams{
+			GroupIds:  ids,
+			KeyPrefix: keyPrefix,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list duplicate files: %w", err)
+		}
+		for _, m := range members {
+			group := &report.Page[index[m.GroupID]]
+			group.Members = append(group.Members, dto.DuplicateObject{
+				Key:          m.Key,
+				LastModified: m.LastModified.Time,
+				StorageClass: m.StorageClass.String,
+			})
+		}
+	}
+
+	prefixes, err := s.queries.ListDuplicatePrefixes(ctx, database.ListDuplicatePrefixesParams{
+		BucketID: row.BucketID,
+		Limit:    safeInt32(prefixLimit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list duplicate prefixes: %w", err)
+	}
+	for _, p := range prefixes {
+		report.Prefixes = append(report.Prefixes, dto.DuplicatePrefix{
+			Prefix:      p.Prefix,
+			Copies:      int64(p.Copies),
+			WastedBytes: p.WastedBytes,
+		})
+	}
+	return report, nil
+}
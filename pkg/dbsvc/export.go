@@ -0,0 +1,72 @@
+package dbsvc
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dbinit"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// ExportObjects reads the files of the catalog selected by filter in key order and passes them to fn,
+// batch files at a time, so that memory does not grow with the size of the bucket. Each batch starts
+// after the last key of the previous one, following the unique (bucket_id, key) index. The slice passed
+// to fn is reused by the next batch.
+// The files are read in a single read-only transaction: they are a consistent snapshot of the catalog.
+func (s *Service) ExportObjects(
+	ctx context.Context, filter dto.CatalogFilter, batch int, fn func([]dto.CatalogObject) error,
+) error {
+	bucket, err := s.queries.GetBucket(ctx, filter.Bucket)
+	if err != nil {
+		return fmt.Errorf("bucket not found: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true, Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := dbinit.NewQuerier(s.cfg.Database.Driver(), tx)
+	params := database.ListExportObjectsParams{
+		BucketID:  bucket.ID,
+		Prefix:    filter.Prefix,
+		Pattern:   escapeLikePattern(filter.Search),
+		Exclude:   filter.Exclude,
+		BatchSize: int32(min(max(batch, 1), math.MaxInt32)), //nolint:gosec // clamped to the int32 range
+	}
+	objects := make([]dto.CatalogObject, 0, params.BatchSize)
+	for {
+		rows, err := qtx.ListExportObjects(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to fetch exported files: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		objects = objects[:0]
+		for _, row := range rows {
+			obj := dto.CatalogObject{
+				Bucket: filter.Bucket, Key: row.Key, Size: row.Size, ETag: row.Etag, StorageClass: row.StorageClass,
+			}
+			if row.LastModified.Valid {
+				t := row.LastModified.Time.In(time.UTC)
+				obj.LastModified = &t
+			}
+			objects = append(objects, obj)
+		}
+		if err := fn(objects); err != nil {
+			return err
+		}
+
+		if len(rows) < int(params.BatchSize) {
+			return nil
+		}
+		params.AfterKey = rows[len(rows)-1].Key
+	}
+}
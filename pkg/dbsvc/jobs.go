@@ -0,0 +1,124 @@
+package dbsvc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// ErrJobNotFound is returned when a job does not exist.
+var ErrJobNotFound = errors.New("job not found")
+
+// CreateJob records a pending background job.
+func (s *Service) CreateJob(ctx context.Context, job dto.Job) (*dto.Job, error) {
+	row, err := s.queries.CreateJob(ctx, database.CreateJobParams{
+		Kind:             job.Kind,
+		BucketName:       job.Bucket,
+		Source:           job.Source,
+		Destination:      job.Destination,
+		TargetConnection: job.TargetConnection,
+		TargetBucket:     job.TargetBucket,
+		Options:          job.Options,
+		DryRun:           job.DryRun,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+	result := convertJobToDTO(row)
+	return &result, nil
+}
+
+// GetJob returns a job by ID.
+func (s *Service) GetJob(ctx context.Context, id int32) (*dto.Job, error) {
+	row, err := s.queries.GetJob(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	result := convertJobToDTO(row)
+	return &result, nil
+}
+
+// StartJob marks a job as running and records the amount of work it has to do.
+func (s *Service) StartJob(ctx context.Context, id int32, totalItems int, totalBytes int64) error {
+	err := s.queries.StartJob(ctx, database.StartJobParams{
+		ID:         id,
+		TotalItems: safeInt32(totalItems),
+		TotalBytes: totalBytes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start job: %w", err)
+	}
+	return nil
+}
+
+// UpdateJobProgress stores the counters of a running job.
+func (s *Service) UpdateJobProgress(ctx context.Context, id int32, progress dto.JobProgress) error {
+	err := s.queries.UpdateJobProgress(ctx, database.UpdateJobProgressParams{
+		ID:           id,
+		DoneItems:    progress.DoneItems,
+		FailedItems:  progress.FailedItems,
+		SkippedItems: progress.SkippedItems,
+		DoneBytes:    progress.DoneBytes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update job progress: %w", err)
+	}
+	return nil
+}
+
+// FinishJob marks a job as completed, or as failed when jobErr is not nil.
+func (s *Service) FinishJob(ctx context.Context, id int32, jobErr error) error {
+	params := database.FinishJobParams{ID: id, Status: dto.JobStatusCompleted}
+	if jobErr != nil {
+		params.Status = dto.JobStatusFailed
+		params.ErrorMessage = sql.NullString{String: jobErr.Error(), Valid: true}
+	}
+	if err := s.queries.FinishJob(ctx, params); err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	return nil
+}
+
+// FailInterruptedJobs marks the jobs left pending or running by a previous process as failed
+// and returns how many there were.
+func (s *Service) FailInterruptedJobs(ctx context.Context) (int64, error) {
+	n, err := s.queries.FailInterruptedJobs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail interrupted jobs: %w", err)
+	}
+	return n, nil
+}
+
+// convertJobToDTO converts a database job row to a DTO.
+func convertJobToDTO(row database.Job) dto.Job {
+	return dto.Job{
+		ID:               row.ID,
+		Kind:             row.Kind,
+		Status:           row.Status,
+		Bucket:           row.BucketName,
+		Source:           row.Source,
+		Destination:      row.Destination,
+		TargetConnection: row.TargetConnection,
+		TargetBucket:     row.TargetBucket,
+		Options:          row.Options,
+		DryRun:           row.DryRun,
+		TotalItems:       row.TotalItems,
+		DoneItems:        row.DoneItems,
+		FailedItems:      row.FailedItems,
+		SkippedItems:     row.SkippedItems,
+		TotalBytes:       row.TotalBytes,
+		DoneBytes:        row.DoneBytes,
+		Error:            row.ErrorMessage.String,
+		StartedAt:        nullTimePtr(row.StartedAt),
+		CompletedAt:      nullTimePtr(row.CompletedAt),
+		CreatedAt:        row.CreatedAt,
+		UpdatedAt:        row.UpdatedAt,
+	}
+}
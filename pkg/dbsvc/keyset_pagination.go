@@ -3,111 +3,163 @@ package dbsvc
 import (
 	"context"
 	"database/sql"
-	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
 )
 
-// KeysetCursor represents a pagination cursor for keyset pagination.
-// It contains the last item's sort keys to enable efficient "seek" queries.
-type KeysetCursor struct {
-	IsFolder bool
-	Key      string
+// segmentSort resolves the sort field and direction for one segment of a directory listing.
+// Folders have no meaningful size, date or storage class, so they are always ordered by name;
+// they follow the requested direction only when sorting by name.
+func segmentSort(isFolder bool, sortOpts dto.SortOptions) (field dto.SortField, desc bool) {
+	if isFolder {
+		return dto.SortByName, sortOpts.Field == dto.SortByName && sortOpts.Descending()
+	}
+	if !dto.ValidSortField(sortOpts.Field) {
+		return dto.SortByName, false
+	}
+	return sortOpts.Field, sortOpts.Descending()
 }
 
-// GetCursorForPage retrieves the cursor for a given page number.
-// Returns nil cursor for page 1 (start from beginning).
-// Returns nil cursor if offset is beyond total items (graceful degradation).
-func (s *Service) GetCursorForPage(
-	ctx context.Context,
-	bucketID int64,
-	prefix string,
-	page int,
-	pageSize int,
-) (*KeysetCursor, error) {
-	if page <= 1 {
-		return nil, nil //nolint:nilnil // Returning nil cursor is intentional for page 1
+// listDirectChildrenAfter returns the folders and files of the page following cursor, or of the
+// first page when cursor is nil: the rest of the folder segment, then the start of the file segment.
+func (s *Service) listDirectChildrenAfter(
+	ctx context.Context, bucketID int32, prefix string, sortOpts dto.SortOptions, cursor *dto.ListingCursor, limit int64,
+) ([]database.S3Object, []database.S3Object, error) {
+	var folders, files []database.S3Object
+	var err error
+	if cursor == nil || cursor.IsFolder {
+		folders, err = s.listDirectChildrenSegment(ctx, bucketID, prefix, true, sortOpts, cursor, false, limit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list folders: %w", err)
+		}
+		limit -= int64(len(folders))
+		cursor = nil
 	}
-
-	offset := int64((page - 1) * pageSize)
-
-	// Get cursor at offset position (last item of previous page)
-	cursor, err := s.queries.GetCursorForDirectChildren(ctx, database.GetCursorForDirectChildrenParams{
-		BucketID: safeInt32(int(bucketID)),
-		Column2:  prefix,
-		Offset:   safeInt32(int(offset - 1)), // Get the last item of previous page
-	})
-
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			// Offset beyond total items - return nil to indicate no more results
-			return nil, nil //nolint:nilnil // Returning nil cursor is intentional for graceful degradation
+	if limit > 0 {
+		files, err = s.listDirectChildrenSegment(ctx, bucketID, prefix, false, sortOpts, cursor, false, limit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list files: %w", err)
 		}
-		return nil, err
 	}
-
-	return &KeysetCursor{
-		IsFolder: cursor.IsFolder.Bool,
-		Key:      cursor.Key,
-	}, nil
+	return folders, files, nil
 }
 
-// GetCursorForFolders retrieves the cursor for folder-only pagination.
-func (s *Service) GetCursorForFolders(
-	ctx context.Context,
-	bucketID int64,
-	prefix string,
-	page int,
-	pageSize int,
-) (*string, error) {
-	if page <= 1 {
-		return nil, nil //nolint:nilnil // Returning nil cursor is intentional for page 1
+// listDirectChildrenBefore returns the folders and files of the page preceding cursor: the end of
+// the file segment before it, then the end of the folder segment, read backwards and put back in order.
+func (s *Service) listDirectChildrenBefore(
+	ctx context.Context, bucketID int32, prefix string, sortOpts dto.SortOptions, cursor dto.ListingCursor, limit int64,
+) ([]database.S3Object, []database.S3Object, error) {
+	var folders, files []database.S3Object
+	var err error
+	folderCursor := &cursor
+	if !cursor.IsFolder {
+		files, err = s.listDirectChildrenSegment(ctx, bucketID, prefix, false, sortOpts, &cursor, true, limit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list files: %w", err)
+		}
+		slices.Reverse(files)
+		limit -= int64(len(files))
+		folderCursor = nil
 	}
-
-	offset := int64((page - 1) * pageSize)
-
-	cursorKey, err := s.queries.GetCursorForListS3Folders(ctx, database.GetCursorForListS3FoldersParams{
-		BucketID: safeInt32(int(bucketID)),
-		Column2:  prefix,
-		Offset:   safeInt32(int(offset - 1)),
-	})
-
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil //nolint:nilnil // Returning nil cursor is intentional for graceful degradation
+	if limit > 0 {
+		folders, err = s.listDirectChildrenSegment(ctx, bucketID, prefix, true, sortOpts, folderCursor, true, limit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list folders: %w", err)
 		}
-		return nil, err
+		slices.Reverse(folders)
 	}
-
-	return &cursorKey, nil
+	return folders, files, nil
 }
 
-// GetCursorForFiles retrieves the cursor for file-only pagination.
-func (s *Service) GetCursorForFiles(
+// listDirectChildrenSegment returns up to limit direct children of one kind (folders or files)
+// after cursor, or from the start of the segment when cursor is nil, ordered according to sortOpts,
+// or in the opposite order when backward is set.
+// Each sort field and direction has its own query, so any page is a seek on the matching index.
+//
+//nolint:funlen // One query per sort field and direction
+func (s *Service) listDirectChildrenSegment(
 	ctx context.Context,
-	bucketID int64,
+	bucketID int32,
 	prefix string,
-	page int,
-	pageSize int,
-) (*string, error) {
-	if page <= 1 {
-		return nil, nil //nolint:nilnil // Returning nil cursor is intentional for page 1
+	isFolder bool,
+	sortOpts dto.SortOptions,
+	cursor *dto.ListingCursor,
+	backward bool,
+	limit int64,
+) ([]database.S3Object, error) {
+	var c dto.ListingCursor
+	var cursorKey sql.NullString
+	if cursor != nil {
+		c = *cursor
+		cursorKey = sql.NullString{String: c.Key, Valid: true}
 	}
+	// The queries sort files without a modification date as the Unix epoch
+	lastModified := c.LastModified
+	if lastModified.IsZero() {
+		lastModified = time.Unix(0, 0).UTC()
+	}
+	pageSize := safeInt32(int(limit))
 
-	offset := int64((page - 1) * pageSize)
-
-	cursorKey, err := s.queries.GetCursorForListS3Files(ctx, database.GetCursorForListS3FilesParams{
-		BucketID: safeInt32(int(bucketID)),
-		Column2:  prefix,
-		Offset:   safeInt32(int(offset - 1)),
-	})
-
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil //nolint:nilnil // Returning nil cursor is intentional for graceful degradation
+	var items []database.S3Object
+	var err error
+	field, desc := segmentSort(isFolder, sortOpts)
+	if backward {
+		desc = !desc
+	}
+	switch field {
+	case dto.SortBySize:
+		if desc {
+			items, err = s.queries.ListDirectChildrenBySizeDesc(ctx, database.ListDirectChildrenBySizeDescParams{
+				BucketID: bucketID, IsFolder: isFolder, Prefix: prefix,
+				CursorKey: cursorKey, CursorSize: c.Size, PageSize: pageSize,
+			})
+		} else {
+			items, err = s.queries.ListDirectChildrenBySizeAsc(ctx, database.ListDirectChildrenBySizeAscParams{
+				BucketID: bucketID, IsFolder: isFolder, Prefix: prefix,
+				CursorKey: cursorKey, CursorSize: c.Size, PageSize: pageSize,
+			})
+		}
+	case dto.SortByDate:
+		if desc {
+			items, err = s.queries.ListDirectChildrenByDateDesc(ctx, database.ListDirectChildrenByDateDescParams{
+				BucketID: bucketID, IsFolder: isFolder, Prefix: prefix,
+				CursorKey: cursorKey, CursorLastModified: lastModified, PageSize: pageSize,
+			})
+		} else {
+			items, err = s.queries.ListDirectChildrenByDateAsc(ctx, database.ListDirectChildrenByDateAscParams{
+				BucketID: bucketID, IsFolder: isFolder, Prefix: prefix,
+				CursorKey: cursorKey, CursorLastModified: lastModified, PageSize: pageSize,
+			})
+		}
+	case dto.SortByStorage:
+		if desc {
+			items, err = s.queries.ListDirectChildrenByStorageDesc(ctx, database.ListDirectChildrenByStorageDescParams{
+				BucketID: bucketID, IsFolder: isFolder, Prefix: prefix,
+				CursorKey: cursorKey, CursorStorageClass: c.StorageClass, PageSize: pageSize,
+			})
+		} else {
+			items, err = s.queries.ListDirectChildrenByStorageAsc(ctx, database.ListDirectChildrenByStorageAscParams{
+				BucketID: bucketID, IsFolder: isFolder, Prefix: prefix,
+				CursorKey: cursorKey, CursorStorageClass: c.StorageClass, PageSize: pageSize,
+			})
+		}
+	default:
+		if desc {
+			items, err = s.queries.ListDirectChildrenByNameDesc(ctx, database.ListDirectChildrenByNameDescParams{
+				BucketID: bucketID, IsFolder: isFolder, Prefix: prefix, CursorKey: cursorKey, PageSize: pageSize,
+			})
+		} else {
+			items, err = s.queries.ListDirectChildrenByNameAsc(ctx, database.ListDirectChildrenByNameAscParams{
+				BucketID: bucketID, IsFolder: isFolder, Prefix: prefix, CursorKey: cursorKey, PageSize: pageSize,
+			})
 		}
-		return nil, err
 	}
-
-	return &cursorKey, nil
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sorted children: %w", err)
+	}
+	return items, nil
 }
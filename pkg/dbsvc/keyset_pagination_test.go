@@ -0,0 +1,124 @@
+package dbsvc
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dbinit"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// newSQLiteService returns a service on a migrated SQLite catalog holding the given entries at the root
+// of the bucket "prod-data".
+func newSQLiteService(t *testing.T, entries []database.CreateS3ObjectParams) *Service {
+	t.Helper()
+	ctx := context.Background()
+	cfg := config.Config{Database: config.DatabaseConfig{
+		URL:             "sqlite:" + filepath.Join(t.TempDir(), "catalog.db"),
+		MaxOpenConns:    5,
+		MaxIdleConns:    2,
+		ConnMaxLifetime: "5m",
+		ConnMaxIdleTime: "1m",
+	}}
+	db, err := dbinit.InitializeDatabase(ctx, cfg.Database, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	s := NewService(cfg, db)
+	bucket, err := s.queries.CreateBucket(ctx, database.CreateBucketParams{Name: "prod-data"})
+	require.NoError(t, err)
+	for _, entry := range entries {
+		entry.BucketID = bucket.ID
+		entry.Prefix = sql.NullString{String: "", Valid: true}
+		_, err := s.queries.CreateS3Object(ctx, entry)
+		require.NoError(t, err)
+	}
+	return s
+}
+
+func TestGetDirectChildrenSorted_KeysetPages(t *testing.T) {
+	day := func(d int) sql.NullTime {
+		return sql.NullTime{Time: time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC), Valid: true}
+	}
+	folder := func(key string) database.CreateS3ObjectParams {
+		return database.CreateS3ObjectParams{Key: key, IsFolder: sql.NullBool{Bool: true, Valid: true}}
+	}
+	file := func(key string, size int64, modified sql.NullTime, class string) database.CreateS3ObjectParams {
+		return database.CreateS3ObjectParams{
+			Key:          key,
+			Size:         size,
+			LastModified: modified,
+			StorageClass: sql.NullString{String: class, Valid: class != ""},
+			IsFolder:     sql.NullBool{Bool: false, Valid: true},
+		}
+	}
+	s := newSQLiteService(t, []database.CreateS3ObjectParams{
+		folder("b/"), folder("a/"), folder("c/"),
+		file("e.txt", 20, day(3), "STANDARD"),
+		file("d.txt", 20, day(1), "GLACIER"),
+		file("f.txt", 5, sql.NullTime{}, ""),
+		file("g.txt", 50, day(2), "STANDARD"),
+	})
+
+	tests := []struct {
+		sort dto.SortOptions
+		want []string
+	}{
+		{dto.DefaultSort(), []string{"a/", "b/", "c/", "d.txt", "e.txt", "f.txt", "g.txt"}},
+		{dto.SortOptions{Field: dto.SortByName, Order: dto.SortDesc}, []string{"c/", "b/", "a/", "g.txt", "f.txt", "e.txt", "d.txt"}},
+		{dto.SortOptions{Field: dto.SortBySize, Order: dto.SortDesc}, []string{"a/", "b/", "c/", "g.txt", "e.txt", "d.txt", "f.txt"}},
+		{dto.SortOptions{Field: dto.SortByDate, Order: dto.SortAsc}, []string{"a/", "b/", "c/", "f.txt", "d.txt", "g.txt", "e.txt"}},
+		{dto.SortOptions{Field: dto.SortByStorage, Order: dto.SortAsc}, []string{"a/", "b/", "c/", "f.txt", "d.txt", "e.txt", "g.txt"}},
+	}
+
+	ctx := context.Background()
+	const pageSize = 2
+	for _, tt := range tests {
+		t.Run(string(tt.sort.Field)+"-"+string(tt.sort.Order), func(t *testing.T) {
+			// Forward: each page starts after the last entry of the previous one
+			var pages [][]dto.S3Object
+			var cursor dto.PageCursor
+			for {
+				folders, files, totalFolders, totalFiles, err := s.GetDirectChildrenSorted(
+					ctx, "prod-data", "", pageSize, tt.sort, cursor,
+				)
+				require.NoError(t, err)
+				assert.Equal(t, int64(3), totalFolders)
+				assert.Equal(t, int64(4), totalFiles)
+				page := append(folders, files...)
+				if len(page) == 0 {
+					break
+				}
+				pages = append(pages, page)
+				last := dto.CursorOf(page[len(page)-1])
+				cursor = dto.PageCursor{After: &last}
+			}
+			var keys []string
+			for _, page := range pages {
+				for _, obj := range page {
+					keys = append(keys, obj.Key)
+				}
+			}
+			assert.Equal(t, tt.want, keys)
+
+			// Backward: each page ends before the first entry of the next one
+			for i := len(pages) - 1; i > 0; i-- {
+				first := dto.CursorOf(pages[i][0])
+				folders, files, _, _, err := s.GetDirectChildrenSorted(
+					ctx, "prod-data", "", pageSize, tt.sort, dto.PageCursor{Before: &first},
+				)
+				require.NoError(t, err)
+				assert.Equal(t, pages[i-1], append(folders, files...), "page %d", i)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package dbsvc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// LifecycleScope selects the files of the catalog one lifecycle action applies to.
+type LifecycleScope struct {
+	Bucket string
+	Prefix string
+	// DueBefore and UpcomingBefore are the last modification dates of the files the action applies
+	// to now, and within the simulated window
+	DueBefore      time.Time
+	UpcomingBefore time.Time
+	// NotBefore excludes the older files, reached by a later action of the same rule
+	NotBefore time.Time
+	MinSize   int64
+	// StorageClasses are the classes the action applies to, all of them when empty
+	StorageClasses []string
+}
+
+// SimulateLifecycleAction counts the files and bytes a lifecycle action applies to now and within the
+// simulated window. Action and Days of the result are left to the caller.
+func (s *Service) SimulateLifecycleAction(ctx context.Context, scope LifecycleScope) (dto.LifecycleImpact, error) {
+	row, err := s.queries.SimulateLifecycleAction(ctx, database.SimulateLifecycleActionParams{
+		BucketName:     scope.Bucket,
+		Prefix:         scope.Prefix,
+		DueBefore:      scope.DueBefore,
+		UpcomingBefore: scope.UpcomingBefore,
+		NotBefore:      scope.NotBefore,
+		MinSize:        scope.MinSize,
+		StorageClasses: scope.StorageClasses,
+	})
+	if err != nil {
+		return dto.LifecycleImpact{}, fmt.Errorf("failed to simulate lifecycle action: %w", err)
+	}
+	return dto.LifecycleImpact{
+		DueObjects:      row.DueObjects,
+		DueBytes:        row.DueBytes,
+		UpcomingObjects: row.UpcomingObjects,
+		UpcomingBytes:   row.UpcomingBytes,
+	}, nil
+}
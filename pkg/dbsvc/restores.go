@@ -0,0 +1,120 @@
+package dbsvc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// CreateRestoreRequest records the restore of an archived object.
+func (s *Service) CreateRestoreRequest(ctx context.Context, req dto.RestoreRequest) (*dto.RestoreRequest, error) {
+	row, err := s.queries.CreateRestoreRequest(ctx, database.CreateRestoreRequestParams{
+		BucketName:  req.Bucket,
+		Key:         req.Key,
+		Tier:        req.Tier,
+		Days:        req.Days,
+		RequestedBy: req.RequestedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restore request: %w", err)
+	}
+	result := convertRestoreRequestToDTO(row)
+	return &result, nil
+}
+
+// GetPendingRestoreRequest returns the running restore of key, or nil if there is none.
+func (s *Service) GetPendingRestoreRequest(ctx context.Context, bucketName, key string) (*dto.RestoreRequest, error) {
+	row, err := s.queries.GetPendingRestoreRequest(ctx, database.GetPendingRestoreRequestParams{
+		BucketName: bucketName,
+		Key:        key,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil //nolint:nilnil // no running restore is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restore request: %w", err)
+	}
+	result := convertRestoreRequestToDTO(row)
+	return &result, nil
+}
+
+// ListRestoreRequests returns the most recent restore requests.
+func (s *Service) ListRestoreRequests(ctx context.Context, limit int) ([]dto.RestoreRequest, error) {
+	rows, err := s.queries.ListRestoreRequests(ctx, safeInt32(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list restore requests: %w", err)
+	}
+	return convertRestoreRequestsToDTO(rows), nil
+}
+
+// ListPendingRestoreRequests returns the pending restore requests, least recently checked first.
+func (s *Service) ListPendingRestoreRequests(ctx context.Context, limit int) ([]dto.RestoreRequest, error) {
+	rows, err := s.queries.ListPendingRestoreRequests(ctx, safeInt32(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending restore requests: %w", err)
+	}
+	return convertRestoreRequestsToDTO(rows), nil
+}
+
+// UpdateRestoreRequest records the status of a restore request found by the poller.
+// expiresAt is the expiry of the restored copy, nil while it is not available.
+func (s *Service) UpdateRestoreRequest(
+	ctx context.Context, id int32, status string, expiresAt *time.Time, message string,
+) error {
+	params := database.UpdateRestoreRequestStatusParams{ID: id, Status: status, ErrorMessage: message}
+	if expiresAt != nil {
+		params.ExpiresAt = sql.NullTime{Time: *expiresAt, Valid: true}
+	}
+	if err := s.queries.UpdateRestoreRequestStatus(ctx, params); err != nil {
+		return fmt.Errorf("failed to update restore request: %w", err)
+	}
+	return nil
+}
+
+// ExpireRestoreRequests marks the restored copies past their expiry as expired.
+func (s *Service) ExpireRestoreRequests(ctx context.Context) (int64, error) {
+	n, err := s.queries.ExpireRestoreRequests(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire restore requests: %w", err)
+	}
+	return n, nil
+}
+
+// TakeRestoreNotifications returns the restored objects of user not notified yet, and marks them notified.
+func (s *Service) TakeRestoreNotifications(ctx context.Context, user string) ([]dto.RestoreRequest, error) {
+	rows, err := s.queries.TakeRestoreNotifications(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take restore notifications: %w", err)
+	}
+	return convertRestoreRequestsToDTO(rows), nil
+}
+
+func convertRestoreRequestsToDTO(rows []database.RestoreRequest) []dto.RestoreRequest {
+	result := make([]dto.RestoreRequest, len(rows))
+	for i, row := range rows {
+		result[i] = convertRestoreRequestToDTO(row)
+	}
+	return result
+}
+
+func convertRestoreRequestToDTO(row database.RestoreRequest) dto.RestoreRequest {
+	return dto.RestoreRequest{
+		ID:          row.ID,
+		Bucket:      row.BucketName,
+		Key:         row.Key,
+		Tier:        row.Tier,
+		Days:        row.Days,
+		Status:      row.Status,
+		RequestedBy: row.RequestedBy,
+		Error:       row.ErrorMessage,
+		ExpiresAt:   nullTimePtr(row.ExpiresAt),
+		RequestedAt: row.RequestedAt,
+		CheckedAt:   nullTimePtr(row.CheckedAt),
+		CompletedAt: nullTimePtr(row.CompletedAt),
+	}
+}
@@ -0,0 +1,53 @@
+package dbsvc
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+)
+
+// rankedSearchCandidates is the number of matches, in key order, ranked by trigram similarity.
+// Ranking every match of a broad term like "a" or ".jpg" would sort the whole bucket.
+const rankedSearchCandidates = 1000
+
+// likeEscaper escapes the LIKE/ILIKE wildcard characters so user input is matched literally.
+// PostgreSQL uses backslash as the default escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLikePattern escapes a search term or a key prefix for use inside a LIKE or ILIKE pattern.
+func escapeLikePattern(term string) string {
+	return likeEscaper.Replace(term)
+}
+
+// trigramSearchAvailable reports whether the pg_trgm extension is installed.
+// The result is cached once the check succeeds; a failed check is retried on the next search.
+func (s *Service) trigramSearchAvailable(ctx context.Context) bool {
+	s.trgmMu.Lock()
+	defer s.trgmMu.Unlock()
+
+	if s.trgmChecked {
+		return s.trgmAvailable
+	}
+
+	installed, err := s.queries.HasTrigramExtension(ctx)
+	if err != nil {
+		s.log.Warn("Failed to detect pg_trgm extension", slog.String("error", err.Error()))
+		return false
+	}
+
+	s.trgmChecked = true
+	s.trgmAvailable = installed
+	if !installed && s.cfg.Database.Driver() != config.DriverSQLite {
+		s.log.Info("pg_trgm extension not installed, search will use sequential ILIKE scans")
+	}
+	return installed
+}
+
+// resetTrigramDetection forces the pg_trgm check to run again on the next search.
+func (s *Service) resetTrigramDetection() {
+	s.trgmMu.Lock()
+	defer s.trgmMu.Unlock()
+	s.trgmChecked = false
+}
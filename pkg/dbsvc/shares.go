@@ -0,0 +1,113 @@
+package dbsvc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// ErrShareNotFound is returned when a share does not exist or belongs to another creator.
+var ErrShareNotFound = errors.New("share not found")
+
+// CreateShare records a new share link.
+func (s *Service) CreateShare(ctx context.Context, share dto.Share) (*dto.Share, error) {
+	row, err := s.queries.CreateShare(ctx, database.CreateShareParams{
+		Token:      share.Token,
+		BucketName: share.Bucket,
+		Key:        share.Key,
+		Creator:    share.Creator,
+		Proxied:    share.Proxied,
+		Url:        share.URL,
+		ExpiresAt:  share.ExpiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share: %w", err)
+	}
+	result := convertShareToDTO(row)
+	return &result, nil
+}
+
+// GetShareByToken returns the share identified by token.
+func (s *Service) GetShareByToken(ctx context.Context, token string) (*dto.Share, error) {
+	row, err := s.queries.GetShareByToken(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrShareNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share: %w", err)
+	}
+	result := convertShareToDTO(row)
+	return &result, nil
+}
+
+// ListShares returns the most recent shares created by creator.
+func (s *Service) ListShares(ctx context.Context, creator string, limit int) ([]dto.Share, error) {
+	rows, err := s.queries.ListSharesByCreator(ctx, database.ListSharesByCreatorParams{
+		Creator: creator,
+		Limit:   safeInt32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shares: %w", err)
+	}
+
+	result := make([]dto.Share, len(rows))
+	for i, row := range rows {
+		result[i] = convertShareToDTO(row)
+	}
+	return result, nil
+}
+
+// RevokeShare marks a share as revoked. Only the creator can revoke a share.
+func (s *Service) RevokeShare(ctx context.Context, id int32, creator string) error {
+	n, err := s.queries.RevokeShare(ctx, database.RevokeShareParams{ID: id, Creator: creator})
+	if err != nil {
+		return fmt.Errorf("failed to revoke share: %w", err)
+	}
+	if n == 0 {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+// RecordShareDownload increments the download counter of a proxied share.
+func (s *Service) RecordShareDownload(ctx context.Context, id int32) error {
+	if err := s.queries.RecordShareDownload(ctx, id); err != nil {
+		return fmt.Errorf("failed to record share download: %w", err)
+	}
+	return nil
+}
+
+// convertShareToDTO converts a database share row to a DTO.
+func convertShareToDTO(row database.Share) dto.Share {
+	share := dto.Share{
+		ID:            row.ID,
+		Token:         row.Token,
+		Bucket:        row.BucketName,
+		Key:           row.Key,
+		Creator:       row.Creator,
+		Proxied:       row.Proxied,
+		URL:           row.Url,
+		ExpiresAt:     row.ExpiresAt,
+		Revoked:       row.Revoked,
+		DownloadCount: row.DownloadCount,
+		CreatedAt:     row.CreatedAt,
+	}
+	if row.RevokedAt.Valid {
+		share.RevokedAt = nullTimePtr(row.RevokedAt)
+	}
+	if row.LastDownloadedAt.Valid {
+		share.LastDownloadedAt = nullTimePtr(row.LastDownloadedAt)
+	}
+	return share
+}
+
+// nullTimePtr returns a pointer to the time of a valid sql.NullTime.
+func nullTimePtr(t sql.NullTime) *time.Time {
+	v := t.Time
+	return &v
+}
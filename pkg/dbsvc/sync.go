@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dbinit"
 )
 
 var (
@@ -59,6 +61,74 @@ func (s *Service) SyncUploadedObject(
 	return nil
 }
 
+// SyncUploadedFolders creates the catalog entries of the folders between base and key,
+// so a directory tree uploaded under base is browsable before the next scan.
+// base must be a folder prefix ("" or ending with "/") that key starts with.
+func (s *Service) SyncUploadedFolders(ctx context.Context, bucketName, base, key string) error {
+	rel := strings.TrimPrefix(key, base)
+	idx := strings.LastIndex(rel, "/")
+	if idx <= 0 {
+		return nil // directly under base
+	}
+
+	folder := base
+	for _, segment := range strings.Split(rel[:idx], "/") {
+		folder += segment + "/"
+		if err := s.SyncUploadedObject(ctx, bucketName, folder, 0, "", ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncMovedObject rewrites the catalog after src was moved to dst at S3.
+// When src is a folder ("/"-terminated) every descendant is moved too, and dst must be a folder.
+// Rows already stored under dst are replaced. All changes are made in one transaction.
+func (s *Service) SyncMovedObject(ctx context.Context, bucketName, src, dst string) error {
+	bucket, err := s.queries.GetBucket(ctx, bucketName)
+	if err != nil {
+		return fmt.Errorf("bucket not found: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	qtx := dbinit.NewQuerier(s.cfg.Database.Driver(), tx)
+
+	if _, err := qtx.DeleteS3ObjectTree(ctx, database.DeleteS3ObjectTreeParams{
+		BucketID:   bucket.ID,
+		Key:        dst,
+		KeyPattern: escapeLikePattern(dst),
+	}); err != nil {
+		return fmt.Errorf("failed to clear move destination: %w", err)
+	}
+
+	moved, err := qtx.MoveS3ObjectTree(ctx, database.MoveS3ObjectTreeParams{
+		BucketID:   bucket.ID,
+		Src:        src,
+		SrcPattern: escapeLikePattern(src),
+		Dst:        dst,
+		DstParent:  extractPrefix(dst),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sync moved object: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit move: %w", err)
+	}
+
+	s.log.Debug("Synced moved object to database",
+		slog.String("bucket", bucketName),
+		slog.String("src", src),
+		slog.String("dst", dst),
+		slog.Int64("rows", moved))
+
+	return nil
+}
+
 // SyncDeletedObject removes an S3 object record from the database after deletion.
 // This keeps the database in sync with S3 after a successful delete operation.
 func (s *Service) SyncDeletedObject(ctx context.Context, bucketName, key string) error {
@@ -122,6 +192,54 @@ func (s *Service) SyncDeletedObjects(ctx context.Context, bucketName string, key
 	return nil
 }
 
+// SyncDeletedBatch removes the records of keys deleted by one batch request in a single statement.
+func (s *Service) SyncDeletedBatch(ctx context.Context, bucketName string, keys []string) error {
+	bucket, err := s.queries.GetBucket(ctx, bucketName)
+	if err != nil {
+		return fmt.Errorf("bucket not found: %w", err)
+	}
+
+	n, err := s.queries.DeleteS3ObjectsByKeys(ctx, database.DeleteS3ObjectsByKeysParams{
+		BucketID: bucket.ID,
+		Keys:     keys,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sync deleted objects: %w", err)
+	}
+
+	s.log.Debug("Synced deleted batch to database",
+		slog.String("bucket", bucketName),
+		slog.Int("keys", len(keys)),
+		slog.Int64("rows", n))
+
+	return nil
+}
+
+// SyncDeletedTree removes the record of key and, for a folder, of everything below it,
+// including folders the catalog inferred without a marker at S3.
+func (s *Service) SyncDeletedTree(ctx context.Context, bucketName, key string) error {
+	bucket, err := s.queries.GetBucket(ctx, bucketName)
+	if err != nil {
+		return fmt.Errorf("bucket not found: %w", err)
+	}
+
+	n, err := s.queries.DeleteS3ObjectTree(ctx, database.DeleteS3ObjectTreeParams{
+		BucketID:   bucket.ID,
+		Key:        key,
+		KeyPattern: escapeLikePattern(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sync deleted tree: %w", err)
+	}
+
+	s.log.Debug("Synced deleted tree to database",
+		slog.String("bucket", bucketName),
+		slog.String("key", key),
+		slog.Int64("rows", n))
+
+	return nil
+}
+
 // extractPrefix extracts the parent folder path from a key.
 // Examples:
 //   - "folder/" -> ""
@@ -0,0 +1,49 @@
+package dbsvc
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+)
+
+// TestSyncTree checks that moving and deleting a folder only touch the keys it prefixes: LIKE
+// wildcards in its name match themselves and the match is case-sensitive.
+func TestSyncTree(t *testing.T) {
+	ctx := context.Background()
+	object := func(key string) database.CreateS3ObjectParams {
+		return database.CreateS3ObjectParams{Key: key, IsFolder: sql.NullBool{Bool: false, Valid: true}}
+	}
+	s := newSQLiteService(t, []database.CreateS3ObjectParams{
+		object("a_%/x.txt"),
+		object("a_%/sub/y.txt"),
+		object("a_%2/z.txt"),
+		object("ab%/w.txt"),
+		object("A_%/v.txt"),
+	})
+	files := func() []string {
+		summary, err := s.GetFolderSummary(ctx, "prod-data", "", 100)
+		if err != nil {
+			t.Fatalf("GetFolderSummary() error = %v", err)
+		}
+		return summary.Sample
+	}
+
+	if err := s.SyncMovedObject(ctx, "prod-data", "a_%/", "moved/"); err != nil {
+		t.Fatalf("SyncMovedObject() error = %v", err)
+	}
+	want := []string{"A_%/v.txt", "a_%2/z.txt", "ab%/w.txt", "moved/sub/y.txt", "moved/x.txt"}
+	if got := files(); !slices.Equal(got, want) {
+		t.Errorf("after move = %v, want %v", got, want)
+	}
+
+	if err := s.SyncDeletedTree(ctx, "prod-data", "a_%2/"); err != nil {
+		t.Fatalf("SyncDeletedTree() error = %v", err)
+	}
+	want = []string{"A_%/v.txt", "ab%/w.txt", "moved/sub/y.txt", "moved/x.txt"}
+	if got := files(); !slices.Equal(got, want) {
+		t.Errorf("after delete = %v, want %v", got, want)
+	}
+}
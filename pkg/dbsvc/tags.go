@@ -0,0 +1,116 @@
+package dbsvc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dbinit"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// searchObjectsByTags returns the objects whose key contains name and which carry every filter.
+func (s *Service) searchObjectsByTags(
+	ctx context.Context, bucketID int32, name string, filters []dto.TagFilter, limit int,
+) ([]dto.S3Object, error) {
+	params := database.SearchS3ObjectsByTagsParams{
+		BucketID: bucketID,
+		Pattern:  escapeLikePattern(name),
+		Limit:    safeInt32(limit),
+	}
+	for _, f := range filters {
+		params.Sources = append(params.Sources, f.Source)
+		params.TagKeys = append(params.TagKeys, f.Key)
+		params.TagValues = append(params.TagValues, f.Value)
+	}
+
+	objects, err := s.queries.SearchS3ObjectsByTags(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search objects by tags: %w", err)
+	}
+	return s.convertToDTO(objects), nil
+}
+
+// SummarizeTags groups the indexed objects of a bucket by tag value, optionally for a single tag key.
+// It also returns how many files have up-to-date tags out of all files.
+func (s *Service) SummarizeTags(
+	ctx context.Context, bucketName, key string, limit int,
+) ([]dto.TagSummary, int64, int64, error) {
+	bucket, err := s.queries.GetBucket(ctx, bucketName)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("bucket not found: %w", err)
+	}
+
+	rows, err := s.queries.SummarizeObjectTags(ctx, database.SummarizeObjectTagsParams{
+		BucketID: bucket.ID,
+		TagKey:   key,
+		Limit:    safeInt32(limit),
+	})
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to summarize tags: %w", err)
+	}
+	coverage, err := s.queries.CountTagIndexedObjects(ctx, bucket.ID)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count indexed objects: %w", err)
+	}
+
+	summaries := make([]dto.TagSummary, 0, len(rows))
+	for _, r := range rows {
+		summaries = append(summaries, dto.TagSummary{
+			TagFilter: dto.TagFilter{Source: r.Source, Key: r.Key, Value: r.Value},
+			Objects:   r.Objects,
+			TotalSize: r.TotalSize,
+		})
+	}
+	return summaries, coverage.Indexed, coverage.Total, nil
+}
+
+// SyncObjectTags replaces the indexed tags of an object after they were edited, so the index does not
+// wait for the next scan: changing tags does not change the ETag the scanner looks at.
+// Objects not in the catalog yet are skipped; the scanner indexes them.
+func (s *Service) SyncObjectTags(ctx context.Context, bucketName, key string, tags map[string]string) error {
+	bucket, err := s.queries.GetBucket(ctx, bucketName)
+	if err != nil {
+		return fmt.Errorf("bucket not found: %w", err)
+	}
+	obj, err := s.queries.GetS3Object(ctx, database.GetS3ObjectParams{BucketID: bucket.ID, Key: key})
+	if errors.Is(err, sql.ErrNoRows) {
+		s.log.Debug("Tagged object not in the catalog", slog.String("key", key))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get object: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	qtx := dbinit.NewQuerier(s.cfg.Database.Driver(), tx)
+
+	if err := qtx.DeleteObjectTags(ctx, database.DeleteObjectTagsParams{
+		ObjectID: obj.ID,
+		Source:   dto.TagSourceTag,
+	}); err != nil {
+		return fmt.Errorf("failed to clear tags: %w", err)
+	}
+	for k, v := range tags {
+		if err := qtx.InsertObjectTag(ctx, database.InsertObjectTagParams{
+			ObjectID: obj.ID,
+			Source:   dto.TagSourceTag,
+			Key:      k,
+			Value:    v,
+		}); err != nil {
+			return fmt.Errorf("failed to store tag %s: %w", k, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tags: %w", err)
+	}
+	return nil
+}
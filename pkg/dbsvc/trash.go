@@ -0,0 +1,103 @@
+package dbsvc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// ErrTrashItemNotFound is returned when a trash item does not exist, or was already restored or purged.
+var ErrTrashItemNotFound = errors.New("trash item not found")
+
+// CreateTrashItem records an object moved to the trash.
+func (s *Service) CreateTrashItem(ctx context.Context, item dto.TrashItem) (*dto.TrashItem, error) {
+	row, err := s.queries.CreateTrashItem(ctx, database.CreateTrashItemParams{
+		BucketName:     item.Bucket,
+		OriginalKey:    item.OriginalKey,
+		TrashBucket:    item.TrashBucket,
+		TrashKey:       item.TrashKey,
+		VersionID:      item.VersionID,
+		DeleteMarkerID: item.DeleteMarkerID,
+		Size:           item.Size,
+		DeletedBy:      item.DeletedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trash item: %w", err)
+	}
+	result := convertTrashItemToDTO(row)
+	return &result, nil
+}
+
+// GetTrashItem returns the trash item identified by id.
+func (s *Service) GetTrashItem(ctx context.Context, id int32) (*dto.TrashItem, error) {
+	row, err := s.queries.GetTrashItem(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTrashItemNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash item: %w", err)
+	}
+	result := convertTrashItemToDTO(row)
+	return &result, nil
+}
+
+// ListTrashItems returns the most recently deleted trash items.
+func (s *Service) ListTrashItems(ctx context.Context, limit int) ([]dto.TrashItem, error) {
+	rows, err := s.queries.ListTrashItems(ctx, safeInt32(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash items: %w", err)
+	}
+	return convertTrashItemsToDTO(rows), nil
+}
+
+// ListTrashItemsDeletedBefore returns the oldest trash items deleted before the given time.
+func (s *Service) ListTrashItemsDeletedBefore(ctx context.Context, before time.Time, limit int) ([]dto.TrashItem, error) {
+	rows, err := s.queries.ListTrashItemsDeletedBefore(ctx, database.ListTrashItemsDeletedBeforeParams{
+		DeletedAt: before,
+		Limit:     safeInt32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired trash items: %w", err)
+	}
+	return convertTrashItemsToDTO(rows), nil
+}
+
+// DeleteTrashItem forgets a trash item once it was restored or purged.
+func (s *Service) DeleteTrashItem(ctx context.Context, id int32) error {
+	n, err := s.queries.DeleteTrashItem(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete trash item: %w", err)
+	}
+	if n == 0 {
+		return ErrTrashItemNotFound
+	}
+	return nil
+}
+
+func convertTrashItemsToDTO(rows []database.TrashItem) []dto.TrashItem {
+	result := make([]dto.TrashItem, len(rows))
+	for i, row := range rows {
+		result[i] = convertTrashItemToDTO(row)
+	}
+	return result
+}
+
+func convertTrashItemToDTO(row database.TrashItem) dto.TrashItem {
+	return dto.TrashItem{
+		ID:             row.ID,
+		Bucket:         row.BucketName,
+		OriginalKey:    row.OriginalKey,
+		TrashBucket:    row.TrashBucket,
+		TrashKey:       row.TrashKey,
+		VersionID:      row.VersionID,
+		DeleteMarkerID: row.DeleteMarkerID,
+		Size:           row.Size,
+		DeletedBy:      row.DeletedBy,
+		DeletedAt:      row.DeletedAt,
+	}
+}
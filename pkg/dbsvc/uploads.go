@@ -0,0 +1,118 @@
+package dbsvc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// ErrUploadSessionNotFound is returned when an upload session does not exist.
+var ErrUploadSessionNotFound = errors.New("upload session not found")
+
+// CreateUploadSession records a new resumable upload bound to an S3 multipart upload.
+func (s *Service) CreateUploadSession(ctx context.Context, session dto.UploadSession) (*dto.UploadSession, error) {
+	row, err := s.queries.CreateUploadSession(ctx, database.CreateUploadSessionParams{
+		ID:          session.ID,
+		BucketName:  session.Bucket,
+		Key:         session.Key,
+		UploadID:    session.UploadID,
+		ContentType: session.ContentType,
+		Size:        session.Size,
+		PartSize:    session.PartSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload session: %w", err)
+	}
+	result := convertUploadSessionToDTO(row, nil)
+	return &result, nil
+}
+
+// GetUploadSession returns an upload session with the parts uploaded so far.
+func (s *Service) GetUploadSession(ctx context.Context, id string) (*dto.UploadSession, error) {
+	row, err := s.queries.GetUploadSession(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUploadSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload session: %w", err)
+	}
+
+	parts, err := s.queries.ListUploadParts(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upload parts: %w", err)
+	}
+	result := convertUploadSessionToDTO(row, parts)
+	return &result, nil
+}
+
+// RecordUploadPart stores a part uploaded to S3; re-uploading a part replaces it.
+func (s *Service) RecordUploadPart(ctx context.Context, sessionID string, part dto.UploadPart) error {
+	err := s.queries.UpsertUploadPart(ctx, database.UpsertUploadPartParams{
+		SessionID:  sessionID,
+		PartNumber: part.Number,
+		Etag:       part.ETag,
+		Size:       part.Size,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record upload part: %w", err)
+	}
+	if err := s.queries.TouchUploadSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to update upload session: %w", err)
+	}
+	return nil
+}
+
+// SetUploadSessionStatus marks a session completed or aborted.
+func (s *Service) SetUploadSessionStatus(ctx context.Context, id, status string) error {
+	err := s.queries.SetUploadSessionStatus(ctx, database.SetUploadSessionStatusParams{ID: id, Status: status})
+	if err != nil {
+		return fmt.Errorf("failed to set upload session status: %w", err)
+	}
+	return nil
+}
+
+// ListStaleUploadSessions returns active sessions without activity since before.
+func (s *Service) ListStaleUploadSessions(
+	ctx context.Context, before time.Time, limit int,
+) ([]dto.UploadSession, error) {
+	rows, err := s.queries.ListStaleUploadSessions(ctx, database.ListStaleUploadSessionsParams{
+		UpdatedAt: before,
+		Limit:     safeInt32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale upload sessions: %w", err)
+	}
+
+	result := make([]dto.UploadSession, len(rows))
+	for i, row := range rows {
+		result[i] = convertUploadSessionToDTO(row, nil)
+	}
+	return result, nil
+}
+
+// convertUploadSessionToDTO converts a database upload session and its parts to a DTO.
+func convertUploadSessionToDTO(row database.UploadSession, parts []database.UploadPart) dto.UploadSession {
+	session := dto.UploadSession{
+		ID:          row.ID,
+		Bucket:      row.BucketName,
+		Key:         row.Key,
+		UploadID:    row.UploadID,
+		ContentType: row.ContentType,
+		Size:        row.Size,
+		PartSize:    row.PartSize,
+		PartCount:   dto.UploadPartCount(row.Size, row.PartSize),
+		Status:      row.Status,
+		Parts:       make([]dto.UploadPart, len(parts)),
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	for i, p := range parts {
+		session.Parts[i] = dto.UploadPart{Number: p.PartNumber, ETag: p.Etag, Size: p.Size}
+	}
+	return session
+}
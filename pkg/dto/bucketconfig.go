@@ -0,0 +1,52 @@
+package dto
+
+import "time"
+
+// Bucket configuration section statuses.
+const (
+	// BucketConfigSet is a section configured on the bucket.
+	BucketConfigSet = "set"
+	// BucketConfigNotSet is a section the bucket has no configuration for.
+	BucketConfigNotSet = "not set"
+	// BucketConfigNotSupported is a section the S3 implementation does not support, such as replication on MinIO.
+	BucketConfigNotSupported = "not supported"
+	// BucketConfigDenied is a section the credentials are not allowed to read.
+	BucketConfigDenied = "access denied"
+	// BucketConfigFailed is a section that could not be read for another reason.
+	BucketConfigFailed = "error"
+)
+
+// BucketConfig is the configuration of a bucket as reported by the Get*Bucket* S3 calls.
+// It is cached in the database by each scan.
+type BucketConfig struct {
+	Bucket    string                `json:"bucket"`
+	FetchedAt time.Time             `json:"fetchedAt"`
+	Sections  []BucketConfigSection `json:"sections"`
+}
+
+// BucketConfigSection is one part of a bucket configuration, such as versioning or CORS.
+type BucketConfigSection struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Properties summarize the configuration, one rule or setting per entry
+	Properties []BucketConfigProperty `json:"properties,omitempty"`
+	// Document is the configuration as JSON, for the bucket policy
+	Document string `json:"document,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// BucketConfigProperty is one setting of a bucket configuration section.
+type BucketConfigProperty struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// Section returns the section called name, or nil.
+func (c BucketConfig) Section(name string) *BucketConfigSection {
+	for i := range c.Sections {
+		if c.Sections[i].Name == name {
+			return &c.Sections[i]
+		}
+	}
+	return nil
+}
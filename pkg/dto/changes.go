@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+// Catalog change types recorded by scans.
+const (
+	ChangeCreated  = "created"
+	ChangeModified = "modified"
+	ChangeDeleted  = "deleted"
+)
+
+// ObjectChange is a file created, modified or deleted between two scans. The old state is empty for
+// created files and the new state for deleted files.
+type ObjectChange struct {
+	Key     string `json:"key"`
+	Change  string `json:"change"`
+	OldSize *int64 `json:"oldSize,omitempty"`
+	OldETag string `json:"oldEtag,omitempty"`
+	NewSize *int64 `json:"newSize,omitempty"`
+	NewETag string `json:"newEtag,omitempty"`
+}
+
+// ScanChanges is a scan job of a bucket with the number of changes it found.
+type ScanChanges struct {
+	ScanJobID   int32      `json:"scanJobId"`
+	Status      string     `json:"status"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	Created     int64      `json:"created"`
+	Modified    int64      `json:"modified"`
+	Deleted     int64      `json:"deleted"`
+}
+
+// ScanDiff is the net change of the files of a bucket between the end of two scans.
+type ScanDiff struct {
+	Bucket string `json:"bucket"`
+	// From is the earlier scan job, 0 for an empty catalog
+	From    int32          `json:"from"`
+	To      int32          `json:"to"`
+	Prefix  string         `json:"prefix"`
+	Changes []ObjectChange `json:"changes"`
+	// Truncated is set when there are more changes than listed
+	Truncated bool `json:"truncated"`
+}
@@ -0,0 +1,65 @@
+package dto
+
+// Differences between the two sides of a comparison, in the order they are checked.
+const (
+	DifferenceMissingTarget = "missing-target"
+	DifferenceMissingSource = "missing-source"
+	DifferenceSize          = "size"
+	DifferenceETag          = "etag"
+	DifferenceStorageClass  = "storage-class"
+)
+
+// Differences lists the differences of a comparison, in the order they are checked.
+var Differences = []string{
+	DifferenceMissingTarget, DifferenceMissingSource, DifferenceSize, DifferenceETag, DifferenceStorageClass,
+}
+
+// CompareSide is one side of a comparison: the files of a bucket under a prefix.
+type CompareSide struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix"`
+}
+
+// CompareEntry is a file differing between the two sides of a comparison. Key is relative to the
+// prefix of each side, and the state of a side is empty when the file is missing there.
+type CompareEntry struct {
+	Key                string `json:"key"`
+	Difference         string `json:"difference"`
+	SourceSize         *int64 `json:"sourceSize,omitempty"`
+	SourceETag         string `json:"sourceEtag,omitempty"`
+	SourceStorageClass string `json:"sourceStorageClass,omitempty"`
+	TargetSize         *int64 `json:"targetSize,omitempty"`
+	TargetETag         string `json:"targetEtag,omitempty"`
+	TargetStorageClass string `json:"targetStorageClass,omitempty"`
+}
+
+// CompareSummary counts the files of each side of a comparison and the files with each difference.
+// A file only counts for its first difference.
+type CompareSummary struct {
+	Source                 CompareSide `json:"source"`
+	Target                 CompareSide `json:"target"`
+	SourceObjects          int64       `json:"sourceObjects"`
+	TargetObjects          int64       `json:"targetObjects"`
+	MissingTarget          int64       `json:"missingTarget"`
+	MissingSource          int64       `json:"missingSource"`
+	SizeMismatches         int64       `json:"sizeMismatches"`
+	ETagMismatches         int64       `json:"etagMismatches"`
+	StorageClassMismatches int64       `json:"storageClassMismatches"`
+}
+
+// Count returns the number of files with difference, or with any difference when difference is empty.
+func (s CompareSummary) Count(difference string) int64 {
+	switch difference {
+	case DifferenceMissingTarget:
+		return s.MissingTarget
+	case DifferenceMissingSource:
+		return s.MissingSource
+	case DifferenceSize:
+		return s.SizeMismatches
+	case DifferenceETag:
+		return s.ETagMismatches
+	case DifferenceStorageClass:
+		return s.StorageClassMismatches
+	}
+	return s.MissingTarget + s.MissingSource + s.SizeMismatches + s.ETagMismatches + s.StorageClassMismatches
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "testing"
+
+func TestCompareSummaryCount(t *testing.T) {
+	summary := CompareSummary{MissingTarget: 1, MissingSource: 2, SizeMismatches: 3, ETagMismatches: 4, StorageClassMismatches: 5}
+	want := map[string]int64{
+		"":                      15,
+		DifferenceMissingTarget: 1,
+		DifferenceMissingSource: 2,
+		DifferenceSize:          3,
+		DifferenceETag:          4,
+		DifferenceStorageClass:  5,
+	}
+	for difference, count := range want {
+		if got := summary.Count(difference); got != count {
+			t.Errorf("Count(%q) = %d, want %d", difference, got, count)
+		}
+	}
+}
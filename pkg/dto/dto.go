@@ -16,6 +16,9 @@ type S3Object struct {
 	Prefix         string    `json:"prefix"`
 	IsDownloadable bool
 	IsRestoring    bool
+	// EncryptionStatus and TagCount are only known for files read from an S3 Inventory report
+	EncryptionStatus string `json:"encryptionStatus,omitempty"`
+	TagCount         *int32 `json:"tagCount,omitempty"`
 }
 
 // Bucket represents an S3 bucket with accessibility status.
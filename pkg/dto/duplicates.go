@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+// DuplicateReport lists the files of a bucket sharing an ETag and a size, as computed by the last
+// run of the duplicates job.
+type DuplicateReport struct {
+	Bucket      string            `json:"bucket"`
+	ComputedAt  time.Time         `json:"computedAt"`
+	Groups      int64             `json:"groups"`
+	WastedBytes int64             `json:"wastedBytes"`
+	Page        []DuplicateGroup  `json:"page"`
+	Prefixes    []DuplicatePrefix `json:"prefixes"`
+}
+
+// DuplicateGroup is a set of files with the same ETag and size.
+type DuplicateGroup struct {
+	ID          int32  `json:"id"`
+	ETag        string `json:"etag"`
+	Size        int64  `json:"size"`
+	Objects     int    `json:"objects"`
+	WastedBytes int64  `json:"wastedBytes"`
+	// Multipart ETags depend on the part size and are not an MD5 of the content: compare checksums
+	// before deleting
+	Multipart bool `json:"multipart"`
+	// Members are the files of the group still in the catalog, oldest first
+	Members []DuplicateObject `json:"members"`
+}
+
+// Resolved reports whether at most one file of the group is left since the report was computed.
+func (g DuplicateGroup) Resolved() bool {
+	return len(g.Members) < 2
+}
+
+// DuplicateObject is a file of a duplicate group.
+type DuplicateObject struct {
+	Key          string    `json:"key"`
+	LastModified time.Time `json:"lastModified"`
+	StorageClass string    `json:"storageClass"`
+}
+
+// DuplicatePrefix is the number and size of the copies in a folder, not counting the oldest file of
+// each group.
+type DuplicatePrefix struct {
+	Prefix      string `json:"prefix"`
+	Copies      int64  `json:"copies"`
+	WastedBytes int64  `json:"wastedBytes"`
+}
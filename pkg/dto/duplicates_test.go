@@ -0,0 +1,14 @@
+package dto
+
+import "testing"
+
+func TestDuplicateGroupResolved(t *testing.T) {
+	group := DuplicateGroup{Members: []DuplicateObject{{Key: "a.bin"}, {Key: "copy/a.bin"}}}
+	if group.Resolved() {
+		t.Error("group with two files should not be resolved")
+	}
+	group.Members = group.Members[:1]
+	if !group.Resolved() {
+		t.Error("group with one file left should be resolved")
+	}
+}
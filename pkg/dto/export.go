@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+// CatalogFilter selects the files of the catalog to export: those of Bucket under Prefix, whose key
+// contains Search when set, and not under Exclude when set.
+type CatalogFilter struct {
+	Bucket  string
+	Prefix  string
+	Search  string
+	Exclude string
+}
+
+// CatalogObject is a file of the catalog, as exported.
+type CatalogObject struct {
+	Bucket       string     `json:"bucket"`
+	Key          string     `json:"key"`
+	Size         int64      `json:"size"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	ETag         string     `json:"etag"`
+	StorageClass string     `json:"storageClass"`
+}
@@ -0,0 +1,83 @@
+package dto
+
+import "time"
+
+// Job statuses.
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// Job kinds.
+const (
+	JobKindMove         = "move"
+	JobKindCopy         = "copy"
+	JobKindDelete       = "delete"
+	JobKindRestore      = "restore"
+	JobKindStorageClass = "storage-class"
+)
+
+// Job is a long-running operation executed in the background, such as moving a folder.
+// Destination is a key in Bucket, or in TargetBucket of TargetConnection when those are set.
+type Job struct {
+	ID               int32      `json:"id"`
+	Kind             string     `json:"kind"`
+	Status           string     `json:"status"`
+	Bucket           string     `json:"bucket"`
+	Source           string     `json:"source"`
+	Destination      string     `json:"destination"`
+	TargetConnection string     `json:"targetConnection,omitempty"`
+	TargetBucket     string     `json:"targetBucket,omitempty"`
+	Options          string     `json:"options,omitempty"`
+	DryRun           bool       `json:"dryRun"`
+	TotalItems       int32      `json:"totalItems"`
+	DoneItems        int32      `json:"doneItems"`
+	FailedItems      int32      `json:"failedItems"`
+	SkippedItems     int32      `json:"skippedItems"`
+	TotalBytes       int64      `json:"totalBytes"`
+	DoneBytes        int64      `json:"doneBytes"`
+	Error            string     `json:"error,omitempty"`
+	StartedAt        *time.Time `json:"startedAt,omitempty"`
+	CompletedAt      *time.Time `json:"completedAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+// Finished reports whether the job has stopped, successfully or not.
+func (j Job) Finished() bool {
+	return j.Status == JobStatusCompleted || j.Status == JobStatusFailed
+}
+
+// Percent returns the job progress from 0 to 100, by bytes when sizes are known, else by items.
+// TotalBytes only counts the bytes the job has to transfer, not those of skipped objects.
+func (j Job) Percent() int {
+	if j.Status == JobStatusCompleted {
+		return 100
+	}
+	switch {
+	case j.TotalBytes > 0:
+		return int(min(j.DoneBytes*100/j.TotalBytes, 100))
+	case j.TotalItems > 0:
+		return int(min((j.DoneItems+j.FailedItems+j.SkippedItems)*100/j.TotalItems, 100))
+	}
+	return 0
+}
+
+// JobProgress is a snapshot of the counters a running job reports.
+type JobProgress struct {
+	DoneItems    int32
+	FailedItems  int32
+	SkippedItems int32
+	DoneBytes    int64
+}
+
+// FolderSummary describes what the catalog holds under a folder, shown before deleting it.
+type FolderSummary struct {
+	Key     string
+	Objects int64
+	Size    int64
+	// Sample holds the first keys of the files under the folder, in key order
+	Sample []string
+}
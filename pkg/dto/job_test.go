@@ -0,0 +1,35 @@
+package dto
+
+import "testing"
+
+func TestJobPercent(t *testing.T) {
+	tests := []struct {
+		name string
+		job  Job
+		want int
+	}{
+		{"pending", Job{Status: JobStatusPending}, 0},
+		{"by bytes", Job{Status: JobStatusRunning, TotalItems: 2, DoneItems: 1, TotalBytes: 400, DoneBytes: 100}, 25},
+		{"by items", Job{Status: JobStatusRunning, TotalItems: 4, DoneItems: 2, FailedItems: 1}, 75},
+		{"skipped count as handled", Job{Status: JobStatusRunning, TotalItems: 4, SkippedItems: 2}, 50},
+		{"completed empty", Job{Status: JobStatusCompleted}, 100},
+	}
+	for _, tt := range tests {
+		if got := tt.job.Percent(); got != tt.want {
+			t.Errorf("%s: Percent() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestJobFinished(t *testing.T) {
+	for status, want := range map[string]bool{
+		JobStatusPending:   false,
+		JobStatusRunning:   false,
+		JobStatusCompleted: true,
+		JobStatusFailed:    true,
+	} {
+		if got := (Job{Status: status}).Finished(); got != want {
+			t.Errorf("Job{Status: %q}.Finished() = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package dto
+
+// LifecycleRule is a bucket lifecycle rule as edited in the lifecycle form.
+// Days fields are 0 when the action is not set.
+type LifecycleRule struct {
+	ID      string
+	Enabled bool
+	// Prefix limits the rule to the keys starting with it, "" for the whole bucket
+	Prefix                   string
+	Transitions              []LifecycleTransition
+	ExpirationDays           int32
+	NoncurrentExpirationDays int32
+	// AbortIncompleteUploadDays removes the parts of multipart uploads left unfinished
+	AbortIncompleteUploadDays int32
+	// Editable is false for rules using settings the form does not support, such as tag filters or
+	// dates; they are listed and kept as they are
+	Editable bool
+	// Summary describes the rule on one line
+	Summary string
+}
+
+// LifecycleTransition moves objects to StorageClass Days after their creation.
+type LifecycleTransition struct {
+	Days         int32
+	StorageClass string
+}
+
+// LifecycleImpact is what one action of a lifecycle rule does to the files of the catalog: the files it
+// applies to as soon as the rule is saved, and those it reaches within the simulated window.
+type LifecycleImpact struct {
+	// Action is "Expire" or "Transition to <storage class>"
+	Action          string
+	Days            int32
+	DueObjects      int64
+	DueBytes        int64
+	UpcomingObjects int64
+	UpcomingBytes   int64
+}
+
+// LifecycleSimulation is the impact of a lifecycle rule on the files of the catalog.
+type LifecycleSimulation struct {
+	// WindowDays is the period of the upcoming counts
+	WindowDays int
+	Impacts    []LifecycleImpact
+	// Notes are the parts of the rule the catalog cannot simulate
+	Notes []string
+}
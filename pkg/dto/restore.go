@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+// Restore request statuses.
+const (
+	RestoreStatusPending  = "pending"
+	RestoreStatusRestored = "restored"
+	RestoreStatusExpired  = "expired"
+	RestoreStatusFailed   = "failed"
+)
+
+// RestoreRequest is the restore of an archived object, tracked until its temporary copy is available.
+type RestoreRequest struct {
+	ID          int32      `json:"id"`
+	Bucket      string     `json:"bucket"`
+	Key         string     `json:"key"`
+	Tier        string     `json:"tier"`
+	Days        int32      `json:"days"`
+	Status      string     `json:"status"`
+	RequestedBy string     `json:"requestedBy"`
+	Error       string     `json:"error,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	RequestedAt time.Time  `json:"requestedAt"`
+	CheckedAt   *time.Time `json:"checkedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// Downloadable reports whether the restored copy can be downloaded at the given time.
+// A restored request without expiry is an object that left the archive classes.
+func (r RestoreRequest) Downloadable(now time.Time) bool {
+	return r.Status == RestoreStatusRestored && (r.ExpiresAt == nil || now.Before(*r.ExpiresAt))
+}
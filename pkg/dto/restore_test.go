@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRestoreRequest_Downloadable(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		req  RestoreRequest
+		want bool
+	}{
+		{"pending", RestoreRequest{Status: RestoreStatusPending}, false},
+		{"restored", RestoreRequest{Status: RestoreStatusRestored, ExpiresAt: &later}, true},
+		{"restored copy past expiry", RestoreRequest{Status: RestoreStatusRestored, ExpiresAt: &earlier}, false},
+		{"restored out of the archive", RestoreRequest{Status: RestoreStatusRestored}, true},
+		{"expired", RestoreRequest{Status: RestoreStatusExpired, ExpiresAt: &earlier}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.req.Downloadable(now); got != tt.want {
+			t.Errorf("%s: Downloadable() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
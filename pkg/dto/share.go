@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// Share is a time-limited link to an object.
+// Presigned shares carry the signed S3 URL; proxied shares are served through /s/{token}.
+type Share struct {
+	ID               int32      `json:"id"`
+	Token            string     `json:"token"`
+	Bucket           string     `json:"bucket"`
+	Key              string     `json:"key"`
+	Creator          string     `json:"creator"`
+	Proxied          bool       `json:"proxied"`
+	URL              string     `json:"url"`
+	ExpiresAt        time.Time  `json:"expiresAt"`
+	Revoked          bool       `json:"revoked"`
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+	DownloadCount    int32      `json:"downloadCount"`
+	LastDownloadedAt *time.Time `json:"lastDownloadedAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+// Expired reports whether the share has passed its expiry time.
+func (s Share) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// Active reports whether the share can still be used.
+func (s Share) Active(now time.Time) bool {
+	return !s.Revoked && !s.Expired(now)
+}
+
+// Status returns a short label for the share state: active, expired or revoked.
+func (s Share) Status(now time.Time) string {
+	switch {
+	case s.Revoked:
+		return "revoked"
+	case s.Expired(now):
+		return "expired"
+	}
+	return "active"
+}
@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShareStatus(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		share  Share
+		want   string
+		active bool
+	}{
+		{"active", Share{ExpiresAt: now.Add(time.Hour)}, "active", true},
+		{"expired", Share{ExpiresAt: now.Add(-time.Second)}, "expired", false},
+		{"expires exactly now", Share{ExpiresAt: now}, "expired", false},
+		{"revoked wins over expired", Share{ExpiresAt: now.Add(-time.Hour), Revoked: true}, "revoked", false},
+	}
+	for _, tt := range tests {
+		if got := tt.share.Status(now); got != tt.want {
+			t.Errorf("%s: Status() = %q, want %q", tt.name, got, tt.want)
+		}
+		if got := tt.share.Active(now); got != tt.active {
+			t.Errorf("%s: Active() = %v, want %v", tt.name, got, tt.active)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package dto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// SortField identifies the column a directory listing is ordered by.
+type SortField string
+
+// SortOrder is the direction of a directory listing sort.
+type SortOrder string
+
+// Supported sort fields.
+const (
+	SortByName    SortField = "name"
+	SortBySize    SortField = "size"
+	SortByDate    SortField = "date"
+	SortByStorage SortField = "storage"
+)
+
+// Supported sort orders.
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// SortOptions describes how a directory listing is ordered.
+// Folders are always listed before files; the sort applies within each group.
+type SortOptions struct {
+	Field SortField `json:"sort"`
+	Order SortOrder `json:"order"`
+}
+
+// DefaultSort returns the default listing order (name, ascending).
+func DefaultSort() SortOptions {
+	return SortOptions{Field: SortByName, Order: SortAsc}
+}
+
+// ValidSortField reports whether field is a supported sort field.
+func ValidSortField(field SortField) bool {
+	switch field {
+	case SortByName, SortBySize, SortByDate, SortByStorage:
+		return true
+	}
+	return false
+}
+
+// ValidSortOrder reports whether order is a supported sort order.
+func ValidSortOrder(order SortOrder) bool {
+	return order == SortAsc || order == SortDesc
+}
+
+// DefaultOrderFor returns the natural first-click order for a field.
+// Size and date default to descending ("largest first", "newest first").
+func DefaultOrderFor(field SortField) SortOrder {
+	if field == SortBySize || field == SortByDate {
+		return SortDesc
+	}
+	return SortAsc
+}
+
+// IsDefault reports whether the options match DefaultSort.
+func (s SortOptions) IsDefault() bool {
+	return s == DefaultSort()
+}
+
+// Descending reports whether the listing is in descending order.
+func (s SortOptions) Descending() bool {
+	return s.Order == SortDesc
+}
+
+// Toggle returns the options resulting from clicking the header of field:
+// the same field flips its order, a different field starts at its default order.
+func (s SortOptions) Toggle(field SortField) SortOptions {
+	if s.Field == field {
+		if s.Order == SortAsc {
+			return SortOptions{Field: field, Order: SortDesc}
+		}
+		return SortOptions{Field: field, Order: SortAsc}
+	}
+	return SortOptions{Field: field, Order: DefaultOrderFor(field)}
+}
+
+// QueryString returns the sort query parameters prefixed with '&',
+// or an empty string for the default sort so existing URLs stay unchanged.
+func (s SortOptions) QueryString() string {
+	if s.IsDefault() {
+		return ""
+	}
+	return fmt.Sprintf("&sort=%s&order=%s", url.QueryEscape(string(s.Field)), url.QueryEscape(string(s.Order)))
+}
+
+// ErrInvalidCursor is returned when a listing cursor token cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid listing cursor")
+
+// ListingCursor is the position of an entry in a sorted directory listing: its segment, the value
+// of the sort field and its key. Only the value of the field the listing is sorted by is used.
+type ListingCursor struct {
+	IsFolder     bool      `json:"f,omitempty"`
+	Key          string    `json:"k"`
+	Size         int64     `json:"s,omitempty"`
+	LastModified time.Time `json:"m,omitzero"`
+	StorageClass string    `json:"c,omitempty"`
+}
+
+// PageCursor selects a page of a sorted directory listing relative to a neighbouring page: the
+// entries after After, or the entries before Before. A zero PageCursor selects the first page.
+type PageCursor struct {
+	After  *ListingCursor
+	Before *ListingCursor
+}
+
+// CursorOf returns the listing cursor of an entry.
+func CursorOf(obj S3Object) ListingCursor {
+	return ListingCursor{
+		IsFolder:     obj.IsFolder,
+		Key:          obj.Key,
+		Size:         obj.Size,
+		LastModified: obj.LastModified,
+		StorageClass: obj.StorageClass,
+	}
+}
+
+// Encode returns the cursor as an opaque URL-safe token.
+func (c ListingCursor) Encode() string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeListingCursor decodes a token returned by Encode.
+func DecodeListingCursor(token string) (ListingCursor, error) {
+	var c ListingCursor
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	if c.Key == "" {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSortOptions_Toggle(t *testing.T) {
+	tests := []struct {
+		name    string
+		current SortOptions
+		field   SortField
+		want    SortOptions
+	}{
+		{"Same field flips asc to desc", SortOptions{SortByName, SortAsc}, SortByName, SortOptions{SortByName, SortDesc}},
+		{"Same field flips desc to asc", SortOptions{SortBySize, SortDesc}, SortBySize, SortOptions{SortBySize, SortAsc}},
+		{"New size field starts desc", DefaultSort(), SortBySize, SortOptions{SortBySize, SortDesc}},
+		{"New date field starts desc", DefaultSort(), SortByDate, SortOptions{SortByDate, SortDesc}},
+		{"New storage field starts asc", SortOptions{SortByDate, SortDesc}, SortByStorage, SortOptions{SortByStorage, SortAsc}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.current.Toggle(tt.field); got != tt.want {
+				t.Errorf("Toggle(%s) = %+v, want %+v", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortOptions_QueryString(t *testing.T) {
+	if got := DefaultSort().QueryString(); got != "" {
+		t.Errorf("Expected empty query string for default sort, got %q", got)
+	}
+
+	got := SortOptions{Field: SortByDate, Order: SortDesc}.QueryString()
+	if got != "&sort=date&order=desc" {
+		t.Errorf("Expected \"&sort=date&order=desc\", got %q", got)
+	}
+}
+
+func TestListingCursor_EncodeDecode(t *testing.T) {
+	want := ListingCursor{
+		Key:          "logs/app 1.log",
+		Size:         42,
+		LastModified: time.Date(2026, 10, 18, 9, 30, 0, 123456000, time.UTC),
+		StorageClass: "GLACIER",
+	}
+	got, err := DecodeListingCursor(want.Encode())
+	if err != nil {
+		t.Fatalf("DecodeListingCursor() unexpected error: %v", err)
+	}
+	if !got.LastModified.Equal(want.LastModified) || got.Key != want.Key || got.Size != want.Size ||
+		got.StorageClass != want.StorageClass || got.IsFolder {
+		t.Errorf("DecodeListingCursor() = %+v, want %+v", got, want)
+	}
+
+	for _, token := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		if _, err := DecodeListingCursor(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeListingCursor(%q) error = %v, want ErrInvalidCursor", token, err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package dto
+
+// StorageClassUsage is the number and size of objects stored in a storage class.
+type StorageClassUsage struct {
+	Class   string
+	Objects int
+	Bytes   int64
+}
+
+// StorageClassEstimate describes a storage class change before it runs: the objects it copies,
+// grouped by their current class, and the monthly storage cost before and after.
+// Archived objects are only copied if they are restored, so they are counted apart.
+type StorageClassEstimate struct {
+	Target        string
+	Objects       int
+	Bytes         int64
+	Unchanged     int
+	Archived      int
+	ArchivedBytes int64
+	Current       []StorageClassUsage
+	// CurrentMonthlyCost and TargetMonthlyCost are the storage costs of the copied objects
+	CurrentMonthlyCost float64
+	TargetMonthlyCost  float64
+}
+
+// MonthlyCostDelta is the change of the monthly storage cost; negative values are savings.
+func (e StorageClassEstimate) MonthlyCostDelta() float64 {
+	return e.TargetMonthlyCost - e.CurrentMonthlyCost
+}
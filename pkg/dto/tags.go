@@ -0,0 +1,125 @@
+package dto
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Sources of indexed tags.
+const (
+	TagSourceTag      = "tag"
+	TagSourceMetadata = "metadata"
+)
+
+// searchTagPrefixes maps the search prefixes to the source they filter on.
+var searchTagPrefixes = map[string]string{
+	"tag:":  TagSourceTag,
+	"meta:": TagSourceMetadata,
+}
+
+// TagFilter restricts a search to objects with an indexed tag or user metadata key.
+// An empty Value matches any value of Key.
+type TagFilter struct {
+	Source string `json:"source"`
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+}
+
+// Query returns the search term selecting the filter, quoted when it contains spaces.
+func (f TagFilter) Query() string {
+	prefix := "tag:"
+	if f.Source == TagSourceMetadata {
+		prefix = "meta:"
+	}
+	term := f.Key
+	if f.Value != "" {
+		term += "=" + f.Value
+	}
+	if strings.ContainsAny(term, " \t\"") {
+		term = strconv.Quote(term)
+	}
+	return prefix + term
+}
+
+// TagSummary is the number and total size of the objects carrying one tag value.
+type TagSummary struct {
+	TagFilter
+	Objects   int64 `json:"objects"`
+	TotalSize int64 `json:"totalSize"`
+}
+
+// ParseSearchQuery splits a search into the name to match and the tag:key=value and meta:key=value
+// filters. Values with spaces can be quoted: tag:"team=data platform" or tag:team="data platform".
+func ParseSearchQuery(query string) (string, []TagFilter) {
+	var (
+		names   []string
+		filters []TagFilter
+	)
+	for _, term := range splitSearchTerms(query) {
+		filter, ok := parseTagTerm(term)
+		if !ok {
+			names = append(names, term)
+			continue
+		}
+		filters = append(filters, filter)
+	}
+	return strings.Join(names, " "), filters
+}
+
+// parseTagTerm parses a tag: or meta: search term.
+func parseTagTerm(term string) (TagFilter, bool) {
+	for prefix, source := range searchTagPrefixes {
+		rest, ok := strings.CutPrefix(term, prefix)
+		if !ok {
+			continue
+		}
+		key, value, _ := strings.Cut(unquote(rest), "=")
+		key = unquote(key)
+		if key == "" {
+			return TagFilter{}, false
+		}
+		if source == TagSourceMetadata {
+			key = strings.ToLower(key)
+		}
+		return TagFilter{Source: source, Key: key, Value: unquote(value)}, true
+	}
+	return TagFilter{}, false
+}
+
+// splitSearchTerms splits a query on spaces outside double quotes. Quotes are kept in the terms.
+func splitSearchTerms(query string) []string {
+	var (
+		terms   []string
+		current strings.Builder
+		quoted  bool
+	)
+	for _, c := range query {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			current.WriteRune(c)
+		case (c == ' ' || c == '\t') && !quoted:
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(c)
+		}
+	}
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+	return terms
+}
+
+// unquote removes the double quotes around s, if any.
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		name    string
+		filters []TagFilter
+	}{
+		{"report", "report", nil},
+		{"tag:project=atlas", "", []TagFilter{{Source: TagSourceTag, Key: "project", Value: "atlas"}}},
+		{"q3 tag:pii report", "q3 report", []TagFilter{{Source: TagSourceTag, Key: "pii"}}},
+		{"meta:Owner=alice", "", []TagFilter{{Source: TagSourceMetadata, Key: "owner", Value: "alice"}}},
+		{`tag:"team=data platform"`, "", []TagFilter{{Source: TagSourceTag, Key: "team", Value: "data platform"}}},
+		{`tag:team="data platform" csv`, "csv", []TagFilter{{Source: TagSourceTag, Key: "team", Value: "data platform"}}},
+		{"tag:", "tag:", nil},
+	}
+	for _, tt := range tests {
+		name, filters := ParseSearchQuery(tt.query)
+		assert.Equal(t, tt.name, name, tt.query)
+		assert.Equal(t, tt.filters, filters, tt.query)
+	}
+}
+
+func TestTagFilterQuery(t *testing.T) {
+	for _, f := range []TagFilter{
+		{Source: TagSourceTag, Key: "project", Value: "atlas"},
+		{Source: TagSourceTag, Key: "pii"},
+		{Source: TagSourceMetadata, Key: "owner", Value: "alice"},
+		{Source: TagSourceTag, Key: "team", Value: "data platform"},
+	} {
+		_, filters := ParseSearchQuery(f.Query())
+		assert.Equal(t, []TagFilter{f}, filters, f.Query())
+	}
+}
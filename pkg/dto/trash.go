@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// TrashItem is an object deleted while the trash was enabled, kept until it is restored or purged.
+// In unversioned buckets the object was copied to TrashKey of TrashBucket; in versioned buckets
+// the deleted version VersionID is hidden behind the delete marker DeleteMarkerID.
+type TrashItem struct {
+	ID             int32     `json:"id"`
+	Bucket         string    `json:"bucket"`
+	OriginalKey    string    `json:"originalKey"`
+	TrashBucket    string    `json:"trashBucket,omitempty"`
+	TrashKey       string    `json:"trashKey,omitempty"`
+	VersionID      string    `json:"versionId,omitempty"`
+	DeleteMarkerID string    `json:"deleteMarkerId,omitempty"`
+	Size           int64     `json:"size"`
+	DeletedBy      string    `json:"deletedBy"`
+	DeletedAt      time.Time `json:"deletedAt"`
+}
+
+// Versioned reports whether the item was deleted with a delete marker rather than copied to the trash.
+func (t TrashItem) Versioned() bool {
+	return t.TrashKey == ""
+}
+
+// PurgeAt returns when the scheduled purge removes the item for good.
+func (t TrashItem) PurgeAt(retention time.Duration) time.Time {
+	return t.DeletedAt.Add(retention)
+}
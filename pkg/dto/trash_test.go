@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrashItem(t *testing.T) {
+	deletedAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	copied := TrashItem{TrashBucket: "bucket", TrashKey: ".trash/1/a.txt", DeletedAt: deletedAt}
+	if copied.Versioned() {
+		t.Error("item copied to the trash should not be versioned")
+	}
+	marked := TrashItem{VersionID: "v1", DeleteMarkerID: "m1", DeletedAt: deletedAt}
+	if !marked.Versioned() {
+		t.Error("item hidden by a delete marker should be versioned")
+	}
+
+	if got, want := copied.PurgeAt(30*24*time.Hour), deletedAt.AddDate(0, 0, 30); !got.Equal(want) {
+		t.Errorf("PurgeAt() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,56 @@
+package dto
+
+import "time"
+
+// Upload session statuses.
+const (
+	UploadStatusActive    = "active"
+	UploadStatusCompleted = "completed"
+	UploadStatusAborted   = "aborted"
+)
+
+// UploadSession is a resumable multipart upload driven by the browser, one part per request.
+type UploadSession struct {
+	ID          string       `json:"id"`
+	Bucket      string       `json:"bucket"`
+	Key         string       `json:"key"`
+	UploadID    string       `json:"-"`
+	ContentType string       `json:"contentType"`
+	Size        int64        `json:"size"`
+	PartSize    int64        `json:"partSize"`
+	PartCount   int32        `json:"partCount"`
+	Status      string       `json:"status"`
+	Parts       []UploadPart `json:"parts"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+// UploadPart is a part of an upload session already stored at S3.
+type UploadPart struct {
+	Number int32  `json:"number"`
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+}
+
+// UploadPartCount returns how many parts of partSize bytes are needed for size bytes.
+// An empty file still needs one (empty) part.
+func UploadPartCount(size, partSize int64) int32 {
+	if size <= 0 || partSize <= 0 {
+		return 1
+	}
+	return int32((size + partSize - 1) / partSize) //nolint:gosec // bounded by S3's 10000 parts
+}
+
+// ExpectedPartSize returns the exact size of part number (1-based) of the session.
+// Every part is PartSize bytes except the last, which holds the remainder.
+func (u UploadSession) ExpectedPartSize(number int32) int64 {
+	if number < u.PartCount {
+		return u.PartSize
+	}
+	return u.Size - int64(u.PartCount-1)*u.PartSize
+}
+
+// Complete reports whether every part of the session has been uploaded.
+func (u UploadSession) Complete() bool {
+	return int32(len(u.Parts)) == u.PartCount //nolint:gosec // parts are bounded by PartCount
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "testing"
+
+func TestUploadPartCount(t *testing.T) {
+	tests := []struct {
+		size, partSize int64
+		want           int32
+	}{
+		{0, 10, 1},
+		{1, 10, 1},
+		{10, 10, 1},
+		{11, 10, 2},
+		{95, 10, 10},
+	}
+	for _, tt := range tests {
+		if got := UploadPartCount(tt.size, tt.partSize); got != tt.want {
+			t.Errorf("UploadPartCount(%d, %d) = %d, want %d", tt.size, tt.partSize, got, tt.want)
+		}
+	}
+}
+
+func TestUploadSessionExpectedPartSize(t *testing.T) {
+	session := UploadSession{Size: 25, PartSize: 10, PartCount: UploadPartCount(25, 10)}
+	for number, want := range map[int32]int64{1: 10, 2: 10, 3: 5} {
+		if got := session.ExpectedPartSize(number); got != want {
+			t.Errorf("ExpectedPartSize(%d) = %d, want %d", number, got, want)
+		}
+	}
+
+	if session.Complete() {
+		t.Error("Complete() = true without parts")
+	}
+	session.Parts = []UploadPart{{Number: 1}, {Number: 2}, {Number: 3}}
+	if !session.Complete() {
+		t.Error("Complete() = false with every part uploaded")
+	}
+}
@@ -0,0 +1,190 @@
+// Package export writes the files of the catalog as CSV, JSON Lines or Parquet.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// Export formats, also used as file extensions.
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+const (
+	// batchSize is the number of files read from the catalog at once.
+	batchSize = 1000
+	// parquetRowsPerRowGroup bounds the rows a Parquet writer buffers before writing a row group.
+	parquetRowsPerRowGroup = 100000
+)
+
+// ErrUnknownFormat is returned for an export format other than csv, ndjson and parquet.
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Formats lists the export formats.
+var Formats = []string{FormatCSV, FormatNDJSON, FormatParquet}
+
+// Source reads the files of the catalog batch by batch, as the database service does.
+type Source interface {
+	ExportObjects(ctx context.Context, filter dto.CatalogFilter, batch int, fn func([]dto.CatalogObject) error) error
+}
+
+// Writer writes files of the catalog in an export format. Close completes the output
+// without closing the underlying writer.
+type Writer interface {
+	Write(objects []dto.CatalogObject) error
+	Close() error
+}
+
+// NewWriter returns a Writer of format writing to w.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return &parquetWriter{w: parquet.NewGenericWriter[parquetRow](w,
+			parquet.Compression(&parquet.Snappy),
+			parquet.MaxRowsPerRowGroup(parquetRowsPerRowGroup),
+		)}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// Catalog writes the files of the catalog selected by filter to w in format, and returns their number.
+func Catalog(ctx context.Context, src Source, filter dto.CatalogFilter, format string, w io.Writer) (int64, error) {
+	writer, err := NewWriter(format, w)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = src.ExportObjects(ctx, filter, batchSize, func(objects []dto.CatalogObject) error {
+		count += int64(len(objects))
+		return writer.Write(objects)
+	})
+	if err != nil {
+		return count, fmt.Errorf("export of %s interrupted: %w", filter.Bucket, err)
+	}
+	return count, writer.Close()
+}
+
+// ContentType returns the media type of format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	}
+	return "application/octet-stream"
+}
+
+// csvWriter writes a header, then one row per file with its last modification time in RFC 3339.
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvWriter) Write(objects []dto.CatalogObject) error {
+	if !c.headerWritten {
+		c.headerWritten = true
+		if err := c.w.Write([]string{"bucket", "key", "size", "last_modified", "etag", "storage_class"}); err != nil {
+			return fmt.Errorf("cannot write CSV header: %w", err)
+		}
+	}
+	for _, obj := range objects {
+		lastModified := ""
+		if obj.LastModified != nil {
+			lastModified = obj.LastModified.UTC().Format(time.RFC3339)
+		}
+		record := []string{obj.Bucket, obj.Key, strconv.FormatInt(obj.Size, 10), lastModified, obj.ETag, obj.StorageClass}
+		if err := c.w.Write(record); err != nil {
+			return fmt.Errorf("cannot write CSV row: %w", err)
+		}
+	}
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return fmt.Errorf("cannot write CSV: %w", err)
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	// An empty export still has its header
+	return c.Write(nil)
+}
+
+// ndjsonWriter writes one JSON object per line and file.
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(objects []dto.CatalogObject) error {
+	for _, obj := range objects {
+		if err := n.enc.Encode(obj); err != nil {
+			return fmt.Errorf("cannot write JSON line: %w", err)
+		}
+	}
+	return nil
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+// parquetRow is the Parquet schema of an exported file.
+type parquetRow struct {
+	Bucket       string `parquet:"bucket,dict"`
+	Key          string `parquet:"key"`
+	Size         int64  `parquet:"size"`
+	LastModified int64  `parquet:"last_modified,optional,timestamp(millisecond)"`
+	ETag         string `parquet:"etag"`
+	StorageClass string `parquet:"storage_class,dict"`
+}
+
+// parquetWriter writes a Parquet file, one row group every parquetRowsPerRowGroup files.
+type parquetWriter struct {
+	w    *parquet.GenericWriter[parquetRow]
+	rows []parquetRow
+}
+
+func (p *parquetWriter) Write(objects []dto.CatalogObject) error {
+	p.rows = p.rows[:0]
+	for _, obj := range objects {
+		row := parquetRow{
+			Bucket:       obj.Bucket,
+			Key:          obj.Key,
+			Size:         obj.Size,
+			ETag:         obj.ETag,
+			StorageClass: obj.StorageClass,
+		}
+		// Zero values of optional columns are written as null
+		if obj.LastModified != nil {
+			row.LastModified = obj.LastModified.UnixMilli()
+		}
+		p.rows = append(p.rows, row)
+	}
+	if _, err := p.w.Write(p.rows); err != nil {
+		return fmt.Errorf("cannot write Parquet rows: %w", err)
+	}
+	return nil
+}
+
+func (p *parquetWriter) Close() error {
+	if err := p.w.Close(); err != nil {
+		return fmt.Errorf("cannot complete Parquet file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testObjects() []dto.CatalogObject {
+	modified := time.Date(2026, 10, 18, 8, 30, 0, 0, time.UTC)
+	return []dto.CatalogObject{
+		{Bucket: "prod-data", Key: "logs/a,b.log", Size: 42, LastModified: &modified, ETag: `"abc"`, StorageClass: "STANDARD"},
+		{Bucket: "prod-data", Key: "logs/c.log", Size: 7, ETag: `"def-2"`, StorageClass: "GLACIER"},
+	}
+}
+
+// writeAll writes objects one batch per file, as the catalog cursor would.
+func writeAll(t *testing.T, format string, objects []dto.CatalogObject) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	require.NoError(t, err)
+	for i := range objects {
+		require.NoError(t, w.Write(objects[i:i+1]))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestNewWriter_UnknownFormat(t *testing.T) {
+	_, err := NewWriter("xlsx", &bytes.Buffer{})
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestCSVWriter(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(writeAll(t, FormatCSV, testObjects()))).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"bucket", "key", "size", "last_modified", "etag", "storage_class"},
+		{"prod-data", "logs/a,b.log", "42", "2026-10-18T08:30:00Z", `"abc"`, "STANDARD"},
+		{"prod-data", "logs/c.log", "7", "", `"def-2"`, "GLACIER"},
+	}, records)
+
+	// An empty export is only the header
+	records, err = csv.NewReader(bytes.NewReader(writeAll(t, FormatCSV, nil))).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	scanner := bufio.NewScanner(bytes.NewReader(writeAll(t, FormatNDJSON, testObjects())))
+	var objects []dto.CatalogObject
+	for scanner.Scan() {
+		var obj dto.CatalogObject
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &obj))
+		objects = append(objects, obj)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, testObjects(), objects)
+}
+
+func TestParquetWriter(t *testing.T) {
+	data := writeAll(t, FormatParquet, testObjects())
+
+	rows, err := parquet.Read[parquetRow](bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "logs/a,b.log", rows[0].Key)
+	assert.Equal(t, int64(42), rows[0].Size)
+	assert.Equal(t, testObjects()[0].LastModified.UnixMilli(), rows[0].LastModified)
+	assert.Zero(t, rows[1].LastModified)
+	assert.Equal(t, "GLACIER", rows[1].StorageClass)
+}
+
+// fakeSource serves objects in batches, failing after the first one when err is set.
+type fakeSource struct {
+	objects []dto.CatalogObject
+	err     error
+	filter  dto.CatalogFilter
+}
+
+func (f *fakeSource) ExportObjects(
+	_ context.Context, filter dto.CatalogFilter, batch int, fn func([]dto.CatalogObject) error,
+) error {
+	f.filter = filter
+	for start := 0; start < len(f.objects); start += batch {
+		if err := fn(f.objects[start:min(start+batch, len(f.objects))]); err != nil {
+			return err
+		}
+		if f.err != nil {
+			return f.err
+		}
+	}
+	return nil
+}
+
+func TestCatalog(t *testing.T) {
+	objects := make([]dto.CatalogObject, batchSize+1)
+	for i := range objects {
+		objects[i] = dto.CatalogObject{Bucket: "prod-data", Key: fmt.Sprintf("logs/%05d.log", i)}
+	}
+	src := &fakeSource{objects: objects}
+	filter := dto.CatalogFilter{Bucket: "prod-data", Prefix: "logs/"}
+
+	var buf bytes.Buffer
+	count, err := Catalog(t.Context(), src, filter, FormatNDJSON, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(objects)), count)
+	assert.Equal(t, filter, src.filter)
+	assert.Equal(t, len(objects), bytes.Count(buf.Bytes(), []byte("\n")))
+
+	src.err = errors.New("connection reset")
+	_, err = Catalog(t.Context(), src, filter, FormatCSV, &bytes.Buffer{})
+	require.ErrorIs(t, err, src.err)
+
+	_, err = Catalog(t.Context(), src, filter, "xlsx", &bytes.Buffer{})
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
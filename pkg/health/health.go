@@ -3,7 +3,6 @@ package health
 
 import (
 	"context"
-	"database/sql"
 	"log/slog"
 	"sync"
 	"time"
@@ -21,10 +20,15 @@ const (
 	StatusUnknown Status = "unknown"
 )
 
+// Pinger is the database connection checked by DatabaseHealth; *sql.DB implements it whatever its driver.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
 // DatabaseHealth tracks database connectivity health.
 type DatabaseHealth struct {
 	mu                 sync.RWMutex
-	db                 *sql.DB
+	db                 Pinger
 	status             Status
 	lastCheck          time.Time
 	lastError          error
@@ -45,7 +49,7 @@ type Info struct {
 }
 
 // NewDatabaseHealth creates a new database health monitor.
-func NewDatabaseHealth(db *sql.DB, logger *slog.Logger) *DatabaseHealth {
+func NewDatabaseHealth(db Pinger, logger *slog.Logger) *DatabaseHealth {
 	const (
 		defaultCheckInterval = 30 * time.Second
 		defaultMaxRetries    = 3
@@ -105,7 +109,7 @@ func (h *DatabaseHealth) IsHealthy() bool {
 }
 
 // UpdateDatabase updates the database connection being monitored.
-func (h *DatabaseHealth) UpdateDatabase(db *sql.DB) {
+func (h *DatabaseHealth) UpdateDatabase(db Pinger) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
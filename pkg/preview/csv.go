@@ -0,0 +1,76 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+)
+
+// CSVPage is one page of rows read from a chunk of a CSV or TSV file.
+type CSVPage struct {
+	Header []string
+	Rows   [][]string
+	// Consumed is the number of chunk bytes covered by Rows; the next page starts at chunk offset + Consumed
+	Consumed int64
+}
+
+// Delimiter returns the field separator for a CSV or TSV key.
+func Delimiter(key string) rune {
+	if Extension(key) == "tsv" {
+		return '\t'
+	}
+	return ','
+}
+
+// newCSVReader returns a lenient reader: previews should show malformed files rather than fail.
+func newCSVReader(data []byte, delimiter rune) *csv.Reader {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.ReuseRecord = false
+	return r
+}
+
+// ReadCSVHeader returns the first record of chunk.
+func ReadCSVHeader(chunk []byte, delimiter rune) []string {
+	record, err := newCSVReader(chunk, delimiter).Read()
+	if err != nil {
+		return nil
+	}
+	return record
+}
+
+// ReadCSVPage parses up to maxRows complete records from chunk.
+// When skipHeader is set the first record is returned as Header instead of a row.
+// If truncated is true the chunk was cut from a larger file, so a final record
+// that is not terminated by a newline is treated as partial and left for the next page.
+func ReadCSVPage(chunk []byte, delimiter rune, maxRows int, skipHeader, truncated bool) CSVPage {
+	var page CSVPage
+	r := newCSVReader(chunk, delimiter)
+
+	for len(page.Rows) < maxRows {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		end := r.InputOffset()
+		if err != nil || (truncated && end >= int64(len(chunk)) && !endsWithNewline(chunk)) {
+			// Parse error or partial last record of a cut chunk: stop before it
+			break
+		}
+
+		if skipHeader && page.Header == nil {
+			page.Header = record
+		} else {
+			page.Rows = append(page.Rows, record)
+		}
+		page.Consumed = end
+	}
+	return page
+}
+
+func endsWithNewline(data []byte) bool {
+	return len(data) > 0 && data[len(data)-1] == '\n'
+}
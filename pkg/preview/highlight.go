@@ -0,0 +1,42 @@
+package preview
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// highlightStyle is the chroma style used for code previews.
+const highlightStyle = "github"
+
+// Highlight returns source rendered as HTML with inline syntax-highlighting styles.
+// The lexer is chosen from the file name, then from the content; unknown files are rendered as plain text.
+// The returned HTML escapes the source and is safe to embed.
+func Highlight(filename, source string) (string, error) {
+	lexer := lexers.Match(filename)
+	if lexer == nil {
+		lexer = lexers.Analyse(source)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	style := styles.Get(highlightStyle)
+	formatter := html.New(html.WithLineNumbers(true), html.TabWidth(4)) //nolint:mnd // Standard tab width
+
+	iterator, err := lexer.Tokenise(nil, source)
+	if err != nil {
+		return "", fmt.Errorf("failed to tokenise %s: %w", filename, err)
+	}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(&buf, style, iterator); err != nil {
+		return "", fmt.Errorf("failed to format %s: %w", filename, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,134 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ErrTooDeep is returned when a JSON document nests deeper than maxJSONDepth.
+var ErrTooDeep = errors.New("JSON document is nested too deeply to preview")
+
+// maxJSONDepth bounds recursion when building and rendering JSON trees.
+const maxJSONDepth = 64
+
+// JSONNodeKind is the type of a JSON value.
+type JSONNodeKind string
+
+// JSON value types.
+const (
+	JSONObject JSONNodeKind = "object"
+	JSONArray  JSONNodeKind = "array"
+	JSONString JSONNodeKind = "string"
+	JSONNumber JSONNodeKind = "number"
+	JSONBool   JSONNodeKind = "bool"
+	JSONNull   JSONNodeKind = "null"
+)
+
+// JSONNode is one value of a parsed JSON document, keeping object keys in document order.
+type JSONNode struct {
+	Kind JSONNodeKind
+	// Key is the member name inside an object, or the index inside an array
+	Key string
+	// Value is the literal of a scalar (strings are quoted)
+	Value    string
+	Children []JSONNode
+}
+
+// IsContainer reports whether the node is an object or an array.
+func (n JSONNode) IsContainer() bool {
+	return n.Kind == JSONObject || n.Kind == JSONArray
+}
+
+// Summary returns a short description of a container, e.g. "{3 keys}" or "[10 items]".
+func (n JSONNode) Summary() string {
+	if n.Kind == JSONObject {
+		return fmt.Sprintf("{%d keys}", len(n.Children))
+	}
+	return fmt.Sprintf("[%d items]", len(n.Children))
+}
+
+// ParseJSONTree parses a complete JSON document into an ordered tree.
+func ParseJSONTree(data []byte) (*JSONNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	root, err := parseJSONValue(dec, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after top-level value")
+	}
+	return &root, nil
+}
+
+// PrettyJSON returns data re-indented with two spaces.
+func PrettyJSON(data []byte) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func parseJSONValue(dec *json.Decoder, key string, depth int) (JSONNode, error) {
+	if depth > maxJSONDepth {
+		return JSONNode{}, ErrTooDeep
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return JSONNode{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	node := JSONNode{Key: key}
+	switch v := tok.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			node.Kind = JSONObject
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return JSONNode{}, fmt.Errorf("invalid JSON: %w", err)
+				}
+				name, _ := keyTok.(string)
+				child, err := parseJSONValue(dec, name, depth+1)
+				if err != nil {
+					return JSONNode{}, err
+				}
+				node.Children = append(node.Children, child)
+			}
+		case '[':
+			node.Kind = JSONArray
+			for i := 0; dec.More(); i++ {
+				child, err := parseJSONValue(dec, strconv.Itoa(i), depth+1)
+				if err != nil {
+					return JSONNode{}, err
+				}
+				node.Children = append(node.Children, child)
+			}
+		}
+		// Consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return JSONNode{}, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case string:
+		node.Kind = JSONString
+		node.Value = strconv.Quote(v)
+	case json.Number:
+		node.Kind = JSONNumber
+		node.Value = v.String()
+	case bool:
+		node.Kind = JSONBool
+		node.Value = strconv.FormatBool(v)
+	case nil:
+		node.Kind = JSONNull
+		node.Value = "null"
+	}
+	return node, nil
+}
@@ -0,0 +1,108 @@
+// Package preview turns the first bytes of an S3 object into inline previews:
+// syntax-highlighted text, collapsible JSON trees and paged CSV/TSV tables.
+package preview
+
+import (
+	"mime"
+	"path"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind is the kind of inline preview available for an object.
+type Kind string
+
+// Supported preview kinds.
+const (
+	KindImage       Kind = "image"
+	KindPDF         Kind = "pdf"
+	KindJSON        Kind = "json"
+	KindCSV         Kind = "csv"
+	KindText        Kind = "text"
+	KindUnsupported Kind = "unsupported"
+)
+
+//nolint:gochecknoglobals // Read-only extension tables
+var (
+	imageExtensions = []string{"png", "jpg", "jpeg", "gif", "webp", "bmp", "svg", "ico", "avif"}
+	textExtensions  = []string{
+		"txt", "log", "md", "markdown", "rst", "ini", "cfg", "conf", "toml", "yaml", "yml", "xml", "html", "htm",
+		"css", "js", "mjs", "ts", "tsx", "jsx", "go", "py", "rb", "java", "kt", "scala", "c", "h", "cc", "cpp",
+		"hpp", "cs", "rs", "php", "pl", "sh", "bash", "zsh", "ps1", "sql", "tf", "hcl", "proto", "graphql",
+		"dockerfile", "makefile", "env", "properties", "gradle", "lua", "r", "swift", "vue", "svelte", "ndjson",
+		"jsonl", "diff", "patch",
+	}
+)
+
+// Extension returns the lowercased extension of key without the dot.
+// Extension-less names such as Dockerfile or Makefile return the lowercased name.
+func Extension(key string) string {
+	name := strings.ToLower(path.Base(key))
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// DetectKind picks the preview kind from the object key, falling back to the content type.
+func DetectKind(key, contentType string) Kind {
+	switch ext := Extension(key); {
+	case slices.Contains(imageExtensions, ext):
+		return KindImage
+	case ext == "pdf":
+		return KindPDF
+	case ext == "json" || ext == "geojson":
+		return KindJSON
+	case ext == "csv" || ext == "tsv":
+		return KindCSV
+	case slices.Contains(textExtensions, ext):
+		return KindText
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		return KindImage
+	case mediaType == "application/pdf":
+		return KindPDF
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return KindJSON
+	case mediaType == "text/csv" || mediaType == "text/tab-separated-values":
+		return KindCSV
+	case strings.HasPrefix(mediaType, "text/"):
+		return KindText
+	}
+	return KindUnsupported
+}
+
+// InlineContentType returns the content type used when streaming an image or PDF inline.
+// S3 objects uploaded without a content type default to binary/octet-stream,
+// which browsers refuse to display.
+func InlineContentType(key, contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil &&
+		(strings.HasPrefix(mediaType, "image/") || mediaType == "application/pdf") {
+		return contentType
+	}
+	if byExt := mime.TypeByExtension("." + Extension(key)); byExt != "" {
+		return byExt
+	}
+	return "application/octet-stream"
+}
+
+// TrimToValidUTF8 drops a trailing partial UTF-8 sequence left by cutting a byte range mid-character.
+func TrimToValidUTF8(data []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(data) > 0; i++ {
+		if utf8.Valid(data) {
+			return data
+		}
+		data = data[:len(data)-1]
+	}
+	return data
+}
+
+// LooksBinary reports whether data seems to be binary rather than text (NUL bytes in the first KB).
+func LooksBinary(data []byte) bool {
+	const sniffLen = 1024
+	return slices.Contains(data[:min(len(data), sniffLen)], 0)
+}
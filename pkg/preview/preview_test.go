@@ -0,0 +1,119 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectKind(t *testing.T) {
+	tests := []struct {
+		key         string
+		contentType string
+		want        Kind
+	}{
+		{"photos/cat.JPG", "", KindImage},
+		{"docs/report.pdf", "", KindPDF},
+		{"data/config.json", "", KindJSON},
+		{"data/export.csv", "", KindCSV},
+		{"data/export.tsv", "", KindCSV},
+		{"logs/app.log", "", KindText},
+		{"src/main.go", "", KindText},
+		{"build/Dockerfile", "", KindText},
+		{"blob", "image/png", KindImage},
+		{"blob", "application/vnd.api+json", KindJSON},
+		{"blob", "text/plain; charset=utf-8", KindText},
+		{"archive.zip", "application/zip", KindUnsupported},
+		{"blob", "", KindUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+" "+tt.contentType, func(t *testing.T) {
+			if got := DetectKind(tt.key, tt.contentType); got != tt.want {
+				t.Errorf("DetectKind(%q, %q) = %s, want %s", tt.key, tt.contentType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimToValidUTF8(t *testing.T) {
+	data := []byte("héllo €")
+	// Cut in the middle of the 3-byte euro sign
+	cut := data[:len(data)-1]
+	if got := string(TrimToValidUTF8(cut)); got != "héllo " {
+		t.Errorf("Expected partial rune to be dropped, got %q", got)
+	}
+	if got := string(TrimToValidUTF8(data)); got != "héllo €" {
+		t.Errorf("Expected valid input unchanged, got %q", got)
+	}
+}
+
+func TestParseJSONTree_KeepsKeyOrder(t *testing.T) {
+	tree, err := ParseJSONTree([]byte(`{"zeta": 1, "alpha": [true, null, "x"], "mid": {"n": 1.50}}`))
+	if err != nil {
+		t.Fatalf("ParseJSONTree() error = %v", err)
+	}
+
+	if tree.Kind != JSONObject || len(tree.Children) != 3 {
+		t.Fatalf("Expected object with 3 children, got %s with %d", tree.Kind, len(tree.Children))
+	}
+	keys := []string{tree.Children[0].Key, tree.Children[1].Key, tree.Children[2].Key}
+	if strings.Join(keys, ",") != "zeta,alpha,mid" {
+		t.Errorf("Expected document key order, got %v", keys)
+	}
+
+	arr := tree.Children[1]
+	if arr.Summary() != "[3 items]" || arr.Children[1].Kind != JSONNull || arr.Children[2].Value != `"x"` {
+		t.Errorf("Unexpected array node: %+v", arr)
+	}
+	if num := tree.Children[2].Children[0]; num.Value != "1.50" {
+		t.Errorf("Expected number literal preserved, got %q", num.Value)
+	}
+}
+
+func TestParseJSONTree_Invalid(t *testing.T) {
+	for _, input := range []string{`{"a": 1`, `{"a": 1} {"b": 2}`, `[` + strings.Repeat("[", maxJSONDepth+1)} {
+		if _, err := ParseJSONTree([]byte(input)); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestReadCSVPage(t *testing.T) {
+	chunk := []byte("id,name\n1,alice\n2,\"bob, jr\"\n3,car")
+
+	page := ReadCSVPage(chunk, ',', 10, true, true)
+	if strings.Join(page.Header, "|") != "id|name" {
+		t.Errorf("Unexpected header %v", page.Header)
+	}
+	if len(page.Rows) != 2 {
+		t.Fatalf("Expected the partial last row to be skipped, got %d rows", len(page.Rows))
+	}
+	if page.Rows[1][1] != "bob, jr" {
+		t.Errorf("Expected quoted field, got %q", page.Rows[1][1])
+	}
+	if next := string(chunk[page.Consumed:]); next != "3,car" {
+		t.Errorf("Expected next page to start at the partial row, got %q", next)
+	}
+
+	// The same chunk at the end of the file keeps its last row
+	page = ReadCSVPage(chunk, ',', 10, true, false)
+	if len(page.Rows) != 3 {
+		t.Errorf("Expected 3 rows for a complete file, got %d", len(page.Rows))
+	}
+
+	// Row limit
+	page = ReadCSVPage(chunk, ',', 1, true, false)
+	if len(page.Rows) != 1 || string(chunk[page.Consumed:]) != "2,\"bob, jr\"\n3,car" {
+		t.Errorf("Unexpected page for row limit 1: %d rows, consumed %d", len(page.Rows), page.Consumed)
+	}
+}
+
+func TestHighlight_EscapesHTML(t *testing.T) {
+	html, err := Highlight("page.txt", "<script>alert(1)</script>")
+	if err != nil {
+		t.Fatalf("Highlight() error = %v", err)
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("Expected source to be HTML-escaped")
+	}
+}
@@ -0,0 +1,35 @@
+package preview
+
+// Result is everything the preview page needs to render one object.
+type Result struct {
+	Key         string
+	Name        string
+	Folder      string
+	Kind        Kind
+	Size        int64
+	ContentType string
+
+	// Notice explains why no content is shown (archived, too large, binary...), or is empty
+	Notice string
+	// Truncated is true when only the first BytesShown bytes of the object are rendered
+	Truncated  bool
+	BytesShown int64
+
+	// HighlightedHTML is the chroma-rendered source for text previews (and JSON that cannot be parsed)
+	HighlightedHTML string
+	// JSON is the parsed document for complete JSON files
+	JSON *JSONNode
+
+	// CSV page state; offsets are byte positions in the object
+	CSVHeader     []string
+	CSVRows       [][]string
+	CSVOffset     int64
+	CSVNextOffset int64
+	CSVHasNext    bool
+	CSVFirstRow   int64 // 1-based number of the first row on this page
+}
+
+// HasContent reports whether there is an inline body to render.
+func (r Result) HasContent() bool {
+	return r.Notice == "" && r.Kind != KindUnsupported
+}
@@ -0,0 +1,425 @@
+package s3svc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// Sections of a bucket configuration, in the order they are shown.
+const (
+	BucketSectionVersioning        = "Versioning"
+	BucketSectionLifecycle         = "Lifecycle rules"
+	BucketSectionReplication       = "Replication"
+	BucketSectionEncryption        = "Default encryption"
+	BucketSectionObjectLock        = "Object lock"
+	BucketSectionCORS              = "CORS"
+	BucketSectionPolicy            = "Bucket policy"
+	BucketSectionPublicAccessBlock = "Public access block"
+	BucketSectionTagging           = "Tags"
+	BucketSectionNotifications     = "Event notifications"
+)
+
+// notConfiguredCodes are the error codes S3 answers for a bucket without the requested configuration.
+var notConfiguredCodes = []string{
+	"NoSuchLifecycleConfiguration",
+	"ReplicationConfigurationNotFoundError",
+	"ServerSideEncryptionConfigurationNotFoundError",
+	"ObjectLockConfigurationNotFoundError",
+	"NoSuchCORSConfiguration",
+	"NoSuchBucketPolicy",
+	"NoSuchPublicAccessBlockConfiguration",
+	"NoSuchTagSet",
+	"NoSuchTagSetError",
+}
+
+// bucketConfigFetcher reads one section of a bucket configuration. It returns no properties and no
+// document when the section is not configured.
+type bucketConfigFetcher func(ctx context.Context, bucket *string) ([]dto.BucketConfigProperty, string, error)
+
+// GetBucketConfig reads the configuration of the bucket with the Get*Bucket* S3 calls. Each section
+// records whether it is set, and why it could not be read: S3-compatible servers such as MinIO
+// answer NotImplemented for the features they lack.
+func (s *Service) GetBucketConfig(ctx context.Context) dto.BucketConfig {
+	fetchers := []struct {
+		name  string
+		fetch bucketConfigFetcher
+	}{
+		{BucketSectionVersioning, s.versioningConfig},
+		{BucketSectionLifecycle, s.lifecycleConfig},
+		{BucketSectionReplication, s.replicationConfig},
+		{BucketSectionEncryption, s.encryptionConfig},
+		{BucketSectionObjectLock, s.objectLockConfig},
+		{BucketSectionCORS, s.corsConfig},
+		{BucketSectionPolicy, s.policyConfig},
+		{BucketSectionPublicAccessBlock, s.publicAccessBlockConfig},
+		{BucketSectionTagging, s.taggingConfig},
+		{BucketSectionNotifications, s.notificationConfig},
+	}
+
+	config := dto.BucketConfig{Bucket: s.cfg.S3.Bucket, FetchedAt: time.Now()}
+	bucket := aws.String(s.cfg.S3.Bucket)
+	for _, f := range fetchers {
+		section := dto.BucketConfigSection{Name: f.name, Status: dto.BucketConfigSet}
+		props, document, err := f.fetch(ctx, bucket)
+		switch {
+		case err != nil:
+			section.Status = bucketConfigErrorStatus(err)
+			if section.Status == dto.BucketConfigFailed {
+				section.Error = err.Error()
+			}
+		case len(props) == 0 && document == "":
+			section.Status = dto.BucketConfigNotSet
+		default:
+			section.Properties, section.Document = props, document
+		}
+		config.Sections = append(config.Sections, section)
+	}
+	return config
+}
+
+// bucketConfigErrorStatus returns the section status matching the error of a Get*Bucket* call.
+func bucketConfigErrorStatus(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch code := apiErr.ErrorCode(); {
+		case slices.Contains(notConfiguredCodes, code):
+			return dto.BucketConfigNotSet
+		case code == "NotImplemented" || code == "XNotImplemented":
+			return dto.BucketConfigNotSupported
+		case code == "AccessDenied":
+			return dto.BucketConfigDenied
+		}
+	}
+	var httpErr *smithyhttp.ResponseError
+	if errors.As(err, &httpErr) {
+		switch httpErr.HTTPStatusCode() {
+		case http.StatusNotImplemented:
+			return dto.BucketConfigNotSupported
+		case http.StatusForbidden:
+			return dto.BucketConfigDenied
+		}
+	}
+	return dto.BucketConfigFailed
+}
+
+func (s *Service) versioningConfig(ctx context.Context, bucket *string) ([]dto.BucketConfigProperty, string, error) {
+	out, err := s.awsS3Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{Bucket: bucket})
+	if err != nil {
+		return nil, "", fmt.Errorf("GetBucketVersioning: %w", err)
+	}
+	// Buckets where versioning was never enabled have no status
+	if out.Status == "" {
+		return nil, "", nil
+	}
+	props := []dto.BucketConfigProperty{{Label: "Status", Value: string(out.Status)}}
+	if out.MFADelete != "" {
+		props = append(props, dto.BucketConfigProperty{Label: "MFA delete", Value: string(out.MFADelete)})
+	}
+	return props, "", nil
+}
+
+func (s *Service) lifecycleConfig(ctx context.Context, bucket *string) ([]dto.BucketConfigProperty, string, error) {
+	out, err := s.awsS3Client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: bucket})
+	if err != nil {
+		return nil, "", fmt.Errorf("GetBucketLifecycleConfiguration: %w", err)
+	}
+	props := make([]dto.BucketConfigProperty, 0, len(out.Rules))
+	for i, rule := range out.Rules {
+		props = append(props, dto.BucketConfigProperty{
+			Label: ruleLabel(rule.ID, i),
+			Value: describeLifecycleRule(rule),
+		})
+	}
+	return props, "", nil
+}
+
+// describeLifecycleRule summarizes the filter and actions of a lifecycle rule on one line.
+func describeLifecycleRule(rule types.LifecycleRule) string {
+	parts := []string{string(rule.Status)}
+	if filter := describeLifecycleFilter(rule); filter != "" {
+		parts = append(parts, filter)
+	}
+	for _, t := range rule.Transitions {
+		parts = append(parts, fmt.Sprintf("to %s %s", t.StorageClass, describeDaysOrDate(t.Days, t.Date)))
+	}
+	if e := rule.Expiration; e != nil {
+		if e.Days != nil || e.Date != nil {
+			parts = append(parts, "expire "+describeDaysOrDate(e.Days, e.Date))
+		}
+		if aws.ToBool(e.ExpiredObjectDeleteMarker) {
+			parts = append(parts, "remove expired delete markers")
+		}
+	}
+	for _, t := range rule.NoncurrentVersionTransitions {
+		parts = append(parts, fmt.Sprintf("noncurrent versions to %s after %d days", t.StorageClass, aws.ToInt32(t.NoncurrentDays)))
+	}
+	if e := rule.NoncurrentVersionExpiration; e != nil {
+		expire := fmt.Sprintf("expire noncurrent versions after %d days", aws.ToInt32(e.NoncurrentDays))
+		if n := aws.ToInt32(e.NewerNoncurrentVersions); n > 0 {
+			expire += fmt.Sprintf(", keeping %d", n)
+		}
+		parts = append(parts, expire)
+	}
+	if a := rule.AbortIncompleteMultipartUpload; a != nil {
+		parts = append(parts, fmt.Sprintf("abort incomplete uploads after %d days", aws.ToInt32(a.DaysAfterInitiation)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// describeLifecycleFilter describes the objects a lifecycle rule applies to, "" for the whole bucket.
+func describeLifecycleFilter(rule types.LifecycleRule) string {
+	var conditions []string
+	prefix := aws.ToString(rule.Prefix) //nolint:staticcheck // still returned for rules created without a filter
+	var tags []types.Tag
+	var larger, smaller *int64
+	if f := rule.Filter; f != nil {
+		prefix = firstNonEmpty(aws.ToString(f.Prefix), prefix)
+		if f.Tag != nil {
+			tags = append(tags, *f.Tag)
+		}
+		larger, smaller = f.ObjectSizeGreaterThan, f.ObjectSizeLessThan
+		if and := f.And; and != nil {
+			prefix = firstNonEmpty(aws.ToString(and.Prefix), prefix)
+			tags = append(tags, and.Tags...)
+			larger, smaller = firstNonNil(and.ObjectSizeGreaterThan, larger), firstNonNil(and.ObjectSizeLessThan, smaller)
+		}
+	}
+	if prefix != "" {
+		conditions = append(conditions, "prefix "+prefix)
+	}
+	for _, t := range tags {
+		conditions = append(conditions, fmt.Sprintf("tag %s=%s", aws.ToString(t.Key), aws.ToString(t.Value)))
+	}
+	if larger != nil {
+		conditions = append(conditions, fmt.Sprintf("larger than %d bytes", *larger))
+	}
+	if smaller != nil {
+		conditions = append(conditions, fmt.Sprintf("smaller than %d bytes", *smaller))
+	}
+	return strings.Join(conditions, ", ")
+}
+
+// describeDaysOrDate describes when a lifecycle action applies.
+func describeDaysOrDate(days *int32, date *time.Time) string {
+	if date != nil {
+		return "on " + date.Format(time.DateOnly)
+	}
+	return fmt.Sprintf("after %d days", aws.ToInt32(days))
+}
+
+func (s *Service) replicationConfig(ctx context.Context, bucket *string) ([]dto.BucketConfigProperty, string, error) {
+	out, err := s.awsS3Client.GetBucketReplication(ctx, &s3.GetBucketReplicationInput{Bucket: bucket})
+	if err != nil {
+		return nil, "", fmt.Errorf("GetBucketReplication: %w", err)
+	}
+	if out.ReplicationConfiguration == nil {
+		return nil, "", nil
+	}
+	props := []dto.BucketConfigProperty{{Label: "Role", Value: aws.ToString(out.ReplicationConfiguration.Role)}}
+	for i, rule := range out.ReplicationConfiguration.Rules {
+		parts := []string{string(rule.Status)}
+		prefix := aws.ToString(rule.Prefix) //nolint:staticcheck // still returned for rules created without a filter
+		if rule.Filter != nil {
+			prefix = firstNonEmpty(aws.ToString(rule.Filter.Prefix), prefix)
+		}
+		if prefix != "" {
+			parts = append(parts, "prefix "+prefix)
+		}
+		if d := rule.Destination; d != nil {
+			destination := "to " + aws.ToString(d.Bucket)
+			if d.StorageClass != "" {
+				destination += " in " + string(d.StorageClass)
+			}
+			parts = append(parts, destination)
+		}
+		if m := rule.DeleteMarkerReplication; m != nil && m.Status != "" {
+			parts = append(parts, "delete markers "+strings.ToLower(string(m.Status)))
+		}
+		props = append(props, dto.BucketConfigProperty{Label: ruleLabel(rule.ID, i), Value: strings.Join(parts, "; ")})
+	}
+	return props, "", nil
+}
+
+func (s *Service) encryptionConfig(ctx context.Context, bucket *string) ([]dto.BucketConfigProperty, string, error) {
+	out, err := s.awsS3Client.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{Bucket: bucket})
+	if err != nil {
+		return nil, "", fmt.Errorf("GetBucketEncryption: %w", err)
+	}
+	if out.ServerSideEncryptionConfiguration == nil {
+		return nil, "", nil
+	}
+	var props []dto.BucketConfigProperty
+	for _, rule := range out.ServerSideEncryptionConfiguration.Rules {
+		if d := rule.ApplyServerSideEncryptionByDefault; d != nil {
+			props = append(props, dto.BucketConfigProperty{Label: "Algorithm", Value: string(d.SSEAlgorithm)})
+			if d.KMSMasterKeyID != nil {
+				props = append(props, dto.BucketConfigProperty{Label: "KMS key", Value: aws.ToString(d.KMSMasterKeyID)})
+			}
+		}
+		if aws.ToBool(rule.BucketKeyEnabled) {
+			props = append(props, dto.BucketConfigProperty{Label: "Bucket key", Value: "enabled"})
+		}
+	}
+	return props, "", nil
+}
+
+func (s *Service) objectLockConfig(ctx context.Context, bucket *string) ([]dto.BucketConfigProperty, string, error) {
+	out, err := s.awsS3Client.GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{Bucket: bucket})
+	if err != nil {
+		return nil, "", fmt.Errorf("GetObjectLockConfiguration: %w", err)
+	}
+	lock := out.ObjectLockConfiguration
+	if lock == nil || lock.ObjectLockEnabled == "" {
+		return nil, "", nil
+	}
+	props := []dto.BucketConfigProperty{{Label: "Status", Value: string(lock.ObjectLockEnabled)}}
+	if lock.Rule != nil && lock.Rule.DefaultRetention != nil {
+		r := lock.Rule.DefaultRetention
+		period := fmt.Sprintf("%d days", aws.ToInt32(r.Days))
+		if r.Years != nil {
+			period = fmt.Sprintf("%d years", aws.ToInt32(r.Years))
+		}
+		props = append(props, dto.BucketConfigProperty{Label: "Default retention", Value: fmt.Sprintf("%s for %s", r.Mode, period)})
+	}
+	return props, "", nil
+}
+
+func (s *Service) corsConfig(ctx context.Context, bucket *string) ([]dto.BucketConfigProperty, string, error) {
+	out, err := s.awsS3Client.GetBucketCors(ctx, &s3.GetBucketCorsInput{Bucket: bucket})
+	if err != nil {
+		return nil, "", fmt.Errorf("GetBucketCors: %w", err)
+	}
+	props := make([]dto.BucketConfigProperty, 0, len(out.CORSRules))
+	for i, rule := range out.CORSRules {
+		value := fmt.Sprintf("%s from %s", strings.Join(rule.AllowedMethods, ", "), strings.Join(rule.AllowedOrigins, ", "))
+		if len(rule.AllowedHeaders) > 0 {
+			value += "; headers " + strings.Join(rule.AllowedHeaders, ", ")
+		}
+		if len(rule.ExposeHeaders) > 0 {
+			value += "; exposes " + strings.Join(rule.ExposeHeaders, ", ")
+		}
+		if rule.MaxAgeSeconds != nil {
+			value += fmt.Sprintf("; max age %ds", aws.ToInt32(rule.MaxAgeSeconds))
+		}
+		props = append(props, dto.BucketConfigProperty{Label: ruleLabel(rule.ID, i), Value: value})
+	}
+	return props, "", nil
+}
+
+func (s *Service) policyConfig(ctx context.Context, bucket *string) ([]dto.BucketConfigProperty, string, error) {
+	out, err := s.awsS3Client.GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{Bucket: bucket})
+	if err != nil {
+		return nil, "", fmt.Errorf("GetBucketPolicy: %w", err)
+	}
+	policy := aws.ToString(out.Policy)
+	var indented bytes.Buffer
+	if json.Indent(&indented, []byte(policy), "", "  ") == nil {
+		policy = indented.String()
+	}
+	return nil, policy, nil
+}
+
+func (s *Service) publicAccessBlockConfig(ctx context.Context, bucket *string) ([]dto.BucketConfigProperty, string, error) {
+	out, err := s.awsS3Client.GetPublicAccessBlock(ctx, &s3.GetPublicAccessBlockInput{Bucket: bucket})
+	if err != nil {
+		return nil, "", fmt.Errorf("GetPublicAccessBlock: %w", err)
+	}
+	block := out.PublicAccessBlockConfiguration
+	if block == nil {
+		return nil, "", nil
+	}
+	return []dto.BucketConfigProperty{
+		{Label: "Block public ACLs", Value: fmt.Sprint(aws.ToBool(block.BlockPublicAcls))},
+		{Label: "Ignore public ACLs", Value: fmt.Sprint(aws.ToBool(block.IgnorePublicAcls))},
+		{Label: "Block public policy", Value: fmt.Sprint(aws.ToBool(block.BlockPublicPolicy))},
+		{Label: "Restrict public buckets", Value: fmt.Sprint(aws.ToBool(block.RestrictPublicBuckets))},
+	}, "", nil
+}
+
+func (s *Service) taggingConfig(ctx context.Context, bucket *string) ([]dto.BucketConfigProperty, string, error) {
+	out, err := s.awsS3Client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: bucket})
+	if err != nil {
+		return nil, "", fmt.Errorf("GetBucketTagging: %w", err)
+	}
+	props := make([]dto.BucketConfigProperty, 0, len(out.TagSet))
+	for _, t := range out.TagSet {
+		props = append(props, dto.BucketConfigProperty{Label: aws.ToString(t.Key), Value: aws.ToString(t.Value)})
+	}
+	return props, "", nil
+}
+
+func (s *Service) notificationConfig(ctx context.Context, bucket *string) ([]dto.BucketConfigProperty, string, error) {
+	out, err := s.awsS3Client.GetBucketNotificationConfiguration(ctx, &s3.GetBucketNotificationConfigurationInput{Bucket: bucket})
+	if err != nil {
+		return nil, "", fmt.Errorf("GetBucketNotificationConfiguration: %w", err)
+	}
+	var props []dto.BucketConfigProperty
+	add := func(kind, id, arn string, events []types.Event, filter *types.NotificationConfigurationFilter) {
+		names := make([]string, 0, len(events))
+		for _, e := range events {
+			names = append(names, string(e))
+		}
+		value := fmt.Sprintf("%s %s on %s", kind, arn, strings.Join(names, ", "))
+		if filter != nil && filter.Key != nil {
+			for _, rule := range filter.Key.FilterRules {
+				value += fmt.Sprintf("; %s %s", strings.ToLower(string(rule.Name)), aws.ToString(rule.Value))
+			}
+		}
+		props = append(props, dto.BucketConfigProperty{Label: ruleLabel(&id, len(props)), Value: value})
+	}
+	for _, c := range out.TopicConfigurations {
+		add("SNS topic", aws.ToString(c.Id), aws.ToString(c.TopicArn), c.Events, c.Filter)
+	}
+	for _, c := range out.QueueConfigurations {
+		add("SQS queue", aws.ToString(c.Id), aws.ToString(c.QueueArn), c.Events, c.Filter)
+	}
+	for _, c := range out.LambdaFunctionConfigurations {
+		add("Lambda function", aws.ToString(c.Id), aws.ToString(c.LambdaFunctionArn), c.Events, c.Filter)
+	}
+	if out.EventBridgeConfiguration != nil {
+		props = append(props, dto.BucketConfigProperty{Label: "EventBridge", Value: "enabled"})
+	}
+	return props, "", nil
+}
+
+// ruleLabel returns the ID of a rule, or its position when it has none.
+func ruleLabel(id *string, index int) string {
+	if aws.ToString(id) != "" {
+		return aws.ToString(id)
+	}
+	return fmt.Sprintf("Rule %d", index+1)
+}
+
+// firstNonEmpty returns the first non-empty string.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// firstNonNil returns the first non-nil pointer.
+func firstNonNil(values ...*int64) *int64 {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
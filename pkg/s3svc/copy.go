@@ -0,0 +1,303 @@
+package s3svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+const (
+	// MaxCopyObjectSize is the largest object a single CopyObject call can copy.
+	// Bigger objects are copied part by part with UploadPartCopy.
+	MaxCopyObjectSize = 5 * 1024 * 1024 * 1024
+	// copyPartSize is the part size of multipart copies (S3 allows up to 5 GB per part).
+	copyPartSize = 512 * 1024 * 1024
+)
+
+// ErrIncompleteCopy is returned when a streamed copy read fewer bytes than the source size.
+var ErrIncompleteCopy = errors.New("copy incomplete")
+
+// ListAllObjects returns every object whose key starts with prefix, descending into sub-folders.
+func (s *Service) ListAllObjects(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	result := []ObjectInfo{}
+	err := s.WalkObjects(ctx, prefix, func(page []ObjectInfo) error {
+		result = append(result, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// WalkObjects lists the objects whose key starts with prefix, descending into sub-folders, and
+// calls fn with each page of at most 1000 of them as it is listed. Listing resumes after the last
+// key of a page, so fn may delete the objects it is given. An error of fn stops the walk.
+func (s *Service) WalkObjects(ctx context.Context, prefix string, fn func(page []ObjectInfo) error) error {
+	paginator := s3.NewListObjectsV2Paginator(s.awsS3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.cfg.S3.Bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("WalkObjects: error of paginator.NextPage: %w", err)
+		}
+		objects := make([]ObjectInfo, 0, len(page.Contents))
+		for _, obj := range page.Contents {
+			objects = append(objects, ObjectInfo{
+				Key:          aws.ToString(obj.Key),
+				Size:         aws.ToInt64(obj.Size),
+				ETag:         aws.ToString(obj.ETag),
+				LastModified: aws.ToTime(obj.LastModified),
+				StorageClass: string(obj.StorageClass),
+			})
+		}
+		if len(objects) == 0 {
+			continue
+		}
+		if err := fn(objects); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObjectExists reports whether key exists. For a "/"-terminated key it reports whether
+// anything at all is stored under that folder, marker or not.
+func (s *Service) ObjectExists(ctx context.Context, key string) (bool, error) {
+	out, err := s.awsS3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.cfg.S3.Bucket),
+		Prefix:  aws.String(key),
+		MaxKeys: aws.Int32(1),
+	})
+	if err != nil {
+		return false, fmt.Errorf("ObjectExists: error when called ListObjectsV2: %w", err)
+	}
+	if len(out.Contents) == 0 {
+		return false, nil
+	}
+	// Keys are listed in lexical order, so an existing key is the first one starting with itself
+	return strings.HasSuffix(key, "/") || aws.ToString(out.Contents[0].Key) == key, nil
+}
+
+// CopyOptions controls what a copy keeps from the source object.
+type CopyOptions struct {
+	// PreserveMetadata keeps the user metadata; the content type is always kept
+	PreserveMetadata bool
+	// PreserveStorageClass keeps the storage class instead of the bucket default
+	PreserveStorageClass bool
+}
+
+// CopyObject copies srcKey to dstKey inside the bucket, keeping its metadata.
+// size is the source object size; objects over MaxCopyObjectSize use a multipart copy.
+func (s *Service) CopyObject(ctx context.Context, srcKey, dstKey string, size int64) error {
+	src := ObjectInfo{Key: srcKey, Size: size}
+	return s.CopyObjectFrom(ctx, s.cfg.S3.Bucket, src, dstKey, CopyOptions{PreserveMetadata: true, PreserveStorageClass: true})
+}
+
+// CopyObjectFrom copies src from srcBucket to dstKey in the service bucket with a server-side copy.
+// The service client must be allowed to read srcBucket. The storage class is only kept when
+// src.StorageClass is known; objects over MaxCopyObjectSize use a multipart copy.
+// When src.VersionID is set, that version is copied rather than the current one.
+func (s *Service) CopyObjectFrom(
+	ctx context.Context, srcBucket string, src ObjectInfo, dstKey string, opts CopyOptions,
+) error {
+	if src.Size > MaxCopyObjectSize {
+		return s.multipartCopy(ctx, srcBucket, src, dstKey, opts)
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(s.cfg.S3.Bucket),
+		Key:        aws.String(dstKey),
+		CopySource: aws.String(copySource(srcBucket, src.Key, src.VersionID)),
+	}
+	if !opts.PreserveMetadata {
+		// REPLACE drops the user metadata along with the content type, which has to be set again
+		contentType := src.ContentType
+		if contentType == "" {
+			head, err := s.awsS3Client.HeadObject(ctx, &s3.HeadObjectInput{
+				Bucket:    aws.String(srcBucket),
+				Key:       aws.String(src.Key),
+				VersionId: optionalString(src.VersionID),
+			})
+			if err != nil {
+				return fmt.Errorf("CopyObjectFrom: error when called HeadObject: %w", err)
+			}
+			contentType = aws.ToString(head.ContentType)
+		}
+		input.MetadataDirective = types.MetadataDirectiveReplace
+		input.ContentType = aws.String(contentType)
+	}
+	if opts.PreserveStorageClass && src.StorageClass != "" {
+		input.StorageClass = types.StorageClass(src.StorageClass)
+	}
+
+	if _, err := s.awsS3Client.CopyObject(ctx, input); err != nil {
+		return fmt.Errorf("CopyObjectFrom: error copying %s: %w", src.Key, err)
+	}
+
+	s.log.Debug("CopyObjectFrom completed",
+		slog.String("srcBucket", srcBucket),
+		slog.String("src", src.Key),
+		slog.String("dst", dstKey))
+	return nil
+}
+
+// StreamCopyFrom copies src from the bucket of the src service to dstKey in the service bucket,
+// reading it with GetObject and writing it with a multipart upload. It is used when both
+// buckets are not reachable by the same client, so a server-side copy is not possible.
+func (s *Service) StreamCopyFrom(
+	ctx context.Context, src *Service, srcKey, dstKey string, opts CopyOptions, partSize int64, concurrency int,
+) (*StreamUploadResult, error) {
+	head, err := src.awsS3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(src.cfg.S3.Bucket),
+		Key:    aws.String(srcKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("StreamCopyFrom: error when called HeadObject: %w", err)
+	}
+	body, err := src.OpenObject(ctx, srcKey)
+	if err != nil {
+		return nil, fmt.Errorf("StreamCopyFrom: %w", err)
+	}
+	defer func() { _ = body.Close() }()
+
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.cfg.S3.Bucket),
+		Key:         aws.String(dstKey),
+		ContentType: head.ContentType,
+	}
+	if opts.PreserveMetadata {
+		input.Metadata = head.Metadata
+	}
+	if opts.PreserveStorageClass {
+		input.StorageClass = head.StorageClass
+	}
+
+	result, err := s.streamUpload(ctx, input, body, partSize, concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("StreamCopyFrom: error copying %s: %w", srcKey, err)
+	}
+	if result.Size != aws.ToInt64(head.ContentLength) {
+		return nil, fmt.Errorf("%w: %s: read %d of %d bytes",
+			ErrIncompleteCopy, srcKey, result.Size, aws.ToInt64(head.ContentLength))
+	}
+
+	s.log.Debug("StreamCopyFrom completed",
+		slog.String("srcBucket", src.cfg.S3.Bucket),
+		slog.String("src", srcKey),
+		slog.String("dst", dstKey),
+		slog.Int64("size", result.Size))
+	return result, nil
+}
+
+// MoveObject copies srcKey to dstKey, then deletes srcKey.
+// The source is left untouched if the copy fails.
+func (s *Service) MoveObject(ctx context.Context, srcKey, dstKey string, size int64) error {
+	if err := s.CopyObject(ctx, srcKey, dstKey, size); err != nil {
+		return err
+	}
+	return s.DeleteObject(ctx, srcKey)
+}
+
+// multipartCopy copies an object of any size with UploadPartCopy.
+// The multipart upload is aborted if a part fails.
+func (s *Service) multipartCopy(
+	ctx context.Context, srcBucket string, src ObjectInfo, dstKey string, opts CopyOptions,
+) error {
+	head, err := s.awsS3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(srcBucket),
+		Key:       aws.String(src.Key),
+		VersionId: optionalString(src.VersionID),
+	})
+	if err != nil {
+		return fmt.Errorf("multipartCopy: error when called HeadObject: %w", err)
+	}
+
+	input := &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.cfg.S3.Bucket),
+		Key:         aws.String(dstKey),
+		ContentType: head.ContentType,
+	}
+	if opts.PreserveMetadata {
+		input.Metadata = head.Metadata
+	}
+	if opts.PreserveStorageClass {
+		input.StorageClass = head.StorageClass
+	}
+	created, err := s.awsS3Client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return fmt.Errorf("multipartCopy: %w", err)
+	}
+	uploadID := aws.ToString(created.UploadId)
+
+	parts, err := s.copyParts(ctx, copySource(srcBucket, src.Key, src.VersionID), dstKey, uploadID, src.Size)
+	if err == nil {
+		_, err = s.CompleteMultipartUpload(ctx, dstKey, uploadID, parts)
+	}
+	if err != nil {
+		if abortErr := s.AbortMultipartUpload(ctx, dstKey, uploadID); abortErr != nil {
+			s.log.Error("multipartCopy: failed to abort upload",
+				slog.String("key", dstKey),
+				slog.String("error", abortErr.Error()))
+		}
+		return fmt.Errorf("multipartCopy: error copying %s: %w", src.Key, err)
+	}
+
+	s.log.Debug("multipartCopy completed",
+		slog.String("src", src.Key),
+		slog.String("dst", dstKey),
+		slog.Int("parts", len(parts)))
+	return nil
+}
+
+// copyParts copies the byte ranges of source, an x-amz-copy-source value, into the parts of a multipart upload.
+func (s *Service) copyParts(ctx context.Context, source, dstKey, uploadID string, size int64) ([]dto.UploadPart, error) {
+	partSize := max(int64(copyPartSize), (size+MaxUploadParts-1)/MaxUploadParts)
+	parts := []dto.UploadPart{}
+
+	for number, offset := int32(1), int64(0); offset < size; number, offset = number+1, offset+partSize {
+		end := min(offset+partSize, size) - 1
+		out, err := s.awsS3Client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:          aws.String(s.cfg.S3.Bucket),
+			Key:             aws.String(dstKey),
+			UploadId:        aws.String(uploadID),
+			PartNumber:      aws.Int32(number),
+			CopySource:      aws.String(source),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", offset, end)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("UploadPartCopy %d: %w", number, err)
+		}
+		etag := ""
+		if out.CopyPartResult != nil {
+			etag = aws.ToString(out.CopyPartResult.ETag)
+		}
+		parts = append(parts, dto.UploadPart{Number: number, ETag: etag, Size: end - offset + 1})
+	}
+	return parts, nil
+}
+
+// copySource builds the URL-encoded "bucket/key" value of the x-amz-copy-source header,
+// followed by "?versionId=" when a version is given.
+func copySource(bucket, key, versionID string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		// PathEscape keeps '+', which S3 would decode as a space
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+	}
+	source := bucket + "/" + strings.Join(segments, "/")
+	if versionID != "" {
+		source += "?versionId=" + url.QueryEscape(versionID)
+	}
+	return source
+}
@@ -95,21 +95,55 @@ func addContentMD5Middleware(contentMD5 string) func(*s3.Options) {
 	}
 }
 
+// MaxDeleteBatch is the largest number of keys a DeleteObjects request accepts.
+const MaxDeleteBatch = 1000
+
+// DeleteFailure is a key S3 reported as not deleted by a DeleteObjects request.
+type DeleteFailure struct {
+	Key     string
+	Code    string
+	Message string
+}
+
 // DeleteObjects deletes multiple objects from S3 in a single batch operation.
 // S3 supports up to 1000 objects per batch request.
 // Parameters:
 //   - ctx: Context for the request
 //   - keys: Slice of S3 object keys to delete
 func (s *Service) DeleteObjects(ctx context.Context, keys []string) error {
+	failures, err := s.DeleteObjectBatch(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	// Check for partial failures
+	if len(failures) > 0 {
+		s.log.Warn("DeleteObjects: some objects failed to delete",
+			slog.Int("failed", len(failures)),
+			slog.Int("total", len(keys)))
+		for _, failure := range failures {
+			s.log.Error("Failed to delete object",
+				slog.String("key", failure.Key),
+				slog.String("code", failure.Code),
+				slog.String("message", failure.Message))
+		}
+		//nolint:err113 // Dynamic error provides useful context about partial deletion failures
+		return fmt.Errorf("DeleteObjects: %d of %d objects failed to delete", len(failures), len(keys))
+	}
+
+	return nil
+}
+
+// DeleteObjectBatch deletes up to MaxDeleteBatch keys with one DeleteObjects request and
+// returns the keys S3 could not delete. An error means the request itself failed.
+func (s *Service) DeleteObjectBatch(ctx context.Context, keys []string) ([]DeleteFailure, error) {
 	if len(keys) == 0 {
-		return nil // Nothing to delete
+		return nil, nil // Nothing to delete
 	}
 
-	// AWS S3 DeleteObjects API has a limit of 1000 objects per request
-	const maxBatchSize = 1000
-	if len(keys) > maxBatchSize {
+	if len(keys) > MaxDeleteBatch {
 		//nolint:err113 // Dynamic error provides useful context about batch size violation
-		return fmt.Errorf("DeleteObjects: too many keys (%d), maximum is %d", len(keys), maxBatchSize)
+		return nil, fmt.Errorf("DeleteObjects: too many keys (%d), maximum is %d", len(keys), MaxDeleteBatch)
 	}
 
 	// Convert string keys to ObjectIdentifier structs
@@ -133,33 +167,28 @@ func (s *Service) DeleteObjects(ctx context.Context, keys []string) error {
 	// Compute Content-MD5 header for MinIO compatibility
 	contentMD5, err := computeDeleteContentMD5(objects, quiet)
 	if err != nil {
-		return fmt.Errorf("DeleteObjects: failed to compute Content-MD5: %w", err)
+		return nil, fmt.Errorf("DeleteObjects: failed to compute Content-MD5: %w", err)
 	}
 
 	// Add Content-MD5 header using middleware
 	output, err := s.awsS3Client.DeleteObjects(ctx, input, addContentMD5Middleware(contentMD5))
 	if err != nil {
-		return fmt.Errorf("DeleteObjects: error deleting from S3: %w", err)
+		return nil, fmt.Errorf("DeleteObjects: error deleting from S3: %w", err)
 	}
 
-	// Check for partial failures
-	if len(output.Errors) > 0 {
-		s.log.Warn("DeleteObjects: some objects failed to delete",
-			slog.Int("failed", len(output.Errors)),
-			slog.Int("total", len(keys)))
-		for _, deleteError := range output.Errors {
-			s.log.Error("Failed to delete object",
-				slog.String("key", *deleteError.Key),
-				slog.String("code", *deleteError.Code),
-				slog.String("message", *deleteError.Message))
-		}
-		//nolint:err113 // Dynamic error provides useful context about partial deletion failures
-		return fmt.Errorf("DeleteObjects: %d of %d objects failed to delete", len(output.Errors), len(keys))
+	failures := make([]DeleteFailure, 0, len(output.Errors))
+	for _, deleteError := range output.Errors {
+		failures = append(failures, DeleteFailure{
+			Key:     aws.ToString(deleteError.Key),
+			Code:    aws.ToString(deleteError.Code),
+			Message: aws.ToString(deleteError.Message),
+		})
 	}
 
 	s.log.Debug("DeleteObjects completed",
 		slog.Int("count", len(keys)),
-		slog.Int("deleted", len(output.Deleted)))
+		slog.Int("deleted", len(output.Deleted)),
+		slog.Int("failed", len(failures)))
 
-	return nil
+	return failures, nil
 }
@@ -0,0 +1,204 @@
+package s3svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrMetadataEditTooLarge is returned when editing the metadata of an object bigger than a single copy allows.
+var ErrMetadataEditTooLarge = errors.New("metadata of objects over 5 GB cannot be edited")
+
+// Tag is an S3 object tag.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// ObjectDetails is everything HeadObject and GetObjectTagging tell about an object.
+type ObjectDetails struct {
+	ObjectInfo
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	ContentLanguage    string
+	Expires            string
+	// Metadata is the user metadata, sent as x-amz-meta-* headers
+	Metadata map[string]string
+	// ServerSideEncryption is AES256, aws:kms or aws:kms:dsse; SSEKMSKeyID is set for KMS
+	ServerSideEncryption string
+	SSEKMSKeyID          string
+	BucketKeyEnabled     bool
+	SSECustomerAlgorithm string
+	// Checksums maps the algorithms the object was uploaded with to their value
+	Checksums    map[string]string
+	ChecksumType string
+	// ObjectLockMode is GOVERNANCE or COMPLIANCE when a retention is set
+	ObjectLockMode        string
+	ObjectLockRetainUntil time.Time
+	LegalHold             string
+	ReplicationStatus     string
+	// Restore is the raw x-amz-restore header of archived objects
+	Restore string
+	// Expiration is the lifecycle expiration header
+	Expiration string
+	Tags       []Tag
+	// TagsError is set when the tags could not be read, for instance without s3:GetObjectTagging
+	TagsError string
+}
+
+// MetadataUpdate is the new content type, cache control and user metadata of an object.
+type MetadataUpdate struct {
+	ContentType  string
+	CacheControl string
+	Metadata     map[string]string
+}
+
+// GetObjectDetails returns the full metadata of key. Failing to read the tags is not an error:
+// it is reported in TagsError.
+func (s *Service) GetObjectDetails(ctx context.Context, key string) (*ObjectDetails, error) {
+	o, err := s.awsS3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(s.cfg.S3.Bucket),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetObjectDetails: error when called HeadObject: %w", err)
+	}
+
+	d := &ObjectDetails{
+		ObjectInfo:            *s.objectInfoFromHead(key, o),
+		CacheControl:          aws.ToString(o.CacheControl),
+		ContentDisposition:    aws.ToString(o.ContentDisposition),
+		ContentEncoding:       aws.ToString(o.ContentEncoding),
+		ContentLanguage:       aws.ToString(o.ContentLanguage),
+		Expires:               aws.ToString(o.ExpiresString),
+		Metadata:              o.Metadata,
+		ServerSideEncryption:  string(o.ServerSideEncryption),
+		SSEKMSKeyID:           aws.ToString(o.SSEKMSKeyId),
+		BucketKeyEnabled:      aws.ToBool(o.BucketKeyEnabled),
+		SSECustomerAlgorithm:  aws.ToString(o.SSECustomerAlgorithm),
+		Checksums:             map[string]string{},
+		ChecksumType:          string(o.ChecksumType),
+		ObjectLockMode:        string(o.ObjectLockMode),
+		ObjectLockRetainUntil: aws.ToTime(o.ObjectLockRetainUntilDate),
+		LegalHold:             string(o.ObjectLockLegalHoldStatus),
+		ReplicationStatus:     string(o.ReplicationStatus),
+		Restore:               aws.ToString(o.Restore),
+		Expiration:            aws.ToString(o.Expiration),
+	}
+	for algorithm, value := range map[string]*string{
+		"CRC32":     o.ChecksumCRC32,
+		"CRC32C":    o.ChecksumCRC32C,
+		"CRC64NVME": o.ChecksumCRC64NVME,
+		"SHA1":      o.ChecksumSHA1,
+		"SHA256":    o.ChecksumSHA256,
+	} {
+		if value != nil {
+			d.Checksums[algorithm] = *value
+		}
+	}
+
+	d.Tags, err = s.GetObjectTags(ctx, key)
+	if err != nil {
+		s.log.Warn("GetObjectDetails: cannot read tags", slog.String("key", key), slog.String("error", err.Error()))
+		d.TagsError = err.Error()
+	}
+	return d, nil
+}
+
+// GetObjectTags returns the tags of key.
+func (s *Service) GetObjectTags(ctx context.Context, key string) ([]Tag, error) {
+	out, err := s.awsS3Client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(s.cfg.S3.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetObjectTags: %w", err)
+	}
+	tags := make([]Tag, 0, len(out.TagSet))
+	for _, t := range out.TagSet {
+		tags = append(tags, Tag{Key: aws.ToString(t.Key), Value: aws.ToString(t.Value)})
+	}
+	return tags, nil
+}
+
+// PutObjectTags replaces the tags of key; an empty list removes them all.
+func (s *Service) PutObjectTags(ctx context.Context, key string, tags []Tag) error {
+	if len(tags) == 0 {
+		if _, err := s.awsS3Client.DeleteObjectTagging(ctx, &s3.DeleteObjectTaggingInput{
+			Bucket: aws.String(s.cfg.S3.Bucket),
+			Key:    aws.String(key),
+		}); err != nil {
+			return fmt.Errorf("PutObjectTags: error when called DeleteObjectTagging: %w", err)
+		}
+		return nil
+	}
+
+	tagSet := make([]types.Tag, 0, len(tags))
+	for _, t := range tags {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(t.Key), Value: aws.String(t.Value)})
+	}
+	if _, err := s.awsS3Client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(s.cfg.S3.Bucket),
+		Key:     aws.String(key),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	}); err != nil {
+		return fmt.Errorf("PutObjectTags: error when called PutObjectTagging: %w", err)
+	}
+
+	s.log.Debug("PutObjectTags completed", slog.String("key", key), slog.Int("tags", len(tags)))
+	return nil
+}
+
+// ReplaceObjectMetadata rewrites the content type, cache control and user metadata of key by copying
+// the object onto itself. The other headers, the storage class, the encryption and the tags are kept.
+// It returns the ETag of the rewritten object.
+func (s *Service) ReplaceObjectMetadata(ctx context.Context, key string, update MetadataUpdate) (string, error) {
+	head, err := s.awsS3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.cfg.S3.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("ReplaceObjectMetadata: error when called HeadObject: %w", err)
+	}
+	if aws.ToInt64(head.ContentLength) > MaxCopyObjectSize {
+		return "", ErrMetadataEditTooLarge
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:                  aws.String(s.cfg.S3.Bucket),
+		Key:                     aws.String(key),
+		CopySource:              aws.String(copySource(s.cfg.S3.Bucket, key, "")),
+		MetadataDirective:       types.MetadataDirectiveReplace,
+		Metadata:                update.Metadata,
+		ContentType:             optionalString(update.ContentType),
+		CacheControl:            optionalString(update.CacheControl),
+		ContentDisposition:      head.ContentDisposition,
+		ContentEncoding:         head.ContentEncoding,
+		ContentLanguage:         head.ContentLanguage,
+		WebsiteRedirectLocation: head.WebsiteRedirectLocation,
+		StorageClass:            head.StorageClass,
+		// Without these the copy would fall back to the bucket default encryption
+		ServerSideEncryption: head.ServerSideEncryption,
+		SSEKMSKeyId:          head.SSEKMSKeyId,
+		BucketKeyEnabled:     head.BucketKeyEnabled,
+	}
+	out, err := s.awsS3Client.CopyObject(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("ReplaceObjectMetadata: error copying %s: %w", key, err)
+	}
+
+	etag := ""
+	if out.CopyObjectResult != nil {
+		etag = aws.ToString(out.CopyObjectResult.ETag)
+	}
+	s.log.Debug("ReplaceObjectMetadata completed", slog.String("key", key))
+	return etag, nil
+}
@@ -0,0 +1,184 @@
+package s3svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+const (
+	// maxLifecycleRuleIDLength is the longest rule ID S3 accepts.
+	maxLifecycleRuleIDLength = 255
+	// minInfrequentAccessDays is how long objects stay in STANDARD before, and in STANDARD_IA or
+	// ONEZONE_IA before the next transition, as S3 requires.
+	minInfrequentAccessDays = 30
+)
+
+// ErrInvalidLifecycleRule is returned for a lifecycle rule S3 would reject.
+var ErrInvalidLifecycleRule = errors.New("invalid lifecycle rule")
+
+// LifecycleTransitionClasses are the storage classes lifecycle rules can move objects to, from the
+// most to the least expensive.
+var LifecycleTransitionClasses = TransitionStorageClasses[1:]
+
+// GetLifecycleRules returns the lifecycle rules of the bucket, none when it has no lifecycle configuration.
+func (s *Service) GetLifecycleRules(ctx context.Context) ([]types.LifecycleRule, error) {
+	out, err := s.awsS3Client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(s.cfg.S3.Bucket),
+	})
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchLifecycleConfiguration" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetLifecycleRules: %w", err)
+	}
+	return out.Rules, nil
+}
+
+// PutLifecycleRules replaces the lifecycle configuration of the bucket with rules. Without rules the
+// lifecycle configuration is deleted, as S3 does not accept an empty one.
+func (s *Service) PutLifecycleRules(ctx context.Context, rules []types.LifecycleRule) error {
+	bucket := aws.String(s.cfg.S3.Bucket)
+	if len(rules) == 0 {
+		if _, err := s.awsS3Client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{Bucket: bucket}); err != nil {
+			return fmt.Errorf("PutLifecycleRules: error when called DeleteBucketLifecycle: %w", err)
+		}
+	} else {
+		_, err := s.awsS3Client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+			Bucket:                 bucket,
+			LifecycleConfiguration: &types.BucketLifecycleConfiguration{Rules: rules},
+		})
+		if err != nil {
+			return fmt.Errorf("PutLifecycleRules: error when called PutBucketLifecycleConfiguration: %w", err)
+		}
+	}
+	s.log.Info("Lifecycle configuration updated", slog.String("bucket", s.cfg.S3.Bucket), slog.Int("rules", len(rules)))
+	return nil
+}
+
+// LifecycleRuleFromS3 converts an S3 lifecycle rule for the lifecycle form. Rules using settings the
+// form does not support are not Editable.
+func LifecycleRuleFromS3(rule types.LifecycleRule) dto.LifecycleRule {
+	r := dto.LifecycleRule{
+		ID:       aws.ToString(rule.ID),
+		Enabled:  rule.Status == types.ExpirationStatusEnabled,
+		Prefix:   aws.ToString(rule.Prefix), //nolint:staticcheck // still returned for rules created without a filter
+		Editable: len(rule.NoncurrentVersionTransitions) == 0,
+		Summary:  describeLifecycleRule(rule),
+	}
+	if f := rule.Filter; f != nil {
+		r.Prefix = firstNonEmpty(aws.ToString(f.Prefix), r.Prefix)
+		if f.And != nil || f.Tag != nil || f.ObjectSizeGreaterThan != nil || f.ObjectSizeLessThan != nil {
+			r.Editable = false
+		}
+	}
+	for _, t := range rule.Transitions {
+		if t.Date != nil {
+			r.Editable = false
+		}
+		r.Transitions = append(r.Transitions, dto.LifecycleTransition{Days: aws.ToInt32(t.Days), StorageClass: string(t.StorageClass)})
+	}
+	if e := rule.Expiration; e != nil {
+		if e.Date != nil || aws.ToBool(e.ExpiredObjectDeleteMarker) {
+			r.Editable = false
+		}
+		r.ExpirationDays = aws.ToInt32(e.Days)
+	}
+	if e := rule.NoncurrentVersionExpiration; e != nil {
+		if aws.ToInt32(e.NewerNoncurrentVersions) > 0 {
+			r.Editable = false
+		}
+		r.NoncurrentExpirationDays = aws.ToInt32(e.NoncurrentDays)
+	}
+	if a := rule.AbortIncompleteMultipartUpload; a != nil {
+		r.AbortIncompleteUploadDays = aws.ToInt32(a.DaysAfterInitiation)
+	}
+	return r
+}
+
+// LifecycleRuleToS3 converts a rule of the lifecycle form for PutLifecycleRules.
+func LifecycleRuleToS3(rule dto.LifecycleRule) types.LifecycleRule {
+	r := types.LifecycleRule{
+		ID:     aws.String(rule.ID),
+		Status: types.ExpirationStatusDisabled,
+		Filter: &types.LifecycleRuleFilter{Prefix: aws.String(rule.Prefix)},
+	}
+	if rule.Enabled {
+		r.Status = types.ExpirationStatusEnabled
+	}
+	for _, t := range rule.Transitions {
+		r.Transitions = append(r.Transitions, types.Transition{
+			Days:         aws.Int32(t.Days),
+			StorageClass: types.TransitionStorageClass(t.StorageClass),
+		})
+	}
+	if rule.ExpirationDays > 0 {
+		r.Expiration = &types.LifecycleExpiration{Days: aws.Int32(rule.ExpirationDays)}
+	}
+	if rule.NoncurrentExpirationDays > 0 {
+		r.NoncurrentVersionExpiration = &types.NoncurrentVersionExpiration{NoncurrentDays: aws.Int32(rule.NoncurrentExpirationDays)}
+	}
+	if rule.AbortIncompleteUploadDays > 0 {
+		r.AbortIncompleteMultipartUpload = &types.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: aws.Int32(rule.AbortIncompleteUploadDays),
+		}
+	}
+	return r
+}
+
+// ValidateLifecycleRule checks a rule of the lifecycle form against the constraints S3 enforces, so
+// the configuration is not rejected after the user confirmed it.
+func ValidateLifecycleRule(rule dto.LifecycleRule) error {
+	switch {
+	case rule.ID == "":
+		return fmt.Errorf("%w: the rule needs an ID", ErrInvalidLifecycleRule)
+	case len(rule.ID) > maxLifecycleRuleIDLength:
+		return fmt.Errorf("%w: the ID is longer than %d characters", ErrInvalidLifecycleRule, maxLifecycleRuleIDLength)
+	case len(rule.Transitions) == 0 && rule.ExpirationDays == 0 && rule.NoncurrentExpirationDays == 0 &&
+		rule.AbortIncompleteUploadDays == 0:
+		return fmt.Errorf("%w: the rule has no action", ErrInvalidLifecycleRule)
+	case rule.ExpirationDays < 0 || rule.NoncurrentExpirationDays < 0 || rule.AbortIncompleteUploadDays < 0:
+		return fmt.Errorf("%w: days cannot be negative", ErrInvalidLifecycleRule)
+	}
+
+	previous := dto.LifecycleTransition{}
+	previousRank := -1
+	for _, t := range rule.Transitions {
+		rank := slices.Index(LifecycleTransitionClasses, types.StorageClass(t.StorageClass))
+		infrequentAccess := t.StorageClass == string(types.StorageClassStandardIa) ||
+			t.StorageClass == string(types.StorageClassOnezoneIa)
+		switch {
+		case rank < 0:
+			return fmt.Errorf("%w: objects cannot transition to %q", ErrInvalidLifecycleRule, t.StorageClass)
+		case t.Days < 0:
+			return fmt.Errorf("%w: days cannot be negative", ErrInvalidLifecycleRule)
+		case infrequentAccess && t.Days < minInfrequentAccessDays:
+			return fmt.Errorf("%w: objects must be at least %d days old to transition to %s",
+				ErrInvalidLifecycleRule, minInfrequentAccessDays, t.StorageClass)
+		case rank <= previousRank:
+			return fmt.Errorf("%w: the transition to %s must go to a cheaper class than %s",
+				ErrInvalidLifecycleRule, t.StorageClass, previous.StorageClass)
+		case previousRank >= 0 && t.Days <= previous.Days:
+			return fmt.Errorf("%w: the transition to %s must come after the one to %s",
+				ErrInvalidLifecycleRule, t.StorageClass, previous.StorageClass)
+		case (previous.StorageClass == string(types.StorageClassStandardIa) ||
+			previous.StorageClass == string(types.StorageClassOnezoneIa)) && t.Days-previous.Days < minInfrequentAccessDays:
+			return fmt.Errorf("%w: objects must stay %d days in %s before the transition to %s",
+				ErrInvalidLifecycleRule, minInfrequentAccessDays, previous.StorageClass, t.StorageClass)
+		}
+		previous, previousRank = t, rank
+	}
+	if rule.ExpirationDays > 0 && previousRank >= 0 && rule.ExpirationDays <= previous.Days {
+		return fmt.Errorf("%w: the expiration must come after the last transition", ErrInvalidLifecycleRule)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package s3svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// S3 multipart upload limits.
+const (
+	// MinPartSize is the smallest part S3 accepts, except for the last part.
+	MinPartSize = 5 * 1024 * 1024
+	// MaxUploadParts is the largest number of parts in one multipart upload.
+	MaxUploadParts = 10000
+)
+
+// ErrNoUploadParts is returned when completing a multipart upload without parts.
+var ErrNoUploadParts = errors.New("multipart upload has no parts")
+
+// StreamUploadResult describes an object written by StreamUpload.
+type StreamUploadResult struct {
+	Size int64
+	ETag string
+}
+
+// StreamUpload reads body to its end and writes it to key as a multipart upload,
+// buffering at most concurrency parts of partSize bytes in memory.
+// Bodies smaller than one part are sent with a single PutObject.
+// On failure the multipart upload is aborted so no orphan parts are left behind.
+func (s *Service) StreamUpload(
+	ctx context.Context,
+	key string,
+	body io.Reader,
+	contentType string,
+	partSize int64,
+	concurrency int,
+) (*StreamUploadResult, error) {
+	out, err := s.streamUpload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.cfg.S3.Bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	}, body, partSize, concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("StreamUpload: error uploading to S3: %w", err)
+	}
+
+	s.log.Debug("StreamUpload completed",
+		slog.String("key", key),
+		slog.String("contentType", contentType),
+		slog.Int64("size", out.Size))
+
+	return out, nil
+}
+
+// streamUpload uploads body with the object settings of input using the upload manager.
+func (s *Service) streamUpload(
+	ctx context.Context, input *s3.PutObjectInput, body io.Reader, partSize int64, concurrency int,
+) (*StreamUploadResult, error) {
+	uploader := manager.NewUploader(s.awsS3Client, func(u *manager.Uploader) {
+		u.PartSize = max(partSize, MinPartSize)
+		u.Concurrency = max(concurrency, 1)
+		u.LeavePartsOnError = false
+	})
+
+	counter := &countingReader{r: body}
+	input.Body = counter
+	out, err := uploader.Upload(ctx, input)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by the callers
+	}
+	return &StreamUploadResult{Size: counter.n, ETag: aws.ToString(out.ETag)}, nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err //nolint:wrapcheck // io.Reader contract: io.EOF must not be wrapped
+}
+
+// CreateMultipartUpload starts a multipart upload and returns its upload ID.
+func (s *Service) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	out, err := s.awsS3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.cfg.S3.Bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("CreateMultipartUpload: %w", err)
+	}
+	return aws.ToString(out.UploadId), nil
+}
+
+// UploadPart streams one part of a multipart upload and returns its ETag.
+// size must be the exact number of bytes body yields.
+func (s *Service) UploadPart(
+	ctx context.Context, key, uploadID string, number int32, body io.Reader, size int64,
+) (string, error) {
+	out, err := s.awsS3Client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(s.cfg.S3.Bucket),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(number),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return "", fmt.Errorf("UploadPart %d: %w", number, err)
+	}
+	return aws.ToString(out.ETag), nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the final object and returns its ETag.
+func (s *Service) CompleteMultipartUpload(
+	ctx context.Context, key, uploadID string, parts []dto.UploadPart,
+) (string, error) {
+	if len(parts) == 0 {
+		return "", ErrNoUploadParts
+	}
+
+	completed := make([]types.CompletedPart, len(parts))
+	for i, p := range parts {
+		completed[i] = types.CompletedPart{PartNumber: aws.Int32(p.Number), ETag: aws.String(p.ETag)}
+	}
+	sort.Slice(completed, func(i, j int) bool {
+		return aws.ToInt32(completed[i].PartNumber) < aws.ToInt32(completed[j].PartNumber)
+	})
+
+	out, err := s.awsS3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.cfg.S3.Bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return "", fmt.Errorf("CompleteMultipartUpload: %w", err)
+	}
+	return aws.ToString(out.ETag), nil
+}
+
+// AbortMultipartUpload discards a multipart upload and the parts stored for it.
+func (s *Service) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	_, err := s.awsS3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.cfg.S3.Bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		return fmt.Errorf("AbortMultipartUpload: %w", err)
+	}
+	return nil
+}
+
+// SessionPartSize returns the part size for an upload of size bytes: the configured part size,
+// raised when needed so the upload fits in MaxUploadParts parts.
+func SessionPartSize(size, configured int64) int64 {
+	partSize := max(configured, MinPartSize)
+	if minimum := (size + MaxUploadParts - 1) / MaxUploadParts; minimum > partSize {
+		partSize = minimum
+	}
+	return partSize
+}
@@ -2,182 +2,173 @@ package s3svc
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math"
 	"log/slog"
-	"strings"
+	"math"
+	"regexp"
 	"time"
-	"unicode"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 )
 
 // DefaultRetentionPolicyInDays is the default number of days that objects will be
 // restored for if not specified in the config.
 const DefaultRetentionPolicyInDays int32 = 2
 
-// IsDownloadable returns true if the object is downloadable.
-func (s *Service) IsDownloadable(ctx context.Context, key string) (bool, bool, error) {
-	var isDownloadable, isRestoring bool
-	hi := s3.HeadObjectInput{
-		Bucket: &s.cfg.S3.Bucket,
-		Key:    &key,
-	}
-	o, err := s.awsS3Client.HeadObject(ctx, &hi)
-	if err != nil {
-		isDownloadable = false
-		isRestoring = false
-		return isDownloadable, isRestoring, fmt.Errorf("IsDownloadable: error when called HeadObject: %w", err)
-	}
+var (
+	// ErrRestoreInProgress is returned when restoring an object whose restore is already running.
+	ErrRestoreInProgress = errors.New("a restore of this object is already in progress")
+	// ErrInvalidRestoreTier is returned for a retrieval tier other than Expedited, Standard or Bulk.
+	ErrInvalidRestoreTier = errors.New("invalid restore tier")
+)
+
+// restoreHeaderPattern matches the name="value" pairs of the x-amz-restore header, such as
+// ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT".
+var restoreHeaderPattern = regexp.MustCompile(`([a-z-]+)="([^"]*)"`)
 
-	// fmt.Printf("%+v\n", o)
-	// fmt.Printf("%+v\n", *o.Restore)
-	// fmt.Printf("%+v\n", o.StorageClass)
-	if o.StorageClass == "" || o.StorageClass == "STANDARD" {
-		isDownloadable = true
-		isRestoring = false
-		return isDownloadable, isRestoring, nil
+// RestoreOptions are the retrieval tier and the number of days the restored copy is kept.
+// Zero values use the Standard tier and the configured restore days.
+type RestoreOptions struct {
+	Tier types.Tier
+	Days int32
+}
+
+// ParseRestoreTier returns the retrieval tier named tier; an empty name is the Standard tier.
+func ParseRestoreTier(tier string) (types.Tier, error) {
+	if tier == "" {
+		return types.TierStandard, nil
 	}
+	for _, t := range []types.Tier{types.TierExpedited, types.TierStandard, types.TierBulk} {
+		if string(t) == tier {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidRestoreTier, tier)
+}
 
-	// If the object is in Glacier, we check if it is downloadable
-	if o.Restore != nil {
-		return s.checkRestoreStatus(*o.Restore)
+// IsDownloadable returns whether the object can be read, and whether a restore is in progress.
+func (s *Service) IsDownloadable(ctx context.Context, key string) (bool, bool, error) {
+	info, err := s.StatObject(ctx, key)
+	if err != nil {
+		return false, false, fmt.Errorf("IsDownloadable: %w", err)
 	}
-	return isDownloadable, isRestoring, nil
+	return info.IsDownloadable, info.IsRestoring, nil
 }
 
-// checkRestoreStatus analyzes the Restore header to determine object availability.
-func (s *Service) checkRestoreStatus(restoreHeader string) (bool, bool, error) {
-	var isDownloadable, isRestoring bool
-	res := conv(strings.ReplaceAll(restoreHeader, ", ", " "))
-	
-	// Check if restoration is in progress
-	if vv, ok := res["ongoing-request"]; ok {
-		switch vv {
-		case "\"false\"":
-			isRestoring = false
-		case "\"true\"":
-			isRestoring = true
-			return isDownloadable, isRestoring, nil
+// restoreStatus analyzes the Restore header of an archived object: the object is downloadable
+// while the restored copy has not expired, and restoring while the request is ongoing.
+func (s *Service) restoreStatus(restoreHeader string) (bool, bool, time.Time) {
+	var expiry time.Time
+	ongoing := false
+	for _, m := range restoreHeaderPattern.FindAllStringSubmatch(restoreHeader, -1) {
+		switch m[1] {
+		case "ongoing-request":
+			ongoing = m[2] == "true"
+		case "expiry-date":
+			tm, err := time.Parse(time.RFC1123, m[2])
+			if err != nil {
+				s.log.Error("restoreStatus: error when parsing time", slog.String("error", err.Error()))
+				continue
+			}
+			expiry = tm
 		}
 	}
-	
-	// Check expiry date if present
-	if vv, ok := res["expiry-date"]; ok {
-		const layout = "\"Mon 2 Jan 2006 15:04:06 MST\""
-		tm, err2 := time.Parse(layout, vv)
-		if err2 != nil {
-			s.log.Error("checkRestoreStatus: error when parsing time", slog.String("error", err2.Error()))
-			return false, isRestoring, nil
-		}
-		
-		// If expiry date is in the future, object is downloadable
-		if time.Now().After(tm) {
-			isDownloadable = true
-			return isDownloadable, isRestoring, nil
-		}
+
+	if ongoing {
+		return false, true, time.Time{}
 	}
-	
-	return isDownloadable, isRestoring, nil
+	return time.Now().Before(expiry), false, expiry
 }
 
-// RestoreObject restores an object.
-func (s *Service) RestoreObject(ctx context.Context, key string) error {
-	// https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/s3@v1.26.0/types#RestoreRequest
-	tt := types.GlacierJobParameters{
-		Tier: "Standard",
+// ListRestoreStatus returns the objects under prefix with the restore status of the archived ones
+// (GLACIER, DEEP_ARCHIVE), read from the listing instead of a HeadObject per object. Only the objects
+// directly under prefix are listed unless recursive is set. The archive access tiers of
+// Intelligent-Tiering objects are not listed, so these need a StatObject.
+func (s *Service) ListRestoreStatus(ctx context.Context, prefix string, recursive bool) (map[string]ObjectInfo, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:                   aws.String(s.cfg.S3.Bucket),
+		Prefix:                   aws.String(prefix),
+		OptionalObjectAttributes: []types.OptionalObjectAttributes{types.OptionalObjectAttributesRestoreStatus},
 	}
-	
-	// Use configured RestoreDays if set, otherwise use the default
-	var restoreDays int32
-	// Check if the RestoreDays is within int32 bounds to prevent overflow
-	switch {
-	case s.cfg.S3.RestoreDays <= 0:
-		restoreDays = DefaultRetentionPolicyInDays
-		s.log.Debug("Using default restore days", slog.Int("days", int(DefaultRetentionPolicyInDays)))
-	case s.cfg.S3.RestoreDays > int(math.MaxInt32):
-		// If RestoreDays exceeds int32 max value, use the maximum value
-		restoreDays = math.MaxInt32
-		s.log.Warn("RestoreDays exceeds maximum allowed value, capping at maximum", 
-			slog.Int("requested", s.cfg.S3.RestoreDays), 
-			slog.Int("maximum", int(math.MaxInt32)))
-	default:
-		// This case should only be reached when RestoreDays is greater than 0 and less than MaxInt32,
-		// but we'll still handle it safely to satisfy the linter and prevent any possible issues.
-		
-		// Create a safe function to convert int to int32 without risk of overflow
-		safeInt32Conversion := func(value int) int32 {
-			// Define the int32 range boundaries
-			const maxInt32Value = 2147483647 // math.MaxInt32
-			
-			// Ensure the value is within int32 bounds
-			if value > maxInt32Value {
-				return math.MaxInt32
+	if !recursive {
+		input.Delimiter = aws.String("/")
+	}
+
+	result := map[string]ObjectInfo{}
+	paginator := s3.NewListObjectsV2Paginator(s.awsS3Client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("ListRestoreStatus: error of paginator.NextPage: %w", err)
+		}
+		for _, obj := range page.Contents {
+			info := ObjectInfo{
+				Key:            aws.ToString(obj.Key),
+				Size:           aws.ToInt64(obj.Size),
+				ETag:           aws.ToString(obj.ETag),
+				LastModified:   aws.ToTime(obj.LastModified),
+				StorageClass:   string(obj.StorageClass),
+				IsDownloadable: !IsArchiveStorageClass(string(obj.StorageClass)),
 			}
-			if value < 0 {
-				return 0 // or DefaultRetentionPolicyInDays if you prefer
+			// Archived objects without a restore status have no restored copy
+			if status := obj.RestoreStatus; status != nil && !info.IsDownloadable {
+				info.IsRestoring = aws.ToBool(status.IsRestoreInProgress)
+				info.RestoreExpiry = aws.ToTime(status.RestoreExpiryDate)
+				info.IsDownloadable = !info.IsRestoring && time.Now().Before(info.RestoreExpiry)
 			}
-			
-			// Now we can safely convert
-			return int32(value)
+			result[info.Key] = info
 		}
-		
-		// Convert using our safe function
-		restoreDays = safeInt32Conversion(s.cfg.S3.RestoreDays)
-		s.log.Debug("Using configured restore days", slog.Int("days", s.cfg.S3.RestoreDays))
 	}
-	
-	r := types.RestoreRequest{
-		Days: aws.Int32(restoreDays),
-		// Type:           "SELECT",
-		GlacierJobParameters: &tt,
-		// Tier: "Standard",
-		// OutputLocation: &x,
-		// Description:    &i,
+	return result, nil
+}
+
+// RestoreObject requests a temporary copy of an archived object.
+func (s *Service) RestoreObject(ctx context.Context, key string, opts RestoreOptions) error {
+	if opts.Tier == "" {
+		opts.Tier = types.TierStandard
 	}
-	p := s3.RestoreObjectInput{
-		Bucket:         &s.cfg.S3.Bucket,
-		Key:            &key,
-		RestoreRequest: &r,
+	if opts.Days <= 0 {
+		opts.Days = s.DefaultRestoreDays()
 	}
-	o, err := s.awsS3Client.RestoreObject(ctx, &p)
+
+	o, err := s.awsS3Client.RestoreObject(ctx, &s3.RestoreObjectInput{
+		Bucket: &s.cfg.S3.Bucket,
+		Key:    &key,
+		RestoreRequest: &types.RestoreRequest{
+			Days:                 aws.Int32(opts.Days),
+			GlacierJobParameters: &types.GlacierJobParameters{Tier: opts.Tier},
+		},
+	})
 	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress" {
+			return fmt.Errorf("RestoreObject: %w", ErrRestoreInProgress)
+		}
 		return fmt.Errorf("RestoreObject: error when called RestoreObject: %w", err)
 	}
-	s.log.Debug("RestoreObject", slog.String("key", key), slog.String("output", fmt.Sprintf("%+v", o)))
+	s.log.Debug("RestoreObject",
+		slog.String("key", key),
+		slog.String("tier", string(opts.Tier)),
+		slog.Int("days", int(opts.Days)),
+		slog.String("output", fmt.Sprintf("%+v", o)))
 	return nil
 }
 
-// conv converts a string to a map.
-func conv(str string) map[string]string {
-	lastQuote := rune(0)
-	f := func(c rune) bool {
-		switch {
-		case c == lastQuote:
-			lastQuote = rune(0)
-			return false
-		case lastQuote != rune(0):
-			return false
-		case unicode.In(c, unicode.Quotation_Mark):
-			lastQuote = c
-			return false
-		default:
-			return unicode.IsSpace(c)
-		}
-	}
-	// splitting string by space but considering quoted section
-	items := strings.FieldsFunc(str, f)
-	// create and fill the map
-	m := make(map[string]string)
-	for _, item := range items {
-		x := strings.Split(item, "=")
-		m[x[0]] = x[1]
+// DefaultRestoreDays returns the configured number of days restored copies are kept.
+func (s *Service) DefaultRestoreDays() int32 {
+	switch {
+	case s.cfg.S3.RestoreDays <= 0:
+		return DefaultRetentionPolicyInDays
+	case s.cfg.S3.RestoreDays > math.MaxInt32:
+		s.log.Warn("RestoreDays exceeds maximum allowed value, capping at maximum",
+			slog.Int("requested", s.cfg.S3.RestoreDays),
+			slog.Int("maximum", math.MaxInt32))
+		return math.MaxInt32
+	default:
+		return int32(s.cfg.S3.RestoreDays) //nolint:gosec // bounded above
 	}
-	// print the map
-	// for k, v := range m {
-	// 	fmt.Printf("%s: %s\n", k, v)
-	// }
-	return m
 }
@@ -0,0 +1,49 @@
+package s3svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// MaxPresignExpiry is the longest validity S3 accepts for a SigV4 presigned URL.
+const MaxPresignExpiry = 7 * 24 * time.Hour
+
+// ErrPresignExpiry is returned for an expiry outside (0, MaxPresignExpiry].
+var ErrPresignExpiry = errors.New("invalid presigned URL expiry")
+
+// PresignGetObject returns a URL that downloads key without credentials until expiry elapses.
+// The response is served as an attachment named after the object.
+// URLs signed with temporary credentials (SSO) stop working when those credentials expire.
+func (s *Service) PresignGetObject(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if expiry <= 0 || expiry > MaxPresignExpiry {
+		return "", fmt.Errorf("%w: %s", ErrPresignExpiry, expiry)
+	}
+
+	presigner := s3.NewPresignClient(s.awsS3Client)
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(key)})
+	req, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket:                     aws.String(s.cfg.S3.Bucket),
+		Key:                        aws.String(key),
+		ResponseContentDisposition: aws.String(disposition),
+	}, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign %s: %w", key, err)
+	}
+	return req.URL, nil
+}
+
+// ForBucket returns a service bound to bucketName that shares this service's client and logger.
+// It is used to serve objects recorded against a bucket other than the one being browsed.
+func (s *Service) ForBucket(bucketName string) *Service {
+	cfg := s.cfg
+	cfg.S3.Bucket = bucketName
+	cfg.S3.Prefix = ""
+	return &Service{cfg: cfg, awsS3Client: s.awsS3Client, log: s.log}
+}
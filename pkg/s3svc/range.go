@@ -0,0 +1,162 @@
+package s3svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var (
+	// ErrInvalidRange is returned when a byte range has a negative offset or a non-positive length.
+	ErrInvalidRange = errors.New("invalid byte range")
+	// ErrObjectNotFound is returned by StatObject when the object does not exist.
+	ErrObjectNotFound = errors.New("object not found")
+)
+
+// ObjectInfo holds the metadata of an S3 object returned by HeadObject.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+	StorageClass string
+	// VersionID is the current version in versioned buckets, else empty or "null"
+	VersionID      string
+	IsDownloadable bool
+	IsRestoring    bool
+	// RestoreExpiry is when the restored copy of an archived object is removed, zero if there is none
+	RestoreExpiry time.Time
+}
+
+// ObjectRange is a chunk of an object read with a byte-range GET.
+type ObjectRange struct {
+	Data []byte
+	// Offset is the position of Data[0] in the object
+	Offset int64
+	// TotalSize is the full object size
+	TotalSize int64
+	// Truncated is true when the object continues after the returned chunk
+	Truncated bool
+}
+
+// StatObject returns the metadata of an object, including whether it can be read
+// (objects archived in Glacier must be restored first).
+func (s *Service) StatObject(ctx context.Context, key string) (*ObjectInfo, error) {
+	return s.StatObjectVersion(ctx, key, "")
+}
+
+// StatObjectVersion is StatObject for one version of the object; an empty versionID is the current one.
+func (s *Service) StatObjectVersion(ctx context.Context, key, versionID string) (*ObjectInfo, error) {
+	o, err := s.awsS3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    &s.cfg.S3.Bucket,
+		Key:       &key,
+		VersionId: optionalString(versionID),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("StatObject: %s: %w", key, ErrObjectNotFound)
+		}
+		return nil, fmt.Errorf("StatObject: error when called HeadObject: %w", err)
+	}
+
+	info := s.objectInfoFromHead(key, o)
+	if versionID != "" {
+		info.VersionID = versionID
+	}
+	return info, nil
+}
+
+// objectInfoFromHead builds the ObjectInfo of key from a HeadObject answer.
+func (s *Service) objectInfoFromHead(key string, o *s3.HeadObjectOutput) *ObjectInfo {
+	info := &ObjectInfo{
+		Key:          key,
+		Size:         aws.ToInt64(o.ContentLength),
+		ContentType:  aws.ToString(o.ContentType),
+		ETag:         aws.ToString(o.ETag),
+		LastModified: aws.ToTime(o.LastModified),
+		StorageClass: string(o.StorageClass),
+		VersionID:    aws.ToString(o.VersionId),
+	}
+
+	switch {
+	case o.StorageClass == "" || o.StorageClass == "STANDARD":
+		info.IsDownloadable = true
+	case o.Restore != nil:
+		info.IsDownloadable, info.IsRestoring, info.RestoreExpiry = s.restoreStatus(*o.Restore)
+	case o.ArchiveStatus != "":
+		// Intelligent-Tiering objects in an archive access tier need a restore, like GLACIER ones
+		info.IsDownloadable = false
+	default:
+		// Non-archive classes (STANDARD_IA, ONEZONE_IA, INTELLIGENT_TIERING, ...) are directly readable
+		info.IsDownloadable = !IsArchiveStorageClass(string(o.StorageClass))
+	}
+	return info
+}
+
+// IsArchiveStorageClass reports whether objects of the given class need a restore before reading.
+func IsArchiveStorageClass(class string) bool {
+	return class == "GLACIER" || class == "DEEP_ARCHIVE"
+}
+
+// GetObjectRange reads at most length bytes of an object starting at offset.
+// Only the requested range is transferred, so previews of huge files stay cheap.
+// totalSize is the known object size (from StatObject or the catalog).
+func (s *Service) GetObjectRange(ctx context.Context, key string, offset, length, totalSize int64) (*ObjectRange, error) {
+	if offset < 0 || length <= 0 {
+		return nil, fmt.Errorf("%w: offset=%d length=%d", ErrInvalidRange, offset, length)
+	}
+
+	result := &ObjectRange{Offset: offset, TotalSize: totalSize}
+	// S3 rejects ranges starting at or beyond the end of the object
+	if offset >= totalSize {
+		return result, nil
+	}
+
+	end := min(offset+length, totalSize) - 1
+	o, err := s.awsS3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &s.cfg.S3.Bucket,
+		Key:    &key,
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, end)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetObjectRange: error when called GetObject: %w", err)
+	}
+	defer o.Body.Close() //nolint:errcheck
+
+	// Bound the read in case the endpoint ignores the Range header
+	data, err := io.ReadAll(io.LimitReader(o.Body, end-offset+1))
+	if err != nil {
+		return nil, fmt.Errorf("GetObjectRange: error reading object body: %w", err)
+	}
+
+	result.Data = data
+	result.Truncated = offset+int64(len(data)) < totalSize
+	s.log.Debug("GetObjectRange",
+		slog.String("key", key),
+		slog.Int64("offset", offset),
+		slog.Int("bytes", len(data)),
+		slog.Int64("totalSize", totalSize))
+	return result, nil
+}
+
+// OpenObject returns a reader on the full object content.
+// The caller must close the returned body.
+func (s *Service) OpenObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	o, err := s.awsS3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &s.cfg.S3.Bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("OpenObject: error when called GetObject: %w", err)
+	}
+	return o.Body, nil
+}
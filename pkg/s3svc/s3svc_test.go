@@ -2,12 +2,23 @@
 package s3svc_test
 
 import (
+	"context"
+	"errors"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
 	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc/s3svctest"
 )
 
 // TestNewS3Svc tests creating a new service
@@ -77,17 +88,422 @@ func TestRestoreDaysConfig(t *testing.T) {
 				},
 			}
 			
-			// We can't test this directly without mocking,
-			// but we're ensuring that the code builds and initializes properly
 			service := s3svc.NewS3Svc(cfg, nil)
-			
-			// Simply verify the service was created
 			if service == nil {
 				t.Fatal("Service should not be nil")
 			}
-			
-			// Note: In a real test, we'd verify the RestoreDays value is used
-			// when making S3 RestoreObject calls, but that requires mocking the AWS SDK
+			if got := service.DefaultRestoreDays(); int(got) != tc.expected {
+				t.Errorf("DefaultRestoreDays() = %d, want %d", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSessionPartSize(t *testing.T) {
+	const gb = int64(1024 * 1024 * 1024)
+	tests := []struct {
+		name             string
+		size, configured int64
+		want             int64
+	}{
+		{"configured size", 10 * gb, 16 * 1024 * 1024, 16 * 1024 * 1024},
+		{"below S3 minimum", 1024, 1024, s3svc.MinPartSize},
+		{"raised to fit 10000 parts", 500 * gb, 16 * 1024 * 1024, (500*gb + s3svc.MaxUploadParts - 1) / s3svc.MaxUploadParts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s3svc.SessionPartSize(tt.size, tt.configured)
+			if got != tt.want {
+				t.Errorf("s3svc.SessionPartSize(%d, %d) = %d, want %d", tt.size, tt.configured, got, tt.want)
+			}
+			if parts := (tt.size + got - 1) / got; parts > s3svc.MaxUploadParts {
+				t.Errorf("%d parts exceed the S3 limit", parts)
+			}
+		})
+	}
+}
+
+func TestParseRestoreTier(t *testing.T) {
+	tests := []struct {
+		tier    string
+		want    types.Tier
+		wantErr bool
+	}{
+		{"", types.TierStandard, false},
+		{"Expedited", types.TierExpedited, false},
+		{"Bulk", types.TierBulk, false},
+		{"bulk", "", true},
+		{"Instant", "", true},
+	}
+	for _, tt := range tests {
+		got, err := s3svc.ParseRestoreTier(tt.tier)
+		if tt.wantErr {
+			if !errors.Is(err, s3svc.ErrInvalidRestoreTier) {
+				t.Errorf("ParseRestoreTier(%q) error = %v, want ErrInvalidRestoreTier", tt.tier, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRestoreTier(%q) = %q, %v, want %q", tt.tier, got, err, tt.want)
+		}
+	}
+}
+
+// TestStatObject_RestoreStatus checks the restore state parsed from the x-amz-restore header
+func TestStatObject_RestoreStatus(t *testing.T) {
+	future := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	past := time.Now().Add(-48 * time.Hour).UTC()
+	tests := []struct {
+		name                    string
+		storageClass, restore   string
+		downloadable, restoring bool
+		expiry                  time.Time
+	}{
+		{"standard", "", "", true, false, time.Time{}},
+		{"archived", "GLACIER", "", false, false, time.Time{}},
+		{"restore running", "GLACIER", `ongoing-request="true"`, false, true, time.Time{}},
+		{"restored", "DEEP_ARCHIVE", `ongoing-request="false", expiry-date="` + future.Format(http.TimeFormat) + `"`, true, false, future},
+		{"restored copy expired", "GLACIER", `ongoing-request="false", expiry-date="` + past.Format(http.TimeFormat) + `"`, false, false, past.Truncate(time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newHeadTestService(t, func(w http.ResponseWriter, _ *http.Request) {
+				if tt.storageClass != "" {
+					w.Header().Set("X-Amz-Storage-Class", tt.storageClass)
+				}
+				if tt.restore != "" {
+					w.Header().Set("X-Amz-Restore", tt.restore)
+				}
+			})
+
+			info, err := svc.StatObject(context.Background(), "archive.tar")
+			if err != nil {
+				t.Fatalf("StatObject: %v", err)
+			}
+			if info.IsDownloadable != tt.downloadable || info.IsRestoring != tt.restoring {
+				t.Errorf("downloadable=%t restoring=%t, want %t %t", info.IsDownloadable, info.IsRestoring, tt.downloadable, tt.restoring)
+			}
+			if !info.RestoreExpiry.Equal(tt.expiry) {
+				t.Errorf("RestoreExpiry = %v, want %v", info.RestoreExpiry, tt.expiry)
+			}
+		})
+	}
+}
+
+func TestStatObject_NotFound(t *testing.T) {
+	svc := newHeadTestService(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, err := svc.StatObject(context.Background(), "missing.txt")
+	if !errors.Is(err, s3svc.ErrObjectNotFound) {
+		t.Errorf("StatObject error = %v, want ErrObjectNotFound", err)
+	}
+}
+
+func TestParseStorageClass(t *testing.T) {
+	class, err := s3svc.ParseStorageClass("GLACIER_IR")
+	if err != nil || class != types.StorageClassGlacierIr {
+		t.Errorf("ParseStorageClass(GLACIER_IR) = %q, %v", class, err)
+	}
+	for _, invalid := range []string{"", "glacier", "REDUCED_REDUNDANCY"} {
+		if _, err := s3svc.ParseStorageClass(invalid); !errors.Is(err, s3svc.ErrInvalidStorageClass) {
+			t.Errorf("ParseStorageClass(%q) error = %v, want ErrInvalidStorageClass", invalid, err)
+		}
+	}
+}
+
+func TestChangeStorageClass(t *testing.T) {
+	var copied http.Header
+	svc := newHeadTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodHead:
+			w.Header().Set("Content-Length", "42")
+			w.Header().Set("X-Amz-Server-Side-Encryption", "aws:kms")
+			w.Header().Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", "key-1")
+		case http.MethodPut:
+			copied = r.Header.Clone()
+			_, _ = io.WriteString(w, `<CopyObjectResult><ETag>"new"</ETag></CopyObjectResult>`)
+		}
+	})
+
+	etag, err := svc.ChangeStorageClass(context.Background(), "a.txt", types.StorageClassStandardIa)
+	if err != nil {
+		t.Fatalf("ChangeStorageClass: %v", err)
+	}
+	if etag != `"new"` {
+		t.Errorf("etag = %s, want \"new\"", etag)
+	}
+	if got := copied.Get("X-Amz-Copy-Source"); got != "bucket/a.txt" {
+		t.Errorf("copy source = %q", got)
+	}
+	if got := copied.Get("X-Amz-Storage-Class"); got != "STANDARD_IA" {
+		t.Errorf("storage class = %q, want STANDARD_IA", got)
+	}
+	if got := copied.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"); got != "key-1" {
+		t.Errorf("KMS key = %q, want key-1", got)
+	}
+}
+
+func TestChangeStorageClass_Archived(t *testing.T) {
+	copies := 0
+	svc := newHeadTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			copies++
+			return
+		}
+		w.Header().Set("X-Amz-Storage-Class", "GLACIER")
+	})
+
+	_, err := svc.ChangeStorageClass(context.Background(), "a.tar", types.StorageClassStandard)
+	if !errors.Is(err, s3svc.ErrObjectArchived) {
+		t.Errorf("ChangeStorageClass error = %v, want ErrObjectArchived", err)
+	}
+	if copies != 0 {
+		t.Errorf("archived object was copied")
+	}
+}
+
+// TestListRestoreStatus checks the restore state read from the RestoreStatus of the listing
+func TestListRestoreStatus(t *testing.T) {
+	future := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	var query url.Values
+	svc := newHeadTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = io.WriteString(w, `<ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated>`+
+			`<Contents><Key>logs/a.txt</Key><Size>1</Size><StorageClass>STANDARD</StorageClass></Contents>`+
+			`<Contents><Key>logs/b.tar</Key><Size>2</Size><StorageClass>GLACIER</StorageClass></Contents>`+
+			`<Contents><Key>logs/c.tar</Key><Size>3</Size><StorageClass>GLACIER</StorageClass>`+
+			`<RestoreStatus><IsRestoreInProgress>true</IsRestoreInProgress></RestoreStatus></Contents>`+
+			`<Contents><Key>logs/d.tar</Key><Size>4</Size><StorageClass>DEEP_ARCHIVE</StorageClass>`+
+			`<RestoreStatus><IsRestoreInProgress>false</IsRestoreInProgress>`+
+			`<RestoreExpiryDate>`+future.Format(time.RFC3339)+`</RestoreExpiryDate></RestoreStatus></Contents>`+
+			`</ListBucketResult>`)
+	})
+
+	objects, err := svc.ListRestoreStatus(context.Background(), "logs/", false)
+	if err != nil {
+		t.Fatalf("ListRestoreStatus: %v", err)
+	}
+	if got := query.Get("delimiter"); got != "/" {
+		t.Errorf("delimiter = %q, want /", got)
+	}
+	tests := []struct {
+		key                     string
+		downloadable, restoring bool
+	}{
+		{"logs/a.txt", true, false},
+		{"logs/b.tar", false, false},
+		{"logs/c.tar", false, true},
+		{"logs/d.tar", true, false},
+	}
+	for _, tt := range tests {
+		info, ok := objects[tt.key]
+		if !ok {
+			t.Errorf("%s not listed", tt.key)
+			continue
+		}
+		if info.IsDownloadable != tt.downloadable || info.IsRestoring != tt.restoring {
+			t.Errorf("%s: downloadable=%t restoring=%t, want %t %t", tt.key, info.IsDownloadable, info.IsRestoring, tt.downloadable, tt.restoring)
+		}
+	}
+	if !objects["logs/d.tar"].RestoreExpiry.Equal(future) {
+		t.Errorf("RestoreExpiry = %v, want %v", objects["logs/d.tar"].RestoreExpiry, future)
+	}
+}
+
+// TestWalkObjects checks that each listed page is handed over before the next one is requested
+func TestWalkObjects(t *testing.T) {
+	var requests []string
+	svc := newHeadTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("continuation-token")
+		requests = append(requests, token)
+		if token == "" {
+			_, _ = io.WriteString(w, `<ListBucketResult><IsTruncated>true</IsTruncated><NextContinuationToken>next</NextContinuationToken>`+
+				`<Contents><Key>d/a</Key><Size>1</Size></Contents><Contents><Key>d/b</Key><Size>2</Size></Contents></ListBucketResult>`)
+			return
+		}
+		_, _ = io.WriteString(w, `<ListBucketResult><IsTruncated>false</IsTruncated>`+
+			`<Contents><Key>d/c</Key><Size>3</Size></Contents></ListBucketResult>`)
+	})
+
+	var pages [][]string
+	err := svc.WalkObjects(context.Background(), "d/", func(page []s3svc.ObjectInfo) error {
+		keys := make([]string, 0, len(page))
+		for _, obj := range page {
+			keys = append(keys, obj.Key)
+		}
+		pages = append(pages, keys)
+		if len(requests) != len(pages) {
+			t.Errorf("page %d handed over after %d requests", len(pages), len(requests))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkObjects: %v", err)
+	}
+	if want := [][]string{{"d/a", "d/b"}, {"d/c"}}; !reflect.DeepEqual(pages, want) {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+
+	stop := errors.New("stop")
+	requests = nil
+	if err := svc.WalkObjects(context.Background(), "d/", func([]s3svc.ObjectInfo) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("WalkObjects error = %v, want the error of fn", err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("%d listings after fn failed, want 1", len(requests))
+	}
+}
+
+func newHeadTestService(t *testing.T, handler http.HandlerFunc) *s3svc.Service {
+	t.Helper()
+
+	client := s3svctest.NewClient(t, handler)
+	svc := s3svc.NewS3Svc(config.Config{S3: config.S3Config{Bucket: "bucket"}}, client)
+	svc.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return svc
+}
+
+func TestGetBucketConfig(t *testing.T) {
+	svc := newHeadTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		fail := func(status int, code string) {
+			w.WriteHeader(status)
+			_, _ = io.WriteString(w, "<Error><Code>"+code+"</Code><Message>"+code+"</Message></Error>")
+		}
+		switch {
+		case query.Has("versioning"):
+			_, _ = io.WriteString(w, `<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>`)
+		case query.Has("lifecycle"):
+			_, _ = io.WriteString(w, `<LifecycleConfiguration><Rule><ID>logs</ID><Filter><Prefix>logs/</Prefix></Filter>`+
+				`<Status>Enabled</Status><Transition><Days>30</Days><StorageClass>GLACIER</StorageClass></Transition>`+
+				`<Expiration><Days>365</Days></Expiration></Rule></LifecycleConfiguration>`)
+		case query.Has("replication"):
+			fail(http.StatusNotImplemented, "NotImplemented")
+		case query.Has("encryption"):
+			fail(http.StatusNotFound, "ServerSideEncryptionConfigurationNotFoundError")
+		case query.Has("object-lock"):
+			fail(http.StatusNotFound, "ObjectLockConfigurationNotFoundError")
+		case query.Has("cors"):
+			fail(http.StatusNotFound, "NoSuchCORSConfiguration")
+		case query.Has("policy"):
+			_, _ = io.WriteString(w, `{"Version":"2012-10-17","Statement":[]}`)
+		case query.Has("publicAccessBlock"):
+			fail(http.StatusForbidden, "AccessDenied")
+		case query.Has("tagging"):
+			_, _ = io.WriteString(w, `<Tagging><TagSet><Tag><Key>team</Key><Value>data</Value></Tag></TagSet></Tagging>`)
+		case query.Has("notification"):
+			_, _ = io.WriteString(w, `<NotificationConfiguration></NotificationConfiguration>`)
+		default:
+			fail(http.StatusInternalServerError, "InternalError")
+		}
+	})
+
+	cfg := svc.GetBucketConfig(context.Background())
+	if len(cfg.Sections) != 10 {
+		t.Fatalf("got %d sections, want 10", len(cfg.Sections))
+	}
+	wantStatus := map[string]string{
+		s3svc.BucketSectionVersioning:        dto.BucketConfigSet,
+		s3svc.BucketSectionLifecycle:         dto.BucketConfigSet,
+		s3svc.BucketSectionReplication:       dto.BucketConfigNotSupported,
+		s3svc.BucketSectionEncryption:        dto.BucketConfigNotSet,
+		s3svc.BucketSectionObjectLock:        dto.BucketConfigNotSet,
+		s3svc.BucketSectionCORS:              dto.BucketConfigNotSet,
+		s3svc.BucketSectionPolicy:            dto.BucketConfigSet,
+		s3svc.BucketSectionPublicAccessBlock: dto.BucketConfigDenied,
+		s3svc.BucketSectionTagging:           dto.BucketConfigSet,
+		s3svc.BucketSectionNotifications:     dto.BucketConfigNotSet,
+	}
+	for name, want := range wantStatus {
+		if section := cfg.Section(name); section == nil || section.Status != want {
+			t.Errorf("%s: section %+v, want status %q", name, section, want)
+		}
+	}
+
+	lifecycle := cfg.Section(s3svc.BucketSectionLifecycle).Properties
+	want := "Enabled; prefix logs/; to GLACIER after 30 days; expire after 365 days"
+	if len(lifecycle) != 1 || lifecycle[0].Label != "logs" || lifecycle[0].Value != want {
+		t.Errorf("lifecycle = %+v, want logs: %s", lifecycle, want)
+	}
+	if doc := cfg.Section(s3svc.BucketSectionPolicy).Document; !strings.Contains(doc, "\n  \"Version\"") {
+		t.Errorf("policy document is not indented: %q", doc)
+	}
+}
+
+func TestGetLifecycleRules_NotConfigured(t *testing.T) {
+	svc := newHeadTestService(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `<Error><Code>NoSuchLifecycleConfiguration</Code><Message>none</Message></Error>`)
+	})
+
+	rules, err := svc.GetLifecycleRules(context.Background())
+	if err != nil || rules != nil {
+		t.Errorf("GetLifecycleRules() = %v, %v, want no rules", rules, err)
+	}
+}
+
+func TestLifecycleRuleRoundTrip(t *testing.T) {
+	rule := dto.LifecycleRule{
+		ID:      "logs",
+		Enabled: true,
+		Prefix:  "logs/",
+		Transitions: []dto.LifecycleTransition{
+			{Days: 30, StorageClass: "STANDARD_IA"},
+			{Days: 90, StorageClass: "GLACIER"},
+		},
+		ExpirationDays:            365,
+		NoncurrentExpirationDays:  7,
+		AbortIncompleteUploadDays: 2,
+		Editable:                  true,
+	}
+
+	got := s3svc.LifecycleRuleFromS3(s3svc.LifecycleRuleToS3(rule))
+	got.Summary = ""
+	if !reflect.DeepEqual(got, rule) {
+		t.Errorf("round trip = %+v, want %+v", got, rule)
+	}
+
+	tagged := s3svc.LifecycleRuleToS3(rule)
+	tagged.Filter = &types.LifecycleRuleFilter{Tag: &types.Tag{Key: aws.String("team"), Value: aws.String("data")}}
+	if s3svc.LifecycleRuleFromS3(tagged).Editable {
+		t.Error("a rule filtered by tag is editable")
+	}
+}
+
+func TestValidateLifecycleRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  dto.LifecycleRule
+		valid bool
+	}{
+		{"expiration", dto.LifecycleRule{ID: "r", ExpirationDays: 1}, true},
+		{"no id", dto.LifecycleRule{ExpirationDays: 1}, false},
+		{"no action", dto.LifecycleRule{ID: "r"}, false},
+		{"negative days", dto.LifecycleRule{ID: "r", ExpirationDays: -1}, false},
+		{"glacier right away", dto.LifecycleRule{ID: "r", Transitions: []dto.LifecycleTransition{{Days: 0, StorageClass: "GLACIER"}}}, true},
+		{"unknown class", dto.LifecycleRule{ID: "r", Transitions: []dto.LifecycleTransition{{Days: 30, StorageClass: "STANDARD"}}}, false},
+		{"infrequent access too early", dto.LifecycleRule{ID: "r", Transitions: []dto.LifecycleTransition{{Days: 10, StorageClass: "STANDARD_IA"}}}, false},
+		{"warmer class", dto.LifecycleRule{ID: "r", Transitions: []dto.LifecycleTransition{
+			{Days: 30, StorageClass: "GLACIER"}, {Days: 90, StorageClass: "STANDARD_IA"},
+		}}, false},
+		{"short stay in infrequent access", dto.LifecycleRule{ID: "r", Transitions: []dto.LifecycleTransition{
+			{Days: 30, StorageClass: "STANDARD_IA"}, {Days: 40, StorageClass: "GLACIER"},
+		}}, false},
+		{"expiration before transition", dto.LifecycleRule{ID: "r", ExpirationDays: 60, Transitions: []dto.LifecycleTransition{
+			{Days: 90, StorageClass: "GLACIER"},
+		}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s3svc.ValidateLifecycleRule(tt.rule)
+			if tt.valid && err != nil {
+				t.Errorf("ValidateLifecycleRule() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, s3svc.ErrInvalidLifecycleRule) {
+				t.Errorf("ValidateLifecycleRule() = %v, want ErrInvalidLifecycleRule", err)
+			}
 		})
 	}
 }
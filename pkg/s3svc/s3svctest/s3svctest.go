@@ -0,0 +1,29 @@
+// Package s3svctest provides S3 clients for tests, talking to a fake S3 server.
+package s3svctest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// NewClient starts a server answering S3 requests with handler and returns a client of it.
+// Buckets are addressed in the path ("/bucket/key") and failed requests are not retried.
+// The server is closed when the test ends.
+func NewClient(t testing.TB, handler http.Handler) *s3.Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return s3.New(s3.Options{
+		BaseEndpoint:     aws.String(server.URL),
+		Region:           "us-east-1",
+		UsePathStyle:     true,
+		Credentials:      credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		RetryMaxAttempts: 1,
+	})
+}
@@ -0,0 +1,149 @@
+package s3svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var (
+	// ErrInvalidStorageClass is returned for a storage class objects cannot be transitioned to.
+	ErrInvalidStorageClass = errors.New("invalid storage class")
+	// ErrObjectArchived is returned when copying an archived object that is not restored.
+	ErrObjectArchived = errors.New("object is archived and must be restored first")
+)
+
+// TransitionStorageClasses are the storage classes objects can be moved to, from the most to the least expensive.
+var TransitionStorageClasses = []types.StorageClass{
+	types.StorageClassStandard,
+	types.StorageClassIntelligentTiering,
+	types.StorageClassStandardIa,
+	types.StorageClassOnezoneIa,
+	types.StorageClassGlacierIr,
+	types.StorageClassGlacier,
+	types.StorageClassDeepArchive,
+}
+
+// ParseStorageClass returns the transition storage class named class.
+func ParseStorageClass(class string) (types.StorageClass, error) {
+	for _, c := range TransitionStorageClasses {
+		if string(c) == class {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidStorageClass, class)
+}
+
+// ChangeStorageClass moves key to another storage class by copying the object onto itself.
+// Metadata, content headers, encryption and tags are kept; objects over MaxCopyObjectSize use a
+// multipart copy. Archived objects must be restored first. It returns the ETag of the new copy.
+func (s *Service) ChangeStorageClass(ctx context.Context, key string, class types.StorageClass) (string, error) {
+	head, err := s.awsS3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.cfg.S3.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("ChangeStorageClass: error when called HeadObject: %w", err)
+	}
+	if downloadable := s.objectInfoFromHead(key, head).IsDownloadable; !downloadable {
+		return "", fmt.Errorf("ChangeStorageClass: %s: %w", key, ErrObjectArchived)
+	}
+
+	var etag string
+	if aws.ToInt64(head.ContentLength) > MaxCopyObjectSize {
+		etag, err = s.multipartStorageClassCopy(ctx, key, head, class)
+	} else {
+		etag, err = s.storageClassCopy(ctx, key, head, class)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	s.log.Debug("ChangeStorageClass completed",
+		slog.String("key", key),
+		slog.String("from", string(head.StorageClass)),
+		slog.String("to", string(class)))
+	return etag, nil
+}
+
+// storageClassCopy copies key onto itself in one CopyObject call, which keeps the metadata and tags.
+func (s *Service) storageClassCopy(
+	ctx context.Context, key string, head *s3.HeadObjectOutput, class types.StorageClass,
+) (string, error) {
+	out, err := s.awsS3Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:       aws.String(s.cfg.S3.Bucket),
+		Key:          aws.String(key),
+		CopySource:   aws.String(copySource(s.cfg.S3.Bucket, key, "")),
+		StorageClass: class,
+		// Without these the copy would fall back to the bucket default encryption
+		ServerSideEncryption: head.ServerSideEncryption,
+		SSEKMSKeyId:          head.SSEKMSKeyId,
+		BucketKeyEnabled:     head.BucketKeyEnabled,
+	})
+	if err != nil {
+		return "", fmt.Errorf("ChangeStorageClass: error copying %s: %w", key, err)
+	}
+	if out.CopyObjectResult == nil {
+		return "", nil
+	}
+	return aws.ToString(out.CopyObjectResult.ETag), nil
+}
+
+// multipartStorageClassCopy copies key onto itself part by part. A multipart upload does not
+// inherit anything from the source, so the headers, metadata and tags are set again.
+func (s *Service) multipartStorageClassCopy(
+	ctx context.Context, key string, head *s3.HeadObjectOutput, class types.StorageClass,
+) (string, error) {
+	tags, err := s.GetObjectTags(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("ChangeStorageClass: %w", err)
+	}
+	tagging := url.Values{}
+	for _, t := range tags {
+		tagging.Set(t.Key, t.Value)
+	}
+
+	created, err := s.awsS3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:                  aws.String(s.cfg.S3.Bucket),
+		Key:                     aws.String(key),
+		StorageClass:            class,
+		Metadata:                head.Metadata,
+		ContentType:             head.ContentType,
+		CacheControl:            head.CacheControl,
+		ContentDisposition:      head.ContentDisposition,
+		ContentEncoding:         head.ContentEncoding,
+		ContentLanguage:         head.ContentLanguage,
+		WebsiteRedirectLocation: head.WebsiteRedirectLocation,
+		Tagging:                 optionalString(tagging.Encode()),
+		ServerSideEncryption:    head.ServerSideEncryption,
+		SSEKMSKeyId:             head.SSEKMSKeyId,
+		BucketKeyEnabled:        head.BucketKeyEnabled,
+	})
+	if err != nil {
+		return "", fmt.Errorf("ChangeStorageClass: %w", err)
+	}
+	uploadID := aws.ToString(created.UploadId)
+
+	// The parts are copied from the version being replaced, so a concurrent write cannot mix contents
+	source := copySource(s.cfg.S3.Bucket, key, aws.ToString(head.VersionId))
+	parts, err := s.copyParts(ctx, source, key, uploadID, aws.ToInt64(head.ContentLength))
+	etag := ""
+	if err == nil {
+		etag, err = s.CompleteMultipartUpload(ctx, key, uploadID, parts)
+	}
+	if err != nil {
+		if abortErr := s.AbortMultipartUpload(ctx, key, uploadID); abortErr != nil {
+			s.log.Error("ChangeStorageClass: failed to abort upload",
+				slog.String("key", key),
+				slog.String("error", abortErr.Error()))
+		}
+		return "", fmt.Errorf("ChangeStorageClass: error copying %s: %w", key, err)
+	}
+	return etag, nil
+}
@@ -0,0 +1,162 @@
+package s3svc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// VersioningEnabled reports whether versioning is enabled on the bucket.
+// Suspended versioning counts as disabled: deletes would not keep the previous version.
+func (s *Service) VersioningEnabled(ctx context.Context) (bool, error) {
+	out, err := s.awsS3Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
+		Bucket: aws.String(s.cfg.S3.Bucket),
+	})
+	if err != nil {
+		return false, fmt.Errorf("VersioningEnabled: error when called GetBucketVersioning: %w", err)
+	}
+	return out.Status == types.BucketVersioningStatusEnabled, nil
+}
+
+// DeleteObjectWithMarker deletes key in a versioned bucket, which only adds a delete marker
+// in front of the current version, and returns the version ID of that marker.
+func (s *Service) DeleteObjectWithMarker(ctx context.Context, key string) (string, error) {
+	out, err := s.awsS3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.cfg.S3.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("DeleteObjectWithMarker: error deleting from S3: %w", err)
+	}
+
+	s.log.Debug("DeleteObjectWithMarker completed",
+		slog.String("key", key),
+		slog.String("marker", aws.ToString(out.VersionId)))
+	return aws.ToString(out.VersionId), nil
+}
+
+// DeleteObjectVersion permanently deletes one version of key, or a delete marker.
+// Deleting the delete marker in front of a version makes that version current again.
+func (s *Service) DeleteObjectVersion(ctx context.Context, key, versionID string) error {
+	_, err := s.awsS3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket:    aws.String(s.cfg.S3.Bucket),
+		Key:       aws.String(key),
+		VersionId: aws.String(versionID),
+	})
+	if err != nil {
+		return fmt.Errorf("DeleteObjectVersion: error deleting %s version %s: %w", key, versionID, err)
+	}
+
+	s.log.Debug("DeleteObjectVersion completed", slog.String("key", key), slog.String("version", versionID))
+	return nil
+}
+
+// ObjectVersion is one version of an object, or a delete marker, as listed by ListObjectVersions.
+type ObjectVersion struct {
+	Key            string
+	VersionID      string
+	IsLatest       bool
+	IsDeleteMarker bool
+	Size           int64
+	ETag           string
+	StorageClass   string
+	LastModified   time.Time
+}
+
+// ListObjectVersions returns the versions and delete markers of key, newest first.
+// Unversioned buckets return a single version with the ID "null".
+func (s *Service) ListObjectVersions(ctx context.Context, key string) ([]ObjectVersion, error) {
+	paginator := s3.NewListObjectVersionsPaginator(s.awsS3Client, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(s.cfg.S3.Bucket),
+		Prefix: aws.String(key),
+	})
+
+	var versions []ObjectVersion
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("ListObjectVersions: %w", err)
+		}
+		pageVersions := pageObjectVersions(page)
+		for _, v := range pageVersions {
+			if v.Key == key {
+				versions = append(versions, v)
+			}
+		}
+		// Keys are listed in order: once a longer key sharing the prefix shows up, key is done
+		if len(pageVersions) > 0 && pageVersions[len(pageVersions)-1].Key > key {
+			break
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].LastModified.After(versions[j].LastModified)
+	})
+	return versions, nil
+}
+
+// ListDeletedObjects returns the delete markers hiding the objects directly in folder,
+// that is the keys whose latest version is a delete marker.
+func (s *Service) ListDeletedObjects(ctx context.Context, folder string) ([]ObjectVersion, error) {
+	paginator := s3.NewListObjectVersionsPaginator(s.awsS3Client, &s3.ListObjectVersionsInput{
+		Bucket:    aws.String(s.cfg.S3.Bucket),
+		Prefix:    aws.String(folder),
+		Delimiter: aws.String("/"),
+	})
+
+	var deleted []ObjectVersion
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("ListDeletedObjects: %w", err)
+		}
+		for _, v := range pageObjectVersions(page) {
+			if v.IsDeleteMarker && v.IsLatest {
+				deleted = append(deleted, v)
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// pageObjectVersions merges the versions and delete markers of a page, sorted by key.
+func pageObjectVersions(page *s3.ListObjectVersionsOutput) []ObjectVersion {
+	versions := make([]ObjectVersion, 0, len(page.Versions)+len(page.DeleteMarkers))
+	for _, v := range page.Versions {
+		versions = append(versions, ObjectVersion{
+			Key:          aws.ToString(v.Key),
+			VersionID:    aws.ToString(v.VersionId),
+			IsLatest:     aws.ToBool(v.IsLatest),
+			Size:         aws.ToInt64(v.Size),
+			ETag:         aws.ToString(v.ETag),
+			StorageClass: string(v.StorageClass),
+			LastModified: aws.ToTime(v.LastModified),
+		})
+	}
+	for _, m := range page.DeleteMarkers {
+		versions = append(versions, ObjectVersion{
+			Key:            aws.ToString(m.Key),
+			VersionID:      aws.ToString(m.VersionId),
+			IsLatest:       aws.ToBool(m.IsLatest),
+			IsDeleteMarker: true,
+			LastModified:   aws.ToTime(m.LastModified),
+		})
+	}
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i].Key < versions[j].Key })
+	return versions
+}
+
+// optionalString returns nil for an empty string, so the parameter is not sent:
+// an empty VersionId selects the current version.
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
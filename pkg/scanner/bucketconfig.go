@@ -0,0 +1,35 @@
+package scanner
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc"
+)
+
+// performBucketConfigPhase reads the bucket configuration (versioning, lifecycle, policy, ...) and caches it
+// with the scan job, so the bucket configuration page does not wait for a dozen S3 calls.
+// Errors are only logged: the page reads the configuration itself when nothing is cached.
+func (s *Service) performBucketConfigPhase(ctx context.Context, bucketName string, bucketID, scanJobID int32) {
+	svc := s3svc.NewS3Svc(s.cfg, s.s3Client).ForBucket(bucketName)
+	svc.SetLogger(s.log)
+	config := svc.GetBucketConfig(ctx)
+
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		s.log.Error("Failed to encode bucket configuration", slog.String("bucket", bucketName), slog.String("error", err.Error()))
+		return
+	}
+	if err := s.queries.UpsertBucketConfig(ctx, database.UpsertBucketConfigParams{
+		BucketID:  bucketID,
+		ScanJobID: sql.NullInt32{Int32: scanJobID, Valid: true},
+		Config:    encoded,
+	}); err != nil {
+		s.log.Error("Failed to save bucket configuration", slog.String("bucket", bucketName), slog.String("error", err.Error()))
+		return
+	}
+	s.log.Debug("Bucket configuration cached", slog.String("bucket", bucketName))
+}
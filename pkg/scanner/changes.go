@@ -0,0 +1,89 @@
+package scanner
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// scanRun identifies the scan job writing the files of a bucket to the catalog.
+type scanRun struct {
+	bucketName string
+	bucketID   int32
+	jobID      int32
+	// recordChanges is false for the first scan of a bucket, which would only record every file as created
+	recordChanges bool
+	// exclude is the folder of the scheduled exports, when written to this bucket, left out of the catalog
+	exclude string
+}
+
+// excluded reports whether key is left out of the catalog.
+func (r scanRun) excluded(key string) bool {
+	return r.exclude != "" && strings.HasPrefix(key, r.exclude)
+}
+
+// newScanRun returns the scan run of a scan job, recording changes unless the catalog has no file of the
+// bucket yet. When that cannot be checked, changes are recorded.
+func (s *Service) newScanRun(ctx context.Context, bucketName string, bucketID, scanJobID int32) scanRun {
+	hasFiles, err := s.queries.BucketHasFiles(ctx, bucketID)
+	if err != nil {
+		s.log.Warn("Cannot tell whether this is the first scan, recording changes",
+			slog.String("bucket", bucketName),
+			slog.String("error", err.Error()))
+		hasFiles = true
+	}
+	if !hasFiles {
+		s.log.Info("First scan of the bucket, not recording changes", slog.String("bucket", bucketName))
+	}
+	return scanRun{
+		bucketName:    bucketName,
+		bucketID:      bucketID,
+		jobID:         scanJobID,
+		recordChanges: hasFiles,
+		exclude:       s.cfg.ExportPrefix(bucketName),
+	}
+}
+
+// objectChange compares a file listed by a scan to its catalog row, nil when the file is not in the
+// catalog yet. It returns false when the size and ETag did not change.
+func objectChange(previous *database.S3Object, key string, size int64, etag string) (database.InsertObjectChangeParams, bool) {
+	change := database.InsertObjectChangeParams{
+		Key:     key,
+		Change:  dto.ChangeCreated,
+		NewSize: sql.NullInt64{Int64: size, Valid: true},
+		NewEtag: sql.NullString{String: etag, Valid: etag != ""},
+	}
+	if previous == nil {
+		return change, true
+	}
+	if previous.Size == size && previous.Etag == change.NewEtag {
+		return change, false
+	}
+	change.Change = dto.ChangeModified
+	change.OldSize = sql.NullInt64{Int64: previous.Size, Valid: true}
+	change.OldEtag = previous.Etag
+	return change, true
+}
+
+// recordObjectChange stores the change of a file found by a scan, if any. Errors are only logged: the
+// history misses the change but the catalog is up to date.
+func (s *Service) recordObjectChange(
+	ctx context.Context, bucketID, scanJobID int32, previous *database.S3Object, key string, size int64, etag string,
+) {
+	change, changed := objectChange(previous, key, size, etag)
+	if !changed {
+		return
+	}
+	change.BucketID = bucketID
+	change.ScanJobID = scanJobID
+	if err := s.queries.InsertObjectChange(ctx, change); err != nil {
+		s.log.Error("Failed to record object change",
+			slog.String("key", key),
+			slog.String("change", change.Change),
+			slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,37 @@
+package scanner
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectChange(t *testing.T) {
+	created, changed := objectChange(nil, "a.txt", 10, `"abc"`)
+	assert.True(t, changed)
+	assert.Equal(t, dto.ChangeCreated, created.Change)
+	assert.False(t, created.OldSize.Valid)
+	assert.Equal(t, sql.NullInt64{Int64: 10, Valid: true}, created.NewSize)
+
+	previous := &database.S3Object{Key: "a.txt", Size: 10, Etag: sql.NullString{String: `"abc"`, Valid: true}}
+	_, changed = objectChange(previous, "a.txt", 10, `"abc"`)
+	assert.False(t, changed, "same size and ETag")
+
+	modified, changed := objectChange(previous, "a.txt", 12, `"def"`)
+	assert.True(t, changed)
+	assert.Equal(t, dto.ChangeModified, modified.Change)
+	assert.Equal(t, sql.NullInt64{Int64: 10, Valid: true}, modified.OldSize)
+	assert.Equal(t, `"abc"`, modified.OldEtag.String)
+	assert.Equal(t, `"def"`, modified.NewEtag.String)
+}
+
+func TestScanRunExcluded(t *testing.T) {
+	assert.False(t, scanRun{}.excluded("s3xplorer-exports/data/1.parquet"))
+
+	scan := scanRun{exclude: "s3xplorer-exports/"}
+	assert.True(t, scan.excluded("s3xplorer-exports/data/1.parquet"))
+	assert.False(t, scan.excluded("data/s3xplorer-exports/1.parquet"))
+}
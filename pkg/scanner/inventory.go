@@ -0,0 +1,499 @@
+package scanner
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/md5" //nolint:gosec // S3 Inventory manifests checksum their files with MD5
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/parquet-go/parquet-go"
+	"github.com/sgaunet/s3xplorer/pkg/database"
+)
+
+// inventoryBatchSize is the number of inventory rows written to the catalog at once.
+const inventoryBatchSize = 1000
+
+// Inventory data file formats, as named by the fileFormat of a manifest.
+const (
+	inventoryFormatCSV     = "CSV"
+	inventoryFormatParquet = "Parquet"
+)
+
+var (
+	// ErrNoInventory is returned when a bucket has no inventory report to read.
+	ErrNoInventory = errors.New("no inventory report")
+	// ErrUnsupportedInventory is returned for an inventory report the scanner cannot read, such as ORC reports.
+	ErrUnsupportedInventory = errors.New("unsupported inventory report")
+	// errInventoryChecksum is returned when a data file does not match the MD5 checksum of its manifest.
+	errInventoryChecksum = errors.New("inventory file checksum mismatch")
+)
+
+// inventoryReportDate matches the folders of the reports of an inventory configuration, named after their date.
+var inventoryReportDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}-\d{2}Z/$`)
+
+// inventoryManifest is the manifest.json of an inventory report.
+type inventoryManifest struct {
+	SourceBucket string          `json:"sourceBucket"`
+	FileFormat   string          `json:"fileFormat"`
+	FileSchema   string          `json:"fileSchema"`
+	Files        []inventoryFile `json:"files"`
+}
+
+// inventoryFile is a data file of an inventory report.
+type inventoryFile struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	MD5Checksum string `json:"MD5checksum"`
+}
+
+// inventoryObject is the current version of a file, as read from an inventory report.
+// Fields missing from the report are left empty.
+type inventoryObject struct {
+	Key              string
+	Size             int64
+	LastModified     *time.Time
+	ETag             string
+	StorageClass     string
+	EncryptionStatus string
+	TagCount         *int32
+}
+
+// inventoryParquetRow holds the columns of a Parquet inventory report read by the scanner.
+type inventoryParquetRow struct {
+	Key              string  `parquet:"key"`
+	IsLatest         *bool   `parquet:"is_latest,optional"`
+	IsDeleteMarker   *bool   `parquet:"is_delete_marker,optional"`
+	Size             *int64  `parquet:"size,optional"`
+	LastModifiedDate *int64  `parquet:"last_modified_date,optional"`
+	ETag             *string `parquet:"e_tag,optional"`
+	StorageClass     *string `parquet:"storage_class,optional"`
+	EncryptionStatus *string `parquet:"encryption_status,optional"`
+	TagCount         *int32  `parquet:"tag_count,optional"`
+}
+
+// performObjectScan writes the files of the bucket to the catalog, from its latest inventory report when
+// inventories are enabled, by listing the bucket when it has no report yet. A report the scanner cannot read
+// fails the scan rather than silently listing a bucket that may be too large for it.
+func (s *Service) performObjectScan(
+	ctx context.Context, scan scanRun, objectCount, objectsCreated, objectsUpdated *int,
+) error {
+	if s.cfg.Scan.Inventory.Enable {
+		err := s.performInventoryScan(ctx, scan, objectCount, objectsCreated, objectsUpdated)
+		if !errors.Is(err, ErrNoInventory) {
+			return err
+		}
+		s.log.Warn("Listing the bucket instead of reading its inventory",
+			slog.String("bucket", scan.bucketName),
+			slog.String("reason", err.Error()))
+	}
+	return s.performS3ObjectScan(ctx, scan, objectCount, objectsCreated, objectsUpdated)
+}
+
+// performInventoryScan writes the files of the latest inventory report of the bucket to the catalog, in batches.
+// It returns ErrNoInventory or ErrUnsupportedInventory before writing anything when the report cannot be read.
+func (s *Service) performInventoryScan(
+	ctx context.Context, scan scanRun, objectCount, objectsCreated, objectsUpdated *int,
+) error {
+	manifest, manifestKey, err := s.latestInventoryManifest(ctx, scan.bucketName)
+	if err != nil {
+		return err
+	}
+	if err := checkInventoryFormat(manifest, manifestKey); err != nil {
+		return err
+	}
+
+	s.log.Info("Phase 2: Reading inventory report",
+		slog.String("bucket", scan.bucketName),
+		slog.String("manifest", manifestKey),
+		slog.Int("files", len(manifest.Files)))
+	return s.readInventory(ctx, manifest, func(objects []inventoryObject) error {
+		objects = slices.DeleteFunc(objects, func(obj inventoryObject) bool { return scan.excluded(obj.Key) })
+		if len(objects) == 0 {
+			return nil
+		}
+		created, err := s.storeInventoryObjects(ctx, scan, objects)
+		if err != nil {
+			return err
+		}
+		*objectCount += len(objects)
+		*objectsCreated += created
+		*objectsUpdated += len(objects) - created
+
+		_, err = s.queries.UpdateScanJobProgress(ctx, database.UpdateScanJobProgressParams{
+			ID:             scan.jobID,
+			ObjectsScanned: sql.NullInt32{Int32: safeInt32(*objectCount), Valid: true},
+		})
+		if err != nil {
+			s.log.Error("Failed to update scan job progress", slog.String("error", err.Error()))
+		}
+		return nil
+	})
+}
+
+// CheckInventory reads the manifest of the latest inventory report of the bucket, and returns
+// ErrUnsupportedInventory when the scanner cannot read its data files. A bucket without a report passes.
+func (s *Service) CheckInventory(ctx context.Context, bucketName string) error {
+	manifest, manifestKey, err := s.latestInventoryManifest(ctx, bucketName)
+	if errors.Is(err, ErrNoInventory) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return checkInventoryFormat(manifest, manifestKey)
+}
+
+// checkInventoryFormat returns ErrUnsupportedInventory for the data files of an ORC report.
+func checkInventoryFormat(manifest *inventoryManifest, manifestKey string) error {
+	if manifest.FileFormat != inventoryFormatCSV && manifest.FileFormat != inventoryFormatParquet {
+		return fmt.Errorf("%w: %s lists %s files, configure the inventory with CSV or Parquet",
+			ErrUnsupportedInventory, manifestKey, manifest.FileFormat)
+	}
+	return nil
+}
+
+// latestInventoryManifest reads the manifest of the latest report of the configured inventory of the bucket,
+// and returns it with its key.
+func (s *Service) latestInventoryManifest(ctx context.Context, bucketName string) (*inventoryManifest, string, error) {
+	inventory := s.cfg.Scan.Inventory
+	if inventory.Bucket == "" || inventory.ID == "" {
+		return nil, "", fmt.Errorf("%w: the inventory bucket and id are not configured", ErrNoInventory)
+	}
+
+	root := inventory.Prefix + bucketName + "/" + inventory.ID + "/"
+	latest := ""
+	paginator := s3.NewListObjectsV2Paginator(s.s3Client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(inventory.Bucket),
+		Prefix:    aws.String(root),
+		Delimiter: aws.String("/"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list inventory reports: %w", err)
+		}
+		for _, p := range page.CommonPrefixes {
+			name := strings.TrimPrefix(aws.ToString(p.Prefix), root)
+			if inventoryReportDate.MatchString(name) && name > latest {
+				latest = name
+			}
+		}
+	}
+	if latest == "" {
+		return nil, "", fmt.Errorf("%w under s3://%s/%s", ErrNoInventory, inventory.Bucket, root)
+	}
+
+	key := root + latest + "manifest.json"
+	out, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(inventory.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read inventory manifest %s: %w", key, err)
+	}
+	defer func() { _ = out.Body.Close() }()
+
+	var manifest inventoryManifest
+	if err := json.NewDecoder(out.Body).Decode(&manifest); err != nil {
+		return nil, "", fmt.Errorf("failed to decode inventory manifest %s: %w", key, err)
+	}
+	if manifest.SourceBucket != bucketName {
+		return nil, "", fmt.Errorf("%w: %s describes bucket %s", ErrNoInventory, key, manifest.SourceBucket)
+	}
+	return &manifest, key, nil
+}
+
+// readInventory reads the current versions of the files of the report under the configured prefix,
+// and passes them to fn inventoryBatchSize at a time. The slice passed to fn is reused by the next batch.
+func (s *Service) readInventory(ctx context.Context, manifest *inventoryManifest, fn func([]inventoryObject) error) error {
+	batch := make([]inventoryObject, 0, inventoryBatchSize)
+	add := func(obj inventoryObject) error {
+		if !strings.HasPrefix(obj.Key, s.cfg.S3.Prefix) {
+			return nil
+		}
+		batch = append(batch, obj)
+		if len(batch) < inventoryBatchSize {
+			return nil
+		}
+		err := fn(batch)
+		batch = batch[:0]
+		return err
+	}
+
+	for _, file := range manifest.Files {
+		if err := s.readInventoryFile(ctx, manifest, file, add); err != nil {
+			return fmt.Errorf("failed to read inventory file %s: %w", file.Key, err)
+		}
+	}
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
+// readInventoryFile passes the current versions of the files of one data file of the report to fn.
+// CSV files are streamed; Parquet files are downloaded to a temporary file, as their footer is read first.
+func (s *Service) readInventoryFile(
+	ctx context.Context, manifest *inventoryManifest, file inventoryFile, fn func(inventoryObject) error,
+) error {
+	out, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.cfg.Scan.Inventory.Bucket),
+		Key:    aws.String(file.Key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+	defer func() { _ = out.Body.Close() }()
+
+	checksum := md5.New() //nolint:gosec // see the import
+	body := io.TeeReader(out.Body, checksum)
+
+	if manifest.FileFormat == inventoryFormatCSV {
+		if err := readInventoryCSV(body, manifest.FileSchema, fn); err != nil {
+			return err
+		}
+		// Read what the gzip reader left, such as padding, before checking the checksum
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return fmt.Errorf("failed to download: %w", err)
+		}
+		return verifyInventoryChecksum(checksum, file.MD5Checksum)
+	}
+
+	tmp, err := os.CreateTemp("", "s3xplorer-inventory-*.parquet")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	size, err := io.Copy(tmp, body)
+	if err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+	if err := verifyInventoryChecksum(checksum, file.MD5Checksum); err != nil {
+		return err
+	}
+	return readInventoryParquet(tmp, size, fn)
+}
+
+// verifyInventoryChecksum compares the MD5 of a data file to the checksum of the manifest, when it has one.
+func verifyInventoryChecksum(checksum hash.Hash, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	if actual := hex.EncodeToString(checksum.Sum(nil)); actual != expected {
+		return fmt.Errorf("%w: got %s, want %s", errInventoryChecksum, actual, expected)
+	}
+	return nil
+}
+
+// readInventoryCSV reads a gzipped CSV data file, whose columns are the fields named by schema.
+// Keys are URL-encoded and ETags unquoted in CSV reports.
+func readInventoryCSV(r io.Reader, schema string, fn func(inventoryObject) error) error {
+	fields := strings.Split(schema, ",")
+	columns := make(map[string]int, len(fields))
+	for i, name := range fields {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["Key"]; !ok {
+		return fmt.Errorf("%w: no Key field in %q", ErrUnsupportedInventory, schema)
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to decompress: %w", err)
+	}
+	reader := csv.NewReader(gz)
+	reader.FieldsPerRecord = len(fields)
+	reader.ReuseRecord = true
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse CSV: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return record[i]
+			}
+			return ""
+		}
+		if field("IsLatest") == "false" || field("IsDeleteMarker") == "true" {
+			continue
+		}
+
+		obj, err := inventoryCSVObject(field)
+		if err != nil {
+			return err
+		}
+		if err := fn(obj); err != nil {
+			return err
+		}
+	}
+}
+
+// inventoryCSVObject converts the fields of a CSV row.
+func inventoryCSVObject(field func(string) string) (inventoryObject, error) {
+	key, err := url.QueryUnescape(field("Key"))
+	if err != nil {
+		return inventoryObject{}, fmt.Errorf("invalid key %q: %w", field("Key"), err)
+	}
+	obj := inventoryObject{
+		Key:              key,
+		ETag:             quoteETag(field("ETag")),
+		StorageClass:     field("StorageClass"),
+		EncryptionStatus: field("EncryptionStatus"),
+	}
+	if v := field("Size"); v != "" {
+		if obj.Size, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return inventoryObject{}, fmt.Errorf("invalid size of %s: %w", key, err)
+		}
+	}
+	if v := field("LastModifiedDate"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return inventoryObject{}, fmt.Errorf("invalid last modification of %s: %w", key, err)
+		}
+		obj.LastModified = &t
+	}
+	if v := field("TagCount"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return inventoryObject{}, fmt.Errorf("invalid tag count of %s: %w", key, err)
+		}
+		count := int32(n) //nolint:gosec // parsed as 32 bits
+		obj.TagCount = &count
+	}
+	return obj, nil
+}
+
+// readInventoryParquet reads a Parquet data file. Columns missing from the file are left empty.
+func readInventoryParquet(r io.ReaderAt, size int64, fn func(inventoryObject) error) error {
+	file, err := parquet.OpenFile(r, size)
+	if err != nil {
+		return fmt.Errorf("failed to open Parquet file: %w", err)
+	}
+	reader := parquet.NewGenericReader[inventoryParquetRow](file)
+	defer func() { _ = reader.Close() }()
+
+	rows := make([]inventoryParquetRow, inventoryBatchSize)
+	for {
+		clear(rows)
+		n, err := reader.Read(rows)
+		for _, row := range rows[:n] {
+			if (row.IsLatest != nil && !*row.IsLatest) || (row.IsDeleteMarker != nil && *row.IsDeleteMarker) {
+				continue
+			}
+			obj := inventoryObject{
+				Key:              row.Key,
+				ETag:             quoteETag(aws.ToString(row.ETag)),
+				StorageClass:     aws.ToString(row.StorageClass),
+				EncryptionStatus: aws.ToString(row.EncryptionStatus),
+				TagCount:         row.TagCount,
+			}
+			if row.Size != nil {
+				obj.Size = *row.Size
+			}
+			if row.LastModifiedDate != nil {
+				t := time.UnixMilli(*row.LastModifiedDate).UTC()
+				obj.LastModified = &t
+			}
+			if err := fn(obj); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read Parquet rows: %w", err)
+		}
+	}
+}
+
+// quoteETag quotes an ETag as ListObjectsV2 returns it, so that inventory and listing scans agree.
+func quoteETag(etag string) string {
+	if etag == "" || strings.HasPrefix(etag, `"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// storeInventoryObjects upserts a batch of files and their parent folders, unmarking them for deletion and
+// recording their changes for the scan job. It returns the number of files new to the catalog.
+func (s *Service) storeInventoryObjects(ctx context.Context, scan scanRun, objects []inventoryObject) (int, error) {
+	folders := database.UpsertInventoryFoldersParams{BucketID: scan.bucketID}
+	params := database.UpsertInventoryObjectsParams{
+		BucketID: scan.bucketID, ScanJobID: scan.jobID, RecordChanges: scan.recordChanges,
+	}
+	seen := make(map[string]struct{})
+	for _, obj := range objects {
+		parent := ""
+		for i := range len(obj.Key) {
+			if obj.Key[i] != '/' {
+				continue
+			}
+			folder := obj.Key[:i+1]
+			if _, ok := seen[folder]; !ok {
+				seen[folder] = struct{}{}
+				folders.Keys = append(folders.Keys, folder)
+				folders.Prefixes = append(folders.Prefixes, parent)
+			}
+			parent = folder
+		}
+
+		lastModified, tagCount := "", ""
+		if obj.LastModified != nil {
+			lastModified = obj.LastModified.Format(time.RFC3339Nano)
+		}
+		if obj.TagCount != nil {
+			tagCount = strconv.Itoa(int(*obj.TagCount))
+		}
+		params.Keys = append(params.Keys, obj.Key)
+		params.Sizes = append(params.Sizes, obj.Size)
+		params.LastModified = append(params.LastModified, lastModified)
+		params.Etags = append(params.Etags, obj.ETag)
+		params.StorageClasses = append(params.StorageClasses, obj.StorageClass)
+		params.EncryptionStatuses = append(params.EncryptionStatuses, obj.EncryptionStatus)
+		params.TagCounts = append(params.TagCounts, tagCount)
+		params.Prefixes = append(params.Prefixes, parent)
+	}
+
+	if len(folders.Keys) > 0 {
+		if err := s.queries.UpsertInventoryFolders(ctx, folders); err != nil {
+			return 0, fmt.Errorf("failed to store folders: %w", err)
+		}
+	}
+	created, err := s.queries.UpsertInventoryObjects(ctx, params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store files: %w", err)
+	}
+	count := 0
+	for _, c := range created {
+		if c {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,211 @@
+package scanner
+
+import (
+	"context"
+	"encoding/xml"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// inventoryRoot holds the buckets served by the stand-in, one folder per bucket.
+const inventoryRoot = "testdata/inventory"
+
+func inventoryConfig() config.Config {
+	return config.Config{Scan: config.ScanConfig{Inventory: config.InventoryConfig{
+		Enable: true, Bucket: "inventory-bucket", Prefix: "reports/", ID: "daily",
+	}}}
+}
+
+// listBucketResult is the ListObjectsV2 answer of the stand-in.
+type listBucketResult struct {
+	XMLName        xml.Name `xml:"ListBucketResult"`
+	Name           string
+	Prefix         string
+	IsTruncated    bool
+	Contents       []listedObject
+	CommonPrefixes []listedPrefix
+}
+
+type listedObject struct{ Key string }
+
+type listedPrefix struct{ Prefix string }
+
+// newInventoryStandIn serves the files of inventoryRoot like an S3 server: GetObject and a single page
+// of ListObjectsV2, with its delimiter.
+func newInventoryStandIn(t *testing.T, cfg config.Config) *Service {
+	t.Helper()
+	return newTagTestService(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		if r.URL.Query().Get("list-type") != "2" {
+			data, err := os.ReadFile(filepath.Join(inventoryRoot, bucket, filepath.FromSlash(key)))
+			if err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`))
+				return
+			}
+			_, _ = w.Write(data)
+			return
+		}
+
+		prefix, delimiter := r.URL.Query().Get("prefix"), r.URL.Query().Get("delimiter")
+		result := listBucketResult{Name: bucket, Prefix: prefix}
+		seen := map[string]bool{}
+		dir := filepath.Join(inventoryRoot, bucket)
+		_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, _ := filepath.Rel(dir, p)
+			key := filepath.ToSlash(rel)
+			if !strings.HasPrefix(key, prefix) {
+				return nil
+			}
+			if i := strings.Index(key[len(prefix):], delimiter); delimiter != "" && i >= 0 {
+				common := key[:len(prefix)+i+len(delimiter)]
+				if !seen[common] {
+					seen[common] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, listedPrefix{Prefix: common})
+				}
+				return nil
+			}
+			result.Contents = append(result.Contents, listedObject{Key: key})
+			return nil
+		})
+		_ = xml.NewEncoder(w).Encode(result)
+	})
+}
+
+// collectInventory reads the latest report of bucket.
+func collectInventory(t *testing.T, s *Service, bucket string) []inventoryObject {
+	t.Helper()
+	manifest, _, err := s.latestInventoryManifest(context.Background(), bucket)
+	require.NoError(t, err)
+
+	var objects []inventoryObject
+	err = s.readInventory(context.Background(), manifest, func(batch []inventoryObject) error {
+		objects = append(objects, batch...)
+		return nil
+	})
+	require.NoError(t, err)
+	return objects
+}
+
+func TestLatestInventoryManifest(t *testing.T) {
+	s := newInventoryStandIn(t, inventoryConfig())
+
+	manifest, key, err := s.latestInventoryManifest(context.Background(), "csv-bucket")
+	require.NoError(t, err)
+	assert.Equal(t, "reports/csv-bucket/daily/2026-10-17T01-00Z/manifest.json", key)
+	assert.Equal(t, inventoryFormatCSV, manifest.FileFormat)
+	assert.Len(t, manifest.Files, 2)
+
+	_, _, err = s.latestInventoryManifest(context.Background(), "listed-bucket")
+	require.ErrorIs(t, err, ErrNoInventory)
+
+	cfg := inventoryConfig()
+	cfg.Scan.Inventory.ID = ""
+	_, _, err = newInventoryStandIn(t, cfg).latestInventoryManifest(context.Background(), "csv-bucket")
+	require.ErrorIs(t, err, ErrNoInventory)
+}
+
+func TestReadInventory_CSV(t *testing.T) {
+	s := newInventoryStandIn(t, inventoryConfig())
+
+	reportModified := time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC)
+	logModified := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	emptyModified := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	tagCount := int32(2)
+	// Older versions and delete markers are left out
+	assert.Equal(t, []inventoryObject{
+		{
+			Key: "data/report 2026.csv", Size: 1024, LastModified: &reportModified,
+			ETag: `"0cc175b9c0f1b6a831c399e269772661"`, StorageClass: "STANDARD", EncryptionStatus: "SSE-S3",
+			TagCount: &tagCount,
+		},
+		{
+			Key: "logs/2026/10/app.log", Size: 2048, LastModified: &logModified,
+			ETag: `"d41d8cd98f00b204e9800998ecf8427e-2"`, StorageClass: "GLACIER", EncryptionStatus: "SSE-KMS",
+		},
+		{
+			Key: "empty.txt", LastModified: &emptyModified,
+			ETag: `"d41d8cd98f00b204e9800998ecf8427e"`, StorageClass: "STANDARD", EncryptionStatus: "NOT-SSE",
+		},
+	}, collectInventory(t, s, "csv-bucket"))
+}
+
+func TestReadInventory_Prefix(t *testing.T) {
+	cfg := inventoryConfig()
+	cfg.S3.Prefix = "logs/"
+	s := newInventoryStandIn(t, cfg)
+
+	objects := collectInventory(t, s, "csv-bucket")
+	require.Len(t, objects, 1)
+	assert.Equal(t, "logs/2026/10/app.log", objects[0].Key)
+}
+
+func TestReadInventory_Parquet(t *testing.T) {
+	s := newInventoryStandIn(t, inventoryConfig())
+
+	modified := time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC)
+	assert.Equal(t, []inventoryObject{{
+		Key: "images/cat photo.jpg", Size: 4096, LastModified: &modified,
+		ETag: `"900150983cd24fb0d6963f7d28e17f72"`, StorageClass: "STANDARD_IA", EncryptionStatus: "SSE-S3",
+	}}, collectInventory(t, s, "parquet-bucket"))
+}
+
+func TestReadInventory_Checksum(t *testing.T) {
+	s := newInventoryStandIn(t, inventoryConfig())
+	manifest, _, err := s.latestInventoryManifest(context.Background(), "csv-bucket")
+	require.NoError(t, err)
+	manifest.Files[1].MD5Checksum = "00000000000000000000000000000000"
+
+	err = s.readInventory(context.Background(), manifest, func([]inventoryObject) error { return nil })
+	require.ErrorIs(t, err, errInventoryChecksum)
+	assert.Contains(t, err.Error(), path.Base(manifest.Files[1].Key))
+}
+
+func TestPerformInventoryScan_Unsupported(t *testing.T) {
+	// The stand-in has no database: the scan must stop before writing to the catalog
+	s := newInventoryStandIn(t, inventoryConfig())
+	var count, created, updated int
+
+	scan := scanRun{bucketName: "orc-bucket", bucketID: 1, jobID: 1, recordChanges: true}
+	err := s.performInventoryScan(context.Background(), scan, &count, &created, &updated)
+	require.ErrorIs(t, err, ErrUnsupportedInventory)
+	assert.Zero(t, count)
+}
+
+func TestPerformObjectScan_UnsupportedInventoryIsNotListed(t *testing.T) {
+	// Listing the bucket instead would need the database the stand-in does not have
+	s := newInventoryStandIn(t, inventoryConfig())
+	var count, created, updated int
+
+	scan := scanRun{bucketName: "orc-bucket", bucketID: 1, jobID: 1, recordChanges: true}
+	err := s.performObjectScan(context.Background(), scan, &count, &created, &updated)
+	require.ErrorIs(t, err, ErrUnsupportedInventory)
+}
+
+func TestCheckInventory(t *testing.T) {
+	s := newInventoryStandIn(t, inventoryConfig())
+
+	require.ErrorIs(t, s.CheckInventory(context.Background(), "orc-bucket"), ErrUnsupportedInventory)
+	require.NoError(t, s.CheckInventory(context.Background(), "csv-bucket"))
+	require.NoError(t, s.CheckInventory(context.Background(), "parquet-bucket"))
+	require.NoError(t, s.CheckInventory(context.Background(), "unknown-bucket"), "no report yet")
+}
+
+func TestQuoteETag(t *testing.T) {
+	assert.Equal(t, `"abc"`, quoteETag("abc"))
+	assert.Equal(t, `"abc"`, quoteETag(`"abc"`))
+	assert.Empty(t, quoteETag(""))
+}
@@ -19,6 +19,7 @@ import (
 	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/sgaunet/s3xplorer/pkg/config"
 	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dbinit"
 )
 
 // ErrNoBucketConfigured is returned when no bucket is configured for scanning.
@@ -28,7 +29,7 @@ var ErrNoBucketConfigured = errors.New("no bucket configured for scanning")
 type Service struct {
 	s3Client *s3.Client
 	db       *sql.DB
-	queries  *database.Queries
+	queries  database.Querier
 	cfg      config.Config
 	log      *slog.Logger
 }
@@ -52,7 +53,7 @@ func NewService(cfg config.Config, s3Client *s3.Client, db *sql.DB) *Service {
 	return &Service{
 		s3Client: s3Client,
 		db:       db,
-		queries:  database.New(db),
+		queries:  dbinit.NewQuerier(cfg.Database.Driver(), db),
 		cfg:      cfg,
 		log:      slog.New(slog.DiscardHandler),
 	}
@@ -134,10 +135,24 @@ func (s *Service) ScanBucket(ctx context.Context, bucketName string) error {
 	)
 
 	// Phase 2: Scan and process all S3 objects and folders
-	scanErr = s.performS3ObjectScan(ctx, bucketName, bucket.ID, scanJob.ID, &objectCount, &objectsCreated, &objectsUpdated)
+	scan := s.newScanRun(ctx, bucketName, bucket.ID, scanJob.ID)
+	scanErr = s.performObjectScan(ctx, scan, &objectCount, &objectsCreated, &objectsUpdated)
 
-	// Phase 3: Delete objects that are still marked for deletion (if deletion sync is enabled)
-	objectsDeleted = s.performDeletionCleanup(ctx, bucketName, bucket.ID)
+	// Phase 3: Delete objects that are still marked for deletion (if deletion sync is enabled).
+	// A failed scan did not reach every object: the marked ones are not known to be deleted.
+	if scanErr == nil {
+		objectsDeleted = s.performDeletionCleanup(ctx, bucketName, bucket.ID, scanJob.ID)
+	}
+
+	// Phase 4: Fetch the tags of new and changed objects (if tag indexing is enabled)
+	if scanErr == nil {
+		s.performTagIndexPhase(ctx, bucketName, bucket.ID)
+	}
+
+	// Phase 5: Cache the bucket configuration shown on the bucket configuration page
+	if scanErr == nil {
+		s.performBucketConfigPhase(ctx, bucketName, bucket.ID, scanJob.ID)
+	}
 
 	// Final progress update
 	_, err = s.queries.UpdateScanJobProgress(ctx, database.UpdateScanJobProgressParams{
@@ -542,9 +557,10 @@ func (s *Service) logBulkScanResults(stats bucketScanStats) {
 		slog.Int("total_objects_deleted", stats.totalObjectsDeleted))
 }
 
-// processObject processes a single S3 object and saves it to the database
+// processObject processes a single S3 object and saves it to the database, recording its change for the scan job.
 // Returns true if object was newly created, false if it was updated.
-func (s *Service) processObject(ctx context.Context, bucketID int32, obj types.Object) (bool, error) {
+func (s *Service) processObject(ctx context.Context, scan scanRun, obj types.Object) (bool, error) {
+	bucketID := scan.bucketID
 	key := aws.ToString(obj.Key)
 	size := obj.Size
 	lastModified := obj.LastModified
@@ -567,11 +583,17 @@ func (s *Service) processObject(ctx context.Context, bucketID int32, obj types.O
 	}
 
 	// Check if object already exists to determine if it's new or updated
-	_, err := s.queries.GetS3Object(ctx, database.GetS3ObjectParams{
+	existing, err := s.queries.GetS3Object(ctx, database.GetS3ObjectParams{
 		BucketID: bucketID,
 		Key:      key,
 	})
 	isNew := err != nil // If we get an error, the object doesn't exist
+	var previous *database.S3Object
+	if err == nil {
+		previous = &existing
+	}
+	// Other errors leave the previous state unknown: the change is not recorded
+	recordChange := scan.recordChanges && (err == nil || errors.Is(err, sql.ErrNoRows))
 
 	// Create or update the object
 	_, err = s.queries.CreateS3Object(ctx, database.CreateS3ObjectParams{
@@ -587,6 +609,9 @@ func (s *Service) processObject(ctx context.Context, bucketID int32, obj types.O
 	if err != nil {
 		return false, fmt.Errorf("failed to create S3 object: %w", err)
 	}
+	if recordChange {
+		s.recordObjectChange(ctx, bucketID, scan.jobID, previous, key, *size, etag)
+	}
 
 	// Unmark the object for deletion since we found it in S3 (if deletion sync is enabled)
 	if s.cfg.Scan.EnableDeletionSync {
@@ -798,8 +823,8 @@ func (s *Service) finalizeScanJob(
 	}
 }
 
-// performDeletionCleanup handles the deletion of objects marked for removal.
-func (s *Service) performDeletionCleanup(ctx context.Context, bucketName string, bucketID int32) int {
+// performDeletionCleanup handles the deletion of objects marked for removal, recording them as deleted by the scan job.
+func (s *Service) performDeletionCleanup(ctx context.Context, bucketName string, bucketID, scanJobID int32) int {
 	if !s.cfg.Scan.EnableDeletionSync {
 		s.log.Info("Deletion sync disabled - skipping Phase 3", slog.String("bucket", bucketName))
 		return 0
@@ -817,6 +842,12 @@ func (s *Service) performDeletionCleanup(ctx context.Context, bucketName string,
 		s.log.Info("Deleting objects no longer in S3",
 			slog.String("bucket", bucketName),
 			slog.Int("count", objectsDeleted))
+		if err := s.queries.RecordDeletedObjectChanges(ctx, database.RecordDeletedObjectChangesParams{
+			BucketID:  bucketID,
+			ScanJobID: scanJobID,
+		}); err != nil {
+			s.log.Error("Failed to record deleted objects", slog.String("error", err.Error()))
+		}
 		if err := s.queries.DeleteMarkedObjects(ctx, bucketID); err != nil {
 			s.log.Error("Failed to delete marked objects", slog.String("error", err.Error()))
 			// Don't fail the entire scan if deletion cleanup fails
@@ -829,11 +860,11 @@ func (s *Service) performDeletionCleanup(ctx context.Context, bucketName string,
 
 // performS3ObjectScan scans and processes all S3 objects and folders.
 func (s *Service) performS3ObjectScan(
-	ctx context.Context, bucketName string, bucketID, scanJobID int32, objectCount, objectsCreated, objectsUpdated *int,
+	ctx context.Context, scan scanRun, objectCount, objectsCreated, objectsUpdated *int,
 ) error {
 	// Use ListObjectsV2 to get all objects
 	paginator := s3.NewListObjectsV2Paginator(s.s3Client, &s3.ListObjectsV2Input{
-		Bucket: aws.String(bucketName),
+		Bucket: aws.String(scan.bucketName),
 		Prefix: aws.String(s.cfg.S3.Prefix),
 	})
 
@@ -843,8 +874,8 @@ func (s *Service) performS3ObjectScan(
 			return fmt.Errorf("failed to list objects: %w", err)
 		}
 
-		s.processPageObjects(ctx, bucketID, scanJobID, page.Contents, objectCount, objectsCreated, objectsUpdated)
-		s.processPageFolders(ctx, bucketID, page.CommonPrefixes, objectsCreated, objectsUpdated)
+		s.processPageObjects(ctx, scan, page.Contents, objectCount, objectsCreated, objectsUpdated)
+		s.processPageFolders(ctx, scan.bucketID, page.CommonPrefixes, objectsCreated, objectsUpdated)
 	}
 
 	return nil
@@ -852,11 +883,14 @@ func (s *Service) performS3ObjectScan(
 
 // processPageObjects processes a batch of S3 objects from a page.
 func (s *Service) processPageObjects(
-	ctx context.Context, bucketID, scanJobID int32, objects []types.Object,
+	ctx context.Context, scan scanRun, objects []types.Object,
 	objectCount, objectsCreated, objectsUpdated *int,
 ) {
 	for _, obj := range objects {
-		isNew, err := s.processObject(ctx, bucketID, obj)
+		if scan.excluded(aws.ToString(obj.Key)) {
+			continue
+		}
+		isNew, err := s.processObject(ctx, scan, obj)
 		if err != nil {
 			s.log.Error("Failed to process object",
 				slog.String("key", aws.ToString(obj.Key)),
@@ -875,7 +909,7 @@ func (s *Service) processPageObjects(
 		// Update progress every 100 objects
 		if *objectCount%100 == 0 {
 			_, err := s.queries.UpdateScanJobProgress(ctx, database.UpdateScanJobProgressParams{
-				ID:             scanJobID,
+				ID:             scan.jobID,
 				ObjectsScanned: sql.NullInt32{Int32: safeInt32(*objectCount), Valid: true},
 			})
 			if err != nil {
@@ -0,0 +1,171 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/sgaunet/s3xplorer/pkg/database"
+	"github.com/sgaunet/s3xplorer/pkg/dbinit"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+)
+
+// tagIndexBatchSize is the number of files read from the catalog per batch of the tag phase.
+const tagIndexBatchSize = 100
+
+// errTagIndexDenied stops the tag phase: every other object would fail the same way.
+var errTagIndexDenied = errors.New("tag indexing is not allowed")
+
+// indexedTag is one row of object_tags.
+type indexedTag struct {
+	Source string
+	Key    string
+	Value  string
+}
+
+// performTagIndexPhase fetches the tags, and the configured metadata keys, of the files whose ETag changed
+// since their tags were last read. S3 calls are limited to Scan.TagRequestsPerSecond. Objects that fail are
+// retried on the next scan. It returns the number of objects indexed.
+func (s *Service) performTagIndexPhase(ctx context.Context, bucketName string, bucketID int32) int {
+	if !s.cfg.Scan.IndexTags {
+		return 0
+	}
+	s.log.Info("Indexing object tags", slog.String("bucket", bucketName))
+
+	limiter := time.NewTicker(time.Second / time.Duration(max(1, s.cfg.Scan.TagRequestsPerSecond)))
+	defer limiter.Stop()
+
+	indexed, failed := 0, 0
+	afterID := int32(0)
+	for {
+		objects, err := s.queries.ListObjectsWithStaleTags(ctx, database.ListObjectsWithStaleTagsParams{
+			BucketID: bucketID,
+			AfterID:  afterID,
+			Limit:    tagIndexBatchSize,
+		})
+		if err != nil {
+			s.log.Error("Failed to list objects to tag", slog.String("error", err.Error()))
+			break
+		}
+		if len(objects) == 0 {
+			break
+		}
+
+		for _, obj := range objects {
+			afterID = obj.ID
+			tags, err := s.fetchObjectTags(ctx, bucketName, obj.Key, limiter.C)
+			if err == nil {
+				err = s.storeObjectTags(ctx, obj, tags)
+			}
+			switch {
+			case err == nil:
+				indexed++
+			case ctx.Err() != nil || errors.Is(err, errTagIndexDenied):
+				s.log.Error("Tag indexing stopped", slog.String("bucket", bucketName), slog.String("error", err.Error()))
+				return indexed
+			default:
+				failed++
+				s.log.Warn("Failed to index object tags", slog.String("key", obj.Key), slog.String("error", err.Error()))
+			}
+		}
+	}
+
+	s.log.Info("Object tags indexed",
+		slog.String("bucket", bucketName),
+		slog.Int("objects_indexed", indexed),
+		slog.Int("objects_failed", failed))
+	return indexed
+}
+
+// fetchObjectTags reads the tags of key, and its user metadata when metadata keys are indexed.
+// It waits for a tick of limiter before each S3 call.
+func (s *Service) fetchObjectTags(ctx context.Context, bucketName, key string, limiter <-chan time.Time) ([]indexedTag, error) {
+	if err := waitTick(ctx, limiter); err != nil {
+		return nil, err
+	}
+	out, err := s.s3Client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, s.tagIndexError("GetObjectTagging", err)
+	}
+	tags := make([]indexedTag, 0, len(out.TagSet))
+	for _, t := range out.TagSet {
+		tags = append(tags, indexedTag{Source: dto.TagSourceTag, Key: aws.ToString(t.Key), Value: aws.ToString(t.Value)})
+	}
+
+	if len(s.cfg.Scan.MetadataKeys) == 0 {
+		return tags, nil
+	}
+	if err := waitTick(ctx, limiter); err != nil {
+		return nil, err
+	}
+	head, err := s.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, s.tagIndexError("HeadObject", err)
+	}
+	for _, k := range s.cfg.Scan.MetadataKeys {
+		if value, ok := head.Metadata[k]; ok {
+			tags = append(tags, indexedTag{Source: dto.TagSourceMetadata, Key: k, Value: value})
+		}
+	}
+	return tags, nil
+}
+
+// tagIndexError wraps an S3 error of the tag phase, as errTagIndexDenied when access is denied.
+func (s *Service) tagIndexError(operation string, err error) error {
+	if s.classifyBucketError(err) == ErrorTypeAccessDenied {
+		return fmt.Errorf("%w: %s: %w", errTagIndexDenied, operation, err)
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
+
+// storeObjectTags replaces the indexed tags of obj and records the ETag they were read for.
+func (s *Service) storeObjectTags(ctx context.Context, obj database.ListObjectsWithStaleTagsRow, tags []indexedTag) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	qtx := dbinit.NewQuerier(s.cfg.Database.Driver(), tx)
+
+	if err := qtx.DeleteObjectTags(ctx, database.DeleteObjectTagsParams{ObjectID: obj.ID}); err != nil {
+		return fmt.Errorf("failed to clear tags: %w", err)
+	}
+	for _, t := range tags {
+		if err := qtx.InsertObjectTag(ctx, database.InsertObjectTagParams{
+			ObjectID: obj.ID,
+			Source:   t.Source,
+			Key:      t.Key,
+			Value:    t.Value,
+		}); err != nil {
+			return fmt.Errorf("failed to store tag %s: %w", t.Key, err)
+		}
+	}
+	if err := qtx.SetObjectTagsEtag(ctx, database.SetObjectTagsEtagParams{ID: obj.ID, TagsEtag: obj.Etag}); err != nil {
+		return fmt.Errorf("failed to record tags ETag: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tags: %w", err)
+	}
+	return nil
+}
+
+// waitTick blocks until the next tick of limiter or the end of ctx.
+func waitTick(ctx context.Context, limiter <-chan time.Time) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("tag indexing interrupted: %w", ctx.Err())
+	case <-limiter:
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/s3svc/s3svctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTagTestService(t *testing.T, cfg config.Config, handler http.HandlerFunc) *Service {
+	t.Helper()
+
+	return NewService(cfg, s3svctest.NewClient(t, handler), nil)
+}
+
+func ticks() <-chan time.Time {
+	c := make(chan time.Time, 2)
+	c <- time.Now()
+	c <- time.Now()
+	return c
+}
+
+func TestFetchObjectTags(t *testing.T) {
+	cfg := config.Config{Scan: config.ScanConfig{IndexTags: true, MetadataKeys: []string{"owner", "missing"}}}
+	s := newTagTestService(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("X-Amz-Meta-Owner", "alice")
+			w.Header().Set("X-Amz-Meta-Other", "ignored")
+			return
+		}
+		fmt.Fprint(w, `<Tagging><TagSet><Tag><Key>project</Key><Value>atlas</Value></Tag></TagSet></Tagging>`)
+	})
+
+	tags, err := s.fetchObjectTags(context.Background(), "bucket", "doc.txt", ticks())
+	require.NoError(t, err)
+	assert.Equal(t, []indexedTag{
+		{Source: dto.TagSourceTag, Key: "project", Value: "atlas"},
+		{Source: dto.TagSourceMetadata, Key: "owner", Value: "alice"},
+	}, tags)
+}
+
+func TestFetchObjectTags_AccessDenied(t *testing.T) {
+	s := newTagTestService(t, config.Config{}, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>denied</Message></Error>`)
+	})
+
+	_, err := s.fetchObjectTags(context.Background(), "bucket", "doc.txt", ticks())
+	require.ErrorIs(t, err, errTagIndexDenied)
+}
+
+func TestFetchObjectTags_Interrupted(t *testing.T) {
+	s := newTagTestService(t, config.Config{}, func(http.ResponseWriter, *http.Request) {})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.fetchObjectTags(ctx, "bucket", "doc.txt", make(chan time.Time))
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -19,11 +19,22 @@ type Scheduler struct {
 	cfg     config.Config
 	log     *slog.Logger
 	db      *sql.DB
+	jobs    []job
+}
+
+// job is a scheduled task registered with AddJob.
+type job struct {
+	name     string
+	schedule string
+	run      func(ctx context.Context)
 }
 
 // NewScheduler creates a new scheduler instance.
+// Schedules accept an optional leading seconds field, so both "0 2 * * *" and "0 0 2 * * *" are valid.
 func NewScheduler(cfg config.Config, db *sql.DB, scannerSvc *scanner.Service) *Scheduler {
-	c := cron.New()
+	c := cron.New(cron.WithParser(cron.NewParser(
+		cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+	)))
 	return &Scheduler{
 		cron:    c,
 		scanner: scannerSvc,
@@ -38,27 +49,45 @@ func (s *Scheduler) SetLogger(log *slog.Logger) {
 	s.log = log
 }
 
-// Start starts the scheduler and adds the scan job.
+// AddJob registers a task run on schedule once the scheduler is started.
+func (s *Scheduler) AddJob(name, schedule string, run func(ctx context.Context)) {
+	s.jobs = append(s.jobs, job{name: name, schedule: schedule, run: run})
+}
+
+// Start starts the scheduler with the scan job, if enabled, and the jobs registered with AddJob.
 func (s *Scheduler) Start(ctx context.Context) error {
-	if !s.cfg.Scan.EnableBackgroundScan {
+	if s.cfg.Scan.EnableBackgroundScan {
+		// Add the scanning job
+		_, err := s.cron.AddFunc(s.cfg.Scan.CronSchedule, func() {
+			s.log.Info("Starting scheduled S3 scan")
+			if err := s.scanner.ScanBucket(ctx, s.cfg.S3.Bucket); err != nil {
+				s.log.Error("Scheduled scan failed", slog.String("error", err.Error()))
+			} else {
+				s.log.Info("Scheduled scan completed successfully")
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add cron job: %w", err)
+		}
+		s.log.Info("Scheduled background scan", slog.String("schedule", s.cfg.Scan.CronSchedule))
+	} else {
 		s.log.Info("Background scanning is disabled")
-		return nil
 	}
 
-	// Add the scanning job
-	_, err := s.cron.AddFunc(s.cfg.Scan.CronSchedule, func() {
-		s.log.Info("Starting scheduled S3 scan")
-		if err := s.scanner.ScanBucket(ctx, s.cfg.S3.Bucket); err != nil {
-			s.log.Error("Scheduled scan failed", slog.String("error", err.Error()))
-		} else {
-			s.log.Info("Scheduled scan completed successfully")
+	for _, j := range s.jobs {
+		if _, err := s.cron.AddFunc(j.schedule, func() {
+			s.log.Info("Starting scheduled job", slog.String("job", j.name))
+			j.run(ctx)
+		}); err != nil {
+			return fmt.Errorf("failed to add %s job: %w", j.name, err)
 		}
-	})
-	if err != nil {
-		return fmt.Errorf("failed to add cron job: %w", err)
+		s.log.Info("Scheduled job", slog.String("job", j.name), slog.String("schedule", j.schedule))
 	}
 
-	s.log.Info("Starting scheduler", slog.String("schedule", s.cfg.Scan.CronSchedule))
+	if len(s.cron.Entries()) == 0 {
+		return nil
+	}
+	s.log.Info("Starting scheduler")
 	s.cron.Start()
 	return nil
 }
@@ -0,0 +1,27 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart_AcceptsSchedulesWithAndWithoutSeconds(t *testing.T) {
+	cfg := config.Config{Scan: config.ScanConfig{EnableBackgroundScan: true, CronSchedule: "0 0 2 * * *"}}
+	s := NewScheduler(cfg, nil, nil)
+	s.AddJob("trash purge", "0 3 * * *", func(context.Context) {})
+
+	require.NoError(t, s.Start(context.Background()))
+	defer s.Stop()
+	assert.Len(t, s.cron.Entries(), 2)
+}
+
+func TestStart_RejectsInvalidJobSchedule(t *testing.T) {
+	s := NewScheduler(config.Config{}, nil, nil)
+	s.AddJob("trash purge", "every night", func(context.Context) {})
+
+	assert.Error(t, s.Start(context.Background()))
+}
@@ -4,11 +4,18 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
+	"net/url"
+	"path"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/a-h/templ"
+	"github.com/sgaunet/s3xplorer/pkg/config"
+	"github.com/sgaunet/s3xplorer/pkg/dto"
+	"github.com/sgaunet/s3xplorer/pkg/preview"
 )
 
 const (
@@ -467,3 +474,429 @@ Skip to content link pattern (already implemented in SkipToContent()):
 
 ─────────────────────────────────────────────────────────────────────────────
 */
+
+// listingURL builds a bucket listing URL for a folder, page and sort order.
+// The default sort is omitted so plain folder links keep their existing form.
+func listingURL(folderPath string, page int, sortOpts dto.SortOptions) string {
+	return fmt.Sprintf("/?folder=%s&page=%d%s", url.QueryEscape(folderPath), page, sortOpts.QueryString())
+}
+
+// nextPageURL returns the URL of the listing page after the current one: the entries after its last entry.
+func nextPageURL(folderPath string, page int, sortOpts dto.SortOptions, folders, files []dto.S3Object) string {
+	var last *dto.S3Object
+	if len(files) > 0 {
+		last = &files[len(files)-1]
+	} else if len(folders) > 0 {
+		last = &folders[len(folders)-1]
+	}
+	return pageURL(folderPath, page+1, sortOpts, "after", last)
+}
+
+// previousPageURL returns the URL of the listing page before the current one: the entries before its
+// first entry. The first page needs no cursor.
+func previousPageURL(folderPath string, page int, sortOpts dto.SortOptions, folders, files []dto.S3Object) string {
+	var first *dto.S3Object
+	if len(folders) > 0 {
+		first = &folders[0]
+	} else if len(files) > 0 {
+		first = &files[0]
+	}
+	if page <= 2 {
+		first = nil
+	}
+	return pageURL(folderPath, page-1, sortOpts, "before", first)
+}
+
+// pageURL builds a listing URL positioned by the cursor of entry in param, or the first page
+// without an entry.
+func pageURL(folderPath string, page int, sortOpts dto.SortOptions, param string, entry *dto.S3Object) string {
+	if entry == nil {
+		return listingURL(folderPath, 1, sortOpts)
+	}
+	return listingURL(folderPath, page, sortOpts) + "&" + param + "=" + dto.CursorOf(*entry).Encode()
+}
+
+// ariaSort returns the aria-sort value of a column header for the current sort.
+func ariaSort(field dto.SortField, current dto.SortOptions) string {
+	if current.Field != field {
+		return "none"
+	}
+	if current.Descending() {
+		return "descending"
+	}
+	return "ascending"
+}
+
+// isPreviewable reports whether a file can be shown on the preview page.
+func isPreviewable(name string) bool {
+	return preview.DetectKind(name, "") != preview.KindUnsupported
+}
+
+// previewURL returns the preview page URL of a key.
+func previewURL(key string) string {
+	return "/preview?key=" + url.QueryEscape(key)
+}
+
+// shareURL returns the share form URL of a key.
+func shareURL(key string) string {
+	return "/share?key=" + url.QueryEscape(key)
+}
+
+// renameURL returns the rename form URL of a key.
+func renameURL(key string) string {
+	return "/rename?key=" + url.QueryEscape(key)
+}
+
+// moveURL returns the "move to..." form URL of a key.
+func moveURL(key string) string {
+	return "/move?key=" + url.QueryEscape(key)
+}
+
+// copyURL returns the "copy to..." form URL of a key.
+func copyURL(key string) string {
+	return "/copy?key=" + url.QueryEscape(key)
+}
+
+// objectDetailsURL returns the detail page URL of a key.
+func objectDetailsURL(key string) string {
+	return "/object?key=" + url.QueryEscape(key)
+}
+
+// storageClassOrStandard returns class, or STANDARD which S3 leaves out of HEAD answers.
+func storageClassOrStandard(class string) string {
+	if class == "" {
+		return "STANDARD"
+	}
+	return class
+}
+
+// orNone returns value, or "none" for a property S3 did not report.
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// sortedPairs returns the entries of m sorted by key, so maps render in a stable order.
+func sortedPairs(m map[string]string) [][2]string {
+	pairs := make([][2]string, 0, len(m))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		pairs = append(pairs, [2]string{k, m[k]})
+	}
+	return pairs
+}
+
+// tagSearchURL returns the search URL of the objects carrying a tag.
+func tagSearchURL(f dto.TagFilter) string {
+	return "/search?searchstr=" + url.QueryEscape(f.Query())
+}
+
+// tagsURL returns the tag summary URL, restricted to one tag key when key is set.
+func tagsURL(key string) string {
+	if key == "" {
+		return "/tags"
+	}
+	return "/tags?key=" + url.QueryEscape(key)
+}
+
+// versionsURL returns the version history URL of a key.
+func versionsURL(key string) string {
+	return "/versions?key=" + url.QueryEscape(key)
+}
+
+// downloadURL returns the download URL of a key.
+func downloadURL(key string) string {
+	return "/download?key=" + url.QueryEscape(key)
+}
+
+// versionDownloadURL returns the download URL of one version of a key.
+func versionDownloadURL(key, versionID string) string {
+	return "/download?key=" + url.QueryEscape(key) + "&version=" + url.QueryEscape(versionID)
+}
+
+// deletedURL returns the URL listing the deleted files of a folder.
+func deletedURL(folder string) string {
+	return "/deleted?folder=" + url.QueryEscape(folder)
+}
+
+// restoreURL returns the restore form URL of an archived object or a folder.
+func restoreURL(key string) string {
+	return "/restore?key=" + url.QueryEscape(key)
+}
+
+// bucketConfigURL returns the configuration page URL of a bucket.
+func bucketConfigURL(bucket string) string {
+	return "/buckets/config?bucket=" + url.QueryEscape(bucket)
+}
+
+// storageClassURL returns the storage class change form URL of an object or a folder.
+func storageClassURL(key string) string {
+	return "/storage-class?key=" + url.QueryEscape(key)
+}
+
+// lifecycleRuleURL returns the edit form URL of a lifecycle rule.
+func lifecycleRuleURL(id string) string {
+	return "/lifecycle/rule?id=" + url.QueryEscape(id)
+}
+
+// lifecycleDays formats the days of a lifecycle action for a form field, empty when the action is not set.
+func lifecycleDays(days int32) string {
+	if days <= 0 {
+		return ""
+	}
+	return strconv.Itoa(int(days))
+}
+
+// lifecycleCount formats the files a lifecycle action applies to.
+func lifecycleCount(objects, bytes int64) string {
+	if objects == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%d files, %s", objects, formatBytes(bytes))
+}
+
+// changesURL returns the What changed URL of the changes under prefix between scan jobs from and to.
+func changesURL(prefix string, from, to int32) string {
+	v := url.Values{}
+	v.Set("prefix", prefix)
+	v.Set("from", strconv.Itoa(int(from)))
+	v.Set("to", strconv.Itoa(int(to)))
+	return "/changes?" + v.Encode()
+}
+
+// previousScanID returns the scan before scans[i], scans being latest first, or 0 for the first scan.
+func previousScanID(scans []dto.ScanChanges, i int) int32 {
+	if i+1 < len(scans) {
+		return scans[i+1].ScanJobID
+	}
+	return 0
+}
+
+// changeSize formats the size of one side of an object change, empty when the side does not exist.
+func changeSize(size *int64) string {
+	if size == nil {
+		return ""
+	}
+	return formatBytes(*size)
+}
+
+// changeClass returns the text color of a kind of object change.
+func changeClass(change string) string {
+	switch change {
+	case dto.ChangeCreated:
+		return "text-green-600 dark:text-green-400"
+	case dto.ChangeDeleted:
+		return "text-red-600 dark:text-red-400"
+	}
+	return "text-blue-600 dark:text-blue-400"
+}
+
+// compareValues returns the query of a comparison, only files with difference when set.
+func compareValues(source, target dto.CompareSide, difference string) url.Values {
+	v := url.Values{}
+	v.Set("source_bucket", source.Bucket)
+	v.Set("source_prefix", source.Prefix)
+	v.Set("target_bucket", target.Bucket)
+	v.Set("target_prefix", target.Prefix)
+	if difference != "" {
+		v.Set("difference", difference)
+	}
+	return v
+}
+
+// compareURL returns the URL of a page of a comparison.
+func compareURL(source, target dto.CompareSide, difference string, page int) string {
+	v := compareValues(source, target, difference)
+	if page > 1 {
+		v.Set("page", strconv.Itoa(page))
+	}
+	return "/compare?" + v.Encode()
+}
+
+// compareExportURL returns the download URL of a comparison in format.
+func compareExportURL(source, target dto.CompareSide, difference, format string) string {
+	v := compareValues(source, target, difference)
+	v.Set("format", format)
+	return "/compare/export?" + v.Encode()
+}
+
+// exportableSearch reports whether the matches of a search can be exported: exports only filter by name.
+func exportableSearch(query string) bool {
+	_, filters := dto.ParseSearchQuery(query)
+	return len(filters) == 0
+}
+
+// catalogExportURL returns the link to the export of the files of the browsed bucket matching a search.
+func catalogExportURL(search, format string) string {
+	return "/export?" + url.Values{"search": {search}, "format": {format}}.Encode()
+}
+
+// differenceLabel returns the title of a comparison difference, empty meaning any difference.
+func differenceLabel(difference string) string {
+	switch difference {
+	case "":
+		return "All differences"
+	case dto.DifferenceMissingTarget:
+		return "Missing in target"
+	case dto.DifferenceMissingSource:
+		return "Missing in source"
+	case dto.DifferenceSize:
+		return "Size"
+	case dto.DifferenceETag:
+		return "ETag"
+	case dto.DifferenceStorageClass:
+		return "Storage class"
+	}
+	return difference
+}
+
+// deleteFolderURL returns the recursive delete preview URL of a folder.
+func deleteFolderURL(key string) string {
+	return "/delete/folder?key=" + url.QueryEscape(key)
+}
+
+// objectName returns the last path segment of a key, without the trailing slash of folders.
+func objectName(key string) string {
+	return path.Base(strings.TrimSuffix(key, "/"))
+}
+
+// parentFolder returns the folder containing key, which may itself be a folder.
+func parentFolder(key string) string {
+	dir := path.Dir(strings.TrimSuffix(key, "/"))
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	return dir + "/"
+}
+
+// jobLabel returns the title of a job kind.
+func jobLabel(kind string) string {
+	switch kind {
+	case dto.JobKindMove:
+		return "Move"
+	case dto.JobKindCopy:
+		return "Copy"
+	case dto.JobKindDelete:
+		return "Delete"
+	case dto.JobKindRestore:
+		return "Restore"
+	case dto.JobKindStorageClass:
+		return "Change storage class of"
+	}
+	return kind
+}
+
+// jobTarget describes where a job writes: the bucket, prefixed by the connection when not the default one.
+func jobTarget(job dto.Job) string {
+	if job.TargetBucket == "" {
+		return job.Bucket
+	}
+	if job.TargetConnection == "" || job.TargetConnection == config.DefaultConnection {
+		return job.TargetBucket
+	}
+	return job.TargetConnection + ": " + job.TargetBucket
+}
+
+// rootLabel returns key, or "/" for the bucket root.
+func rootLabel(key string) string {
+	if key == "" {
+		return "/"
+	}
+	return key
+}
+
+// jobReturnFolder returns the folder the job page links back to: the destination when it is
+// in the browsed bucket, else the folder of the source.
+func jobReturnFolder(job dto.Job) string {
+	if job.Destination == "" {
+		return parentFolder(job.Source)
+	}
+	if job.TargetBucket == "" || (job.TargetBucket == job.Bucket && jobTarget(job) == job.TargetBucket) {
+		return parentFolder(job.Destination)
+	}
+	return parentFolder(job.Source)
+}
+
+// formatCost formats a price in US dollars.
+func formatCost(cost float64) string {
+	return fmt.Sprintf("$%.2f", cost)
+}
+
+// formatCostDelta formats a price change in US dollars with its sign.
+func formatCostDelta(delta float64) string {
+	if delta < 0 {
+		return fmt.Sprintf("-$%.2f", -delta)
+	}
+	return fmt.Sprintf("+$%.2f", delta)
+}
+
+// formatBytes formats a byte count in human readable format.
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return strconv.FormatInt(size, 10) + " B"
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// expiryOption is one choice of the share expiry select.
+type expiryOption struct {
+	Value    string
+	Label    string
+	Selected bool
+}
+
+// shareExpiryPresets are the lifetimes offered in the share form, capped by the configured maximum.
+var shareExpiryPresets = []time.Duration{
+	time.Hour,
+	6 * time.Hour,
+	hoursPerDay * time.Hour,
+	3 * hoursPerDay * time.Hour,
+	hoursPerWeek * time.Hour,
+	30 * hoursPerDay * time.Hour,
+}
+
+// shareExpiryOptions returns the presets up to maxExpiry (always including maxExpiry itself),
+// with def preselected.
+func shareExpiryOptions(maxExpiry, def time.Duration) []expiryOption {
+	durations := make([]time.Duration, 0, len(shareExpiryPresets)+2)
+	for _, d := range shareExpiryPresets {
+		if d < maxExpiry {
+			durations = append(durations, d)
+		}
+	}
+	durations = append(durations, maxExpiry)
+	if !slices.Contains(durations, def) {
+		durations = append(durations, def)
+		slices.Sort(durations)
+	}
+
+	options := make([]expiryOption, len(durations))
+	for i, d := range durations {
+		options[i] = expiryOption{Value: d.String(), Label: formatExpiry(d), Selected: d == def}
+	}
+	return options
+}
+
+// formatExpiry renders a share lifetime as whole days, hours or minutes.
+func formatExpiry(d time.Duration) string {
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	switch {
+	case d >= hoursPerDay*time.Hour && d%(hoursPerDay*time.Hour) == 0:
+		return plural(int64(d/(hoursPerDay*time.Hour)), "day")
+	case d >= time.Hour && d%time.Hour == 0:
+		return plural(int64(d/time.Hour), "hour")
+	}
+	return plural(int64(d.Round(time.Minute)/time.Minute), "minute")
+}
@@ -116,17 +116,31 @@ function toggleAllCheckboxes(checked) {
   updateDeleteButton();
 }
 
-// Update delete button state based on checkbox selection
+// Update delete and archive button state based on checkbox selection
 function updateDeleteButton() {
   const checkboxes = document.querySelectorAll('.file-checkbox:checked');
+  const count = checkboxes.length;
+
   const deleteButton = document.getElementById('delete-button');
   const deleteCount = document.getElementById('delete-count');
+  if (deleteButton && deleteCount) {
+    deleteCount.textContent = count;
+    deleteButton.disabled = count === 0;
+  }
 
-  if (!deleteButton || !deleteCount) return;
+  const storageClassButton = document.getElementById('storage-class-button');
+  const storageClassCount = document.getElementById('storage-class-count');
+  if (storageClassButton && storageClassCount) {
+    storageClassCount.textContent = count;
+    storageClassButton.disabled = count === 0;
+  }
 
-  const count = checkboxes.length;
-  deleteCount.textContent = count;
-  deleteButton.disabled = count === 0;
+  const archiveButton = document.getElementById('archive-button');
+  const archiveCount = document.getElementById('archive-count');
+  if (archiveButton && archiveCount) {
+    archiveCount.textContent = count;
+    archiveButton.disabled = count === 0;
+  }
 
   // Update select-all checkbox state
   const selectAll = document.getElementById('select-all');
@@ -163,3 +177,468 @@ function submitDeleteForm() {
 
   form.submit();
 }
+
+// Submit archive form with selected file keys, using the format chosen for folder downloads
+function submitArchiveForm() {
+  const checkboxes = document.querySelectorAll('.file-checkbox:checked');
+  if (checkboxes.length === 0) return;
+
+  const form = document.getElementById('archive-form');
+  const container = document.getElementById('archive-keys-container');
+  const formatSelect = document.getElementById('archive-format');
+  container.innerHTML = ''; // Clear previous
+
+  if (formatSelect) {
+    document.getElementById('archive-form-format').value = formatSelect.value;
+  }
+
+  checkboxes.forEach(checkbox => {
+    const input = document.createElement('input');
+    input.type = 'hidden';
+    input.name = 'keys';
+    input.value = checkbox.dataset.key;
+    container.appendChild(input);
+  });
+
+  form.submit();
+}
+
+// Open the storage class form for the selected file keys
+function submitStorageClassForm() {
+  const checkboxes = document.querySelectorAll('.file-checkbox:checked');
+  if (checkboxes.length === 0) return;
+
+  const form = document.getElementById('storage-class-form');
+  const container = document.getElementById('storage-class-keys-container');
+  container.innerHTML = ''; // Clear previous
+
+  checkboxes.forEach(checkbox => {
+    const input = document.createElement('input');
+    input.type = 'hidden';
+    input.name = 'keys';
+    input.value = checkbox.dataset.key;
+    container.appendChild(input);
+  });
+
+  form.submit();
+}
+
+// Copy a share link to the clipboard, falling back to selecting it for manual copy
+function copyShareLink(button) {
+  const input = document.getElementById(button.dataset.target);
+  if (!input) return;
+
+  input.select();
+  if (navigator.clipboard) {
+    navigator.clipboard.writeText(input.value).then(() => {
+      button.textContent = 'Copied';
+      setTimeout(() => { button.textContent = 'Copy'; }, 2000);
+    });
+  }
+}
+
+// Resumable chunked uploads through the /api/uploads session API.
+// Parts are sent in parallel; the session id is kept in localStorage so selecting
+// the same file again after an interruption only sends the missing parts.
+// Falls back to the streamed form POST when the session API is unavailable.
+const UPLOAD_CONCURRENCY = 4;
+const UPLOAD_PART_RETRIES = 3;
+
+function uploadResumeKey(folder, file) {
+  return `s3xplorer-upload:${folder}${file.name}:${file.size}:${file.lastModified}`;
+}
+
+async function uploadJSON(url, options) {
+  const response = await fetch(url, options);
+  const body = response.status === 204 ? null : await response.json().catch(() => null);
+  if (!response.ok) {
+    const error = new Error((body && body.error) || `HTTP ${response.status}`);
+    error.status = response.status;
+    throw error;
+  }
+  return body;
+}
+
+async function openUploadSession(folder, file) {
+  const resumeKey = uploadResumeKey(folder, file);
+  const previous = localStorage.getItem(resumeKey);
+  if (previous) {
+    try {
+      const session = await uploadJSON(`/api/uploads/${encodeURIComponent(previous)}`);
+      if (session.status === 'active') return session;
+    } catch (e) {
+      // Unknown or expired session: start a new one
+    }
+    localStorage.removeItem(resumeKey);
+  }
+
+  const session = await uploadJSON('/api/uploads', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ folder: folder, filename: file.name, size: file.size, contentType: file.type }),
+  });
+  localStorage.setItem(resumeKey, session.id);
+  return session;
+}
+
+async function uploadSessionPart(session, file, number) {
+  const start = (number - 1) * session.partSize;
+  const chunk = file.slice(start, Math.min(start + session.partSize, file.size));
+  for (let attempt = 1; ; attempt++) {
+    try {
+      return await uploadJSON(`/api/uploads/${encodeURIComponent(session.id)}/parts/${number}`, {
+        method: 'PUT',
+        body: chunk,
+      });
+    } catch (e) {
+      if (attempt >= UPLOAD_PART_RETRIES || (e.status && e.status < 500)) throw e;
+    }
+  }
+}
+
+async function startChunkedUpload(event) {
+  const form = event.target;
+  const file = form.querySelector('input[type="file"]').files[0];
+  if (!file || !window.fetch) return true; // let the browser submit the form
+
+  event.preventDefault();
+  const folder = form.querySelector('input[name="folder"]').value;
+  const progress = document.getElementById('upload-progress');
+  const report = (text) => {
+    if (progress) {
+      progress.textContent = text;
+      progress.classList.remove('hidden');
+    }
+  };
+
+  let session;
+  try {
+    session = await openUploadSession(folder, file);
+  } catch (e) {
+    if (e.status === 503 || e.status === 404 || e.status === 405) {
+      form.submit(); // no database: stream the file through the form POST instead
+      return false;
+    }
+    report(`Upload failed: ${e.message}`);
+    return false;
+  }
+
+  const done = new Set(session.parts.map(p => p.number));
+  const pending = [];
+  for (let n = 1; n <= session.partCount; n++) {
+    if (!done.has(n)) pending.push(n);
+  }
+  report(`Uploading ${file.name}: ${done.size} of ${session.partCount} parts`);
+
+  try {
+    const worker = async () => {
+      while (pending.length > 0) {
+        const number = pending.shift();
+        await uploadSessionPart(session, file, number);
+        done.add(number);
+        report(`Uploading ${file.name}: ${done.size} of ${session.partCount} parts`);
+      }
+    };
+    await Promise.all(Array.from({ length: Math.min(UPLOAD_CONCURRENCY, pending.length) }, worker));
+    await uploadJSON(`/api/uploads/${encodeURIComponent(session.id)}/complete`, { method: 'POST' });
+  } catch (e) {
+    report(`Upload interrupted (${e.message}). Select the same file again to resume.`);
+    return false;
+  }
+
+  localStorage.removeItem(uploadResumeKey(folder, file));
+  window.location.href = `/?folder=${encodeURIComponent(folder)}&page=1`;
+  return false;
+}
+
+// Drag-and-drop batch uploads: many files and whole directory trees.
+// Relative paths are preserved as key prefixes under the current folder; conflicts with
+// the catalog are resolved (skip, overwrite or rename) before files are sent to /upload/batch.
+const BATCH_UPLOAD_CONCURRENCY = 3;
+let pendingBatch = [];
+
+function readDirectoryEntries(reader) {
+  return new Promise((resolve, reject) => reader.readEntries(resolve, reject));
+}
+
+async function collectEntry(entry, prefix, out) {
+  if (entry.isFile) {
+    const file = await new Promise((resolve, reject) => entry.file(resolve, reject));
+    out.push({ file: file, path: prefix + file.name });
+    return;
+  }
+  if (entry.isDirectory) {
+    const reader = entry.createReader();
+    // readEntries returns directory contents in batches until an empty batch
+    for (let batch = await readDirectoryEntries(reader); batch.length > 0; batch = await readDirectoryEntries(reader)) {
+      for (const child of batch) {
+        await collectEntry(child, prefix + entry.name + '/', out);
+      }
+    }
+  }
+}
+
+async function collectDroppedFiles(dataTransfer) {
+  const out = [];
+  const entries = Array.from(dataTransfer.items || [])
+    .map(item => item.webkitGetAsEntry && item.webkitGetAsEntry())
+    .filter(Boolean);
+  if (entries.length === 0) {
+    Array.from(dataTransfer.files).forEach(file => out.push({ file: file, path: file.name }));
+    return out;
+  }
+  for (const entry of entries) {
+    await collectEntry(entry, '', out);
+  }
+  return out;
+}
+
+function queueSelectedFiles(input) {
+  const items = Array.from(input.files).map(file => ({ file: file, path: file.webkitRelativePath || file.name }));
+  input.value = '';
+  prepareBatchUpload(items);
+}
+
+async function prepareBatchUpload(items) {
+  if (items.length === 0) return;
+  const dropzone = document.getElementById('upload-dropzone');
+  const conflictsPanel = document.getElementById('upload-conflicts');
+  pendingBatch = items;
+
+  let conflicts = [];
+  try {
+    const response = await uploadJSON('/api/uploads/conflicts', {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ folder: dropzone.dataset.folder, paths: items.map(i => i.path) }),
+    });
+    conflicts = response.conflicts || [];
+  } catch (e) {
+    showUploadSummary(`Cannot upload: ${e.message}`);
+    return;
+  }
+
+  const conflictPaths = new Set(conflicts.map(c => c.path));
+  pendingBatch.forEach(item => { item.conflict = conflictPaths.has(item.path); });
+  if (conflicts.length === 0) {
+    startBatchUpload();
+    return;
+  }
+
+  const list = document.getElementById('upload-conflict-list');
+  list.innerHTML = '';
+  conflicts.slice(0, 20).forEach(c => {
+    const li = document.createElement('li');
+    li.textContent = c.key;
+    list.appendChild(li);
+  });
+  if (conflicts.length > 20) {
+    const li = document.createElement('li');
+    li.textContent = `… and ${conflicts.length - 20} more`;
+    list.appendChild(li);
+  }
+  document.getElementById('upload-conflict-count').textContent = conflicts.length;
+  conflictsPanel.classList.remove('hidden');
+}
+
+function addQueueRow(item) {
+  const queue = document.getElementById('upload-queue');
+  const li = document.createElement('li');
+  li.className = 'flex items-center gap-4 py-2 text-sm';
+  const name = document.createElement('span');
+  name.className = 'upload-queue-name flex-1 text-gray-900 dark:text-white';
+  name.textContent = item.path;
+  name.title = item.path;
+  const progress = document.createElement('progress');
+  progress.max = item.file.size || 1;
+  progress.value = 0;
+  const status = document.createElement('span');
+  status.className = 'w-32 text-xs text-gray-600 dark:text-gray-400';
+  status.textContent = 'queued';
+  li.append(name, progress, status);
+  queue.appendChild(li);
+  queue.classList.remove('hidden');
+  return { progress: progress, status: status };
+}
+
+function sendBatchFile(item, folder, policy, row) {
+  return new Promise(resolve => {
+    const form = new FormData();
+    form.append('folder', folder);
+    form.append('conflict', policy);
+    form.append('path', item.path);
+    form.append('file', item.file, item.file.name);
+
+    const xhr = new XMLHttpRequest();
+    xhr.open('POST', '/upload/batch');
+    xhr.upload.onprogress = (e) => {
+      if (e.lengthComputable) {
+        row.progress.max = e.total;
+        row.progress.value = e.loaded;
+      }
+    };
+    xhr.onload = () => {
+      let result = { path: item.path, status: 'failed', error: `HTTP ${xhr.status}` };
+      try {
+        const body = JSON.parse(xhr.responseText);
+        if (body.items && body.items.length > 0) result = body.items[0];
+        else if (body.error) result.error = body.error;
+      } catch (e) {
+        // keep the HTTP status as the error
+      }
+      resolve(result);
+    };
+    xhr.onerror = () => resolve({ path: item.path, status: 'failed', error: 'network error' });
+    xhr.send(form);
+  });
+}
+
+async function startBatchUpload() {
+  const folder = document.getElementById('upload-dropzone').dataset.folder;
+  const checked = document.querySelector('input[name="upload-conflict"]:checked');
+  const policy = checked ? checked.value : 'skip';
+  document.getElementById('upload-conflicts').classList.add('hidden');
+  document.getElementById('upload-summary').classList.add('hidden');
+
+  const items = pendingBatch;
+  pendingBatch = [];
+  const results = [];
+  const queue = items.map(item => ({ item: item, row: addQueueRow(item) }));
+
+  const worker = async () => {
+    while (queue.length > 0) {
+      const { item, row } = queue.shift();
+      let result;
+      if (item.conflict && policy === 'skip') {
+        result = { path: item.path, status: 'skipped' }; // no need to send the bytes
+      } else {
+        row.status.textContent = 'uploading';
+        result = await sendBatchFile(item, folder, policy, row);
+      }
+      row.progress.value = row.progress.max;
+      row.status.textContent = result.status === 'renamed' ? `renamed to ${result.key.split('/').pop()}` :
+        result.status === 'failed' ? `failed: ${result.error}` : result.status;
+      results.push(result);
+    }
+  };
+  await Promise.all(Array.from({ length: Math.min(BATCH_UPLOAD_CONCURRENCY, queue.length) }, worker));
+
+  const count = (status) => results.filter(r => r.status === status).length;
+  showUploadSummary(`${count('uploaded')} uploaded, ${count('renamed')} renamed, ` +
+    `${count('skipped')} skipped, ${count('failed')} failed.`, true);
+}
+
+function showUploadSummary(text, withRefresh) {
+  const summary = document.getElementById('upload-summary');
+  summary.textContent = text + ' ';
+  if (withRefresh) {
+    const link = document.createElement('a');
+    link.href = window.location.href;
+    link.className = 'text-blue-600 dark:text-blue-400 hover:underline';
+    link.textContent = 'Refresh folder';
+    summary.appendChild(link);
+  }
+  summary.classList.remove('hidden');
+}
+
+document.addEventListener('DOMContentLoaded', () => {
+  const dropzone = document.getElementById('upload-dropzone');
+  if (!dropzone) return;
+  const panel = document.getElementById('upload-form');
+  const hasFiles = (e) => e.dataTransfer && Array.from(e.dataTransfer.types || []).includes('Files');
+
+  // Dragging files anywhere over the page opens the upload panel
+  document.addEventListener('dragenter', (e) => {
+    if (hasFiles(e)) panel.classList.remove('hidden');
+  });
+  dropzone.addEventListener('dragover', (e) => {
+    if (!hasFiles(e)) return;
+    e.preventDefault();
+    dropzone.classList.add('is-dragover');
+  });
+  dropzone.addEventListener('dragleave', () => dropzone.classList.remove('is-dragover'));
+  dropzone.addEventListener('drop', async (e) => {
+    e.preventDefault();
+    dropzone.classList.remove('is-dragover');
+    prepareBatchUpload(await collectDroppedFiles(e.dataTransfer));
+  });
+});
+
+// Background job progress page: poll the job until it finishes, then reload for the final state
+const JOB_POLL_INTERVAL_MS = 1000;
+
+function formatJobBytes(size) {
+  if (size < 1024) return `${size} B`;
+  let value = size / 1024;
+  let unit = 0;
+  while (value >= 1024 && unit < 5) {
+    value /= 1024;
+    unit++;
+  }
+  return `${value.toFixed(1)} ${'KMGTPE'[unit]}B`;
+}
+
+async function pollJob(status) {
+  try {
+    const response = await fetch(`/api/jobs/${status.dataset.jobId}`, { headers: { Accept: 'application/json' } });
+    if (response.ok) {
+      const job = await response.json();
+      if (job.status === 'completed' || job.status === 'failed') {
+        window.location.reload();
+        return;
+      }
+      const handled = job.doneItems + job.failedItems + job.skippedItems;
+      document.getElementById('job-progress').value = job.totalBytes > 0
+        ? Math.min(100, Math.floor(job.doneBytes * 100 / job.totalBytes))
+        : (job.totalItems > 0 ? Math.floor(handled * 100 / job.totalItems) : 0);
+      document.getElementById('job-counts').textContent =
+        `${job.doneItems} of ${job.totalItems} objects` +
+        (job.failedItems > 0 ? `, ${job.failedItems} failed` : '') +
+        (job.skippedItems > 0 ? `, ${job.skippedItems} skipped` : '') +
+        ` • ${formatJobBytes(job.doneBytes)} of ${formatJobBytes(job.totalBytes)} • ${job.status}`;
+    }
+  } catch (err) {
+    // Network hiccup: try again on the next tick
+  }
+  setTimeout(() => pollJob(status), JOB_POLL_INTERVAL_MS);
+}
+
+document.addEventListener('DOMContentLoaded', () => {
+  const status = document.getElementById('job-status');
+  if (status && status.dataset.finished !== 'true') {
+    setTimeout(() => pollJob(status), JOB_POLL_INTERVAL_MS);
+  }
+});
+
+// Restore notifications: ask the server for restores of the current user that completed, and list
+// them in the banner under the menu with a download link
+const RESTORE_POLL_INTERVAL_MS = 60000;
+
+async function pollRestoreNotifications(banner) {
+  try {
+    const response = await fetch('/api/restores/notifications', { method: 'POST', headers: { Accept: 'application/json' } });
+    if (response.ok) {
+      const restored = await response.json();
+      const list = banner.firstElementChild;
+      for (const req of restored) {
+        const line = document.createElement('p');
+        line.textContent = `${req.key} is restored. `;
+        const link = document.createElement('a');
+        link.href = `/download?key=${encodeURIComponent(req.key)}`;
+        link.className = 'font-medium hover:underline';
+        link.textContent = 'Download';
+        line.appendChild(link);
+        list.appendChild(line);
+      }
+      if (restored.length > 0) banner.classList.remove('hidden');
+    }
+  } catch (err) {
+    // Network hiccup: try again on the next tick
+  }
+  setTimeout(() => pollRestoreNotifications(banner), RESTORE_POLL_INTERVAL_MS);
+}
+
+document.addEventListener('DOMContentLoaded', () => {
+  const banner = document.getElementById('restore-notifications');
+  if (banner) pollRestoreNotifications(banner);
+});
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/url"
 	"os"
@@ -34,6 +35,15 @@ import (
 var ErrConfigFileNotProvided = errors.New("configuration file not provided")
 
 func main() {
+	// s3xplorer export writes the catalog without starting the web server
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Parse configuration
 	cfg, err := parseConfig()
 	if err != nil {
@@ -42,7 +52,7 @@ func main() {
 	}
 
 	// Initialize the logger
-	l := initTrace(cfg.LogLevel)
+	l := initTrace(cfg.LogLevel, os.Stdout)
 
 	// Handle SIGTERM/SIGINT
 	ctx, cancelFunc := context.WithCancel(context.Background())
@@ -70,14 +80,33 @@ func main() {
 
 		// Initialize services
 		dbService, scannerService, scheduler = initServices(cfg, s3Client, dbConn, l)
+		if err := checkInventory(ctx, cfg, scannerService, l); err != nil {
+			l.Error("Refusing to start", slog.String("error", err.Error()))
+			_ = dbConn.Close()
+			os.Exit(1) //nolint:gocritic // the database connection is closed above
+		}
 	}
 
 	// Create and start the web server immediately (handles nil dbService gracefully)
 	s := app.NewApp(cfg, s3Client, dbService)
 	s.SetLogger(l)
+	s.SetConnections(initConnectionClients(ctx, cfg, l))
 
 	// Start background processes after web server is running
 	if scannerService != nil && scheduler != nil {
+		if cfg.Trash.Enable {
+			scheduler.AddJob("trash purge", cfg.Trash.PurgeSchedule, s.PurgeExpiredTrash)
+		}
+		scheduler.AddJob("change history purge", cfg.Changes.PurgeSchedule, s.PurgeExpiredChanges)
+		if cfg.S3.EnableGlacierRestore {
+			scheduler.AddJob("restore poll", cfg.S3.RestorePollSchedule, s.PollRestores)
+		}
+		if cfg.Duplicates.Enable {
+			scheduler.AddJob("duplicates report", cfg.Duplicates.Schedule, s.ComputeDuplicates)
+		}
+		if cfg.Export.Enable {
+			scheduler.AddJob("catalog export", cfg.Export.Schedule, s.ExportCatalogs)
+		}
 		// Run initial scan in background to avoid blocking web server startup
 		go func() {
 			l.Info("Starting initial scan in background - web server is ready for health checks")
@@ -121,14 +150,23 @@ func initInfrastructure(ctx context.Context, cfg configapp.Config, l *slog.Logge
 		return nil, nil, fmt.Errorf("error initializing S3 client: %w", err)
 	}
 
-	dbConn, err := dbinit.InitializeDatabase(ctx, cfg.Database, l)
+	dbConn, err := openCatalog(ctx, cfg, l)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error initializing database: %w", err)
+		return nil, nil, err
 	}
 
 	return s3Client, dbConn, nil
 }
 
+// openCatalog initializes the catalog database and applies its migrations.
+func openCatalog(ctx context.Context, cfg configapp.Config, l *slog.Logger) (*sql.DB, error) {
+	dbConn, err := dbinit.InitializeDatabase(ctx, cfg.Database, l)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing database: %w", err)
+	}
+	return dbConn, nil
+}
+
 // initServices creates and configures all services.
 func initServices(
 	cfg configapp.Config, s3Client *s3.Client, dbConn *sql.DB, l *slog.Logger,
@@ -145,6 +183,22 @@ func initServices(
 	return dbService, scannerService, scheduler
 }
 
+// checkInventory returns an error when the latest inventory report of the configured bucket cannot be read
+// by the scanner, such as an ORC report, rather than failing every scan. Other errors are only logged.
+func checkInventory(ctx context.Context, cfg configapp.Config, scannerService *scanner.Service, l *slog.Logger) error {
+	if !cfg.Scan.Inventory.Enable || cfg.S3.Bucket == "" {
+		return nil
+	}
+	err := scannerService.CheckInventory(ctx, cfg.S3.Bucket)
+	if errors.Is(err, scanner.ErrUnsupportedInventory) {
+		return fmt.Errorf("scan.inventory: %w", err)
+	}
+	if err != nil {
+		l.Warn("Cannot check the inventory report", slog.String("bucket", cfg.S3.Bucket), slog.String("error", err.Error()))
+	}
+	return nil
+}
+
 // performInitialScan runs the initial bucket scan if enabled.
 func performInitialScan(ctx context.Context, cfg configapp.Config, scannerService *scanner.Service, l *slog.Logger) {
 	if !cfg.Scan.EnableInitialScan {
@@ -189,8 +243,8 @@ func SetupCloseHandler(_ context.Context, cancelFunc context.CancelFunc, log *sl
 	}()
 }
 
-// initTrace initializes the logger.
-func initTrace(debugLevel string) *slog.Logger {
+// initTrace initializes the logger writing to w.
+func initTrace(debugLevel string, w io.Writer) *slog.Logger {
 	handlerOptions := &slog.HandlerOptions{
 		Level: slog.LevelDebug,
 		// AddSource: true,
@@ -210,7 +264,7 @@ func initTrace(debugLevel string) *slog.Logger {
 		handlerOptions.Level = slog.LevelInfo
 	}
 
-	handler := slog.NewTextHandler(os.Stdout, handlerOptions)
+	handler := slog.NewTextHandler(w, handlerOptions)
 	// handler := slog.NewJSONHandler(os.Stdout, nil) // JSON format
 	logger := slog.New(handler)
 	return logger
@@ -251,6 +305,25 @@ func initS3Client(ctx context.Context, configApp configapp.Config) (*s3.Client,
 	}), nil
 }
 
+// initConnectionClients builds an S3 client for each extra connection of the configuration.
+// Connections that cannot be initialized are logged and left out.
+func initConnectionClients(ctx context.Context, cfg configapp.Config, l *slog.Logger) map[string]*s3.Client {
+	clients := make(map[string]*s3.Client, len(cfg.Connections))
+	for _, conn := range cfg.Connections {
+		if conn.Name == "" || conn.Name == configapp.DefaultConnection {
+			l.Warn("Ignoring connection without a name or named like the s3 section", slog.String("name", conn.Name))
+			continue
+		}
+		client, err := initS3Client(ctx, configapp.Config{S3: conn.S3Config()})
+		if err != nil {
+			l.Error("Failed to initialize connection", slog.String("name", conn.Name), slog.String("error", err.Error()))
+			continue
+		}
+		clients[conn.Name] = client
+	}
+	return clients
+}
+
 // GetAwsConfig returns an aws.Config based on the provided configuration.
 func GetAwsConfig(ctx context.Context, cfgApp configapp.Config) (aws.Config, error) {
 	// Initialize an empty config